@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"crypto/tls"
 	"expvar"
 	"flag"
@@ -10,10 +11,10 @@ import (
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
-	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
@@ -24,11 +25,13 @@ import (
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
 	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 
-	"github.com/kinecosystem/agora-common/headers"
-	"github.com/kinecosystem/agora-common/httpgateway"
+	"github.com/kinecosystem/agora-common/logging"
 	"github.com/kinecosystem/agora-common/metrics"
-	"github.com/kinecosystem/agora-common/protobuf/validation"
+	_ "github.com/kinecosystem/agora-common/metrics/memory"
+	_ "github.com/kinecosystem/agora-common/metrics/statsd"
+	"github.com/kinecosystem/agora-common/version"
 )
 
 // App is a long lived application that services network requests.
@@ -60,42 +63,77 @@ type App interface {
 	Stop()
 }
 
-var (
-	configPath = flag.String("config", "config.yaml", "configuration file path")
+// MetricsApp is an App that uses the metrics.Client configured by the metrics
+// section of BaseConfig.
+type MetricsApp interface {
+	App
 
-	osSigCh = make(chan os.Signal, 1)
-)
+	// SetMetricsClient provides the app with the configured metrics client. It
+	// is called before Init, and only if a metrics client type is configured.
+	//
+	// The client is closed by Run after the app has been stopped.
+	SetMetricsClient(client metrics.Client)
+}
 
-func init() {
-	signal.Notify(osSigCh, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGHUP)
+// HealthApp is an App that reports its own health, such as setting NOT_SERVING
+// during degradations.
+type HealthApp interface {
+	App
+
+	// SetHealthServer provides the app with the gRPC health server, which is
+	// also used by the readiness endpoints. It is called before Init.
+	//
+	// Note that the statuses of services with health checks configured via
+	// WithHealthCheck are periodically overwritten by the health checks.
+	SetHealthServer(serv *health.Server)
+}
+
+// DebugApp is an App that serves custom admin endpoints, such as cache dumps
+// or manual triggers, on the debug HTTP server.
+type DebugApp interface {
+	App
+
+	// SetDebugMux provides the app with the ServeMux of the debug HTTP server,
+	// which listens on debug_listen_address. It is called before Init.
+	//
+	// The /debug/pprof/, /debug/vars, /debug/version, /debug/loglevel,
+	// /healthz/live, /healthz/ready and /metrics paths are reserved.
+	SetDebugMux(mux *http.ServeMux)
 }
 
+// Run runs the app until it, or any of its servers, shuts down, or until the
+// process receives a shutdown signal.
+//
+// The -config flag and the signal handlers are only installed by Run, so that
+// packages that merely import app (such as app/client) do not affect the flags
+// or signal handling of the importing binary.
 func Run(app App, options ...Option) error {
+	configPath := flag.String("config", "config.yaml", "configuration file path")
 	flag.Parse()
 
-	opts := opts{
-		unaryServerInterceptors: []grpc.UnaryServerInterceptor{
-			validation.UnaryServerInterceptor(),
-			headers.UnaryServerInterceptor(),
-		},
-		streamServerInterceptors: []grpc.StreamServerInterceptor{
-			validation.StreamServerInterceptor(),
-			headers.StreamServerInterceptor(),
-		},
-	}
-	for _, o := range options {
-		o(&opts)
-	}
+	osSigCh := make(chan os.Signal, 1)
+	signal.Notify(osSigCh, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGHUP, syscall.SIGUSR1)
+	defer signal.Stop(osSigCh)
+
+	opts := newOpts(options...)
 
 	_ = viper.BindEnv("listen_address", "LISTEN_ADDRESS")
 	_ = viper.BindEnv("insecure_listen_address", "INSECURE_LISTEN_ADDRESS")
+	_ = viper.BindEnv("enable_insecure_listener", "ENABLE_INSECURE_LISTENER")
 	_ = viper.BindEnv("debug_listen_address", "DEBUG_LISTEN_ADDRESS")
 	_ = viper.BindEnv("log_level", "LOG_LEVEL")
 	_ = viper.BindEnv("log_type", "LOG_TYPE")
 	_ = viper.BindEnv("tls_certificate", "TLS_CERTIFICATE")
 	_ = viper.BindEnv("tls_private_key", "TLS_PRIVATE_KEY")
+	_ = viper.BindEnv("tls_client_ca", "TLS_CLIENT_CA")
+	_ = viper.BindEnv("tls_client_auth", "TLS_CLIENT_AUTH")
+	_ = viper.BindEnv("tls_reload_interval", "TLS_RELOAD_INTERVAL")
+	_ = viper.BindEnv("enable_reflection", "ENABLE_REFLECTION")
+	_ = viper.BindEnv("metrics.client_type", "METRICS_CLIENT_TYPE")
+	_ = viper.BindEnv("tracing.enabled", "TRACING_ENABLED")
+	_ = viper.BindEnv("tracing.endpoint", "TRACING_ENDPOINT")
 
-	logger := logrus.StandardLogger().WithField("type", "agora/app")
+	logger := logging.OrDefault(opts.logger).WithField("type", "agora/app")
 
 	// viper.ReadInConfig only returns ConfigFileNotFoundError if it has to search
 	// for a default config file because one hasn't been explicitly set. That is,
@@ -116,12 +154,30 @@ func Run(app App, options ...Option) error {
 	}
 
 	config := defaultConfig
-	if err := viper.Unmarshal(&config); err != nil {
+	config.EnableReflection = reflectionEnabledByDefault()
+	if err := unmarshalConfig(viper.GetViper(), &config); err != nil {
 		logger.WithError(err).Error("failed to unmarshal config")
 		os.Exit(1)
 	}
+	if err := config.validate(); err != nil {
+		logger.WithError(err).Error("invalid config")
+		os.Exit(1)
+	}
+
+	configureLogger(config, opts.metricsRegisterer(), logger)
+	if opts.logLevelCfg != nil {
+		go watchLogLevel(context.Background(), logger, opts.logLevelCfg, logrus.GetLevel())
+	}
 
-	configureLogger(config)
+	buildInfo := version.Get()
+	logger.WithFields(logging.Fields{
+		"version":    buildInfo.Version,
+		"commit":     buildInfo.Commit,
+		"build_time": buildInfo.BuildTime,
+		"go_version": buildInfo.GoVersion,
+	}).Info("starting")
+	logger.WithField("config", redactedConfig(config)).Info("effective config")
+	metrics.RegisterWith(opts.metricsRegisterer(), "", nil, version.NewCollector())
 
 	// We don't want to expose pprof/expvar publically, so we reset the default
 	// http ServeMux, which will have those installed due to the init() function
@@ -130,6 +186,8 @@ func Run(app App, options ...Option) error {
 	http.DefaultServeMux = http.NewServeMux()
 
 	debugHTTPMux := http.NewServeMux()
+	debugHTTPMux.Handle("/debug/version", version.Handler())
+	debugHTTPMux.Handle("/debug/loglevel", logLevelHandler(logger))
 	if config.EnableExpvar {
 		debugHTTPMux.Handle("/debug/vars", expvar.Handler())
 	}
@@ -141,7 +199,8 @@ func Run(app App, options ...Option) error {
 		debugHTTPMux.HandleFunc("/debug/pprof/trace", pprof.Trace)
 	}
 
-	if config.EnableExpvar || config.EnablePprof {
+	debugApp, isDebugApp := app.(DebugApp)
+	if config.EnableExpvar || config.EnablePprof || isDebugApp {
 		go func() {
 			for {
 				if err := http.ListenAndServe(config.DebugListenAddress, debugHTTPMux); err != nil {
@@ -154,77 +213,185 @@ func Run(app App, options ...Option) error {
 
 	var secureLis, insecureLis net.Listener
 	var transportCreds credentials.TransportCredentials
+	var certReloader *certReloader
 
-	insecureLis, err = net.Listen("tcp", config.InsecureListenAddress)
-	if err != nil {
-		logger.WithError(err).Errorf("failed to listen on %s", config.InsecureListenAddress)
-		os.Exit(1)
-	}
+	_, isHTTPApp := app.(HTTPApp)
+	gatewayEnabled := isHTTPApp || opts.httpGatewayEnabled || opts.grpcWebEnabled
 
-	if config.TLSCertificate != "" {
-		if config.TLSKey == "" {
-			logger.Error("tls key must be provided if certificate is specified")
+	insecureListenAddress := resolveInsecureListenAddress(config, gatewayEnabled)
+	if insecureListenAddress != "" {
+		insecureLis, err = net.Listen("tcp", insecureListenAddress)
+		if err != nil {
+			logger.WithError(err).Errorf("failed to listen on %s", insecureListenAddress)
 			os.Exit(1)
 		}
+	}
 
-		certBytes, err := LoadFile(config.TLSCertificate)
+	if config.TLSCertificate != "" {
+		var tlsConfig *tls.Config
+		tlsConfig, certReloader, err = newTLSConfig(logger, config.secureListenerConfig())
 		if err != nil {
-			logger.WithError(err).Error("failed to load tls certificate")
+			logger.WithError(err).Error("failed to configure tls")
 			os.Exit(1)
 		}
 
-		keyBytes, err := LoadFile(config.TLSKey)
+		transportCreds = credentials.NewTLS(tlsConfig)
+		secureLis, err = net.Listen("tcp", config.ListenAddress)
 		if err != nil {
-			logger.WithError(err).Error("failed to load tls key")
+			logger.WithError(err).Errorf("failed to listen on %s", config.ListenAddress)
 			os.Exit(1)
 		}
+	}
 
-		cert, err := tls.X509KeyPair(certBytes, keyBytes)
-		if err != nil {
-			logger.WithError(err).Error("invalid certificate/private key")
+	for _, l := range opts.listeners {
+		if err := l.listen(logger, config.Listeners); err != nil {
+			logger.WithError(err).Errorf("failed to configure listener %s", l.name)
 			os.Exit(1)
 		}
+	}
 
-		transportCreds = credentials.NewServerTLSFromCert(&cert)
-		secureLis, err = net.Listen("tcp", config.ListenAddress)
+	if secureLis == nil && insecureLis == nil {
+		logger.Error("no listeners configured, either tls or the insecure listener must be enabled")
+		os.Exit(1)
+	}
+
+	metricsClient, err := newMetricsClient(config.Metrics)
+	if err != nil {
+		logger.WithError(err).Error("failed to create metrics client")
+		os.Exit(1)
+	}
+	if metricsClient != nil {
+		defer metricsClient.Close()
+
+		if metricsApp, ok := app.(MetricsApp); ok {
+			metricsApp.SetMetricsClient(metricsClient)
+		} else {
+			logger.Warn("metrics client configured, but app does not implement MetricsApp")
+		}
+	}
+
+	if config.Tracing.Enabled {
+		tracerProvider, err := newTracerProvider(context.Background(), config.Tracing)
 		if err != nil {
-			logger.WithError(err).Errorf("failed to listen on %s", config.ListenAddress)
+			logger.WithError(err).Error("failed to configure tracing")
 			os.Exit(1)
 		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := tracerProvider.Shutdown(ctx); err != nil {
+				logger.WithError(err).Warn("failed to flush spans")
+			}
+		}()
+	}
+
+	healthServ := health.NewServer()
+	debugHTTPMux.Handle("/healthz/live", livenessHandler())
+	debugHTTPMux.Handle("/healthz/ready", healthHandler(healthServ))
+	if isDebugApp {
+		debugApp.SetDebugMux(debugHTTPMux)
 	}
 
-	if err := app.Init(config.AppConfig); err != nil {
+	if err := initApp(app, config, healthServ); err != nil {
 		logger.WithError(err).Error("failed to initialize application")
 		os.Exit(1)
 	}
 
-	secureServ := grpc.NewServer(
-		grpc.Creds(transportCreds),
-		grpc_middleware.WithUnaryServerChain(
-			append([]grpc.UnaryServerInterceptor{grpc_prometheus.UnaryServerInterceptor}, opts.unaryServerInterceptors...)...,
-		),
-		grpc_middleware.WithStreamServerChain(
-			append([]grpc.StreamServerInterceptor{grpc_prometheus.StreamServerInterceptor}, opts.streamServerInterceptors...)...,
-		),
-	)
-	insecureServ := grpc.NewServer(
-		grpc_middleware.WithUnaryServerChain(
-			append([]grpc.UnaryServerInterceptor{grpc_prometheus.UnaryServerInterceptor}, opts.unaryServerInterceptors...)...,
-		),
-		grpc_middleware.WithStreamServerChain(
-			append([]grpc.StreamServerInterceptor{grpc_prometheus.StreamServerInterceptor}, opts.streamServerInterceptors...)...,
-		),
+	// If a custom registry is configured, the gRPC server metrics are registered
+	// with it rather than the default (global) registry.
+	//
+	// Metrics are served in the OpenMetrics format (if requested) so that
+	// exemplars are exposed.
+	grpcMetrics := grpc_prometheus.DefaultServerMetrics
+	metricsHandler := promhttp.InstrumentMetricHandler(
+		prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}),
 	)
+	if opts.metricsRegistry != nil {
+		grpcMetrics = grpc_prometheus.NewServerMetrics()
+		metricsHandler = promhttp.HandlerFor(opts.metricsRegistry, promhttp.HandlerOpts{EnableOpenMetrics: true})
+	}
+
+	newServerOpts := newServerOptsFunc(config, grpcMetrics, opts.metricsRegisterer())
+	serverOpts := newServerOpts(opts.unaryServerInterceptors, opts.streamServerInterceptors)
+	secureConns, insecureConns := &connTracker{}, &connTracker{}
+	secureServ := grpc.NewServer(append(serverOpts, grpc.Creds(transportCreds), grpc.StatsHandler(secureConns))...)
+	insecureServ := grpc.NewServer(append(serverOpts, grpc.StatsHandler(insecureConns))...)
 	app.RegisterWithGRPC(secureServ)
 	app.RegisterWithGRPC(insecureServ)
-	grpc_prometheus.Register(secureServ)
-	grpc_prometheus.Register(insecureServ)
 
-	grpc_prometheus.EnableHandlingTimeHistogram(grpc_prometheus.WithHistogramBuckets(metrics.MinuteDistributionBuckets))
-	debugHTTPMux.Handle("/metrics", promhttp.Handler())
+	for _, l := range opts.listeners {
+		listenerOpts := append(newServerOpts(l.unaryServerInterceptors, l.streamServerInterceptors), grpc.StatsHandler(l.conns))
+		if l.creds != nil {
+			listenerOpts = append(listenerOpts, grpc.Creds(l.creds))
+		}
+		l.serv = grpc.NewServer(listenerOpts...)
+		l.register(l.serv)
+	}
+
+	if config.EnableReflection {
+		reflection.Register(secureServ)
+		reflection.Register(insecureServ)
+		for _, l := range opts.listeners {
+			reflection.Register(l.serv)
+		}
+	}
+	grpcMetrics.InitializeMetrics(secureServ)
+	grpcMetrics.InitializeMetrics(insecureServ)
+	for _, l := range opts.listeners {
+		grpcMetrics.InitializeMetrics(l.serv)
+	}
+	if opts.metricsRegistry != nil {
+		metrics.RegisterWith(opts.metricsRegistry, "", nil, grpcMetrics)
+	}
 
-	healthgrpc.RegisterHealthServer(secureServ, health.NewServer())
-	healthgrpc.RegisterHealthServer(insecureServ, health.NewServer())
+	debugHTTPMux.Handle("/metrics", metricsHandler)
+
+	if config.MetricsServer.ListenAddress != "" {
+		metricsServerHandler, err := newMetricsServerHandler(config.MetricsServer, metricsHandler)
+		if err != nil {
+			logger.WithError(err).Error("failed to configure metrics server")
+			os.Exit(1)
+		}
+
+		go func() {
+			for {
+				if err := http.ListenAndServe(config.MetricsServer.ListenAddress, metricsServerHandler); err != nil {
+					logger.WithError(err).Warn("Metrics HTTP server failed. Retrying in 5s...")
+				}
+				time.Sleep(5 * time.Second)
+			}
+		}()
+	}
+
+	enableRuntimeMetrics := config.EnableRuntimeMetrics
+	if opts.runtimeMetrics != nil {
+		enableRuntimeMetrics = *opts.runtimeMetrics
+	}
+	if enableRuntimeMetrics {
+		registerRuntimeMetrics(opts.metricsRegisterer())
+	}
+
+	healthgrpc.RegisterHealthServer(secureServ, healthServ)
+	healthgrpc.RegisterHealthServer(insecureServ, healthServ)
+	for _, l := range opts.listeners {
+		healthgrpc.RegisterHealthServer(l.serv, healthServ)
+	}
+
+	healthCheckStopCh := make(chan struct{})
+	defer close(healthCheckStopCh)
+	if len(opts.healthChecks) > 0 {
+		go runHealthChecks(logger, healthServ, opts.healthChecks, config.HealthCheckInterval, healthCheckStopCh)
+	}
+
+	tlsReloadStopCh := make(chan struct{})
+	defer close(tlsReloadStopCh)
+	certReloaders := listenerCertReloaders(certReloader, opts.listeners)
+	if config.TLSReloadInterval > 0 {
+		for _, r := range certReloaders {
+			go r.run(config.TLSReloadInterval, tlsReloadStopCh)
+		}
+	}
 
 	secureServShutdownCh := make(chan struct{})
 	inssecureServShutdownCh := make(chan struct{})
@@ -241,32 +408,49 @@ func Run(app App, options ...Option) error {
 		}()
 	}
 
-	go func() {
-		if err := insecureServ.Serve(insecureLis); err != nil {
-			logger.WithError(err).Error("grpc serve stopped")
-		} else {
-			logger.Info("grpc server stopped")
-		}
+	if insecureLis != nil {
+		go func() {
+			if err := insecureServ.Serve(insecureLis); err != nil {
+				logger.WithError(err).Error("grpc serve stopped")
+			} else {
+				logger.Info("grpc server stopped")
+			}
 
-		close(inssecureServShutdownCh)
-	}()
+			close(inssecureServShutdownCh)
+		}()
+	}
 
-	if opts.httpGatewayEnabled {
-		go func() {
-			cc, err := grpc.Dial(
-				insecureLis.Addr().String(),
-				grpc.WithInsecure(),
-				grpc.WithDefaultCallOptions(grpc.ForceCodec(&httpgateway.BinaryCodec{})),
-			)
-			if err != nil {
-				logger.WithError(err).Errorf("failed to dial %s", insecureLis.Addr().String())
-				return
+	listenerShutdownCh := make(chan string, len(opts.listeners))
+	for _, l := range opts.listeners {
+		go func(l *listener) {
+			if err := l.serv.Serve(l.lis); err != nil {
+				logger.WithError(err).WithField("listener", l.name).Error("grpc serve stopped")
+			} else {
+				logger.WithField("listener", l.name).Info("grpc server stopped")
 			}
 
-			m := httpgateway.New(secureServ, cc, opts.httpGatewayOptions...)
-			if err := m.ListenAndServeHTTP(config.HTTPGatewayAddress); err != nil {
-				logger.WithError(err).Warn("failed to serve")
-				return
+			listenerShutdownCh <- l.name
+		}(l)
+	}
+
+	var gatewayServer *http.Server
+	if gatewayEnabled {
+		handler, conns, err := newHTTPGatewayHandler(context.Background(), app, opts, secureServ, insecureLis.Addr().String(), healthServ)
+		if err != nil {
+			logger.WithError(err).Error("failed to create http gateway")
+			os.Exit(1)
+		}
+		for _, cc := range conns {
+			defer cc.Close()
+		}
+
+		gatewayServer = &http.Server{
+			Addr:    config.HTTPGatewayAddress,
+			Handler: handler,
+		}
+		go func() {
+			if err := gatewayServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.WithError(err).Warn("failed to serve http gateway")
 			}
 		}()
 	}
@@ -275,25 +459,67 @@ func Run(app App, options ...Option) error {
 	//    1. OS Signal telling us to shutdown
 	//    2. The gRPC Server has shutdown (for whatever reason)
 	//    3. The application has shutdown (for whatever reason)
-	select {
-	case <-osSigCh:
-		logger.Info("interrupt received, shutting down")
-	case <-secureServShutdownCh:
-		logger.Info("secure grpc server shutdown")
-	case <-inssecureServShutdownCh:
-		logger.Info("insecure grpc server shutdown")
-	case <-app.ShutdownChan():
-		logger.Info("app shutdown")
+	//
+	// SIGHUP reloads the TLS certificates and runs the reload hooks, and
+	// SIGUSR1 dumps the goroutine stacks, rather than shutting down.
+	for {
+		select {
+		case sig := <-osSigCh:
+			switch sig {
+			case syscall.SIGHUP:
+				logger.Info("hangup received, reloading")
+				for _, r := range certReloaders {
+					r.reloadAndLog()
+				}
+				runReloadHooks(logger)
+				continue
+			case syscall.SIGUSR1:
+				dumpGoroutines(logger)
+				continue
+			}
+			logger.Info("interrupt received, shutting down")
+		case <-secureServShutdownCh:
+			logger.Info("secure grpc server shutdown")
+		case <-inssecureServShutdownCh:
+			logger.Info("insecure grpc server shutdown")
+		case name := <-listenerShutdownCh:
+			logger.WithField("listener", name).Info("grpc server shutdown")
+		case <-app.ShutdownChan():
+			logger.Info("app shutdown")
+		}
+
+		break
 	}
 
+	drainer := newDrainer(logger, opts.metricsRegisterer(), config.ShutdownDrainTimeout)
 	shutdownCh := make(chan struct{})
 	go func() {
 		// Both the gRPC server and the application should have idempotent
 		// shutdown methods, so it's fine call them both, regardless of the
 		// shutdown condition.
-		secureServ.GracefulStop()
-		insecureServ.GracefulStop()
+		if gatewayServer != nil {
+			_ = gatewayServer.Close()
+		}
+		var wg sync.WaitGroup
+		wg.Add(2 + len(opts.listeners))
+		go func() {
+			defer wg.Done()
+			drainer.drain("secure", secureServ, secureConns)
+		}()
+		go func() {
+			defer wg.Done()
+			drainer.drain("insecure", insecureServ, insecureConns)
+		}()
+		for _, l := range opts.listeners {
+			go func(l *listener) {
+				defer wg.Done()
+				drainer.drain(l.name, l.serv, l.conns)
+			}(l)
+		}
+		wg.Wait()
+
 		app.Stop()
+		runShutdownHooks(logger, config.ShutdownHookTimeout)
 
 		close(shutdownCh)
 	}()
@@ -306,32 +532,59 @@ func Run(app App, options ...Option) error {
 	}
 }
 
+// resolveInsecureListenAddress returns the address of the plaintext listener, or
+// an empty string if it is disabled.
+//
+// The HTTP gateway forwards requests to the plaintext listener, so if it is
+// disabled, an ephemeral loopback listener is used for the gateway instead.
+func resolveInsecureListenAddress(config BaseConfig, gatewayEnabled bool) string {
+	switch {
+	case config.EnableInsecureListener:
+		return config.InsecureListenAddress
+	case gatewayEnabled:
+		return "localhost:0"
+	default:
+		return ""
+	}
+}
+
+// newMetricsClient creates the metrics client specified by the config, or nil if
+// no client type is configured.
+func newMetricsClient(config MetricsConfig) (metrics.Client, error) {
+	if config.ClientType == "" {
+		return nil, nil
+	}
+
+	return metrics.CreateClient(config.ClientType, &metrics.ClientConfig{
+		Namespace:  config.Namespace,
+		GlobalTags: config.GlobalTags,
+		Address:    config.Address,
+		SampleRate: config.SampleRate,
+	})
+}
+
+// registerRuntimeMetrics registers the Go runtime and process collectors. The
+// default registerer already includes them, in which case this is a no-op.
+func registerRuntimeMetrics(registerer prometheus.Registerer) {
+	metrics.RegisterWith(registerer, "", nil, prometheus.NewGoCollector())
+	metrics.RegisterWith(registerer, "", nil, prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
+}
+
 type prometheusLogger struct {
 	warnCounter  prometheus.Counter
 	errorCounter prometheus.Counter
 }
 
-func newPrometheusLogger() *prometheusLogger {
+func newPrometheusLogger(registerer prometheus.Registerer) *prometheusLogger {
 	l := &prometheusLogger{}
-	l.warnCounter = prometheus.NewCounter(prometheus.CounterOpts{
+	l.warnCounter = metrics.RegisterWith(registerer, "", nil, prometheus.NewCounter(prometheus.CounterOpts{
 		Name:      "logging_warns",
 		Namespace: "agora",
-	})
-	if err := prometheus.Register(l.warnCounter); err != nil {
-		if e, ok := err.(prometheus.AlreadyRegisteredError); ok {
-			l.warnCounter = e.ExistingCollector.(prometheus.Counter)
-		}
-	}
-
-	l.errorCounter = prometheus.NewCounter(prometheus.CounterOpts{
+	})).(prometheus.Counter)
+	l.errorCounter = metrics.RegisterWith(registerer, "", nil, prometheus.NewCounter(prometheus.CounterOpts{
 		Name:      "logging_errors",
 		Namespace: "agora",
-	})
-	if err := prometheus.Register(l.errorCounter); err != nil {
-		if e, ok := err.(prometheus.AlreadyRegisteredError); ok {
-			l.errorCounter = e.ExistingCollector.(prometheus.Counter)
-		}
-	}
+	})).(prometheus.Counter)
 
 	return l
 }
@@ -354,7 +607,7 @@ func (p *prometheusLogger) Fire(e *logrus.Entry) error {
 	return nil
 }
 
-func configureLogger(config BaseConfig) {
+func configureLogger(config BaseConfig, registerer prometheus.Registerer, log logging.Logger) {
 	switch strings.ToLower(config.LogType) {
 	case "human":
 		// The default formatter for logrus is 'human' readable.
@@ -369,9 +622,9 @@ func configureLogger(config BaseConfig) {
 	if err != nil {
 		logrus.StandardLogger().WithField("log_level", config.LogLevel).Warn("unknown log level, ignoring")
 	} else {
-		logrus.SetLevel(level)
+		setLogLevel(log, level)
 	}
 
 	logrus.SetOutput(os.Stdout)
-	logrus.StandardLogger().Hooks.Add(newPrometheusLogger())
+	logrus.StandardLogger().Hooks.Add(newPrometheusLogger(registerer))
 }
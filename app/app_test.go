@@ -0,0 +1,64 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kinecosystem/agora-common/metrics/memory"
+)
+
+func TestRegisterRuntimeMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	registerRuntimeMetrics(registry)
+	registerRuntimeMetrics(registry)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	names := make(map[string]bool)
+	for _, f := range families {
+		names[f.GetName()] = true
+	}
+	assert.True(t, names["go_goroutines"])
+	assert.True(t, names["go_memstats_alloc_bytes"])
+	assert.True(t, names["process_cpu_seconds_total"])
+
+	// The default registerer already includes the runtime collectors.
+	registerRuntimeMetrics(prometheus.DefaultRegisterer)
+}
+
+func TestNewMetricsClient(t *testing.T) {
+	client, err := newMetricsClient(MetricsConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, client)
+
+	_, err = newMetricsClient(MetricsConfig{ClientType: "unknown"})
+	assert.Error(t, err)
+
+	client, err = newMetricsClient(MetricsConfig{
+		ClientType: memory.ClientType,
+		Namespace:  "test",
+		GlobalTags: []string{"service:test"},
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	require.NoError(t, client.Count("metric", 1, nil))
+	records := client.(*memory.Client).CountRecords()
+	require.Len(t, records, 1)
+	assert.Equal(t, "test_metric", records[0].Name)
+	assert.Equal(t, []string{"service:test"}, records[0].Tags)
+}
+
+func TestResolveInsecureListenAddress(t *testing.T) {
+	config := defaultConfig
+	assert.Equal(t, "localhost:8086", resolveInsecureListenAddress(config, false))
+	assert.Equal(t, "localhost:8086", resolveInsecureListenAddress(config, true))
+
+	config.EnableInsecureListener = false
+	assert.Equal(t, "", resolveInsecureListenAddress(config, false))
+	assert.Equal(t, "localhost:0", resolveInsecureListenAddress(config, true))
+}
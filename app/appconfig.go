@@ -0,0 +1,118 @@
+package app
+
+import (
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+// Get returns the value of the key, and whether or not it is set. Keys of
+// nested values are separated by '.' (e.g. cache.size).
+//
+// Note that keys are case insensitive, as viper lower cases them.
+func (c Config) Get(key string) (interface{}, bool) {
+	var value interface{} = map[string]interface{}(c)
+	for _, part := range strings.Split(strings.ToLower(key), ".") {
+		switch m := value.(type) {
+		case map[string]interface{}:
+			value = m[part]
+		case Config:
+			value = m[part]
+		case map[interface{}]interface{}:
+			value = m[part]
+		default:
+			return nil, false
+		}
+
+		if value == nil {
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+// GetString returns the value of the key as a string, or the provided default
+// if the key is not set or cannot be converted.
+func (c Config) GetString(key string, defaultValue string) string {
+	var s string
+	if !c.getAs(key, &s) {
+		return defaultValue
+	}
+	return s
+}
+
+// GetInt returns the value of the key as an int, or the provided default if
+// the key is not set or cannot be converted.
+func (c Config) GetInt(key string, defaultValue int) int {
+	var i int
+	if !c.getAs(key, &i) {
+		return defaultValue
+	}
+	return i
+}
+
+// GetDuration returns the value of the key as a time.Duration, or the provided
+// default if the key is not set or cannot be converted. Durations may be
+// specified as either Go duration strings (e.g. 1m30s) or ISO-8601 durations
+// (e.g. PT1M30S).
+func (c Config) GetDuration(key string, defaultValue time.Duration) time.Duration {
+	var d time.Duration
+	if !c.getAs(key, &d) {
+		return defaultValue
+	}
+	return d
+}
+
+// DecodeInto decodes the config into target, which must be a pointer to a
+// struct (with mapstructure tags) or map. Keys that do not map to a field of
+// target result in an error, so that typos are not silently ignored.
+//
+// If validate is non-nil, it is called once the config has been decoded, and
+// any error it returns is returned by DecodeInto.
+func (c Config) DecodeInto(target interface{}, validate func() error) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       DecodeHook,
+		ErrorUnused:      true,
+		WeaklyTypedInput: true,
+		Result:           target,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create decoder")
+	}
+
+	if err := decoder.Decode(map[string]interface{}(c)); err != nil {
+		return errors.Wrap(err, "failed to decode app config")
+	}
+
+	if validate != nil {
+		if err := validate(); err != nil {
+			return errors.Wrap(err, "invalid app config")
+		}
+	}
+
+	return nil
+}
+
+// getAs decodes the value of the key into target, returning whether or not the
+// key is set and could be decoded. Values are weakly typed, since values
+// provided by environment variables are always strings.
+func (c Config) getAs(key string, target interface{}) bool {
+	value, ok := c.Get(key)
+	if !ok {
+		return false
+	}
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       DecodeHook,
+		WeaklyTypedInput: true,
+		Result:           target,
+	})
+	if err != nil {
+		return false
+	}
+
+	return decoder.Decode(value) == nil
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Get(t *testing.T) {
+	config := Config{
+		"name":     "agora",
+		"count":    "10",
+		"size":     5,
+		"interval": "PT1M30S",
+		"timeout":  "250ms",
+		"cache": map[string]interface{}{
+			"ttl":  "10s",
+			"size": 100,
+		},
+		"yaml": map[interface{}]interface{}{
+			"name": "nested",
+		},
+	}
+
+	assert.Equal(t, "agora", config.GetString("name", "default"))
+	assert.Equal(t, "agora", config.GetString("NAME", "default"))
+	assert.Equal(t, "5", config.GetString("size", "default"))
+	assert.Equal(t, "nested", config.GetString("yaml.name", "default"))
+	assert.Equal(t, "default", config.GetString("missing", "default"))
+	assert.Equal(t, "default", config.GetString("cache", "default"))
+
+	assert.Equal(t, 10, config.GetInt("count", 1))
+	assert.Equal(t, 5, config.GetInt("size", 1))
+	assert.Equal(t, 100, config.GetInt("cache.size", 1))
+	assert.Equal(t, 1, config.GetInt("name", 1))
+	assert.Equal(t, 1, config.GetInt("cache.missing.size", 1))
+
+	assert.Equal(t, 90*time.Second, config.GetDuration("interval", time.Second))
+	assert.Equal(t, 250*time.Millisecond, config.GetDuration("timeout", time.Second))
+	assert.Equal(t, 10*time.Second, config.GetDuration("cache.ttl", time.Second))
+	assert.Equal(t, time.Second, config.GetDuration("name", time.Second))
+	assert.Equal(t, time.Second, config.GetDuration("missing", time.Second))
+
+	var nilConfig Config
+	assert.Equal(t, "default", nilConfig.GetString("name", "default"))
+}
+
+func TestConfig_DecodeInto(t *testing.T) {
+	type cacheConfig struct {
+		TTL  time.Duration `mapstructure:"ttl"`
+		Size int           `mapstructure:"size"`
+	}
+	type appConfig struct {
+		Name  string      `mapstructure:"name"`
+		Cache cacheConfig `mapstructure:"cache"`
+	}
+
+	config := Config{
+		"name": "agora",
+		"cache": map[string]interface{}{
+			"ttl":  "PT10S",
+			"size": "100",
+		},
+	}
+
+	var decoded appConfig
+	require.NoError(t, config.DecodeInto(&decoded, nil))
+	assert.Equal(t, appConfig{
+		Name: "agora",
+		Cache: cacheConfig{
+			TTL:  10 * time.Second,
+			Size: 100,
+		},
+	}, decoded)
+
+	validationErr := errors.New("size too large")
+	err := config.DecodeInto(&decoded, func() error {
+		if decoded.Cache.Size > 10 {
+			return validationErr
+		}
+		return nil
+	})
+	assert.Equal(t, validationErr, errors.Cause(err))
+
+	config["unknown"] = true
+	assert.Error(t, config.DecodeInto(&appConfig{}, nil))
+}
@@ -0,0 +1,189 @@
+// Package client provides a standard way of dialing other agora services, so
+// that outbound connections are as uniform as the inbound connections of
+// app.Run.
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+
+	"github.com/kinecosystem/agora-common/app"
+	"github.com/kinecosystem/agora-common/headers"
+	"github.com/kinecosystem/agora-common/metrics/grpcclient"
+	"github.com/kinecosystem/agora-common/retry"
+	"github.com/kinecosystem/agora-common/retry/backoff"
+)
+
+// Config contains the configuration of a connection to another service. It is
+// intended to be decoded from the app's configuration (see app.Config).
+type Config struct {
+	// Address is the target of the connection. It is required.
+	Address string `mapstructure:"address"`
+
+	// TLS configures whether or not the connection uses TLS.
+	TLS bool `mapstructure:"tls"`
+	// TLSCA is an optional URL that specifies a bundle of PEM encoded CA
+	// certificates used to verify the server. If empty, the system roots are
+	// used. URLs are loaded with app.LoadFile.
+	TLSCA string `mapstructure:"tls_ca"`
+	// TLSCertificate and TLSKey are optional URLs that specify the client
+	// certificate, for servers that require mutual TLS. URLs are loaded with
+	// app.LoadFile.
+	TLSCertificate string `mapstructure:"tls_certificate"`
+	TLSKey         string `mapstructure:"tls_private_key"`
+	// TLSServerName overrides the server name used to verify the server's
+	// certificate.
+	TLSServerName string `mapstructure:"tls_server_name"`
+
+	// RetryMethods are the full names of the unary methods that are retried
+	// if they fail with codes.Unavailable, such as
+	// "/grpc.health.v1.Health/Check". Methods are only retried if they are
+	// listed, as not every RPC is safe to retry.
+	RetryMethods []string `mapstructure:"retry_methods"`
+	// MaxAttempts is the maximum number of attempts of the retried methods. A
+	// value of 1 disables retries. If zero, DefaultMaxAttempts is used.
+	MaxAttempts uint `mapstructure:"max_attempts"`
+	// RetryBaseDelay is the base delay of the exponential backoff between
+	// attempts. If zero, DefaultRetryBaseDelay is used.
+	RetryBaseDelay time.Duration `mapstructure:"retry_base_delay"`
+	// RetryMaxDelay is the maximum delay between attempts. If zero,
+	// DefaultRetryMaxDelay is used.
+	RetryMaxDelay time.Duration `mapstructure:"retry_max_delay"`
+}
+
+// Defaults of the retry configuration.
+const (
+	DefaultMaxAttempts    = 3
+	DefaultRetryBaseDelay = 100 * time.Millisecond
+	DefaultRetryMaxDelay  = 2 * time.Second
+)
+
+// Dial creates a client connection to the configured service, with the
+// standard interceptors installed. In execution order, they are:
+//
+//  1. The client metrics and tracing interceptors (see grpcclient.Dial).
+//  2. The header propagation interceptors (see headers.UnaryClientInterceptor).
+//  3. A retry interceptor, which retries the configured unary RPCs (see
+//     Config.RetryMethods) that fail with codes.Unavailable.
+//
+// Any interceptors provided in opts are executed after the standard
+// interceptors.
+func Dial(ctx context.Context, config Config, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	if config.Address == "" {
+		return nil, errors.New("address must be provided")
+	}
+
+	creds := grpc.WithInsecure()
+	if config.TLS {
+		tlsConfig, err := newTLSConfig(config)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to configure tls")
+		}
+		creds = grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))
+	}
+
+	dialOpts := append(grpcclient.DialOptions(),
+		creds,
+		grpc.WithChainUnaryInterceptor(
+			headers.UnaryClientInterceptor(),
+			retryUnaryClientInterceptor(config),
+		),
+		grpc.WithChainStreamInterceptor(headers.StreamClientInterceptor()),
+	)
+
+	cc, err := grpc.DialContext(ctx, config.Address, append(dialOpts, opts...)...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to dial %s", config.Address)
+	}
+	return cc, nil
+}
+
+func newTLSConfig(config Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName: config.TLSServerName,
+	}
+
+	if config.TLSCA != "" {
+		caBytes, err := app.LoadFile(config.TLSCA)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load tls ca")
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caBytes) {
+			return nil, errors.New("tls ca does not contain any valid certificates")
+		}
+	}
+
+	if config.TLSCertificate != "" || config.TLSKey != "" {
+		certBytes, err := app.LoadFile(config.TLSCertificate)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load tls certificate")
+		}
+		keyBytes, err := app.LoadFile(config.TLSKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load tls key")
+		}
+
+		cert, err := tls.X509KeyPair(certBytes, keyBytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid tls certificate or key")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// retryUnaryClientInterceptor returns a grpc.UnaryClientInterceptor that
+// retries the configured RPCs that fail with codes.Unavailable, with
+// exponential backoff. Retries stop once the RPC's context is done.
+func retryUnaryClientInterceptor(config Config) grpc.UnaryClientInterceptor {
+	retryMethods := make(map[string]struct{}, len(config.RetryMethods))
+	for _, m := range config.RetryMethods {
+		retryMethods[m] = struct{}{}
+	}
+
+	maxAttempts := config.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	baseDelay := config.RetryBaseDelay
+	if baseDelay <= 0 {
+		baseDelay = DefaultRetryBaseDelay
+	}
+	maxDelay := config.RetryMaxDelay
+	if maxDelay <= 0 {
+		maxDelay = DefaultRetryMaxDelay
+	}
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := retryMethods[method]; !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		_, err := retry.RetryWithContext(
+			ctx,
+			func(ctx context.Context) error {
+				return invoker(ctx, method, req, reply, cc, opts...)
+			},
+			retry.Limit(maxAttempts),
+			retry.RetriableGRPCCodes(codes.Unavailable),
+			retry.BackoffWithJitterWithContext(ctx, backoff.BinaryExponential(baseDelay), maxDelay, 0.1),
+		)
+		if err == context.Canceled || err == context.DeadlineExceeded {
+			// The context was done while backing off, so the error is not from
+			// the RPC itself.
+			return status.FromContextError(err).Err()
+		}
+		return err
+	}
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+type flakyHealthServer struct {
+	healthpb.UnimplementedHealthServer
+
+	mu       sync.Mutex
+	failures int
+	calls    int
+}
+
+func (s *flakyHealthServer) Check(_ context.Context, _ *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls++
+	if s.calls <= s.failures {
+		return nil, status.Error(codes.Unavailable, "unavailable")
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func startServer(t *testing.T, healthServ healthpb.HealthServer) (addr string, stop func()) {
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	serv := grpc.NewServer()
+	healthpb.RegisterHealthServer(serv, healthServ)
+	go func() {
+		_ = serv.Serve(lis)
+	}()
+
+	return lis.Addr().String(), serv.Stop
+}
+
+func TestDial_Retries(t *testing.T) {
+	healthServ := &flakyHealthServer{failures: 2}
+	addr, stop := startServer(t, healthServ)
+	defer stop()
+
+	cc, err := Dial(context.Background(), Config{
+		Address:        addr,
+		RetryMethods:   []string{"/grpc.health.v1.Health/Check"},
+		RetryBaseDelay: time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer cc.Close()
+
+	resp, err := healthpb.NewHealthClient(cc).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+	assert.Equal(t, 3, healthServ.calls)
+
+	// Retries are bound by the configured attempts.
+	healthServ.calls = 0
+	cc, err = Dial(context.Background(), Config{
+		Address:      addr,
+		RetryMethods: []string{"/grpc.health.v1.Health/Check"},
+		MaxAttempts:  1,
+	})
+	require.NoError(t, err)
+	defer cc.Close()
+
+	_, err = healthpb.NewHealthClient(cc).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 1, healthServ.calls)
+}
+
+func TestDial_RetriesOptIn(t *testing.T) {
+	healthServ := &flakyHealthServer{failures: 2}
+	addr, stop := startServer(t, healthServ)
+	defer stop()
+
+	// Methods that are not configured are not retried.
+	cc, err := Dial(context.Background(), Config{
+		Address:        addr,
+		RetryMethods:   []string{"/grpc.health.v1.Health/Watch"},
+		RetryBaseDelay: time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer cc.Close()
+
+	_, err = healthpb.NewHealthClient(cc).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 1, healthServ.calls)
+}
+
+func TestDial_Invalid(t *testing.T) {
+	_, err := Dial(context.Background(), Config{})
+	assert.Error(t, err)
+
+	_, err = Dial(context.Background(), Config{
+		Address: "localhost:0",
+		TLS:     true,
+		TLSCA:   "file:///does/not/exist",
+	})
+	assert.Error(t, err)
+
+	_, err = Dial(context.Background(), Config{
+		Address:        "localhost:0",
+		TLS:            true,
+		TLSCertificate: "file:///does/not/exist",
+	})
+	assert.Error(t, err)
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	tlsConfig, err := newTLSConfig(Config{TLSServerName: "agora.internal"})
+	require.NoError(t, err)
+	assert.Equal(t, "agora.internal", tlsConfig.ServerName)
+	assert.Nil(t, tlsConfig.RootCAs)
+	assert.Empty(t, tlsConfig.Certificates)
+}
@@ -1,7 +1,12 @@
 package app
 
 import (
+	"reflect"
 	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/kinecosystem/agora-common/timeutil"
 )
 
 // Config is the application specific configuration.
@@ -14,45 +19,304 @@ type BaseConfig struct {
 	LogLevel string `mapstructure:"log_level"`
 	LogType  string `mapstructure:"log_type"`
 
-	ListenAddress         string        `mapstructure:"listen_address"`
-	InsecureListenAddress string        `mapstructure:"insecure_listen_address"`
-	ShutdownGracePeriod   time.Duration `mapstructure:"shutdown_grace_period"`
+	// ListenAddress is the address of the TLS gRPC listener, which is only
+	// started if TLSCertificate is configured.
+	ListenAddress string `mapstructure:"listen_address"`
+
+	// InsecureListenAddress is the address of the plaintext gRPC listener,
+	// intended for in-cluster traffic and sidecars. It serves the same services
+	// and interceptors as the TLS listener, and is only started if
+	// EnableInsecureListener is set.
+	InsecureListenAddress  string `mapstructure:"insecure_listen_address"`
+	EnableInsecureListener bool   `mapstructure:"enable_insecure_listener"`
+
+	ShutdownGracePeriod time.Duration `mapstructure:"shutdown_grace_period"`
+
+	// ShutdownDrainTimeout is how long the gRPC servers wait for in-flight
+	// RPCs to complete once they stop accepting new RPCs. Once elapsed, any
+	// remaining connections are forcibly closed, so that long-lived streams do
+	// not hold up shutdown. A value of 0 waits until the ShutdownGracePeriod.
+	ShutdownDrainTimeout time.Duration `mapstructure:"shutdown_drain_timeout"`
+
+	// ShutdownHookTimeout is the default timeout of hooks registered with
+	// OnShutdown. Hooks are also bound by the ShutdownGracePeriod.
+	ShutdownHookTimeout time.Duration `mapstructure:"shutdown_hook_timeout"`
 
 	HTTPGatewayAddress string `mapstructure:"http_gateway_address"`
 
+	// WarmupTimeout bounds the Warmup of apps that implement WarmupApp. If
+	// zero, the warmup is not bound.
+	WarmupTimeout time.Duration `mapstructure:"warmup_timeout"`
+
+	// HealthCheckInterval is the interval at which health checks configured
+	// with WithHealthCheck are run.
+	HealthCheckInterval time.Duration `mapstructure:"health_check_interval"`
+
 	// TLSCertificate is an optional URL that specified a TLS certificate to be
 	// used for the gRPC server.
 	//
-	// Currently only two supported URL schemes are supported: file, s3.
+	// The supported URL schemes are: file, s3, secretsmanager, ssm, https.
 	// If no scheme is specified, file is used.
 	TLSCertificate string `mapstructure:"tls_certificate"`
 	// TLSKey is an optional URL that specifies a TLS Private Key to be used for the
 	// gRPC server.
 	//
-	// Currently only two supported URL schemes are supported: file, s3.
+	// The supported URL schemes are: file, s3, secretsmanager, ssm, https.
 	// If no scheme is specified, file is used.
 	TLSKey string `mapstructure:"tls_private_key"`
+	// TLSClientCA is an optional URL that specifies a bundle of PEM encoded CA
+	// certificates, used to verify client certificates.
+	//
+	// The supported URL schemes are: file, s3, secretsmanager, ssm, https.
+	// If no scheme is specified, file is used.
+	TLSClientCA string `mapstructure:"tls_client_ca"`
+	// TLSClientAuth is the client authentication (mutual TLS) mode of the
+	// gRPC server. One of: none (default), request, require, verify_if_given,
+	// require_and_verify. Modes that verify client certificates require
+	// TLSClientCA to be set.
+	TLSClientAuth string `mapstructure:"tls_client_auth"`
+	// TLSReloadInterval is the interval at which TLSCertificate and TLSKey are
+	// reloaded, allowing certificates to be rotated without a restart. If zero,
+	// they are only reloaded when the process receives SIGHUP.
+	TLSReloadInterval time.Duration `mapstructure:"tls_reload_interval"`
 
 	EnablePprof        bool   `mapstructure:"enable_pprof"`
 	EnableExpvar       bool   `mapstructure:"enable_expvar"`
 	DebugListenAddress string `mapstructure:"debug_listen_address"`
 
+	// EnableRuntimeMetrics configures whether or not the Go runtime (goroutines,
+	// GC, memstats) and process metrics are exposed on the /metrics endpoint.
+	EnableRuntimeMetrics bool `mapstructure:"enable_runtime_metrics"`
+
+	// Metrics configures the metrics.Client provided to apps that implement
+	// MetricsApp.
+	Metrics MetricsConfig `mapstructure:"metrics"`
+
+	// MetricsServer configures a dedicated listener for the /metrics endpoint,
+	// so that it can be scraped without exposing the debug endpoints.
+	MetricsServer MetricsServerConfig `mapstructure:"metrics_server"`
+
+	// EnableReflection configures whether or not the gRPC reflection service is
+	// registered, allowing tools such as grpcurl to discover services. It is
+	// enabled by default in the dev and test environments (AGORA_ENVIRONMENT).
+	EnableReflection bool `mapstructure:"enable_reflection"`
+
+	// GRPC configures the limits and keepalive behaviour of the gRPC servers.
+	GRPC GRPCConfig `mapstructure:"grpc"`
+
+	// RequestLog configures the logging of gRPC requests.
+	RequestLog RequestLogConfig `mapstructure:"request_log"`
+
+	// Deadline configures the bounds of the deadlines of gRPC requests.
+	Deadline DeadlineConfig `mapstructure:"deadline"`
+
+	// Tracing configures the export of OpenTelemetry traces.
+	Tracing TracingConfig `mapstructure:"tracing"`
+
+	// Listeners configures the additional gRPC listeners registered with
+	// WithListener, keyed by (lower case) listener name.
+	Listeners map[string]ListenerConfig `mapstructure:"listeners"`
+
 	// Arbitrary configuration that the service can define / implement.
 	//
-	// Users should use mapstructure.Decode for ServiceConfig.
+	// Users should use Config.DecodeInto to decode it into a struct, or the
+	// typed accessors (e.g. Config.GetDuration) for individual values.
 	AppConfig Config `mapstructure:"app"`
 }
 
+// ListenerConfig contains the configuration of an additional gRPC listener
+// registered with WithListener.
+type ListenerConfig struct {
+	// Address is the address of the listener. It is required.
+	Address string `mapstructure:"address"`
+
+	// TLSCertificate, TLSKey, TLSClientCA and TLSClientAuth configure the TLS of
+	// the listener, in the same manner as the corresponding BaseConfig fields.
+	// If TLSCertificate is empty, the listener is plaintext.
+	TLSCertificate string `mapstructure:"tls_certificate"`
+	TLSKey         string `mapstructure:"tls_private_key"`
+	TLSClientCA    string `mapstructure:"tls_client_ca"`
+	TLSClientAuth  string `mapstructure:"tls_client_auth"`
+}
+
+// secureListenerConfig returns the ListenerConfig of the TLS gRPC listener.
+func (c BaseConfig) secureListenerConfig() ListenerConfig {
+	return ListenerConfig{
+		Address:        c.ListenAddress,
+		TLSCertificate: c.TLSCertificate,
+		TLSKey:         c.TLSKey,
+		TLSClientCA:    c.TLSClientCA,
+		TLSClientAuth:  c.TLSClientAuth,
+	}
+}
+
+// MetricsConfig contains the configuration of the app's metrics.Client.
+type MetricsConfig struct {
+	// ClientType is the type of metrics client to create (e.g. statsd). If
+	// empty, no client is created.
+	ClientType string `mapstructure:"client_type"`
+
+	// Address is the address of the metrics backend. If empty, the client's
+	// default (including any environment configuration) is used.
+	Address string `mapstructure:"address"`
+
+	Namespace  string   `mapstructure:"namespace"`
+	GlobalTags []string `mapstructure:"global_tags"`
+
+	// SampleRate is the rate at which metrics are sampled. If zero, the
+	// client's default (including any environment configuration) is used.
+	SampleRate float64 `mapstructure:"sample_rate"`
+}
+
+// MetricsServerConfig contains the configuration of the dedicated metrics
+// listener.
+type MetricsServerConfig struct {
+	// ListenAddress is the address of the listener. If empty, the listener is
+	// not started, and metrics are only served on the debug listener.
+	ListenAddress string `mapstructure:"listen_address"`
+
+	// BearerToken, if set, is required in the Authorization header (as
+	// "Bearer <token>") of requests to the listener.
+	BearerToken string `mapstructure:"bearer_token"`
+
+	// AllowedIPs, if set, restricts requests to the listener to the provided
+	// IP addresses or CIDR ranges.
+	AllowedIPs []string `mapstructure:"allowed_ips"`
+}
+
+// GRPCConfig contains the configuration of the gRPC servers. Zero values use
+// the gRPC defaults.
+type GRPCConfig struct {
+	// MaxRecvMsgSize and MaxSendMsgSize are the maximum message sizes, in bytes,
+	// the servers can receive and send.
+	MaxRecvMsgSize int `mapstructure:"max_recv_msg_size"`
+	MaxSendMsgSize int `mapstructure:"max_send_msg_size"`
+
+	// MaxConcurrentStreams is the maximum number of concurrent streams for each
+	// client connection.
+	MaxConcurrentStreams uint32 `mapstructure:"max_concurrent_streams"`
+
+	// MaxConnectionIdle is the duration after which idle connections are closed.
+	MaxConnectionIdle time.Duration `mapstructure:"max_connection_idle"`
+	// MaxConnectionAge is the maximum duration a connection may exist before it
+	// is gracefully closed, and MaxConnectionAgeGrace is the additional period
+	// after which it is forcibly closed.
+	MaxConnectionAge      time.Duration `mapstructure:"max_connection_age"`
+	MaxConnectionAgeGrace time.Duration `mapstructure:"max_connection_age_grace"`
+
+	// KeepaliveTime is the duration after which the server pings an idle
+	// connection, and KeepaliveTimeout is the duration the server waits for a
+	// response before closing it.
+	KeepaliveTime    time.Duration `mapstructure:"keepalive_time"`
+	KeepaliveTimeout time.Duration `mapstructure:"keepalive_timeout"`
+
+	// KeepaliveMinTime is the minimum interval at which clients may send
+	// keepalive pings, and KeepalivePermitWithoutStream allows clients to send
+	// pings when there are no active streams. Clients that violate the policy
+	// are disconnected.
+	KeepaliveMinTime             time.Duration `mapstructure:"keepalive_min_time"`
+	KeepalivePermitWithoutStream bool          `mapstructure:"keepalive_permit_without_stream"`
+}
+
+// RequestLogConfig contains the configuration of gRPC request logging.
+type RequestLogConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// SampleRate is the rate, between 0 and 1, at which successful requests are
+	// logged. Failed requests, and requests slower than SlowThreshold (if set),
+	// are always logged.
+	SampleRate    float64       `mapstructure:"sample_rate"`
+	SlowThreshold time.Duration `mapstructure:"slow_threshold"`
+
+	// Headers are the request headers included in the log entries.
+	Headers []string `mapstructure:"headers"`
+}
+
+// DeadlineConfig contains the configuration of the deadlines of incoming gRPC
+// requests.
+type DeadlineConfig struct {
+	// DefaultTimeout is the timeout applied to unary requests that do not have
+	// a deadline. If zero, such requests are unbounded.
+	DefaultTimeout time.Duration `mapstructure:"default_timeout"`
+
+	// MaxTimeout is the maximum remaining time of a request's deadline.
+	// Requests with a later deadline are rejected. If zero, deadlines are not
+	// limited.
+	MaxTimeout time.Duration `mapstructure:"max_timeout"`
+}
+
+// TracingConfig contains the configuration of OpenTelemetry tracing.
+type TracingConfig struct {
+	// Enabled configures whether or not spans are exported, and gRPC requests
+	// are traced.
+	Enabled bool `mapstructure:"enabled"`
+
+	// Endpoint is the address of the OTLP gRPC collector.
+	Endpoint string `mapstructure:"endpoint"`
+	// Insecure disables TLS when connecting to the collector.
+	Insecure bool `mapstructure:"insecure"`
+	// Headers are sent with each export request (e.g. for authentication).
+	Headers map[string]string `mapstructure:"headers"`
+
+	// ServiceName is the service.name resource attribute of exported spans.
+	ServiceName string `mapstructure:"service_name"`
+
+	// SampleRate is the rate, between 0 and 1, at which new traces are
+	// sampled. Requests that are part of an existing trace respect the sampling
+	// decision of the caller.
+	SampleRate float64 `mapstructure:"sample_rate"`
+}
+
 var defaultConfig = BaseConfig{
 	LogType: "json",
 
-	ListenAddress:         ":8085",
-	InsecureListenAddress: "localhost:8086",
-	ShutdownGracePeriod:   30 * time.Second,
+	ListenAddress:          ":8085",
+	InsecureListenAddress:  "localhost:8086",
+	EnableInsecureListener: true,
+	ShutdownGracePeriod:    30 * time.Second,
+	ShutdownDrainTimeout:   15 * time.Second,
+	ShutdownHookTimeout:    10 * time.Second,
 
 	HTTPGatewayAddress: ":8080",
 
+	WarmupTimeout: time.Minute,
+
+	HealthCheckInterval: 10 * time.Second,
+
 	EnablePprof:        true,
 	EnableExpvar:       true,
 	DebugListenAddress: ":8123",
+
+	EnableRuntimeMetrics: true,
+
+	RequestLog: RequestLogConfig{
+		SampleRate: 1.0,
+	},
+
+	Tracing: TracingConfig{
+		Endpoint:   "localhost:4317",
+		SampleRate: 1.0,
+	},
+}
+
+// DefaultConfig returns the BaseConfig used by Run before the configuration
+// is loaded.
+func DefaultConfig() BaseConfig {
+	return defaultConfig
+}
+
+// DecodeHook is the mapstructure.DecodeHookFunc used to decode BaseConfig. In
+// addition to viper's default hooks, durations may be specified as either Go
+// duration strings (e.g. 1m30s) or ISO-8601 durations (e.g. PT1M30S).
+var DecodeHook = mapstructure.ComposeDecodeHookFunc(
+	durationDecodeHook,
+	mapstructure.StringToSliceHookFunc(","),
+)
+
+func durationDecodeHook(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String || to != reflect.TypeOf(time.Duration(0)) {
+		return data, nil
+	}
+
+	return timeutil.ParseDuration(data.(string))
 }
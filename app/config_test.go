@@ -0,0 +1,164 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeHook(t *testing.T) {
+	for raw, expected := range map[string]time.Duration{
+		"45s":     45 * time.Second,
+		"PT1M30S": 90 * time.Second,
+	} {
+		var config BaseConfig
+		decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+			DecodeHook: DecodeHook,
+			Result:     &config,
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, decoder.Decode(map[string]interface{}{
+			"shutdown_grace_period": raw,
+		}))
+		assert.Equal(t, expected, config.ShutdownGracePeriod, raw)
+	}
+
+	var config BaseConfig
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: DecodeHook,
+		Result:     &config,
+	})
+	require.NoError(t, err)
+	assert.Error(t, decoder.Decode(map[string]interface{}{
+		"shutdown_grace_period": "invalid",
+	}))
+}
+
+func TestDecodeMetricsConfig(t *testing.T) {
+	var config BaseConfig
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: DecodeHook,
+		Result:     &config,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, decoder.Decode(map[string]interface{}{
+		"metrics": map[string]interface{}{
+			"client_type": "statsd",
+			"address":     "unix:///var/run/datadog/dsd.socket",
+			"namespace":   "agora",
+			"global_tags": "service:a,env:test",
+			"sample_rate": 0.5,
+		},
+	}))
+	assert.Equal(t, MetricsConfig{
+		ClientType: "statsd",
+		Address:    "unix:///var/run/datadog/dsd.socket",
+		Namespace:  "agora",
+		GlobalTags: []string{"service:a", "env:test"},
+		SampleRate: 0.5,
+	}, config.Metrics)
+}
+
+func TestDecodeGRPCConfig(t *testing.T) {
+	var config BaseConfig
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: DecodeHook,
+		Result:     &config,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, decoder.Decode(map[string]interface{}{
+		"grpc": map[string]interface{}{
+			"max_recv_msg_size":               8 << 20,
+			"max_concurrent_streams":          1000,
+			"max_connection_age":              "PT5M",
+			"max_connection_age_grace":        "30s",
+			"keepalive_min_time":              "10s",
+			"keepalive_permit_without_stream": true,
+		},
+	}))
+	assert.Equal(t, GRPCConfig{
+		MaxRecvMsgSize:               8 << 20,
+		MaxConcurrentStreams:         1000,
+		MaxConnectionAge:             5 * time.Minute,
+		MaxConnectionAgeGrace:        30 * time.Second,
+		KeepaliveMinTime:             10 * time.Second,
+		KeepalivePermitWithoutStream: true,
+	}, config.GRPC)
+}
+
+func TestDecodeRequestLogConfig(t *testing.T) {
+	config := defaultConfig
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: DecodeHook,
+		Result:     &config,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, decoder.Decode(map[string]interface{}{
+		"request_log": map[string]interface{}{
+			"enabled":        true,
+			"slow_threshold": "500ms",
+			"headers":        "user-agent,kin-user-agent",
+		},
+	}))
+	assert.Equal(t, RequestLogConfig{
+		Enabled:       true,
+		SampleRate:    1.0,
+		SlowThreshold: 500 * time.Millisecond,
+		Headers:       []string{"user-agent", "kin-user-agent"},
+	}, config.RequestLog)
+}
+
+func TestDecodeListenerConfig(t *testing.T) {
+	config := defaultConfig
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: DecodeHook,
+		Result:     &config,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, decoder.Decode(map[string]interface{}{
+		"listeners": map[string]interface{}{
+			"admin": map[string]interface{}{
+				"address":         "localhost:8090",
+				"tls_certificate": "file:///certs/admin.pem",
+				"tls_private_key": "file:///certs/admin.key",
+				"tls_client_auth": "require_and_verify",
+			},
+		},
+	}))
+	assert.Equal(t, map[string]ListenerConfig{
+		"admin": {
+			Address:        "localhost:8090",
+			TLSCertificate: "file:///certs/admin.pem",
+			TLSKey:         "file:///certs/admin.key",
+			TLSClientAuth:  "require_and_verify",
+		},
+	}, config.Listeners)
+}
+
+func TestDecodeDeadlineConfig(t *testing.T) {
+	config := defaultConfig
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: DecodeHook,
+		Result:     &config,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, decoder.Decode(map[string]interface{}{
+		"deadline": map[string]interface{}{
+			"default_timeout": "10s",
+			"max_timeout":     "PT5M",
+		},
+	}))
+	assert.Equal(t, DeadlineConfig{
+		DefaultTimeout: 10 * time.Second,
+		MaxTimeout:     5 * time.Minute,
+	}, config.Deadline)
+}
@@ -0,0 +1,126 @@
+package app
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+
+	"github.com/kinecosystem/agora-common/logging"
+	"github.com/kinecosystem/agora-common/metrics"
+)
+
+// connTracker is a stats.Handler that tracks the number of open connections
+// and in-flight RPCs of a gRPC server.
+type connTracker struct {
+	conns int64
+	rpcs  int64
+}
+
+// TagRPC implements stats.Handler.TagRPC.
+func (t *connTracker) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+// HandleRPC implements stats.Handler.HandleRPC.
+func (t *connTracker) HandleRPC(_ context.Context, s stats.RPCStats) {
+	switch s.(type) {
+	case *stats.Begin:
+		atomic.AddInt64(&t.rpcs, 1)
+	case *stats.End:
+		atomic.AddInt64(&t.rpcs, -1)
+	}
+}
+
+// TagConn implements stats.Handler.TagConn.
+func (t *connTracker) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+// HandleConn implements stats.Handler.HandleConn.
+func (t *connTracker) HandleConn(_ context.Context, s stats.ConnStats) {
+	switch s.(type) {
+	case *stats.ConnBegin:
+		atomic.AddInt64(&t.conns, 1)
+	case *stats.ConnEnd:
+		atomic.AddInt64(&t.conns, -1)
+	}
+}
+
+func (t *connTracker) openConns() int64 {
+	return atomic.LoadInt64(&t.conns)
+}
+
+func (t *connTracker) inFlightRPCs() int64 {
+	return atomic.LoadInt64(&t.rpcs)
+}
+
+// drainer stops gRPC servers in two phases: the server stops accepting new
+// RPCs and waits for in-flight RPCs to complete, and once the drain timeout
+// has elapsed, any remaining connections are forcibly closed.
+type drainer struct {
+	log     logging.Logger
+	timeout time.Duration
+
+	droppedConns *prometheus.CounterVec
+	droppedRPCs  *prometheus.CounterVec
+}
+
+func newDrainer(log logging.Logger, registerer prometheus.Registerer, timeout time.Duration) *drainer {
+	droppedConns := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_drain_dropped_connections_total",
+		Help: "Total number of gRPC connections forcibly closed after the shutdown drain timeout elapsed.",
+	}, []string{"listener"})
+	droppedRPCs := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_drain_dropped_rpcs_total",
+		Help: "Total number of in-flight gRPC requests cancelled after the shutdown drain timeout elapsed.",
+	}, []string{"listener"})
+
+	return &drainer{
+		log:          log.WithField("method", "drain"),
+		timeout:      timeout,
+		droppedConns: metrics.RegisterWith(registerer, "", nil, droppedConns).(*prometheus.CounterVec),
+		droppedRPCs:  metrics.RegisterWith(registerer, "", nil, droppedRPCs).(*prometheus.CounterVec),
+	}
+}
+
+// drain gracefully stops the server, and forcibly stops it if it has not
+// stopped within the drain timeout. If the drain timeout is not positive, the
+// server is stopped gracefully without a timeout.
+//
+// drain returns whether or not the server was forcibly stopped.
+func (d *drainer) drain(listener string, serv *grpc.Server, tracker *connTracker) bool {
+	if d.timeout <= 0 {
+		serv.GracefulStop()
+		return false
+	}
+
+	stoppedCh := make(chan struct{})
+	go func() {
+		serv.GracefulStop()
+		close(stoppedCh)
+	}()
+
+	select {
+	case <-stoppedCh:
+		return false
+	case <-time.After(d.timeout):
+	}
+
+	conns, rpcs := tracker.openConns(), tracker.inFlightRPCs()
+	d.log.WithFields(logging.Fields{
+		"listener":    listener,
+		"connections": conns,
+		"rpcs":        rpcs,
+	}).Warn("drain timeout elapsed, forcing grpc server to stop")
+
+	d.droppedConns.WithLabelValues(listener).Add(float64(conns))
+	d.droppedRPCs.WithLabelValues(listener).Add(float64(rpcs))
+
+	serv.Stop()
+	<-stoppedCh
+	return true
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/kinecosystem/agora-common/logging"
+	"github.com/kinecosystem/agora-common/testutil"
+)
+
+func TestDrainer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	d := newDrainer(logging.Default(), registry, 100*time.Millisecond)
+
+	tracker := &connTracker{}
+	serv := grpc.NewServer(grpc.StatsHandler(tracker))
+	healthpb.RegisterHealthServer(serv, health.NewServer())
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go func() {
+		_ = serv.Serve(lis)
+	}()
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Watch streams until the client cancels, so the server cannot be stopped
+	// gracefully.
+	stream, err := healthpb.NewHealthClient(conn).Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	require.NoError(t, testutil.WaitFor(time.Second, 10*time.Millisecond, func() bool {
+		return tracker.openConns() == 1 && tracker.inFlightRPCs() == 1
+	}))
+
+	start := time.Now()
+	assert.True(t, d.drain("insecure", serv, tracker))
+	assert.True(t, time.Since(start) >= 100*time.Millisecond)
+
+	_, err = stream.Recv()
+	assert.Error(t, err)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	values := make(map[string]float64)
+	for _, f := range families {
+		require.Len(t, f.GetMetric(), 1)
+		assert.Equal(t, "insecure", f.GetMetric()[0].GetLabel()[0].GetValue())
+		values[f.GetName()] = f.GetMetric()[0].GetCounter().GetValue()
+	}
+	assert.Equal(t, map[string]float64{
+		"grpc_server_drain_dropped_connections_total": 1,
+		"grpc_server_drain_dropped_rpcs_total":        1,
+	}, values)
+}
+
+func TestDrainer_Graceful(t *testing.T) {
+	d := newDrainer(logging.Default(), prometheus.NewRegistry(), time.Minute)
+
+	tracker := &connTracker{}
+	serv := grpc.NewServer(grpc.StatsHandler(tracker))
+	healthpb.RegisterHealthServer(serv, health.NewServer())
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go func() {
+		_ = serv.Serve(lis)
+	}()
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	start := time.Now()
+	assert.False(t, d.drain("insecure", serv, tracker))
+	assert.True(t, time.Since(start) < time.Minute)
+}
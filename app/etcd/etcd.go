@@ -0,0 +1,172 @@
+// Package etcd provides a standard way of constructing etcd clients, so that
+// services do not each hand-roll a clientv3.Config.
+package etcd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"strings"
+	"time"
+
+	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
+	"github.com/pkg/errors"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"google.golang.org/grpc"
+
+	"github.com/kinecosystem/agora-common/app"
+)
+
+// Config contains the configuration of an etcd client. It is intended to be
+// decoded from the app's configuration (see app.Config), using app.DecodeHook.
+type Config struct {
+	// Endpoints are the URLs of the etcd cluster members. At least one is
+	// required.
+	Endpoints []string `mapstructure:"endpoints"`
+
+	// TLS configures whether or not the client uses TLS.
+	TLS bool `mapstructure:"tls"`
+	// TLSCA is an optional URL that specifies a bundle of PEM encoded CA
+	// certificates used to verify the cluster. If empty, the system roots are
+	// used. URLs are loaded with app.LoadFile.
+	TLSCA string `mapstructure:"tls_ca"`
+	// TLSCertificate and TLSKey are optional URLs that specify the client
+	// certificate, for clusters that authenticate clients with TLS. URLs are
+	// loaded with app.LoadFile.
+	TLSCertificate string `mapstructure:"tls_certificate"`
+	TLSKey         string `mapstructure:"tls_private_key"`
+
+	// Username is the user to authenticate as, if the cluster has
+	// authentication enabled.
+	Username string `mapstructure:"username"`
+	// Password is a URL that specifies the password of the user, so that it is
+	// not stored in the config itself. URLs are loaded with app.LoadFile.
+	Password string `mapstructure:"password"`
+
+	// DialTimeout is the timeout for establishing the initial connection. If
+	// zero, DefaultDialTimeout is used.
+	DialTimeout time.Duration `mapstructure:"dial_timeout"`
+	// KeepAliveTime is the interval at which the client pings the cluster to
+	// check the connection. If zero, DefaultKeepAliveTime is used.
+	KeepAliveTime time.Duration `mapstructure:"keep_alive_time"`
+	// KeepAliveTimeout is the time the client waits for a response to a ping
+	// before closing the connection. If zero, DefaultKeepAliveTimeout is used.
+	KeepAliveTimeout time.Duration `mapstructure:"keep_alive_timeout"`
+}
+
+// Defaults of the timeout configuration.
+const (
+	DefaultDialTimeout      = 5 * time.Second
+	DefaultKeepAliveTime    = 30 * time.Second
+	DefaultKeepAliveTimeout = 10 * time.Second
+)
+
+// NewClientConfig returns the clientv3.Config for the provided config.
+//
+// The returned config blocks until the initial connection is established (or
+// the dial timeout expires), so that misconfigured clients fail at startup.
+// Requests are instrumented with the gRPC client metrics of
+// go-grpc-prometheus.
+func NewClientConfig(config Config) (clientv3.Config, error) {
+	var endpoints []string
+	for _, e := range config.Endpoints {
+		if e = strings.TrimSpace(e); e != "" {
+			endpoints = append(endpoints, e)
+		}
+	}
+	if len(endpoints) == 0 {
+		return clientv3.Config{}, errors.New("at least one endpoint must be provided")
+	}
+
+	c := clientv3.Config{
+		Endpoints:            endpoints,
+		DialTimeout:          orDefault(config.DialTimeout, DefaultDialTimeout),
+		DialKeepAliveTime:    orDefault(config.KeepAliveTime, DefaultKeepAliveTime),
+		DialKeepAliveTimeout: orDefault(config.KeepAliveTimeout, DefaultKeepAliveTimeout),
+		DialOptions: []grpc.DialOption{
+			grpc.WithBlock(),
+			grpc.WithUnaryInterceptor(grpc_prometheus.UnaryClientInterceptor),
+			grpc.WithStreamInterceptor(grpc_prometheus.StreamClientInterceptor),
+		},
+	}
+
+	if config.TLS {
+		tlsConfig, err := newTLSConfig(config)
+		if err != nil {
+			return clientv3.Config{}, errors.Wrap(err, "failed to configure tls")
+		}
+		c.TLS = tlsConfig
+	}
+
+	if config.Username != "" {
+		if config.Password == "" {
+			return clientv3.Config{}, errors.New("password must be provided with username")
+		}
+
+		password, err := app.LoadFile(config.Password)
+		if err != nil {
+			return clientv3.Config{}, errors.Wrap(err, "failed to load password")
+		}
+
+		c.Username = config.Username
+		c.Password = strings.TrimSpace(string(password))
+	}
+
+	return c, nil
+}
+
+// NewClient returns a new etcd client for the provided config. See
+// NewClientConfig for details.
+func NewClient(config Config) (*clientv3.Client, error) {
+	c, err := NewClientConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := clientv3.New(c)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create etcd client")
+	}
+	return client, nil
+}
+
+func newTLSConfig(config Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+
+	if config.TLSCA != "" {
+		caBytes, err := app.LoadFile(config.TLSCA)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load tls ca")
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caBytes) {
+			return nil, errors.New("tls ca does not contain any valid certificates")
+		}
+	}
+
+	if config.TLSCertificate != "" || config.TLSKey != "" {
+		certBytes, err := app.LoadFile(config.TLSCertificate)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load tls certificate")
+		}
+		keyBytes, err := app.LoadFile(config.TLSKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load tls key")
+		}
+
+		cert, err := tls.X509KeyPair(certBytes, keyBytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid tls certificate or key")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+func orDefault(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
@@ -0,0 +1,65 @@
+package etcd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClientConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	passwordPath := filepath.Join(dir, "password")
+	require.NoError(t, ioutil.WriteFile(passwordPath, []byte("hunter2\n"), 0600))
+
+	c, err := NewClientConfig(Config{
+		Endpoints:   []string{"http://etcd-0:2379", " ", "http://etcd-1:2379"},
+		Username:    "user",
+		Password:    passwordPath,
+		DialTimeout: time.Second,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"http://etcd-0:2379", "http://etcd-1:2379"}, c.Endpoints)
+	assert.Equal(t, "user", c.Username)
+	assert.Equal(t, "hunter2", c.Password)
+	assert.Nil(t, c.TLS)
+	assert.Equal(t, time.Second, c.DialTimeout)
+	assert.Equal(t, DefaultKeepAliveTime, c.DialKeepAliveTime)
+	assert.Equal(t, DefaultKeepAliveTimeout, c.DialKeepAliveTimeout)
+	assert.NotEmpty(t, c.DialOptions)
+
+	// Without a CA, the system roots are used.
+	c, err = NewClientConfig(Config{Endpoints: []string{"https://etcd-0:2379"}, TLS: true})
+	require.NoError(t, err)
+	require.NotNil(t, c.TLS)
+	assert.Nil(t, c.TLS.RootCAs)
+}
+
+func TestNewClientConfig_Invalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	invalidPath := filepath.Join(dir, "invalid")
+	require.NoError(t, ioutil.WriteFile(invalidPath, []byte("not a certificate"), 0600))
+
+	for _, config := range []Config{
+		{},
+		{Endpoints: []string{""}},
+		{Endpoints: []string{"http://etcd-0:2379"}, Username: "user"},
+		{Endpoints: []string{"http://etcd-0:2379"}, Username: "user", Password: filepath.Join(dir, "missing")},
+		{Endpoints: []string{"https://etcd-0:2379"}, TLS: true, TLSCA: invalidPath},
+		{Endpoints: []string{"https://etcd-0:2379"}, TLS: true, TLSCertificate: invalidPath, TLSKey: invalidPath},
+	} {
+		_, err := NewClientConfig(config)
+		assert.Error(t, err)
+	}
+}
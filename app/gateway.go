@@ -0,0 +1,123 @@
+package app
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/handlers"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/improbable-eng/grpc-web/go/grpcweb"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/kinecosystem/agora-common/httpgateway"
+)
+
+// HTTPApp is an App that serves grpc-gateway handlers on the HTTP gateway
+// address, in addition to its gRPC services.
+type HTTPApp interface {
+	App
+
+	// RegisterWithHTTP provides a mechanism for the application to register
+	// grpc-gateway handlers (e.g. pb.RegisterServiceHandler(ctx, mux, cc)) with
+	// the HTTP gateway. The provided connection is connected to the app's gRPC
+	// server.
+	RegisterWithHTTP(ctx context.Context, mux *runtime.ServeMux, cc *grpc.ClientConn) error
+}
+
+// newHTTPGatewayHandler returns the handler served on the HTTP gateway address.
+//
+// It serves:
+//   - /healthz, which reports the overall health of the server.
+//   - gRPC-Web requests (and their CORS preflight requests), which are served
+//     by the gRPC server, if enabled via WithGRPCWebEnabled.
+//   - /api/, which forwards requests to the gRPC server, if the gateway is
+//     enabled via WithHTTPGatewayEnabled.
+//   - All other paths are served by the grpc-gateway handlers registered by
+//     the app, if it implements HTTPApp.
+//
+// The returned connections should be closed when the gateway is stopped.
+func newHTTPGatewayHandler(ctx context.Context, app App, o opts, serv *grpc.Server, grpcAddr string, healthServ healthgrpc.HealthServer) (http.Handler, []*grpc.ClientConn, error) {
+	var conns []*grpc.ClientConn
+	closeConns := func() {
+		for _, cc := range conns {
+			cc.Close()
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", healthHandler(healthServ))
+
+	if o.httpGatewayEnabled {
+		cc, err := grpc.Dial(
+			grpcAddr,
+			grpc.WithInsecure(),
+			grpc.WithDefaultCallOptions(grpc.ForceCodec(&httpgateway.BinaryCodec{})),
+		)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to dial %s", grpcAddr)
+		}
+		conns = append(conns, cc)
+
+		mux.Handle("/api/", httpgateway.New(serv, cc, o.httpGatewayOptions...).Handler())
+	}
+
+	if httpApp, ok := app.(HTTPApp); ok {
+		cc, err := grpc.Dial(grpcAddr, grpc.WithInsecure())
+		if err != nil {
+			closeConns()
+			return nil, nil, errors.Wrapf(err, "failed to dial %s", grpcAddr)
+		}
+		conns = append(conns, cc)
+
+		gwMux := runtime.NewServeMux()
+		if err := httpApp.RegisterWithHTTP(ctx, gwMux, cc); err != nil {
+			closeConns()
+			return nil, nil, errors.Wrap(err, "failed to register http handlers")
+		}
+
+		var handler http.Handler = gwMux
+		if len(o.httpCORSOrigins) > 0 {
+			handler = handlers.CORS(
+				handlers.AllowedOrigins(o.httpCORSOrigins),
+				handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
+				handlers.AllowedMethods([]string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}),
+			)(handler)
+		}
+		mux.Handle("/", handler)
+	}
+
+	if o.grpcWebEnabled {
+		return newGRPCWebHandler(serv, o.httpCORSOrigins, mux), conns, nil
+	}
+	return mux, conns, nil
+}
+
+// newGRPCWebHandler returns a handler that serves gRPC-Web requests with the
+// gRPC server, and all other requests with the provided handler.
+//
+// Cross-origin requests are only allowed from the provided origins ("*" allows
+// all origins).
+func newGRPCWebHandler(serv *grpc.Server, corsOrigins []string, handler http.Handler) http.Handler {
+	allowedOrigins := make(map[string]struct{})
+	for _, origin := range corsOrigins {
+		allowedOrigins[origin] = struct{}{}
+	}
+
+	wrapped := grpcweb.WrapServer(serv, grpcweb.WithOriginFunc(func(origin string) bool {
+		if _, ok := allowedOrigins["*"]; ok {
+			return true
+		}
+		_, ok := allowedOrigins[origin]
+		return ok
+	}))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if wrapped.IsGrpcWebRequest(r) || wrapped.IsAcceptableGrpcCorsRequest(r) {
+			wrapped.ServeHTTP(w, r)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
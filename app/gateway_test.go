@@ -0,0 +1,162 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/utilities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type testHTTPApp struct {
+	App
+	registered bool
+}
+
+func (a *testHTTPApp) RegisterWithHTTP(ctx context.Context, mux *runtime.ServeMux, cc *grpc.ClientConn) error {
+	a.registered = cc != nil
+
+	pattern := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"hello"}, ""))
+	mux.Handle("GET", pattern, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		_, _ = w.Write([]byte("world"))
+	})
+	return nil
+}
+
+func TestHTTPGatewayHandler(t *testing.T) {
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	serv := grpc.NewServer()
+	healthServ := health.NewServer()
+	healthgrpc.RegisterHealthServer(serv, healthServ)
+	go func() {
+		_ = serv.Serve(lis)
+	}()
+	defer serv.Stop()
+
+	app := &testHTTPApp{}
+	o := opts{
+		httpGatewayEnabled: true,
+		httpCORSOrigins:    []string{"https://kin.org"},
+	}
+	handler, conns, err := newHTTPGatewayHandler(context.Background(), app, o, serv, lis.Addr().String(), healthServ)
+	require.NoError(t, err)
+	assert.Len(t, conns, 2)
+	for _, cc := range conns {
+		defer cc.Close()
+	}
+	assert.True(t, app.registered)
+
+	httpServ := httptest.NewServer(handler)
+	defer httpServ.Close()
+
+	req, err := http.NewRequest("GET", httpServ.URL+"/hello", nil)
+	require.NoError(t, err)
+	req.Header.Set("Origin", "https://kin.org")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "world", string(body))
+	assert.Equal(t, "https://kin.org", resp.Header.Get("Access-Control-Allow-Origin"))
+
+	// The binary gateway only supports POST requests.
+	resp, err = http.Get(httpServ.URL + "/api/grpc.health.v1.Health/Check")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	resp, err = http.Get(httpServ.URL + "/healthz")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	healthServ.SetServingStatus("", healthgrpc.HealthCheckResponse_NOT_SERVING)
+	resp, err = http.Get(httpServ.URL + "/healthz")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestGRPCWebHandler(t *testing.T) {
+	serv := grpc.NewServer()
+	healthgrpc.RegisterHealthServer(serv, health.NewServer())
+
+	fallback := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("fallback"))
+	})
+	httpServ := httptest.NewServer(newGRPCWebHandler(serv, []string{"https://kin.org"}, fallback))
+	defer httpServ.Close()
+
+	reqBytes, err := proto.Marshal(&healthgrpc.HealthCheckRequest{})
+	require.NoError(t, err)
+	frame := make([]byte, 5+len(reqBytes))
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(reqBytes)))
+	copy(frame[5:], reqBytes)
+
+	req, err := http.NewRequest(http.MethodPost, httpServ.URL+"/grpc.health.v1.Health/Check", bytes.NewReader(frame))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/grpc-web+proto")
+	req.Header.Set("X-Grpc-Web", "1")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/grpc-web+proto", resp.Header.Get("Content-Type"))
+
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.True(t, len(body) > 5)
+	// The first frame is the response message, and the second frame contains
+	// the trailers.
+	msgLen := binary.BigEndian.Uint32(body[1:5])
+	var healthResp healthgrpc.HealthCheckResponse
+	require.NoError(t, proto.Unmarshal(body[5:5+msgLen], &healthResp))
+	assert.Equal(t, healthgrpc.HealthCheckResponse_SERVING, healthResp.Status)
+	assert.Contains(t, string(body[5+msgLen:]), "grpc-status: 0")
+
+	// CORS preflight requests are only allowed from the configured origins.
+	for origin, allowed := range map[string]bool{
+		"https://kin.org":  true,
+		"https://evil.com": false,
+	} {
+		req, err = http.NewRequest(http.MethodOptions, httpServ.URL+"/grpc.health.v1.Health/Check", nil)
+		require.NoError(t, err)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		req.Header.Set("Access-Control-Request-Headers", "content-type,x-grpc-web")
+
+		resp, err = http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		if allowed {
+			assert.Equal(t, origin, resp.Header.Get("Access-Control-Allow-Origin"))
+		} else {
+			assert.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+		}
+	}
+
+	// Other requests are served by the provided handler.
+	resp, err = http.Get(httpServ.URL + "/hello")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err = ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "fallback", string(body))
+}
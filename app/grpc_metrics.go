@@ -0,0 +1,74 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+
+	"github.com/kinecosystem/agora-common/metrics"
+)
+
+// handlingTimeHistogram records the handling time of gRPC requests.
+//
+// It mirrors the grpc_server_handling_seconds histogram of go-grpc-prometheus,
+// but attaches the trace ID of sampled requests as exemplars.
+type handlingTimeHistogram struct {
+	histogram *prometheus.HistogramVec
+}
+
+func newHandlingTimeHistogram(registerer prometheus.Registerer) *handlingTimeHistogram {
+	histogram := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_server_handling_seconds",
+		Help:    "Histogram of response latency (seconds) of gRPC that had been application-level handled by the server.",
+		Buckets: metrics.MinuteDistributionBuckets,
+	}, []string{"grpc_type", "grpc_service", "grpc_method"})
+
+	return &handlingTimeHistogram{
+		histogram: metrics.RegisterWith(registerer, "", nil, histogram).(*prometheus.HistogramVec),
+	}
+}
+
+func (h *handlingTimeHistogram) unaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		h.observe(ctx, "unary", info.FullMethod, start)
+		return resp, err
+	}
+}
+
+func (h *handlingTimeHistogram) streamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		h.observe(ss.Context(), streamType(info), info.FullMethod, start)
+		return err
+	}
+}
+
+func (h *handlingTimeHistogram) observe(ctx context.Context, grpcType, fullMethod string, start time.Time) {
+	service, method := "unknown", "unknown"
+	if parts := strings.SplitN(strings.TrimPrefix(fullMethod, "/"), "/", 2); len(parts) == 2 {
+		service, method = parts[0], parts[1]
+	}
+
+	metrics.ObserveWithTraceExemplar(
+		ctx,
+		h.histogram.WithLabelValues(grpcType, service, method),
+		time.Since(start).Seconds(),
+	)
+}
+
+func streamType(info *grpc.StreamServerInfo) string {
+	switch {
+	case info.IsClientStream && !info.IsServerStream:
+		return "client_stream"
+	case !info.IsClientStream && info.IsServerStream:
+		return "server_stream"
+	default:
+		return "bidi_stream"
+	}
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/kinecosystem/agora-common/metrics"
+	"github.com/kinecosystem/agora-common/testutil"
+)
+
+func TestHandlingTimeHistogram(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	handlingTime := newHandlingTimeHistogram(registry)
+
+	traceID := trace.TraceID{1, 2, 3}
+	tracing := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = trace.ContextWithRemoteSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     trace.SpanID{1},
+			TraceFlags: trace.FlagsSampled,
+		}))
+		return handler(ctx, req)
+	}
+
+	conn, serv, err := testutil.NewServer(
+		testutil.WithUnaryServerInterceptor(tracing),
+		testutil.WithUnaryServerInterceptor(handlingTime.unaryServerInterceptor()),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	serv.RegisterService(func(s *grpc.Server) {
+		healthpb.RegisterHealthServer(s, health.NewServer())
+	})
+	stopFunc, err := serv.Serve()
+	require.NoError(t, err)
+	defer stopFunc()
+
+	_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	assert.Equal(t, "grpc_server_handling_seconds", families[0].GetName())
+
+	m := families[0].GetMetric()[0]
+	labels := make(map[string]string)
+	for _, l := range m.GetLabel() {
+		labels[l.GetName()] = l.GetValue()
+	}
+	assert.Equal(t, map[string]string{
+		"grpc_type":    "unary",
+		"grpc_service": "grpc.health.v1.Health",
+		"grpc_method":  "Check",
+	}, labels)
+	assert.EqualValues(t, 1, m.GetHistogram().GetSampleCount())
+
+	var exemplarTraceIDs []string
+	for _, b := range m.GetHistogram().GetBucket() {
+		for _, l := range b.GetExemplar().GetLabel() {
+			if l.GetName() == metrics.TraceIDExemplarLabel {
+				exemplarTraceIDs = append(exemplarTraceIDs, l.GetValue())
+			}
+		}
+	}
+	assert.Equal(t, []string{traceID.String()}, exemplarTraceIDs)
+}
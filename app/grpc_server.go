@@ -0,0 +1,117 @@
+package app
+
+import (
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+
+	"github.com/kinecosystem/agora-common/deadline"
+	"github.com/kinecosystem/agora-common/env"
+	"github.com/kinecosystem/agora-common/requestlog"
+)
+
+// newServerOptsFunc returns a function that returns the options of a gRPC
+// server, which runs the provided interceptors after the base interceptors
+// (metrics, tracing, handling time, request logging and deadlines).
+//
+// The handling time histogram is recorded directly after the tracing
+// interceptor, so that the request's span is attached as an exemplar, and the
+// recorded time covers the remaining interceptors.
+func newServerOptsFunc(config BaseConfig, grpcMetrics *grpc_prometheus.ServerMetrics, registerer prometheus.Registerer) func([]grpc.UnaryServerInterceptor, []grpc.StreamServerInterceptor) []grpc.ServerOption {
+	handlingTime := newHandlingTimeHistogram(registerer)
+	baseUnaryInterceptors := []grpc.UnaryServerInterceptor{grpcMetrics.UnaryServerInterceptor()}
+	baseStreamInterceptors := []grpc.StreamServerInterceptor{grpcMetrics.StreamServerInterceptor()}
+	if config.Tracing.Enabled {
+		baseUnaryInterceptors = append(baseUnaryInterceptors, otelgrpc.UnaryServerInterceptor())
+		baseStreamInterceptors = append(baseStreamInterceptors, otelgrpc.StreamServerInterceptor())
+	}
+	baseUnaryInterceptors = append(baseUnaryInterceptors, handlingTime.unaryServerInterceptor())
+	baseStreamInterceptors = append(baseStreamInterceptors, handlingTime.streamServerInterceptor())
+	if config.RequestLog.Enabled {
+		logOpts := config.RequestLog.options()
+		baseUnaryInterceptors = append(baseUnaryInterceptors, requestlog.UnaryServerInterceptor(logOpts...))
+		baseStreamInterceptors = append(baseStreamInterceptors, requestlog.StreamServerInterceptor(logOpts...))
+	}
+	if config.Deadline.DefaultTimeout > 0 || config.Deadline.MaxTimeout > 0 {
+		deadlineOpts := config.Deadline.options()
+		baseUnaryInterceptors = append(baseUnaryInterceptors, deadline.UnaryServerInterceptor(deadlineOpts...))
+		baseStreamInterceptors = append(baseStreamInterceptors, deadline.StreamServerInterceptor(deadlineOpts...))
+	}
+
+	return func(unary []grpc.UnaryServerInterceptor, stream []grpc.StreamServerInterceptor) []grpc.ServerOption {
+		unaryInterceptors := append([]grpc.UnaryServerInterceptor{}, baseUnaryInterceptors...)
+		unaryInterceptors = append(unaryInterceptors, unary...)
+		streamInterceptors := append([]grpc.StreamServerInterceptor{}, baseStreamInterceptors...)
+		streamInterceptors = append(streamInterceptors, stream...)
+
+		return append([]grpc.ServerOption{
+			grpc_middleware.WithUnaryServerChain(unaryInterceptors...),
+			grpc_middleware.WithStreamServerChain(streamInterceptors...),
+		}, config.GRPC.serverOptions()...)
+	}
+}
+
+// serverOptions returns the grpc.ServerOptions that apply the configuration.
+// Options whose configuration is unset are omitted, so that the gRPC defaults
+// are used.
+func (c GRPCConfig) serverOptions() []grpc.ServerOption {
+	var opts []grpc.ServerOption
+
+	if c.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(c.MaxRecvMsgSize))
+	}
+	if c.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(c.MaxSendMsgSize))
+	}
+	if c.MaxConcurrentStreams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(c.MaxConcurrentStreams))
+	}
+
+	params := keepalive.ServerParameters{
+		MaxConnectionIdle:     c.MaxConnectionIdle,
+		MaxConnectionAge:      c.MaxConnectionAge,
+		MaxConnectionAgeGrace: c.MaxConnectionAgeGrace,
+		Time:                  c.KeepaliveTime,
+		Timeout:               c.KeepaliveTimeout,
+	}
+	if params != (keepalive.ServerParameters{}) {
+		opts = append(opts, grpc.KeepaliveParams(params))
+	}
+
+	if c.KeepaliveMinTime > 0 || c.KeepalivePermitWithoutStream {
+		opts = append(opts, grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             c.KeepaliveMinTime,
+			PermitWithoutStream: c.KeepalivePermitWithoutStream,
+		}))
+	}
+
+	return opts
+}
+
+// options returns the requestlog.Options that apply the configuration.
+func (c RequestLogConfig) options() []requestlog.Option {
+	return []requestlog.Option{
+		requestlog.WithSampleRate(c.SampleRate),
+		requestlog.WithSlowThreshold(c.SlowThreshold),
+		requestlog.WithHeaders(c.Headers...),
+	}
+}
+
+// options returns the deadline.Options that apply the configuration.
+func (c DeadlineConfig) options() []deadline.Option {
+	return []deadline.Option{
+		deadline.WithDefaultTimeout(c.DefaultTimeout),
+		deadline.WithMaxTimeout(c.MaxTimeout),
+	}
+}
+
+// reflectionEnabledByDefault returns whether or not the gRPC reflection service
+// is enabled if not explicitly configured, which is only the case in the dev
+// and test environments.
+func reflectionEnabledByDefault() bool {
+	e, err := env.FromEnvVariable()
+	return err == nil && e != env.AgoraEnvironmentProd
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"context"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCConfig_ServerOptions(t *testing.T) {
+	assert.Empty(t, GRPCConfig{}.serverOptions())
+
+	config := GRPCConfig{
+		MaxRecvMsgSize:       1024,
+		MaxSendMsgSize:       1024,
+		MaxConcurrentStreams: 100,
+		MaxConnectionAge:     time.Minute,
+		KeepaliveMinTime:     time.Second,
+	}
+	assert.Len(t, config.serverOptions(), 5)
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	serv := grpc.NewServer(config.serverOptions()...)
+	healthgrpc.RegisterHealthServer(serv, health.NewServer())
+	go func() {
+		_ = serv.Serve(lis)
+	}()
+	defer serv.Stop()
+
+	cc, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer cc.Close()
+
+	client := healthgrpc.NewHealthClient(cc)
+
+	resp, err := client.Check(context.Background(), &healthgrpc.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthgrpc.HealthCheckResponse_SERVING, resp.Status)
+
+	_, err = client.Check(context.Background(), &healthgrpc.HealthCheckRequest{
+		Service: strings.Repeat("a", 2048),
+	})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestReflectionEnabledByDefault(t *testing.T) {
+	prev, wasSet := os.LookupEnv("AGORA_ENVIRONMENT")
+	defer func() {
+		if wasSet {
+			os.Setenv("AGORA_ENVIRONMENT", prev)
+		} else {
+			os.Unsetenv("AGORA_ENVIRONMENT")
+		}
+	}()
+
+	for environment, expected := range map[string]bool{
+		"":        false,
+		"invalid": false,
+		"prod":    false,
+		"dev":     true,
+		"test":    true,
+	} {
+		require.NoError(t, os.Setenv("AGORA_ENVIRONMENT", environment))
+		assert.Equal(t, expected, reflectionEnabledByDefault(), environment)
+	}
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/kinecosystem/agora-common/logging"
+)
+
+// HealthCheck checks the health of a component of the application, returning
+// an error if it is unhealthy.
+type HealthCheck func(ctx context.Context) error
+
+type namedHealthCheck struct {
+	service string
+	check   HealthCheck
+}
+
+// runHealthChecks periodically runs the health checks, reflecting the results
+// in the health server until stopCh is closed.
+func runHealthChecks(log logging.Logger, serv *health.Server, checks []namedHealthCheck, interval time.Duration, stopCh <-chan struct{}) {
+	log = log.WithField("method", "runHealthChecks")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		updateHealth(log, serv, checks, interval)
+
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// updateHealth runs the health checks, setting the status of each check's
+// service. The overall status of the server (the empty service) is serving
+// only if all checks pass.
+func updateHealth(log logging.Logger, serv *health.Server, checks []namedHealthCheck, timeout time.Duration) {
+	overall := healthgrpc.HealthCheckResponse_SERVING
+	for _, c := range checks {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := c.check(ctx)
+		cancel()
+
+		status := healthgrpc.HealthCheckResponse_SERVING
+		if err != nil {
+			log.WithError(err).WithField("service", c.service).Warn("health check failed")
+			status = healthgrpc.HealthCheckResponse_NOT_SERVING
+			overall = healthgrpc.HealthCheckResponse_NOT_SERVING
+		}
+
+		if c.service != "" {
+			serv.SetServingStatus(c.service, status)
+		}
+	}
+
+	serv.SetServingStatus("", overall)
+}
+
+// livenessHandler returns an http.Handler that responds with 200 as long as the
+// process is able to serve requests, regardless of the health of the app.
+func livenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("alive"))
+	})
+}
+
+// healthHandler returns an http.Handler that responds with 200 if the server
+// (or the service specified by the service query parameter) is serving, and
+// 503 otherwise.
+func healthHandler(healthServ healthgrpc.HealthServer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, err := healthServ.Check(r.Context(), &healthgrpc.HealthCheckRequest{
+			Service: r.URL.Query().Get("service"),
+		})
+		if err != nil || resp.Status != healthgrpc.HealthCheckResponse_SERVING {
+			http.Error(w, "not serving", http.StatusServiceUnavailable)
+			return
+		}
+
+		_, _ = w.Write([]byte("serving"))
+	})
+}
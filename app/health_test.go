@@ -0,0 +1,85 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/health"
+	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/kinecosystem/agora-common/logging"
+)
+
+func TestUpdateHealth(t *testing.T) {
+	serv := health.NewServer()
+	log := logging.Default().WithField("type", "agora/app")
+
+	var processorErr error
+	checks := []namedHealthCheck{
+		{
+			service: "processor",
+			check: func(ctx context.Context) error {
+				return processorErr
+			},
+		},
+		{
+			service: "other",
+			check: func(ctx context.Context) error {
+				return nil
+			},
+		},
+	}
+
+	assertStatus := func(service string, expected healthgrpc.HealthCheckResponse_ServingStatus) {
+		resp, err := serv.Check(context.Background(), &healthgrpc.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		assert.Equal(t, expected, resp.Status)
+	}
+
+	updateHealth(log, serv, checks, time.Second)
+	assertStatus("", healthgrpc.HealthCheckResponse_SERVING)
+	assertStatus("processor", healthgrpc.HealthCheckResponse_SERVING)
+	assertStatus("other", healthgrpc.HealthCheckResponse_SERVING)
+
+	processorErr = errors.New("stuck")
+	updateHealth(log, serv, checks, time.Second)
+	assertStatus("", healthgrpc.HealthCheckResponse_NOT_SERVING)
+	assertStatus("processor", healthgrpc.HealthCheckResponse_NOT_SERVING)
+	assertStatus("other", healthgrpc.HealthCheckResponse_SERVING)
+
+	processorErr = nil
+	updateHealth(log, serv, checks, time.Second)
+	assertStatus("", healthgrpc.HealthCheckResponse_SERVING)
+	assertStatus("processor", healthgrpc.HealthCheckResponse_SERVING)
+}
+
+func TestHealthHandlers(t *testing.T) {
+	healthServ := health.NewServer()
+	live := httptest.NewServer(livenessHandler())
+	defer live.Close()
+	ready := httptest.NewServer(healthHandler(healthServ))
+	defer ready.Close()
+
+	statusCode := func(url string) int {
+		resp, err := http.Get(url)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusOK, statusCode(live.URL))
+	assert.Equal(t, http.StatusOK, statusCode(ready.URL))
+	assert.Equal(t, http.StatusServiceUnavailable, statusCode(ready.URL+"?service=unknown"))
+
+	healthServ.SetServingStatus("", healthgrpc.HealthCheckResponse_NOT_SERVING)
+	healthServ.SetServingStatus("db", healthgrpc.HealthCheckResponse_SERVING)
+	assert.Equal(t, http.StatusOK, statusCode(live.URL))
+	assert.Equal(t, http.StatusServiceUnavailable, statusCode(ready.URL))
+	assert.Equal(t, http.StatusOK, statusCode(ready.URL+"?service=db"))
+}
@@ -0,0 +1,133 @@
+package app
+
+import (
+	"crypto/tls"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/kinecosystem/agora-common/headers"
+	"github.com/kinecosystem/agora-common/logging"
+	"github.com/kinecosystem/agora-common/protobuf/validation"
+)
+
+// ListenerOption configures an additional gRPC listener registered with
+// WithListener.
+type ListenerOption func(l *listener)
+
+// WithListenerUnaryInterceptor configures the listener's gRPC server to use the
+// provided interceptor.
+//
+// Interceptors are evaluated in addition order, and configured interceptors are
+// executed after the app's default interceptors. Interceptors configured with
+// WithUnaryServerInterceptor do not apply to the listener.
+func WithListenerUnaryInterceptor(interceptor grpc.UnaryServerInterceptor) ListenerOption {
+	return func(l *listener) {
+		l.unaryServerInterceptors = append(l.unaryServerInterceptors, interceptor)
+	}
+}
+
+// WithListenerStreamInterceptor configures the listener's gRPC server to use the
+// provided interceptor.
+//
+// Interceptors are evaluated in addition order, and configured interceptors are
+// executed after the app's default interceptors. Interceptors configured with
+// WithStreamServerInterceptor do not apply to the listener.
+func WithListenerStreamInterceptor(interceptor grpc.StreamServerInterceptor) ListenerOption {
+	return func(l *listener) {
+		l.streamServerInterceptors = append(l.streamServerInterceptors, interceptor)
+	}
+}
+
+// listener is an additional gRPC server, serving its own set of services.
+type listener struct {
+	name     string
+	register func(s *grpc.Server)
+
+	unaryServerInterceptors  []grpc.UnaryServerInterceptor
+	streamServerInterceptors []grpc.StreamServerInterceptor
+
+	lis          net.Listener
+	creds        credentials.TransportCredentials
+	certReloader *certReloader
+	conns        *connTracker
+	serv         *grpc.Server
+}
+
+func newListener(name string, register func(s *grpc.Server), opts ...ListenerOption) *listener {
+	l := &listener{
+		name:                     name,
+		register:                 register,
+		unaryServerInterceptors:  defaultUnaryServerInterceptors(),
+		streamServerInterceptors: defaultStreamServerInterceptors(),
+		conns:                    &connTracker{},
+	}
+	for _, o := range opts {
+		o(l)
+	}
+
+	return l
+}
+
+// listen starts listening on the address configured for the listener, loading
+// its TLS configuration if one is specified.
+func (l *listener) listen(log logging.Logger, configs map[string]ListenerConfig) (err error) {
+	// viper lower cases keys, so the listener names in the configuration are
+	// always lower case.
+	config, ok := configs[strings.ToLower(l.name)]
+	if !ok || config.Address == "" {
+		return errors.Errorf("no address configured for listener %s", l.name)
+	}
+
+	if config.TLSCertificate != "" {
+		var tlsConfig *tls.Config
+		tlsConfig, l.certReloader, err = newTLSConfig(log.WithField("listener", l.name), config)
+		if err != nil {
+			return errors.Wrapf(err, "failed to configure tls for listener %s", l.name)
+		}
+		l.creds = credentials.NewTLS(tlsConfig)
+	}
+
+	l.lis, err = net.Listen("tcp", config.Address)
+	if err != nil {
+		return errors.Wrapf(err, "failed to listen on %s", config.Address)
+	}
+
+	return nil
+}
+
+// listenerCertReloaders returns the certReloaders of the secure listener (if
+// any) and the additional listeners that are configured with TLS.
+func listenerCertReloaders(secure *certReloader, listeners []*listener) []*certReloader {
+	var reloaders []*certReloader
+	if secure != nil {
+		reloaders = append(reloaders, secure)
+	}
+	for _, l := range listeners {
+		if l.certReloader != nil {
+			reloaders = append(reloaders, l.certReloader)
+		}
+	}
+	return reloaders
+}
+
+// defaultUnaryServerInterceptors returns the unary interceptors that are
+// configured for each gRPC server by default.
+func defaultUnaryServerInterceptors() []grpc.UnaryServerInterceptor {
+	return []grpc.UnaryServerInterceptor{
+		validation.UnaryServerInterceptor(),
+		headers.UnaryServerInterceptor(),
+	}
+}
+
+// defaultStreamServerInterceptors returns the stream interceptors that are
+// configured for each gRPC server by default.
+func defaultStreamServerInterceptors() []grpc.StreamServerInterceptor {
+	return []grpc.StreamServerInterceptor{
+		validation.StreamServerInterceptor(),
+		headers.StreamServerInterceptor(),
+	}
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/kinecosystem/agora-common/logging"
+)
+
+func TestListener(t *testing.T) {
+	var called []string
+	interceptor := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		called = append(called, info.FullMethod)
+		return nil, status.Error(codes.PermissionDenied, "denied")
+	}
+
+	l := newListener("Admin", func(s *grpc.Server) {
+		healthpb.RegisterHealthServer(s, health.NewServer())
+	}, WithListenerUnaryInterceptor(interceptor))
+
+	// The address must be configured.
+	assert.Error(t, l.listen(logging.Default(), nil))
+	assert.Error(t, l.listen(logging.Default(), map[string]ListenerConfig{"admin": {}}))
+
+	require.NoError(t, l.listen(logging.Default(), map[string]ListenerConfig{
+		"admin": {Address: "localhost:0"},
+	}))
+	assert.Nil(t, l.creds)
+	assert.Nil(t, l.certReloader)
+
+	l.serv = grpc.NewServer(grpc.ChainUnaryInterceptor(l.unaryServerInterceptors...))
+	l.register(l.serv)
+	go func() {
+		_ = l.serv.Serve(l.lis)
+	}()
+	defer l.serv.Stop()
+
+	conn, err := grpc.Dial(l.lis.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, []string{"/grpc.health.v1.Health/Check"}, called)
+}
+
+func TestListener_TLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	certPath, keyPath, _, _ := writeTestCert(t, dir, "server", nil, nil)
+
+	l := newListener("admin", func(s *grpc.Server) {})
+	assert.Error(t, l.listen(logging.Default(), map[string]ListenerConfig{
+		"admin": {Address: "localhost:0", TLSCertificate: certPath},
+	}))
+
+	require.NoError(t, l.listen(logging.Default(), map[string]ListenerConfig{
+		"admin": {Address: "localhost:0", TLSCertificate: certPath, TLSKey: keyPath},
+	}))
+	defer l.lis.Close()
+	assert.NotNil(t, l.creds)
+	assert.NotNil(t, l.certReloader)
+
+	plaintext := newListener("internal", func(s *grpc.Server) {})
+	secure := &certReloader{}
+	assert.Equal(t, []*certReloader{secure, l.certReloader}, listenerCertReloaders(secure, []*listener{l, plaintext}))
+	assert.Equal(t, []*certReloader{l.certReloader}, listenerCertReloaders(nil, []*listener{plaintext, l}))
+}
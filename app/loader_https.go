@@ -0,0 +1,113 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kinecosystem/agora-common/timeutil"
+)
+
+const (
+	// HTTPSChecksumParam is the query parameter of an https URL that contains
+	// the hex encoded SHA-256 checksum of the file. It is required.
+	HTTPSChecksumParam = "sha256"
+
+	// HTTPSTimeoutParam is the query parameter of an https URL that contains
+	// the timeout of the download, as either a Go or ISO-8601 duration. If not
+	// specified, a timeout of 1 minute is used.
+	HTTPSTimeoutParam = "timeout"
+)
+
+// HTTPSLoader is a FileLoader that downloads files over HTTPS, such as from an
+// internal file server. The downloaded file must match the checksum specified
+// in the URL. For example:
+//
+//	https://files.internal/certs/server.pem?sha256=<hex>&timeout=30s
+//
+// The checksum and timeout parameters are not sent to the server.
+type HTTPSLoader struct {
+	client *http.Client
+}
+
+// Load implements FileLoader.Load.
+func (l HTTPSLoader) Load(u *url.URL) ([]byte, error) {
+	if u.Scheme != "https" {
+		return nil, errors.Errorf("invalid scheme: %s", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, errors.New("missing host")
+	}
+
+	query := u.Query()
+
+	checksum, err := hex.DecodeString(query.Get(HTTPSChecksumParam))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid checksum")
+	}
+	if len(checksum) != sha256.Size {
+		return nil, errors.Errorf("missing or invalid %s checksum", HTTPSChecksumParam)
+	}
+
+	timeout := time.Minute
+	if s := query.Get(HTTPSTimeoutParam); s != "" {
+		if timeout, err = timeutil.ParseDuration(s); err != nil {
+			return nil, errors.Wrap(err, "invalid timeout")
+		}
+	}
+
+	query.Del(HTTPSChecksumParam)
+	query.Del(HTTPSTimeoutParam)
+	fileURL := *u
+	fileURL.RawQuery = query.Encode()
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+	defer cancelFunc()
+
+	req, err := http.NewRequest(http.MethodGet, fileURL.String(), nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create request for %s", fileURL.String())
+	}
+
+	resp, err := l.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load %s", fileURL.String())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to load %s: unexpected status %s", fileURL.String(), resp.Status)
+	}
+
+	contents, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", fileURL.String())
+	}
+
+	actual := sha256.Sum256(contents)
+	if !bytes.Equal(actual[:], checksum) {
+		return nil, errors.Errorf(
+			"checksum mismatch for %s: expected %s, got %s",
+			fileURL.String(),
+			hex.EncodeToString(checksum),
+			hex.EncodeToString(actual[:]),
+		)
+	}
+
+	return contents, nil
+}
+
+func init() {
+	ctr := func() (FileLoader, error) {
+		return &HTTPSLoader{client: http.DefaultClient}, nil
+	}
+
+	RegisterFileLoaderCtor("https", ctr)
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/external"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/secretsmanageriface"
+	"github.com/pkg/errors"
+)
+
+// SecretsManagerLoader is a FileLoader that loads secrets from AWS Secrets
+// Manager.
+//
+// URLs are of the form secretsmanager://<secret-name>, where the secret name
+// may contain '/'. The version_stage or version_id query parameters may be used
+// to load a specific version of the secret. For example:
+//
+//	secretsmanager://prod/agora/tls-key?version_stage=AWSPREVIOUS
+type SecretsManagerLoader struct {
+	client secretsmanageriface.ClientAPI
+}
+
+// Load implements FileLoader.Load.
+func (l SecretsManagerLoader) Load(url *url.URL) ([]byte, error) {
+	if url.Scheme != "secretsmanager" {
+		return nil, errors.Errorf("invalid scheme: %s", url.Scheme)
+	}
+	if url.Host == "" {
+		return nil, errors.New("missing secret name")
+	}
+
+	input := &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(url.Host + url.Path),
+	}
+	if stage := url.Query().Get("version_stage"); stage != "" {
+		input.VersionStage = aws.String(stage)
+	}
+	if id := url.Query().Get("version_id"); id != "" {
+		input.VersionId = aws.String(id)
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), time.Minute)
+	defer cancelFunc()
+
+	resp, err := l.client.GetSecretValueRequest(input).Send(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load %s", url.String())
+	}
+
+	if resp.SecretString != nil {
+		return []byte(*resp.SecretString), nil
+	}
+	return resp.SecretBinary, nil
+}
+
+func init() {
+	var init sync.Once
+
+	var loader FileLoader
+	var initErr error
+
+	ctr := func() (FileLoader, error) {
+		init.Do(func() {
+			cfg, err := external.LoadDefaultAWSConfig()
+			if err != nil {
+				initErr = errors.Wrap(err, "failed to initialize SecretsManagerLoader")
+				return
+			}
+
+			loader = &SecretsManagerLoader{client: secretsmanager.New(cfg)}
+		})
+
+		if initErr != nil {
+			return nil, initErr
+		}
+
+		return loader, nil
+	}
+
+	RegisterFileLoaderCtor("secretsmanager", ctr)
+}
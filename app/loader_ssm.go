@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/external"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/ssmiface"
+	"github.com/pkg/errors"
+)
+
+// SSMLoader is a FileLoader that loads parameters from the AWS Systems Manager
+// Parameter Store. SecureString parameters are decrypted.
+//
+// URLs are of the form ssm://<parameter-name>. Hierarchical parameter names
+// start with '/', and so are specified with an empty host. For example:
+//
+//	ssm:///prod/agora/tls-key
+type SSMLoader struct {
+	client ssmiface.ClientAPI
+}
+
+// Load implements FileLoader.Load.
+func (l SSMLoader) Load(url *url.URL) ([]byte, error) {
+	if url.Scheme != "ssm" {
+		return nil, errors.Errorf("invalid scheme: %s", url.Scheme)
+	}
+
+	name := url.Host + url.Path
+	if name == "" {
+		return nil, errors.New("missing parameter name")
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), time.Minute)
+	defer cancelFunc()
+
+	resp, err := l.client.GetParameterRequest(&ssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(true),
+	}).Send(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load %s", url.String())
+	}
+	if resp.Parameter == nil || resp.Parameter.Value == nil {
+		return nil, errors.Errorf("parameter %s has no value", name)
+	}
+
+	return []byte(*resp.Parameter.Value), nil
+}
+
+func init() {
+	var init sync.Once
+
+	var loader FileLoader
+	var initErr error
+
+	ctr := func() (FileLoader, error) {
+		init.Do(func() {
+			cfg, err := external.LoadDefaultAWSConfig()
+			if err != nil {
+				initErr = errors.Wrap(err, "failed to initialize SSMLoader")
+				return
+			}
+
+			loader = &SSMLoader{client: ssm.New(cfg)}
+		})
+
+		if initErr != nil {
+			return nil, initErr
+		}
+
+		return loader, nil
+	}
+
+	RegisterFileLoaderCtor("ssm", ctr)
+}
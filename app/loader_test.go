@@ -3,15 +3,24 @@ package app
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"path"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/defaults"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
 	"github.com/ory/dockertest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -88,6 +97,163 @@ func TestS3Loader_BadURL(t *testing.T) {
 	}
 }
 
+// newTestAWSConfig returns an aws.Config that sends requests to a test server,
+// which responds to each request using the handler.
+func newTestAWSConfig(t *testing.T, handler func(target string, body map[string]interface{}) (int, interface{})) (aws.Config, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		status, resp := handler(r.Header.Get("X-Amz-Target"), body)
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		w.WriteHeader(status)
+		require.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+
+	cfg := defaults.Config()
+	cfg.Region = "test-region-1"
+	cfg.Credentials = aws.NewStaticCredentialsProvider("test", "test", "test")
+	cfg.EndpointResolver = aws.ResolveWithEndpointURL(server.URL)
+	return cfg, server.Close
+}
+
+func TestSecretsManagerLoader(t *testing.T) {
+	cfg, cleanup := newTestAWSConfig(t, func(target string, body map[string]interface{}) (int, interface{}) {
+		assert.Equal(t, "secretsmanager.GetSecretValue", target)
+
+		switch body["SecretId"] {
+		case "prod/agora/tls-key":
+			if body["VersionStage"] == "AWSPREVIOUS" {
+				return http.StatusOK, map[string]interface{}{"SecretString": "previous"}
+			}
+			return http.StatusOK, map[string]interface{}{"SecretString": "hello"}
+		case "binary":
+			return http.StatusOK, map[string]interface{}{"SecretBinary": []byte("binary")}
+		default:
+			return http.StatusBadRequest, map[string]interface{}{
+				"__type":  "ResourceNotFoundException",
+				"Message": "secret not found",
+			}
+		}
+	})
+	defer cleanup()
+
+	l := SecretsManagerLoader{
+		client: secretsmanager.New(cfg),
+	}
+
+	for u, expected := range map[string]string{
+		"secretsmanager://prod/agora/tls-key":                           "hello",
+		"secretsmanager://prod/agora/tls-key?version_stage=AWSPREVIOUS": "previous",
+		"secretsmanager://binary":                                       "binary",
+	} {
+		contents, err := l.Load(getURL(t, u))
+		assert.NoError(t, err, "failed to load %s", u)
+		assert.Equal(t, []byte(expected), contents)
+	}
+
+	_, err := l.Load(getURL(t, "secretsmanager://missing"))
+	assert.Error(t, err)
+
+	for _, u := range []string{
+		"file:///file",
+		"secretsmanager://",
+	} {
+		_, err := l.Load(getURL(t, u))
+		assert.NotNil(t, err, "expected url to fail: %s", u)
+	}
+}
+
+func TestSSMLoader(t *testing.T) {
+	cfg, cleanup := newTestAWSConfig(t, func(target string, body map[string]interface{}) (int, interface{}) {
+		assert.Equal(t, "AmazonSSM.GetParameter", target)
+		assert.Equal(t, true, body["WithDecryption"])
+
+		switch body["Name"] {
+		case "/prod/agora/tls-key", "name":
+			return http.StatusOK, map[string]interface{}{
+				"Parameter": map[string]interface{}{"Name": body["Name"], "Value": "hello"},
+			}
+		default:
+			return http.StatusBadRequest, map[string]interface{}{
+				"__type":  "ParameterNotFound",
+				"Message": "parameter not found",
+			}
+		}
+	})
+	defer cleanup()
+
+	l := SSMLoader{
+		client: ssm.New(cfg),
+	}
+
+	for _, u := range []string{
+		"ssm:///prod/agora/tls-key",
+		"ssm://name",
+	} {
+		contents, err := l.Load(getURL(t, u))
+		assert.NoError(t, err, "failed to load %s", u)
+		assert.Equal(t, []byte("hello"), contents)
+	}
+
+	_, err := l.Load(getURL(t, "ssm:///missing"))
+	assert.Error(t, err)
+
+	for _, u := range []string{
+		"file:///file",
+		"ssm://",
+	} {
+		_, err := l.Load(getURL(t, u))
+		assert.NotNil(t, err, "expected url to fail: %s", u)
+	}
+}
+
+func TestHTTPSLoader(t *testing.T) {
+	contents := []byte("hello")
+	sum := sha256.Sum256(contents)
+	checksum := hex.EncodeToString(sum[:])
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The loader parameters are not forwarded to the server.
+		assert.Empty(t, r.URL.Query().Get(HTTPSChecksumParam))
+		assert.Empty(t, r.URL.Query().Get(HTTPSTimeoutParam))
+
+		switch r.URL.Path {
+		case "/file":
+			assert.Equal(t, "1", r.URL.Query().Get("version"))
+			_, _ = w.Write(contents)
+		case "/slow":
+			time.Sleep(100 * time.Millisecond)
+			_, _ = w.Write(contents)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	l := HTTPSLoader{
+		client: server.Client(),
+	}
+
+	actual, err := l.Load(getURL(t, fmt.Sprintf("%s/file?version=1&sha256=%s&timeout=PT1M", server.URL, checksum)))
+	require.NoError(t, err)
+	assert.Equal(t, contents, actual)
+
+	for _, u := range []string{
+		fmt.Sprintf("%s/file?version=1", server.URL),
+		fmt.Sprintf("%s/file?version=1&sha256=%s", server.URL, checksum[2:]),
+		fmt.Sprintf("%s/file?version=1&sha256=%s", server.URL, hex.EncodeToString(make([]byte, sha256.Size))),
+		fmt.Sprintf("%s/file?version=1&sha256=%s&timeout=invalid", server.URL, checksum),
+		fmt.Sprintf("%s/slow?sha256=%s&timeout=10ms", server.URL, checksum),
+		fmt.Sprintf("%s/missing?sha256=%s", server.URL, checksum),
+		fmt.Sprintf("http://%s/file?version=1&sha256=%s", server.Listener.Addr().String(), checksum),
+		fmt.Sprintf("https:///file?sha256=%s", checksum),
+	} {
+		_, err := l.Load(getURL(t, u))
+		assert.NotNil(t, err, "expected url to fail: %s", u)
+	}
+}
+
 func getURL(t *testing.T, u string) *url.URL {
 	url, err := url.Parse(u)
 	require.NoError(t, err)
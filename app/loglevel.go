@@ -0,0 +1,105 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/kinecosystem/agora-common/config"
+	"github.com/kinecosystem/agora-common/logging"
+)
+
+// logLevelPollInterval is the interval at which the log level config provided
+// via WithLogLevelConfig is checked for changes.
+var logLevelPollInterval = 10 * time.Second
+
+// logLevelHandler returns a handler that reports the current log level on GET
+// requests, and changes it on POST requests with a level form value. It is
+// served on the debug listen address (:8123 by default), e.g.:
+//
+//	curl -X POST -d level=debug localhost:8123/debug/loglevel
+//
+// Changes are not persisted, and may be overridden by a log level config.
+func logLevelHandler(log logging.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			level, err := logrus.ParseLevel(strings.ToLower(r.FormValue("level")))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			log.WithFields(logging.Fields{
+				"old_level": logrus.GetLevel().String(),
+				"new_level": level.String(),
+			}).Info("log level changed via debug endpoint")
+			setLogLevel(log, level)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, logrus.GetLevel().String())
+	})
+}
+
+// watchLogLevel polls the level config until the context is cancelled,
+// applying its value whenever it changes. If the config is (or becomes) empty,
+// the default level is applied.
+func watchLogLevel(ctx context.Context, log logging.Logger, levelConfig config.String, defaultLevel logrus.Level) {
+	ticker := time.NewTicker(logLevelPollInterval)
+	defer ticker.Stop()
+
+	var current string
+	for {
+		if value := strings.ToLower(strings.TrimSpace(levelConfig.Get(ctx))); value != current {
+			current = value
+			applyConfigLogLevel(log, value, defaultLevel)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func applyConfigLogLevel(log logging.Logger, value string, defaultLevel logrus.Level) {
+	level := defaultLevel
+	if value != "" {
+		var err error
+		if level, err = logrus.ParseLevel(value); err != nil {
+			log.WithField("log_level", value).Warn("unknown log level in config, ignoring")
+			return
+		}
+	}
+
+	if level == logrus.GetLevel() {
+		return
+	}
+
+	log.WithFields(logging.Fields{
+		"old_level": logrus.GetLevel().String(),
+		"new_level": level.String(),
+	}).Info("log level changed via config")
+	setLogLevel(log, level)
+}
+
+// setLogLevel sets the level of the standard logrus logger, which is used by
+// components that are not configured with a logger, as well as the level of
+// the app's logger, if it is a logging.LevelSetter.
+func setLogLevel(log logging.Logger, level logrus.Level) {
+	logrus.SetLevel(level)
+	if setter, ok := log.(logging.LevelSetter); ok {
+		setter.SetLevel(level)
+	}
+}
@@ -0,0 +1,127 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kinecosystem/agora-common/config/memory"
+	"github.com/kinecosystem/agora-common/config/wrapper"
+	"github.com/kinecosystem/agora-common/logging"
+	"github.com/kinecosystem/agora-common/testutil"
+)
+
+func TestLogLevelHandler(t *testing.T) {
+	defer logrus.SetLevel(logrus.GetLevel())
+	logrus.SetLevel(logrus.InfoLevel)
+
+	handler := logLevelHandler(logging.Default())
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/loglevel", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "info\n", rec.Body.String())
+
+	req := httptest.NewRequest(http.MethodPost, "/debug/loglevel", strings.NewReader(url.Values{"level": {"DEBUG"}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "debug\n", rec.Body.String())
+	assert.Equal(t, logrus.DebugLevel, logrus.GetLevel())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/debug/loglevel?level=invalid", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, logrus.DebugLevel, logrus.GetLevel())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/debug/loglevel", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+type levelSetterLogger struct {
+	logging.Logger
+	level logrus.Level
+}
+
+func (l *levelSetterLogger) SetLevel(level logrus.Level) {
+	l.level = level
+}
+
+func TestLogLevelHandler_LevelSetter(t *testing.T) {
+	defer logrus.SetLevel(logrus.GetLevel())
+	logrus.SetLevel(logrus.InfoLevel)
+
+	log := &levelSetterLogger{Logger: logging.Default(), level: logrus.InfoLevel}
+	handler := logLevelHandler(log)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/debug/loglevel?level=warn", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, logrus.WarnLevel, logrus.GetLevel())
+	assert.Equal(t, logrus.WarnLevel, log.level)
+}
+
+func TestWatchLogLevel(t *testing.T) {
+	defer logrus.SetLevel(logrus.GetLevel())
+	defer func(interval time.Duration) { logLevelPollInterval = interval }(logLevelPollInterval)
+	logLevelPollInterval = 10 * time.Millisecond
+	logrus.SetLevel(logrus.InfoLevel)
+
+	memConfig := memory.NewConfig("debug")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		watchLogLevel(ctx, logging.Default(), wrapper.NewStringConfig(memConfig, ""), logrus.InfoLevel)
+		close(done)
+	}()
+
+	waitForLevel := func(level logrus.Level) {
+		require.NoError(t, testutil.WaitFor(time.Second, 10*time.Millisecond, func() bool {
+			return logrus.GetLevel() == level
+		}))
+	}
+
+	waitForLevel(logrus.DebugLevel)
+
+	// Invalid levels are ignored.
+	memConfig.SetValue("invalid")
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, logrus.DebugLevel, logrus.GetLevel())
+
+	memConfig.SetValue("warn")
+	waitForLevel(logrus.WarnLevel)
+
+	// Clearing the config restores the default level.
+	memConfig.ClearValue()
+	waitForLevel(logrus.InfoLevel)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("watch did not stop")
+	}
+}
+
+func TestSetLogLevel(t *testing.T) {
+	defer logrus.SetLevel(logrus.GetLevel())
+	logrus.SetLevel(logrus.InfoLevel)
+
+	setLogLevel(logging.Default(), logrus.WarnLevel)
+	assert.Equal(t, logrus.WarnLevel, logrus.GetLevel())
+
+	log := &levelSetterLogger{Logger: logging.Default(), level: logrus.InfoLevel}
+	setLogLevel(log, logrus.DebugLevel)
+	assert.Equal(t, logrus.DebugLevel, logrus.GetLevel())
+	assert.Equal(t, logrus.DebugLevel, log.level)
+}
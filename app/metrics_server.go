@@ -0,0 +1,88 @@
+package app
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// parseAllowedIPs parses IP addresses and CIDR ranges into networks.
+func parseAllowedIPs(allowed []string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, s := range allowed {
+		s = strings.TrimSpace(s)
+		if !strings.Contains(s, "/") {
+			ip := net.ParseIP(s)
+			if ip == nil {
+				return nil, errors.Errorf("invalid ip address: %s", s)
+			}
+
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid cidr: %s", s)
+		}
+		networks = append(networks, network)
+	}
+
+	return networks, nil
+}
+
+// newMetricsServerHandler returns the handler served on the dedicated metrics
+// listener, which serves the metrics handler at /metrics, protected by the
+// configured bearer token and IP allowlist.
+func newMetricsServerHandler(config MetricsServerConfig, metricsHandler http.Handler) (http.Handler, error) {
+	allowed, err := parseAllowedIPs(config.AllowedIPs)
+	if err != nil {
+		return nil, err
+	}
+
+	expectedAuth := []byte("Bearer " + config.BearerToken)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(allowed) > 0 && !ipAllowed(r.RemoteAddr, allowed) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		if config.BearerToken != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expectedAuth) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		metricsHandler.ServeHTTP(w, r)
+	}))
+
+	return mux, nil
+}
+
+func ipAllowed(remoteAddr string, allowed []*net.IPNet) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range allowed {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsServerHandler(t *testing.T) {
+	metricsHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	handler, err := newMetricsServerHandler(MetricsServerConfig{
+		BearerToken: "token",
+		AllowedIPs:  []string{"10.0.0.0/8", "192.168.1.1"},
+	}, metricsHandler)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		path       string
+		remoteAddr string
+		auth       string
+		expected   int
+	}{
+		{"/metrics", "10.1.2.3:1234", "Bearer token", http.StatusOK},
+		{"/metrics", "192.168.1.1:1234", "Bearer token", http.StatusOK},
+		{"/metrics", "192.168.1.2:1234", "Bearer token", http.StatusForbidden},
+		{"/metrics", "10.1.2.3:1234", "Bearer other", http.StatusUnauthorized},
+		{"/metrics", "10.1.2.3:1234", "", http.StatusUnauthorized},
+		{"/debug/pprof/", "10.1.2.3:1234", "Bearer token", http.StatusNotFound},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		req.RemoteAddr = tc.remoteAddr
+		if tc.auth != "" {
+			req.Header.Set("Authorization", tc.auth)
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, tc.expected, rec.Code, "%s from %s", tc.path, tc.remoteAddr)
+	}
+
+	// Without any protection configured, all requests are allowed.
+	handler, err = newMetricsServerHandler(MetricsServerConfig{}, metricsHandler)
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	_, err = newMetricsServerHandler(MetricsServerConfig{AllowedIPs: []string{"invalid"}}, metricsHandler)
+	assert.Error(t, err)
+	_, err = newMetricsServerHandler(MetricsServerConfig{AllowedIPs: []string{"10.0.0.0/33"}}, metricsHandler)
+	assert.Error(t, err)
+}
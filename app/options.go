@@ -1,9 +1,12 @@
 package app
 
 import (
+	"github.com/prometheus/client_golang/prometheus"
 	"google.golang.org/grpc"
 
+	"github.com/kinecosystem/agora-common/config"
 	"github.com/kinecosystem/agora-common/httpgateway"
+	"github.com/kinecosystem/agora-common/logging"
 )
 
 // Option configures the environment run by Run().
@@ -15,6 +18,36 @@ type opts struct {
 
 	httpGatewayEnabled bool
 	httpGatewayOptions []httpgateway.MuxOption
+	httpCORSOrigins    []string
+	grpcWebEnabled     bool
+
+	healthChecks []namedHealthCheck
+
+	metricsRegistry *prometheus.Registry
+	runtimeMetrics  *bool
+
+	logger      logging.Logger
+	logLevelCfg config.String
+
+	listeners []*listener
+}
+
+func newOpts(options ...Option) opts {
+	o := opts{
+		unaryServerInterceptors:  defaultUnaryServerInterceptors(),
+		streamServerInterceptors: defaultStreamServerInterceptors(),
+	}
+	for _, option := range options {
+		option(&o)
+	}
+	return o
+}
+
+func (o *opts) metricsRegisterer() prometheus.Registerer {
+	if o.metricsRegistry != nil {
+		return o.metricsRegistry
+	}
+	return prometheus.DefaultRegisterer
 }
 
 // WithUnaryServerInterceptor configures the app's gRPC server to use the provided interceptor.
@@ -44,3 +77,90 @@ func WithHTTPGatewayEnabled(enabled bool, muxOpts ...httpgateway.MuxOption) Opti
 		o.httpGatewayOptions = muxOpts
 	}
 }
+
+// WithHTTPCORSOrigins configures the grpc-gateway handlers registered by an
+// HTTPApp, and gRPC-Web requests (see WithGRPCWebEnabled), to allow
+// cross-origin requests from the provided origins ("*" allows all origins).
+func WithHTTPCORSOrigins(origins ...string) Option {
+	return func(o *opts) {
+		o.httpCORSOrigins = append(o.httpCORSOrigins, origins...)
+	}
+}
+
+// WithGRPCWebEnabled configures whether or not the HTTP gateway serves
+// gRPC-Web requests with the app's gRPC server, allowing browser-based clients
+// to call the app's services directly. Enabling gRPC-Web enables the HTTP
+// gateway.
+func WithGRPCWebEnabled(enabled bool) Option {
+	return func(o *opts) {
+		o.grpcWebEnabled = enabled
+	}
+}
+
+// WithHealthCheck configures a health check that is periodically run, with the
+// result reported by the app's gRPC health service for the provided service name.
+//
+// The overall health of the server (the empty service name) is only serving if
+// all configured health checks pass. An empty service name may be provided to
+// only affect the overall health.
+func WithHealthCheck(service string, check HealthCheck) Option {
+	return func(o *opts) {
+		o.healthChecks = append(o.healthChecks, namedHealthCheck{
+			service: service,
+			check:   check,
+		})
+	}
+}
+
+// WithMetricsRegistry configures the app to register its metrics with, and
+// serve metrics from, the provided registry instead of the default registry.
+func WithMetricsRegistry(registry *prometheus.Registry) Option {
+	return func(o *opts) {
+		o.metricsRegistry = registry
+	}
+}
+
+// WithRuntimeMetrics configures whether or not the Go runtime and process
+// metrics are exposed on the /metrics endpoint, overriding the
+// enable_runtime_metrics config.
+func WithRuntimeMetrics(enabled bool) Option {
+	return func(o *opts) {
+		o.runtimeMetrics = &enabled
+	}
+}
+
+// WithLogger configures the logger used by Run, instead of the standard logrus
+// logger. The log_type configuration only applies to the standard logrus
+// logger, while log_level (and any changes made via /debug/loglevel or
+// WithLogLevelConfig) also applies to the logger if it is a
+// logging.LevelSetter, such as those returned by zaplogger.NewWithLevel.
+func WithLogger(log logging.Logger) Option {
+	return func(o *opts) {
+		o.logger = log
+	}
+}
+
+// WithLogLevelConfig configures Run to apply the log level provided by the
+// config (e.g. an etcd backed config.String) to the standard logrus logger,
+// overriding log_level, so that debug logging can be enabled without a
+// restart. If the config is empty, log_level is used.
+//
+// The config is checked for changes periodically.
+func WithLogLevelConfig(level config.String) Option {
+	return func(o *opts) {
+		o.logLevelCfg = level
+	}
+}
+
+// WithListener configures Run to serve an additional gRPC server, such as an
+// admin service on an internal-only port. The services of the server are
+// registered by the provided function, rather than by App.RegisterWithGRPC.
+//
+// The address and TLS settings of the listener are configured under
+// listeners.<name> (see ListenerConfig), and its interceptor chain is
+// independent of the interceptors configured for the app's servers.
+func WithListener(name string, register func(s *grpc.Server), listenerOpts ...ListenerOption) Option {
+	return func(o *opts) {
+		o.listeners = append(o.listeners, newListener(name, register, listenerOpts...))
+	}
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	"github.com/kinecosystem/agora-common/metrics"
+)
+
+// NewServer initializes the app with the provided configuration, and returns a
+// gRPC server with the services, interceptors, health service and (if
+// enabled) reflection service that Run configures.
+//
+// Unlike Run, NewServer does not load the configuration, listen, handle
+// signals, or serve the debug and HTTP gateway endpoints. It is intended for
+// in-process tests of an app (see testutil/apptest). Callers are responsible
+// for serving, and stopping both the server and the app.
+func NewServer(app App, config BaseConfig, options ...Option) (*grpc.Server, error) {
+	opts := newOpts(options...)
+
+	healthServ := health.NewServer()
+	if err := initApp(app, config, healthServ); err != nil {
+		return nil, err
+	}
+
+	grpcMetrics := grpc_prometheus.DefaultServerMetrics
+	if opts.metricsRegistry != nil {
+		grpcMetrics = grpc_prometheus.NewServerMetrics()
+		metrics.RegisterWith(opts.metricsRegistry, "", nil, grpcMetrics)
+	}
+
+	newServerOpts := newServerOptsFunc(config, grpcMetrics, opts.metricsRegisterer())
+	serv := grpc.NewServer(newServerOpts(opts.unaryServerInterceptors, opts.streamServerInterceptors)...)
+	app.RegisterWithGRPC(serv)
+	if config.EnableReflection {
+		reflection.Register(serv)
+	}
+	grpcMetrics.InitializeMetrics(serv)
+	healthgrpc.RegisterHealthServer(serv, healthServ)
+
+	return serv, nil
+}
+
+// initApp initializes the app, including its warmup if it implements
+// WarmupApp. Apps that implement HealthApp are provided the health server
+// before they are initialized.
+func initApp(app App, config BaseConfig, healthServ *health.Server) error {
+	if healthApp, ok := app.(HealthApp); ok {
+		healthApp.SetHealthServer(healthServ)
+	}
+
+	// Apps that warm up are not ready until their warmup has completed.
+	warmupApp, isWarmupApp := app.(WarmupApp)
+	if isWarmupApp {
+		healthServ.SetServingStatus("", healthgrpc.HealthCheckResponse_NOT_SERVING)
+	}
+
+	if err := app.Init(config.AppConfig); err != nil {
+		return errors.Wrap(err, "failed to initialize app")
+	}
+
+	if isWarmupApp {
+		if err := runWarmup(warmupApp, config.WarmupTimeout); err != nil {
+			return err
+		}
+		healthServ.SetServingStatus("", healthgrpc.HealthCheckResponse_SERVING)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kinecosystem/agora-common/logging"
+)
+
+// ShutdownHook cleans up a resource when the app is shut down. The provided
+// context is cancelled once the hook's timeout has elapsed.
+type ShutdownHook func(ctx context.Context)
+
+type shutdownHook struct {
+	hook    ShutdownHook
+	timeout time.Duration
+}
+
+var (
+	shutdownHooksMu sync.Mutex
+	shutdownHooks   []shutdownHook
+)
+
+// OnShutdown registers a hook that is run by Run once the gRPC servers and the
+// app have been stopped, with the configured shutdown_hook_timeout.
+//
+// Hooks are run sequentially, in the reverse order of registration, so that
+// resources are cleaned up in the reverse order they were created (e.g. a queue
+// is flushed before the database pool it writes to is closed).
+func OnShutdown(hook ShutdownHook) {
+	OnShutdownWithTimeout(0, hook)
+}
+
+// OnShutdownWithTimeout registers a hook like OnShutdown, with the provided
+// timeout instead of the configured shutdown_hook_timeout.
+func OnShutdownWithTimeout(timeout time.Duration, hook ShutdownHook) {
+	shutdownHooksMu.Lock()
+	defer shutdownHooksMu.Unlock()
+
+	shutdownHooks = append(shutdownHooks, shutdownHook{
+		hook:    hook,
+		timeout: timeout,
+	})
+}
+
+// runShutdownHooks runs the registered shutdown hooks in the reverse order of
+// registration. If a hook does not return within its timeout, the remaining
+// hooks are run without waiting for it.
+func runShutdownHooks(log logging.Logger, defaultTimeout time.Duration) {
+	log = log.WithField("method", "runShutdownHooks")
+
+	shutdownHooksMu.Lock()
+	hooks := make([]shutdownHook, len(shutdownHooks))
+	copy(hooks, shutdownHooks)
+	shutdownHooksMu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		timeout := hooks[i].timeout
+		if timeout <= 0 {
+			timeout = defaultTimeout
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		doneCh := make(chan struct{})
+		go func(hook ShutdownHook) {
+			defer close(doneCh)
+			hook(ctx)
+		}(hooks[i].hook)
+
+		select {
+		case <-doneCh:
+		case <-ctx.Done():
+			log.WithField("hook", i).Warnf("shutdown hook did not complete within %v", timeout)
+		}
+		cancel()
+	}
+}
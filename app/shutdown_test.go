@@ -0,0 +1,49 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kinecosystem/agora-common/logging"
+)
+
+func TestShutdownHooks(t *testing.T) {
+	shutdownHooksMu.Lock()
+	shutdownHooks = nil
+	shutdownHooksMu.Unlock()
+
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, name)
+	}
+
+	OnShutdown(func(ctx context.Context) {
+		record("db")
+	})
+	OnShutdownWithTimeout(10*time.Millisecond, func(ctx context.Context) {
+		// Blocks until its timeout elapses, which should not hold up the
+		// remaining hooks for longer than its timeout.
+		<-ctx.Done()
+	})
+	OnShutdown(func(ctx context.Context) {
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.True(t, time.Until(deadline) > 500*time.Millisecond)
+		record("queue")
+	})
+
+	start := time.Now()
+	runShutdownHooks(logging.Default(), time.Second)
+	assert.True(t, time.Since(start) < 500*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"queue", "db"}, order)
+}
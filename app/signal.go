@@ -0,0 +1,62 @@
+package app
+
+import (
+	"bytes"
+	"runtime"
+	"runtime/pprof"
+	"sync"
+
+	"github.com/kinecosystem/agora-common/logging"
+)
+
+// ReloadHook reloads a resource, such as configuration that is read once at
+// startup, when the process receives SIGHUP.
+type ReloadHook func() error
+
+var (
+	reloadHooksMu sync.Mutex
+	reloadHooks   []ReloadHook
+)
+
+// OnReload registers a hook that is run by Run when the process receives
+// SIGHUP, after the TLS certificates (if configured) have been reloaded.
+//
+// Hooks are run sequentially, in the order of registration. Errors returned
+// by hooks are logged, and do not prevent the remaining hooks from running.
+func OnReload(hook ReloadHook) {
+	reloadHooksMu.Lock()
+	defer reloadHooksMu.Unlock()
+
+	reloadHooks = append(reloadHooks, hook)
+}
+
+// runReloadHooks runs the registered reload hooks in the order of registration.
+func runReloadHooks(log logging.Logger) {
+	log = log.WithField("method", "runReloadHooks")
+
+	reloadHooksMu.Lock()
+	hooks := make([]ReloadHook, len(reloadHooks))
+	copy(hooks, reloadHooks)
+	reloadHooksMu.Unlock()
+
+	for i, hook := range hooks {
+		if err := hook(); err != nil {
+			log.WithError(err).WithField("hook", i).Warn("reload hook failed")
+		}
+	}
+}
+
+// dumpGoroutines logs the stacks of all goroutines, which is useful for
+// diagnosing deadlocks when the pprof endpoints are not reachable.
+func dumpGoroutines(log logging.Logger) {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 2); err != nil {
+		log.WithError(err).Warn("failed to dump goroutines")
+		return
+	}
+
+	log.WithFields(logging.Fields{
+		"goroutines": runtime.NumGoroutine(),
+		"stacks":     buf.String(),
+	}).Info("goroutine dump")
+}
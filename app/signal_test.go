@@ -0,0 +1,33 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kinecosystem/agora-common/logging"
+)
+
+func TestReloadHooks(t *testing.T) {
+	reloadHooksMu.Lock()
+	reloadHooks = nil
+	reloadHooksMu.Unlock()
+
+	var order []string
+	OnReload(func() error {
+		order = append(order, "config")
+		return errors.New("failed to reload config")
+	})
+	OnReload(func() error {
+		order = append(order, "cache")
+		return nil
+	})
+
+	// A failed hook does not prevent the remaining hooks from running.
+	runReloadHooks(logging.Default())
+	assert.Equal(t, []string{"config", "cache"}, order)
+
+	runReloadHooks(logging.Default())
+	assert.Equal(t, []string{"config", "cache", "config", "cache"}, order)
+}
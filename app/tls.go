@@ -0,0 +1,147 @@
+package app
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kinecosystem/agora-common/logging"
+)
+
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"":                   tls.NoClientCert,
+	"none":               tls.NoClientCert,
+	"request":            tls.RequestClientCert,
+	"require":            tls.RequireAnyClientCert,
+	"verify_if_given":    tls.VerifyClientCertIfGiven,
+	"require_and_verify": tls.RequireAndVerifyClientCert,
+}
+
+// clientAuthVerifies returns whether or not the client auth mode verifies
+// client certificates, which requires the client CAs to be configured.
+func clientAuthVerifies(clientAuth tls.ClientAuthType) bool {
+	return clientAuth == tls.VerifyClientCertIfGiven || clientAuth == tls.RequireAndVerifyClientCert
+}
+
+// newTLSConfig returns the tls.Config of a gRPC listener, along with the
+// certReloader that serves its certificate.
+func newTLSConfig(log logging.Logger, config ListenerConfig) (*tls.Config, *certReloader, error) {
+	if config.TLSKey == "" {
+		return nil, nil, errors.New("tls key must be provided if certificate is specified")
+	}
+
+	reloader, err := newCertReloader(log, config.TLSCertificate, config.TLSKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clientAuth, ok := clientAuthTypes[strings.ToLower(config.TLSClientAuth)]
+	if !ok {
+		return nil, nil, errors.Errorf("unknown tls client auth mode: %s", config.TLSClientAuth)
+	}
+
+	tlsConfig := &tls.Config{
+		GetCertificate: reloader.getCertificate,
+		ClientAuth:     clientAuth,
+	}
+
+	if config.TLSClientCA != "" {
+		caBytes, err := LoadFile(config.TLSClientCA)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to load tls client ca")
+		}
+
+		tlsConfig.ClientCAs = x509.NewCertPool()
+		if !tlsConfig.ClientCAs.AppendCertsFromPEM(caBytes) {
+			return nil, nil, errors.New("tls client ca does not contain any valid certificates")
+		}
+	} else if clientAuthVerifies(clientAuth) {
+		return nil, nil, errors.New("tls client ca must be provided to verify client certificates")
+	}
+
+	return tlsConfig, reloader, nil
+}
+
+// certReloader serves a TLS certificate that can be reloaded from its URLs,
+// allowing certificates to be rotated without restarting the application.
+type certReloader struct {
+	log     logging.Logger
+	certURL string
+	keyURL  string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+}
+
+func newCertReloader(log logging.Logger, certURL, keyURL string) (*certReloader, error) {
+	r := &certReloader{
+		log:     log.WithField("method", "certReloader"),
+		certURL: certURL,
+		keyURL:  keyURL,
+	}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// reload loads the certificate and key from their URLs. If either fails to
+// load, the previous certificate continues to be served.
+func (r *certReloader) reload() error {
+	certBytes, err := LoadFile(r.certURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to load tls certificate")
+	}
+
+	keyBytes, err := LoadFile(r.keyURL)
+	if err != nil {
+		return errors.Wrap(err, "failed to load tls key")
+	}
+
+	cert, err := tls.X509KeyPair(certBytes, keyBytes)
+	if err != nil {
+		return errors.Wrap(err, "invalid certificate/private key")
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.mu.Unlock()
+
+	return nil
+}
+
+// reloadAndLog reloads the certificate, logging the result.
+func (r *certReloader) reloadAndLog() {
+	if err := r.reload(); err != nil {
+		r.log.WithError(err).Warn("failed to reload tls certificate, continuing to use previous certificate")
+		return
+	}
+
+	r.log.Info("reloaded tls certificate")
+}
+
+// run periodically reloads the certificate until stopCh is closed.
+func (r *certReloader) run(interval time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			r.reloadAndLog()
+		}
+	}
+}
+
+func (r *certReloader) getCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
@@ -0,0 +1,194 @@
+package app
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kinecosystem/agora-common/logging"
+)
+
+// writeTestCert writes a PEM encoded certificate and key signed by the parent
+// (or self-signed, if parent is nil) to the directory, returning their paths.
+func writeTestCert(t *testing.T, dir, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (certPath, keyPath string, cert *x509.Certificate, key *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err = x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPath = filepath.Join(dir, name+".crt")
+	keyPath = filepath.Join(dir, name+".key")
+	require.NoError(t, ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+
+	return certPath, keyPath, cert, key
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	caPath, _, ca, caKey := writeTestCert(t, dir, "ca", nil, nil)
+	certPath, keyPath, _, _ := writeTestCert(t, dir, "server", ca, caKey)
+
+	config := ListenerConfig{
+		TLSCertificate: certPath,
+		TLSKey:         keyPath,
+	}
+	tlsConfig, _, err := newTLSConfig(logging.Default(), config)
+	require.NoError(t, err)
+	assert.NotNil(t, tlsConfig.GetCertificate)
+	assert.Equal(t, tls.NoClientCert, tlsConfig.ClientAuth)
+	assert.Nil(t, tlsConfig.ClientCAs)
+
+	config.TLSClientAuth = "require_and_verify"
+	_, _, err = newTLSConfig(logging.Default(), config)
+	assert.Error(t, err)
+
+	config.TLSClientCA = caPath
+	tlsConfig, _, err = newTLSConfig(logging.Default(), config)
+	require.NoError(t, err)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, tlsConfig.ClientAuth)
+	assert.NotNil(t, tlsConfig.ClientCAs)
+
+	config.TLSClientAuth = "invalid"
+	_, _, err = newTLSConfig(logging.Default(), config)
+	assert.Error(t, err)
+
+	config.TLSClientAuth = "request"
+	config.TLSClientCA = keyPath
+	_, _, err = newTLSConfig(logging.Default(), config)
+	assert.Error(t, err)
+
+	_, _, err = newTLSConfig(logging.Default(), ListenerConfig{TLSCertificate: certPath})
+	assert.Error(t, err)
+}
+
+func TestNewTLSConfig_MutualTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	caPath, _, ca, caKey := writeTestCert(t, dir, "ca", nil, nil)
+	certPath, keyPath, _, _ := writeTestCert(t, dir, "server", ca, caKey)
+	clientCertPath, clientKeyPath, _, _ := writeTestCert(t, dir, "client", ca, caKey)
+
+	tlsConfig, _, err := newTLSConfig(logging.Default(), ListenerConfig{
+		TLSCertificate: certPath,
+		TLSKey:         keyPath,
+		TLSClientCA:    caPath,
+		TLSClientAuth:  "require_and_verify",
+	})
+	require.NoError(t, err)
+
+	lis, err := tls.Listen("tcp", "localhost:0", tlsConfig)
+	require.NoError(t, err)
+	defer lis.Close()
+
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	handshake := func(certs []tls.Certificate) error {
+		conn, err := tls.Dial("tcp", lis.Addr().String(), &tls.Config{
+			RootCAs:      roots,
+			Certificates: certs,
+			ServerName:   "localhost",
+		})
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		// With TLS 1.3, client certificate failures are only reported once
+		// the connection is used.
+		_, err = conn.Read(make([]byte, 1))
+		return err
+	}
+
+	assert.Error(t, handshake(nil))
+
+	clientCert, err := tls.LoadX509KeyPair(clientCertPath, clientKeyPath)
+	require.NoError(t, err)
+
+	// The server closes the connection after a successful handshake.
+	assert.Equal(t, io.EOF, handshake([]tls.Certificate{clientCert}))
+}
+
+func TestCertReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, _, ca, caKey := writeTestCert(t, dir, "ca", nil, nil)
+	certPath, keyPath, first, _ := writeTestCert(t, dir, "server", ca, caKey)
+
+	reloader, err := newCertReloader(logging.Default(), certPath, keyPath)
+	require.NoError(t, err)
+
+	cert, err := reloader.getCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, first.Raw, cert.Certificate[0])
+
+	// Rotate the certificate, which overwrites the files.
+	_, _, second, _ := writeTestCert(t, dir, "server", ca, caKey)
+	require.NoError(t, reloader.reload())
+
+	cert, err = reloader.getCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, second.Raw, cert.Certificate[0])
+
+	// A failed reload continues to serve the previous certificate.
+	require.NoError(t, ioutil.WriteFile(keyPath, []byte("invalid"), 0600))
+	assert.Error(t, reloader.reload())
+
+	cert, err = reloader.getCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, second.Raw, cert.Certificate[0])
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpgrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+// newTracerProvider returns a TracerProvider that exports spans to the OTLP
+// collector in the configuration, and installs it (along with the W3C trace
+// context and baggage propagators) as the global provider.
+//
+// The provider should be shut down to flush any buffered spans.
+func newTracerProvider(ctx context.Context, config TracingConfig) (*sdktrace.TracerProvider, error) {
+	driverOpts := []otlpgrpc.Option{
+		otlpgrpc.WithEndpoint(config.Endpoint),
+	}
+	if config.Insecure {
+		driverOpts = append(driverOpts, otlpgrpc.WithInsecure())
+	}
+	if len(config.Headers) > 0 {
+		driverOpts = append(driverOpts, otlpgrpc.WithHeaders(config.Headers))
+	}
+
+	exporter, err := otlp.NewExporter(ctx, otlpgrpc.NewDriver(driverOpts...))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create otlp exporter")
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRate))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.ServiceNameKey.String(config.ServiceName))),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return provider, nil
+}
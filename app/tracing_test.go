@@ -0,0 +1,39 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+)
+
+func TestNewTracerProvider(t *testing.T) {
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	defer func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	}()
+
+	config := defaultConfig.Tracing
+	config.Insecure = true
+	config.ServiceName = "test"
+
+	provider, err := newTracerProvider(context.Background(), config)
+	require.NoError(t, err)
+	defer func() {
+		// The collector does not exist, so flushing may fail.
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		_ = provider.Shutdown(ctx)
+	}()
+
+	assert.Equal(t, provider, otel.GetTracerProvider())
+	assert.ElementsMatch(t, []string{"traceparent", "tracestate", "baggage"}, otel.GetTextMapPropagator().Fields())
+
+	_, span := otel.Tracer("test").Start(context.Background(), "span")
+	assert.True(t, span.SpanContext().IsSampled())
+	span.End()
+}
@@ -0,0 +1,180 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// redactedValue replaces the values of sensitive keys in the config summary.
+const redactedValue = "[redacted]"
+
+// sensitiveKeyPattern matches the config keys whose values are redacted from
+// the config summary, including keys of the app config and of maps such as
+// tracing.headers.
+var sensitiveKeyPattern = regexp.MustCompile(`(?i)(secret|password|passwd|token|private_key|api_key|apikey|credential|authorization)`)
+
+// unmarshalConfig unmarshals the configuration loaded by v into config.
+// Unknown keys are rejected, so that typos are not silently ignored.
+func unmarshalConfig(v *viper.Viper, config *BaseConfig) error {
+	return v.Unmarshal(config, viper.DecodeHook(DecodeHook), func(c *mapstructure.DecoderConfig) {
+		c.ErrorUnused = true
+	})
+}
+
+// validate returns an error describing all of the problems with the config,
+// or nil if there are none.
+func (c BaseConfig) validate() error {
+	var problems []string
+	addProblem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if c.EnableInsecureListener && c.InsecureListenAddress == "" {
+		addProblem("insecure_listen_address must be set if enable_insecure_listener is set")
+	}
+	if c.TLSCertificate != "" && c.ListenAddress == "" {
+		addProblem("listen_address must be set if tls_certificate is set")
+	}
+	for _, p := range validateTLS("", c.secureListenerConfig()) {
+		addProblem("%s", p)
+	}
+
+	for name, l := range c.Listeners {
+		prefix := fmt.Sprintf("listeners.%s.", name)
+		if l.Address == "" {
+			addProblem("%saddress must be set", prefix)
+		}
+		for _, p := range validateTLS(prefix, l) {
+			addProblem("%s", p)
+		}
+	}
+
+	if c.ShutdownGracePeriod <= 0 {
+		addProblem("shutdown_grace_period must be positive")
+	}
+	if c.HealthCheckInterval <= 0 {
+		addProblem("health_check_interval must be positive")
+	}
+	if c.RequestLog.SampleRate < 0 || c.RequestLog.SampleRate > 1 {
+		addProblem("request_log.sample_rate must be between 0 and 1")
+	}
+	if c.Tracing.Enabled && c.Tracing.Endpoint == "" {
+		addProblem("tracing.endpoint must be set if tracing is enabled")
+	}
+	if c.Tracing.SampleRate < 0 || c.Tracing.SampleRate > 1 {
+		addProblem("tracing.sample_rate must be between 0 and 1")
+	}
+	if c.Deadline.DefaultTimeout > 0 && c.Deadline.MaxTimeout > 0 && c.Deadline.DefaultTimeout > c.Deadline.MaxTimeout {
+		addProblem("deadline.default_timeout must not exceed deadline.max_timeout")
+	}
+
+	if _, err := parseAllowedIPs(c.MetricsServer.AllowedIPs); err != nil {
+		addProblem("metrics_server.allowed_ips: %v", err)
+	}
+	if c.MetricsServer.ListenAddress == "" && (c.MetricsServer.BearerToken != "" || len(c.MetricsServer.AllowedIPs) > 0) {
+		addProblem("metrics_server.listen_address must be set if other metrics_server options are set")
+	}
+
+	if len(problems) > 0 {
+		return errors.Errorf("invalid config: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// validateTLS returns the problems with the TLS configuration of a listener,
+// with the keys prefixed by the provided prefix.
+func validateTLS(prefix string, config ListenerConfig) []string {
+	var problems []string
+
+	if config.TLSCertificate != "" && config.TLSKey == "" {
+		problems = append(problems, fmt.Sprintf("%stls_private_key must be set if %stls_certificate is set", prefix, prefix))
+	}
+	if config.TLSCertificate == "" {
+		if config.TLSKey != "" || config.TLSClientCA != "" || config.TLSClientAuth != "" {
+			problems = append(problems, fmt.Sprintf("%stls_certificate must be set if other tls options are set", prefix))
+		}
+	}
+
+	clientAuth, ok := clientAuthTypes[strings.ToLower(config.TLSClientAuth)]
+	if !ok {
+		problems = append(problems, fmt.Sprintf("unknown %stls_client_auth mode: %s", prefix, config.TLSClientAuth))
+	} else if config.TLSClientCA == "" && clientAuthVerifies(clientAuth) {
+		problems = append(problems, fmt.Sprintf("%stls_client_ca must be set to verify client certificates", prefix))
+	}
+
+	return problems
+}
+
+// redactedConfig returns the config as a map keyed by the config keys, with
+// the values of sensitive keys redacted, so that it can be logged.
+func redactedConfig(config BaseConfig) map[string]interface{} {
+	summary := make(map[string]interface{})
+	if err := mapstructure.Decode(config, &summary); err != nil {
+		return map[string]interface{}{"error": err.Error()}
+	}
+
+	summary = redact(summary).(map[string]interface{})
+
+	// Tracing headers are typically used for authentication with the
+	// collector, regardless of their names.
+	if tracing, ok := summary["tracing"].(map[string]interface{}); ok {
+		if headers, ok := tracing["headers"].(map[string]interface{}); ok {
+			for k := range headers {
+				headers[k] = redactedValue
+			}
+		}
+	}
+
+	return summary
+}
+
+// redact returns a copy of v in which maps and structs are converted to
+// map[string]interface{}, and the values of sensitive keys are redacted.
+func redact(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+
+	if s, ok := v.(fmt.Stringer); ok {
+		return s.String()
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Struct:
+		m := make(map[string]interface{})
+		if err := mapstructure.Decode(v, &m); err != nil {
+			return redactedValue
+		}
+		return redact(m)
+	case reflect.Map:
+		m := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			if sensitiveKeyPattern.MatchString(key) {
+				m[key] = redactedValue
+			} else {
+				m[key] = redact(iter.Value().Interface())
+			}
+		}
+		return m
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return v
+		}
+		s := make([]interface{}, rv.Len())
+		for i := range s {
+			s[i] = redact(rv.Index(i).Interface())
+		}
+		return s
+	default:
+		return v
+	}
+}
@@ -0,0 +1,157 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, defaultConfig.validate())
+
+	valid := defaultConfig
+	valid.TLSCertificate = "file:///cert.pem"
+	valid.TLSKey = "file:///key.pem"
+	valid.TLSClientCA = "file:///ca.pem"
+	valid.TLSClientAuth = "require_and_verify"
+	valid.Listeners = map[string]ListenerConfig{
+		"admin": {Address: "localhost:8090"},
+	}
+	assert.NoError(t, valid.validate())
+
+	for name, modify := range map[string]func(c *BaseConfig){
+		"invalid metrics server allowed ip": func(c *BaseConfig) {
+			c.MetricsServer.ListenAddress = "localhost:9090"
+			c.MetricsServer.AllowedIPs = []string{"invalid"}
+		},
+		"missing metrics server listen address": func(c *BaseConfig) {
+			c.MetricsServer.BearerToken = "token"
+		},
+		"missing tls key": func(c *BaseConfig) {
+			c.TLSCertificate = "file:///cert.pem"
+		},
+		"missing tls certificate": func(c *BaseConfig) {
+			c.TLSKey = "file:///key.pem"
+		},
+		"missing tls client ca": func(c *BaseConfig) {
+			c.TLSCertificate = "file:///cert.pem"
+			c.TLSKey = "file:///key.pem"
+			c.TLSClientAuth = "verify_if_given"
+		},
+		"invalid tls client auth": func(c *BaseConfig) {
+			c.TLSCertificate = "file:///cert.pem"
+			c.TLSKey = "file:///key.pem"
+			c.TLSClientAuth = "invalid"
+		},
+		"missing insecure listen address": func(c *BaseConfig) {
+			c.InsecureListenAddress = ""
+		},
+		"missing listener address": func(c *BaseConfig) {
+			c.Listeners = map[string]ListenerConfig{"admin": {}}
+		},
+		"missing listener tls key": func(c *BaseConfig) {
+			c.Listeners = map[string]ListenerConfig{"admin": {Address: ":8090", TLSCertificate: "file:///cert.pem"}}
+		},
+		"invalid shutdown grace period": func(c *BaseConfig) {
+			c.ShutdownGracePeriod = 0
+		},
+		"invalid health check interval": func(c *BaseConfig) {
+			c.HealthCheckInterval = 0
+		},
+		"invalid request log sample rate": func(c *BaseConfig) {
+			c.RequestLog.SampleRate = 2
+		},
+		"missing tracing endpoint": func(c *BaseConfig) {
+			c.Tracing.Enabled = true
+			c.Tracing.Endpoint = ""
+		},
+		"invalid deadlines": func(c *BaseConfig) {
+			c.Deadline.DefaultTimeout = time.Minute
+			c.Deadline.MaxTimeout = time.Second
+		},
+	} {
+		config := defaultConfig
+		modify(&config)
+		assert.Error(t, config.validate(), name)
+	}
+
+	// All of the problems are reported.
+	config := defaultConfig
+	config.ShutdownGracePeriod = 0
+	config.TLSKey = "file:///key.pem"
+	err := config.validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "shutdown_grace_period")
+	assert.Contains(t, err.Error(), "tls_certificate")
+}
+
+func TestRedactedConfig(t *testing.T) {
+	config := defaultConfig
+	config.TLSCertificate = "file:///cert.pem"
+	config.TLSKey = "file:///key.pem"
+	config.Tracing.Headers = map[string]string{"x-honeycomb-team": "secret"}
+	config.Listeners = map[string]ListenerConfig{
+		"admin": {Address: ":8090", TLSKey: "file:///admin-key.pem"},
+	}
+	config.AppConfig = Config{
+		"db_password": "hunter2",
+		"cache": map[string]interface{}{
+			"size":      10,
+			"api_token": "abc",
+		},
+	}
+
+	summary := redactedConfig(config)
+	assert.Equal(t, "file:///cert.pem", summary["tls_certificate"])
+	assert.Equal(t, redactedValue, summary["tls_private_key"])
+	assert.Equal(t, "30s", summary["shutdown_grace_period"])
+	assert.Equal(t, map[string]interface{}{"x-honeycomb-team": redactedValue}, summary["tracing"].(map[string]interface{})["headers"])
+
+	admin := summary["listeners"].(map[string]interface{})["admin"].(map[string]interface{})
+	assert.Equal(t, ":8090", admin["address"])
+	assert.Equal(t, redactedValue, admin["tls_private_key"])
+
+	app := summary["app"].(map[string]interface{})
+	assert.Equal(t, redactedValue, app["db_password"])
+	assert.Equal(t, map[string]interface{}{"size": 10, "api_token": redactedValue}, app["cache"])
+
+	// The config itself is not modified.
+	assert.Equal(t, "hunter2", config.AppConfig["db_password"])
+	assert.Equal(t, "secret", config.Tracing.Headers["x-honeycomb-team"])
+}
+
+func TestUnmarshalConfig(t *testing.T) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	require.NoError(t, v.ReadConfig(strings.NewReader(`
+listen_address: ":9000"
+shutdown_grace_period: PT1M
+tracing:
+  enabled: true
+app:
+  anything: goes
+`)))
+
+	config := defaultConfig
+	require.NoError(t, unmarshalConfig(v, &config))
+	assert.Equal(t, ":9000", config.ListenAddress)
+	assert.Equal(t, time.Minute, config.ShutdownGracePeriod)
+	assert.True(t, config.Tracing.Enabled)
+	assert.Equal(t, "goes", config.AppConfig.GetString("anything", ""))
+
+	for _, yaml := range []string{
+		"listen_adress: \":9000\"",
+		"tracing:\n  enabled: true\n  endpont: localhost:4317",
+	} {
+		v := viper.New()
+		v.SetConfigType("yaml")
+		require.NoError(t, v.ReadConfig(strings.NewReader(yaml)))
+
+		config := defaultConfig
+		assert.Error(t, unmarshalConfig(v, &config), yaml)
+	}
+}
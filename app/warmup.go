@@ -0,0 +1,39 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// WarmupApp is an App that primes its resources, such as caches or config
+// watches, before it receives traffic.
+type WarmupApp interface {
+	App
+
+	// Warmup is called after Init, before the gRPC servers start serving and
+	// before the health service reports the server as SERVING. If Warmup
+	// returns an error, the app is not started.
+	//
+	// The provided context is cancelled once the configured warmup_timeout has
+	// elapsed.
+	Warmup(ctx context.Context) error
+}
+
+// runWarmup runs the app's warmup, bound by the provided timeout. If the
+// timeout is not positive, the warmup is not bound.
+func runWarmup(app WarmupApp, timeout time.Duration) error {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	if err := app.Warmup(ctx); err != nil {
+		return errors.Wrapf(err, "warmup failed after %v", time.Since(start))
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+type warmupApp struct {
+	warmup func(ctx context.Context) error
+}
+
+func (a *warmupApp) Init(_ Config) error              { return nil }
+func (a *warmupApp) RegisterWithGRPC(_ *grpc.Server)  {}
+func (a *warmupApp) ShutdownChan() <-chan struct{}    { return nil }
+func (a *warmupApp) Stop()                            {}
+func (a *warmupApp) Warmup(ctx context.Context) error { return a.warmup(ctx) }
+
+func TestRunWarmup(t *testing.T) {
+	var called bool
+	app := &warmupApp{warmup: func(ctx context.Context) error {
+		called = true
+		_, hasDeadline := ctx.Deadline()
+		assert.False(t, hasDeadline)
+		return nil
+	}}
+	require.NoError(t, runWarmup(app, 0))
+	assert.True(t, called)
+
+	app.warmup = func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	err := runWarmup(app, 10*time.Millisecond)
+	assert.Equal(t, context.DeadlineExceeded, errors.Cause(err))
+
+	app.warmup = func(ctx context.Context) error {
+		return errors.New("prefetch failed")
+	}
+	assert.Error(t, runWarmup(app, time.Second))
+}
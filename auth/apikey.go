@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"github.com/kinecosystem/agora-common/config"
+)
+
+// DefaultAPIKeyHeader is the header that contains the API key of requests.
+const DefaultAPIKeyHeader = "x-api-key"
+
+// APIKey returns an Authenticator that accepts requests whose header contains
+// one of the keys. The keys are read from the config on each request, so that
+// they may be rotated at runtime.
+func APIKey(header string, keys config.StringSlice) Authenticator {
+	return AuthenticatorFunc(func(ctx context.Context, _ string, _ interface{}) (context.Context, error) {
+		provided := headerValue(ctx, header)
+		if provided == "" {
+			return nil, ErrUnauthenticated
+		}
+
+		for _, key := range keys.Get(ctx) {
+			if key != "" && subtle.ConstantTimeCompare([]byte(provided), []byte(key)) == 1 {
+				return ctx, nil
+			}
+		}
+
+		return nil, ErrUnauthenticated
+	})
+}
@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/kinecosystem/agora-common/config"
+	"github.com/kinecosystem/agora-common/webhook"
+)
+
+// DefaultHMACHeader is the header that contains the HMAC signature of requests.
+var DefaultHMACHeader = strings.ToLower(webhook.AgoraHMACHeader)
+
+// DefaultHMACTimestampHeader is the header that contains the time at which a
+// request was signed, in seconds since the Unix epoch.
+const DefaultHMACTimestampHeader = "x-agora-hmac-timestamp"
+
+type hmacOptions struct {
+	timestampHeader string
+	maxSkew         time.Duration
+}
+
+// HMACOption configures the Authenticator returned by HMAC.
+type HMACOption func(o *hmacOptions)
+
+// WithHMACTimestampHeader configures the header that contains the signing
+// time of requests.
+func WithHMACTimestampHeader(header string) HMACOption {
+	return func(o *hmacOptions) {
+		o.timestampHeader = header
+	}
+}
+
+// WithMaxSkew configures how far the signing time of a request may be from the
+// current time. Requests outside of this window are rejected, which bounds the
+// time for which a captured request can be replayed.
+func WithMaxSkew(maxSkew time.Duration) HMACOption {
+	return func(o *hmacOptions) {
+		o.maxSkew = maxSkew
+	}
+}
+
+var defaultHMACOptions = hmacOptions{
+	timestampHeader: DefaultHMACTimestampHeader,
+	maxSkew:         5 * time.Minute,
+}
+
+// HMAC returns an Authenticator that accepts requests whose header contains
+// the signature of the request, as computed by SignRequest, and whose signing
+// time is within the configured skew (see WithMaxSkew).
+//
+// The secret is read from the config on each request, so that it may be
+// rotated at runtime. Streams are not supported, as they have no single
+// request to sign.
+func HMAC(header string, secret config.SecretValue, opts ...HMACOption) Authenticator {
+	o := defaultHMACOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return AuthenticatorFunc(func(ctx context.Context, method string, req interface{}) (context.Context, error) {
+		msg, ok := req.(proto.Message)
+		if !ok {
+			return nil, errors.Wrap(ErrUnauthenticated, "hmac authentication requires a unary protobuf request")
+		}
+
+		signature := headerValue(ctx, header)
+		if signature == "" {
+			return nil, ErrUnauthenticated
+		}
+
+		ts, err := strconv.ParseInt(headerValue(ctx, o.timestampHeader), 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(ErrUnauthenticated, "missing or invalid signing time")
+		}
+		if skew := time.Since(time.Unix(ts, 0)); skew > o.maxSkew || skew < -o.maxSkew {
+			return nil, errors.Wrap(ErrUnauthenticated, "signing time outside of allowed skew")
+		}
+
+		key := secret.Get(ctx).Reveal()
+		if len(key) == 0 {
+			return nil, errors.Wrap(ErrUnauthenticated, "no hmac secret configured")
+		}
+
+		payload, err := signingPayload(method, ts, msg)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to encode request")
+		}
+
+		if !webhook.Verify(key, payload, signature) {
+			return nil, errors.Wrap(ErrUnauthenticated, "invalid signature")
+		}
+
+		return ctx, nil
+	})
+}
+
+// SignRequest returns the base64 encoded HMAC-SHA256 of the request to the
+// full gRPC method (such as "/package.Service/Method"), signed at the provided
+// time, keyed by the secret. The signature and the signing time (in seconds
+// since the Unix epoch) should be sent in the DefaultHMACHeader and
+// DefaultHMACTimestampHeader headers respectively.
+//
+// The signed payload is:
+//
+//	<method> "\n" <signing time> "\n" <request>
+//
+// where the request is encoded using the canonical proto3 JSON mapping, with
+// the original proto field names, and no insignificant whitespace.
+func SignRequest(secret []byte, method string, signedAt time.Time, req proto.Message) (string, error) {
+	payload, err := signingPayload(method, signedAt.Unix(), req)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode request")
+	}
+
+	return webhook.Sign(secret, payload), nil
+}
+
+func signingPayload(method string, ts int64, req proto.Message) ([]byte, error) {
+	encoded, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(proto.MessageV2(req))
+	if err != nil {
+		return nil, err
+	}
+
+	// protojson does not guarantee stable whitespace, so it is removed.
+	var body bytes.Buffer
+	if err := json.Compact(&body, encoded); err != nil {
+		return nil, err
+	}
+
+	payload := make([]byte, 0, len(method)+body.Len()+22)
+	payload = append(payload, method...)
+	payload = append(payload, '\n')
+	payload = strconv.AppendInt(payload, ts, 10)
+	payload = append(payload, '\n')
+	return append(payload, body.Bytes()...), nil
+}
@@ -0,0 +1,166 @@
+// Package auth provides gRPC server interceptors that authenticate requests
+// using pluggable Authenticators.
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ErrUnauthenticated is returned by Authenticators when a request does not
+// contain valid credentials.
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Authenticator authenticates requests.
+type Authenticator interface {
+	// Authenticate returns an error if the request to the method is not
+	// authenticated. Otherwise, it returns the context the request should be
+	// handled with, which may contain the authenticated identity.
+	//
+	// The request is nil for streams.
+	Authenticate(ctx context.Context, method string, req interface{}) (context.Context, error)
+}
+
+// AuthenticatorFunc is an adapter to allow the use of functions as
+// Authenticators.
+type AuthenticatorFunc func(ctx context.Context, method string, req interface{}) (context.Context, error)
+
+// Authenticate implements Authenticator.Authenticate.
+func (f AuthenticatorFunc) Authenticate(ctx context.Context, method string, req interface{}) (context.Context, error) {
+	return f(ctx, method, req)
+}
+
+// Any returns an Authenticator that authenticates requests accepted by any of
+// the provided authenticators, which are tried in order.
+func Any(authenticators ...Authenticator) Authenticator {
+	return AuthenticatorFunc(func(ctx context.Context, method string, req interface{}) (context.Context, error) {
+		err := ErrUnauthenticated
+		for _, a := range authenticators {
+			var authCtx context.Context
+			if authCtx, err = a.Authenticate(ctx, method, req); err == nil {
+				return authCtx, nil
+			}
+		}
+		return nil, err
+	})
+}
+
+type options struct {
+	exempt map[string]struct{}
+	prefix []string
+}
+
+// Option configures the interceptors.
+type Option func(o *options)
+
+// WithExemptMethods configures methods (e.g. /package.Service/Method) that do
+// not require authentication. A trailing '*' exempts all methods with the
+// preceding prefix (e.g. /grpc.health.v1.Health/*).
+func WithExemptMethods(methods ...string) Option {
+	return func(o *options) {
+		for _, m := range methods {
+			if strings.HasSuffix(m, "*") {
+				o.prefix = append(o.prefix, strings.TrimSuffix(m, "*"))
+			} else {
+				o.exempt[m] = struct{}{}
+			}
+		}
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		exempt: make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func (o *options) isExempt(method string) bool {
+	if _, ok := o.exempt[method]; ok {
+		return true
+	}
+	for _, p := range o.prefix {
+		if strings.HasPrefix(method, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that rejects
+// requests not accepted by the authenticator with codes.Unauthenticated.
+func UnaryServerInterceptor(a Authenticator, opts ...Option) grpc.UnaryServerInterceptor {
+	o := newOptions(opts)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if o.isExempt(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		authCtx, err := a.Authenticate(ctx, info.FullMethod, req)
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		return handler(authCtx, req)
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that rejects
+// streams not accepted by the authenticator with codes.Unauthenticated.
+func StreamServerInterceptor(a Authenticator, opts ...Option) grpc.StreamServerInterceptor {
+	o := newOptions(opts)
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if o.isExempt(info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		authCtx, err := a.Authenticate(ss.Context(), info.FullMethod, nil)
+		if err != nil {
+			return toStatus(err)
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: authCtx})
+	}
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context implements grpc.ServerStream.Context.
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// toStatus converts authentication errors to a status error. Errors that are
+// already status errors (e.g. codes.PermissionDenied) are returned as is.
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(codes.Unauthenticated, err.Error())
+}
+
+// headerValue returns the first value of the header in the incoming metadata.
+func headerValue(ctx context.Context, header string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get(header)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
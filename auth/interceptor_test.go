@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/kinecosystem/agora-common/config"
+	"github.com/kinecosystem/agora-common/config/memory"
+	"github.com/kinecosystem/agora-common/config/wrapper"
+)
+
+func callUnary(interceptor grpc.UnaryServerInterceptor, ctx context.Context, method string, req interface{}) error {
+	_, err := interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "resp", nil
+	})
+	return err
+}
+
+func withHeaders(kv ...string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(kv...))
+}
+
+func TestUnaryServerInterceptor_Exemptions(t *testing.T) {
+	deny := AuthenticatorFunc(func(ctx context.Context, method string, req interface{}) (context.Context, error) {
+		return nil, ErrUnauthenticated
+	})
+	interceptor := UnaryServerInterceptor(deny, WithExemptMethods("/test.Service/Public", "/grpc.health.v1.Health/*"))
+
+	assert.NoError(t, callUnary(interceptor, context.Background(), "/test.Service/Public", nil))
+	assert.NoError(t, callUnary(interceptor, context.Background(), "/grpc.health.v1.Health/Check", nil))
+	assert.Equal(t, codes.Unauthenticated, status.Code(callUnary(interceptor, context.Background(), "/test.Service/Private", nil)))
+
+	// Status errors are returned as is.
+	forbidden := AuthenticatorFunc(func(ctx context.Context, method string, req interface{}) (context.Context, error) {
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	})
+	interceptor = UnaryServerInterceptor(forbidden)
+	assert.Equal(t, codes.PermissionDenied, status.Code(callUnary(interceptor, context.Background(), "/test.Service/Private", nil)))
+}
+
+func TestAPIKey(t *testing.T) {
+	keys := memory.NewConfig([]string{"key-1", "key-2"})
+	interceptor := UnaryServerInterceptor(APIKey(DefaultAPIKeyHeader, wrapper.NewStringSliceConfig(keys, nil)))
+
+	assert.NoError(t, callUnary(interceptor, withHeaders(DefaultAPIKeyHeader, "key-2"), "/test.Service/Method", nil))
+	assert.Equal(t, codes.Unauthenticated, status.Code(callUnary(interceptor, withHeaders(DefaultAPIKeyHeader, "key-3"), "/test.Service/Method", nil)))
+	assert.Equal(t, codes.Unauthenticated, status.Code(callUnary(interceptor, context.Background(), "/test.Service/Method", nil)))
+
+	// Keys may be rotated at runtime.
+	keys.SetValue([]string{"key-3"})
+	assert.NoError(t, callUnary(interceptor, withHeaders(DefaultAPIKeyHeader, "key-3"), "/test.Service/Method", nil))
+	assert.Equal(t, codes.Unauthenticated, status.Code(callUnary(interceptor, withHeaders(DefaultAPIKeyHeader, "key-2"), "/test.Service/Method", nil)))
+}
+
+func TestHMAC(t *testing.T) {
+	secret := []byte("secret")
+	interceptor := UnaryServerInterceptor(HMAC(DefaultHMACHeader, wrapper.NewSecretConfig(memory.NewConfig(secret), nil)))
+
+	const method = "/test.Service/Method"
+	req := &healthgrpc.HealthCheckRequest{Service: "test"}
+	now := time.Now()
+	signed := func(secret []byte, method string, signedAt time.Time) context.Context {
+		sig, err := SignRequest(secret, method, signedAt, req)
+		require.NoError(t, err)
+		return withHeaders(DefaultHMACHeader, sig, DefaultHMACTimestampHeader, strconv.FormatInt(signedAt.Unix(), 10))
+	}
+
+	assert.NoError(t, callUnary(interceptor, signed(secret, method, now), method, req))
+	assert.NoError(t, callUnary(interceptor, signed(secret, method, now.Add(-time.Minute)), method, req))
+	assert.Equal(t, codes.Unauthenticated, status.Code(callUnary(interceptor, signed([]byte("other"), method, now), method, req)))
+	assert.Equal(t, codes.Unauthenticated, status.Code(callUnary(interceptor, context.Background(), method, req)))
+
+	// Signatures are bound to the method and the request.
+	assert.Equal(t, codes.Unauthenticated, status.Code(callUnary(interceptor, signed(secret, method, now), "/test.Service/Other", req)))
+	assert.Equal(t, codes.Unauthenticated, status.Code(callUnary(interceptor, signed(secret, method, now), method, &healthgrpc.HealthCheckRequest{Service: "other"})))
+
+	// Signatures are bound to the signing time, which must be recent.
+	sig, err := SignRequest(secret, method, now, req)
+	require.NoError(t, err)
+	ctx := withHeaders(DefaultHMACHeader, sig, DefaultHMACTimestampHeader, strconv.FormatInt(now.Unix()+1, 10))
+	assert.Equal(t, codes.Unauthenticated, status.Code(callUnary(interceptor, ctx, method, req)))
+	assert.Equal(t, codes.Unauthenticated, status.Code(callUnary(interceptor, withHeaders(DefaultHMACHeader, sig), method, req)))
+	assert.Equal(t, codes.Unauthenticated, status.Code(callUnary(interceptor, signed(secret, method, now.Add(-10*time.Minute)), method, req)))
+	assert.Equal(t, codes.Unauthenticated, status.Code(callUnary(interceptor, signed(secret, method, now.Add(10*time.Minute)), method, req)))
+
+	interceptor = UnaryServerInterceptor(HMAC(DefaultHMACHeader, wrapper.NewSecretConfig(memory.NewConfig(secret), nil), WithMaxSkew(time.Hour)))
+	assert.NoError(t, callUnary(interceptor, signed(secret, method, now.Add(-10*time.Minute)), method, req))
+
+	// Requests must be protobuf messages.
+	assert.Equal(t, codes.Unauthenticated, status.Code(callUnary(interceptor, signed(secret, method, now), method, "req")))
+
+	// Without a configured secret, all requests are rejected.
+	interceptor = UnaryServerInterceptor(HMAC(DefaultHMACHeader, wrapper.NewSecretConfig(config.NoopConfig, nil)))
+	assert.Equal(t, codes.Unauthenticated, status.Code(callUnary(interceptor, signed(nil, method, now), method, req)))
+}
+
+func TestAny(t *testing.T) {
+	a := Any(
+		APIKey(DefaultAPIKeyHeader, wrapper.NewStringSliceConfig(memory.NewConfig([]string{"key"}), nil)),
+		APIKey("x-other-key", wrapper.NewStringSliceConfig(memory.NewConfig([]string{"other"}), nil)),
+	)
+	interceptor := UnaryServerInterceptor(a)
+
+	assert.NoError(t, callUnary(interceptor, withHeaders(DefaultAPIKeyHeader, "key"), "/test.Service/Method", nil))
+	assert.NoError(t, callUnary(interceptor, withHeaders("x-other-key", "other"), "/test.Service/Method", nil))
+	assert.Equal(t, codes.Unauthenticated, status.Code(callUnary(interceptor, withHeaders("x-other-key", "key"), "/test.Service/Method", nil)))
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	type identityKey struct{}
+	a := AuthenticatorFunc(func(ctx context.Context, method string, req interface{}) (context.Context, error) {
+		if headerValue(ctx, "identity") == "" {
+			return nil, ErrUnauthenticated
+		}
+		return context.WithValue(ctx, identityKey{}, headerValue(ctx, "identity")), nil
+	})
+	interceptor := StreamServerInterceptor(a)
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Stream"}
+
+	var identity interface{}
+	err := interceptor(nil, &testServerStream{ctx: withHeaders("identity", "user")}, info, func(srv interface{}, ss grpc.ServerStream) error {
+		identity = ss.Context().Value(identityKey{})
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "user", identity)
+
+	err = interceptor(nil, &testServerStream{ctx: context.Background()}, info, func(srv interface{}, ss grpc.ServerStream) error {
+		return nil
+	})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
@@ -0,0 +1,197 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultJWTHeader is the header that contains the bearer token of requests.
+	DefaultJWTHeader = "authorization"
+
+	bearerPrefix = "bearer "
+)
+
+// Supported JWT signing algorithms.
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+)
+
+// KeyFunc returns the key used to verify tokens signed with the algorithm and
+// key ID (the kid header, which may be empty).
+//
+// HS256 keys must be []byte, RS256 keys *rsa.PublicKey, and ES256 keys
+// *ecdsa.PublicKey.
+type KeyFunc func(ctx context.Context, alg, kid string) (interface{}, error)
+
+// Claims are the claims of a validated JWT.
+type Claims map[string]interface{}
+
+// Subject returns the sub claim.
+func (c Claims) Subject() string {
+	sub, _ := c["sub"].(string)
+	return sub
+}
+
+type claimsKey struct{}
+
+// ClaimsFromContext returns the claims of the JWT that authenticated the
+// request, if any.
+func ClaimsFromContext(ctx context.Context) (Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(Claims)
+	return claims, ok
+}
+
+type jwtOptions struct {
+	header     string
+	algorithms []string
+	issuer     string
+	audience   string
+	leeway     time.Duration
+}
+
+// JWTOption configures the Authenticator returned by JWT.
+type JWTOption func(o *jwtOptions)
+
+// WithJWTHeader configures the header that contains the bearer token.
+func WithJWTHeader(header string) JWTOption {
+	return func(o *jwtOptions) {
+		o.header = header
+	}
+}
+
+// WithAlgorithms configures the signing algorithms that tokens may use. Tokens
+// signed with any other algorithm are rejected before the KeyFunc is invoked.
+//
+// By default, only RS256 and ES256 are allowed. HS256 should only be allowed
+// if the KeyFunc never returns HS256 keys that are derived from public keys.
+func WithAlgorithms(algs ...string) JWTOption {
+	return func(o *jwtOptions) {
+		o.algorithms = algs
+	}
+}
+
+// WithIssuer configures the required iss claim.
+func WithIssuer(issuer string) JWTOption {
+	return func(o *jwtOptions) {
+		o.issuer = issuer
+	}
+}
+
+// WithAudience configures an audience that the aud claim must contain.
+func WithAudience(audience string) JWTOption {
+	return func(o *jwtOptions) {
+		o.audience = audience
+	}
+}
+
+// WithLeeway configures the clock skew allowed when validating the exp and
+// nbf claims.
+func WithLeeway(leeway time.Duration) JWTOption {
+	return func(o *jwtOptions) {
+		o.leeway = leeway
+	}
+}
+
+var defaultJWTOptions = jwtOptions{
+	header:     DefaultJWTHeader,
+	algorithms: []string{RS256, ES256},
+	leeway:     time.Minute,
+}
+
+// JWT returns an Authenticator that accepts requests containing a valid bearer
+// token, signed with one of the allowed algorithms (see WithAlgorithms). The
+// claims of the token are available to handlers via ClaimsFromContext.
+//
+// Tokens must contain a numeric exp claim. The nbf claim is validated if
+// present.
+func JWT(keyFunc KeyFunc, opts ...JWTOption) Authenticator {
+	o := defaultJWTOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	parser := &jwt.Parser{
+		ValidMethods: o.algorithms,
+		// The claims are validated by jwtOptions.validate, which allows for
+		// leeway, and requires the exp claim.
+		SkipClaimsValidation: true,
+	}
+
+	return AuthenticatorFunc(func(ctx context.Context, _ string, _ interface{}) (context.Context, error) {
+		value := headerValue(ctx, o.header)
+		if len(value) <= len(bearerPrefix) || !strings.EqualFold(value[:len(bearerPrefix)], bearerPrefix) {
+			return nil, ErrUnauthenticated
+		}
+
+		var mapClaims jwt.MapClaims
+		_, err := parser.ParseWithClaims(value[len(bearerPrefix):], &mapClaims, func(token *jwt.Token) (interface{}, error) {
+			kid, _ := token.Header["kid"].(string)
+			return keyFunc(ctx, token.Method.Alg(), kid)
+		})
+		if err != nil {
+			return nil, errors.Wrap(ErrUnauthenticated, "invalid token")
+		}
+
+		claims := Claims(mapClaims)
+		if err := o.validate(claims, time.Now()); err != nil {
+			return nil, err
+		}
+
+		return context.WithValue(ctx, claimsKey{}, claims), nil
+	})
+}
+
+func (o *jwtOptions) validate(claims Claims, now time.Time) error {
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return errors.Wrap(ErrUnauthenticated, "token has no valid exp claim")
+	}
+	if now.Add(-o.leeway).After(time.Unix(int64(exp), 0)) {
+		return errors.Wrap(ErrUnauthenticated, "token expired")
+	}
+
+	if nbfClaim, present := claims["nbf"]; present {
+		nbf, ok := nbfClaim.(float64)
+		if !ok {
+			return errors.Wrap(ErrUnauthenticated, "token has no valid nbf claim")
+		}
+		if now.Add(o.leeway).Before(time.Unix(int64(nbf), 0)) {
+			return errors.Wrap(ErrUnauthenticated, "token not yet valid")
+		}
+	}
+
+	if o.issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != o.issuer {
+			return errors.Wrap(ErrUnauthenticated, "invalid token issuer")
+		}
+	}
+
+	if o.audience != "" && !hasAudience(claims["aud"], o.audience) {
+		return errors.Wrap(ErrUnauthenticated, "invalid token audience")
+	}
+
+	return nil
+}
+
+// hasAudience returns whether the aud claim, which may be a string or an array
+// of strings, contains the audience.
+func hasAudience(aud interface{}, audience string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, a := range aud {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func signJWT(t *testing.T, alg, kid string, key interface{}, claims map[string]interface{}) string {
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var sig []byte
+	switch alg {
+	case HS256:
+		h := hmac.New(sha256.New, key.([]byte))
+		h.Write([]byte(signed))
+		sig = h.Sum(nil)
+	case RS256:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, key.(*rsa.PrivateKey), crypto.SHA256, digest[:])
+		require.NoError(t, err)
+	case ES256:
+		r, s, err := ecdsa.Sign(rand.Reader, key.(*ecdsa.PrivateKey), digest[:])
+		require.NoError(t, err)
+		sig = make([]byte, 64)
+		rb, sb := r.Bytes(), s.Bytes()
+		copy(sig[32-len(rb):32], rb)
+		copy(sig[64-len(sb):], sb)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestJWT(t *testing.T) {
+	hmacKey := []byte("secret")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	keyFunc := func(_ context.Context, alg, kid string) (interface{}, error) {
+		switch kid {
+		case "hmac":
+			return hmacKey, nil
+		case "rsa":
+			return &rsaKey.PublicKey, nil
+		case "ec":
+			return &ecKey.PublicKey, nil
+		default:
+			return nil, errors.New("unknown key")
+		}
+	}
+
+	var claims Claims
+	interceptor := UnaryServerInterceptor(JWT(keyFunc, WithAlgorithms(HS256, RS256, ES256), WithIssuer("agora"), WithAudience("service")))
+	call := func(token string) error {
+		_, err := interceptor(withHeaders(DefaultJWTHeader, "Bearer "+token), nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			claims, _ = ClaimsFromContext(ctx)
+			return "resp", nil
+		})
+		return err
+	}
+
+	valid := map[string]interface{}{
+		"sub": "user",
+		"iss": "agora",
+		"aud": []string{"other", "service"},
+		"exp": time.Now().Add(time.Hour).Unix(),
+		"nbf": time.Now().Add(-time.Hour).Unix(),
+	}
+
+	for _, tc := range []struct {
+		alg string
+		kid string
+		key interface{}
+	}{
+		{HS256, "hmac", hmacKey},
+		{RS256, "rsa", rsaKey},
+		{ES256, "ec", ecKey},
+	} {
+		claims = nil
+		require.NoError(t, call(signJWT(t, tc.alg, tc.kid, tc.key, valid)), tc.alg)
+		assert.Equal(t, "user", claims.Subject())
+	}
+
+	invalid := []string{
+		"",
+		"malformed",
+		// Unknown key.
+		signJWT(t, HS256, "unknown", hmacKey, valid),
+		// Algorithm does not match the key.
+		signJWT(t, HS256, "rsa", hmacKey, valid),
+		// Invalid signature.
+		signJWT(t, HS256, "hmac", []byte("other"), valid),
+		// Unsigned.
+		signJWT(t, "none", "hmac", nil, valid),
+	}
+
+	for _, modify := range []func(c map[string]interface{}){
+		func(c map[string]interface{}) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+		func(c map[string]interface{}) { delete(c, "exp") },
+		func(c map[string]interface{}) { c["exp"] = "never" },
+		func(c map[string]interface{}) { c["nbf"] = "now" },
+		func(c map[string]interface{}) { c["nbf"] = time.Now().Add(time.Hour).Unix() },
+		func(c map[string]interface{}) { c["iss"] = "other" },
+		func(c map[string]interface{}) { c["aud"] = "other" },
+	} {
+		c := make(map[string]interface{})
+		for k, v := range valid {
+			c[k] = v
+		}
+		modify(c)
+		invalid = append(invalid, signJWT(t, HS256, "hmac", hmacKey, c))
+	}
+
+	for i, token := range invalid {
+		assert.Equal(t, codes.Unauthenticated, status.Code(call(token)), "token %d", i)
+	}
+
+	// Tokens must be provided as bearer tokens.
+	_, err = interceptor(withHeaders(DefaultJWTHeader, signJWT(t, HS256, "hmac", hmacKey, valid)), nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "resp", nil
+	})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestJWT_Algorithms(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var requestedAlgs []string
+	keyFunc := func(_ context.Context, alg, kid string) (interface{}, error) {
+		requestedAlgs = append(requestedAlgs, alg)
+		if alg == HS256 {
+			// A KeyFunc that naively returns the public key for any
+			// algorithm, which HS256 would otherwise accept as a secret.
+			return []byte("public key"), nil
+		}
+		return &rsaKey.PublicKey, nil
+	}
+
+	valid := map[string]interface{}{
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+
+	a := JWT(keyFunc)
+	ctx := withHeaders(DefaultJWTHeader, "Bearer "+signJWT(t, RS256, "", rsaKey, valid))
+	_, err = a.Authenticate(ctx, "/test.Service/Method", nil)
+	assert.NoError(t, err)
+
+	// HS256 is not allowed by default, and the KeyFunc is not consulted.
+	ctx = withHeaders(DefaultJWTHeader, "Bearer "+signJWT(t, HS256, "", []byte("public key"), valid))
+	_, err = a.Authenticate(ctx, "/test.Service/Method", nil)
+	assert.True(t, errors.Is(err, ErrUnauthenticated))
+	assert.Equal(t, []string{RS256}, requestedAlgs)
+
+	// Nor is RS256 if the allowed algorithms are pinned otherwise.
+	a = JWT(keyFunc, WithAlgorithms(ES256))
+	ctx = withHeaders(DefaultJWTHeader, "Bearer "+signJWT(t, RS256, "", rsaKey, valid))
+	_, err = a.Authenticate(ctx, "/test.Service/Method", nil)
+	assert.True(t, errors.Is(err, ErrUnauthenticated))
+	assert.Equal(t, []string{RS256}, requestedAlgs)
+}
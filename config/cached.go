@@ -0,0 +1,92 @@
+package config
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Cached is a Config that caches the value of an underlying Config, so that
+// Get does not block on the underlying Config once a value has been loaded.
+type Cached struct {
+	config Config
+	ttl    time.Duration
+
+	stateMu    sync.RWMutex
+	loaded     bool
+	value      interface{}
+	err        error
+	loadedAt   time.Time
+	refreshing bool
+	shutdown   bool
+}
+
+// NewCached returns a Cached config, which caches values of the provided
+// Config for up to the specified TTL.
+//
+// The first Get loads the value synchronously. Once the cached value is older
+// than the TTL, Get continues to return it while the value is refreshed in the
+// background. If the refresh fails, the stale value is retained, and the
+// refresh is retried on a subsequent Get.
+func NewCached(config Config, ttl time.Duration) *Cached {
+	return &Cached{
+		config: config,
+		ttl:    ttl,
+	}
+}
+
+// Get implements Config.Get
+func (c *Cached) Get(ctx context.Context) (interface{}, error) {
+	c.stateMu.Lock()
+	if c.shutdown {
+		c.stateMu.Unlock()
+		return nil, ErrShutdown
+	}
+	if !c.loaded {
+		c.stateMu.Unlock()
+		return c.load(ctx)
+	}
+
+	value, err := c.value, c.err
+	if time.Since(c.loadedAt) >= c.ttl && !c.refreshing {
+		c.refreshing = true
+		go c.refresh()
+	}
+	c.stateMu.Unlock()
+
+	return value, err
+}
+
+// Shutdown implements Config.Shutdown
+func (c *Cached) Shutdown() {
+	c.stateMu.Lock()
+	c.shutdown = true
+	c.stateMu.Unlock()
+
+	c.config.Shutdown()
+}
+
+// load loads the value from the underlying config, caching it if successful.
+func (c *Cached) load(ctx context.Context) (interface{}, error) {
+	value, err := c.config.Get(ctx)
+	if err != nil && err != ErrNoValue {
+		return nil, err
+	}
+
+	c.stateMu.Lock()
+	c.loaded = true
+	c.value, c.err = value, err
+	c.loadedAt = time.Now()
+	c.stateMu.Unlock()
+
+	return value, err
+}
+
+func (c *Cached) refresh() {
+	// Errors are intentionally ignored, as the stale value is retained.
+	_, _ = c.load(context.Background())
+
+	c.stateMu.Lock()
+	c.refreshing = false
+	c.stateMu.Unlock()
+}
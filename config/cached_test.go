@@ -0,0 +1,118 @@
+package config_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kinecosystem/agora-common/config"
+	"github.com/kinecosystem/agora-common/config/memory"
+	"github.com/kinecosystem/agora-common/testutil"
+)
+
+// blockingConfig blocks Get calls while blocked.
+type blockingConfig struct {
+	*memory.Config
+
+	mu      sync.Mutex
+	blockCh chan struct{}
+	calls   int
+}
+
+func (c *blockingConfig) Get(ctx context.Context) (interface{}, error) {
+	c.mu.Lock()
+	c.calls++
+	blockCh := c.blockCh
+	c.mu.Unlock()
+
+	if blockCh != nil {
+		<-blockCh
+	}
+	return c.Config.Get(ctx)
+}
+
+func (c *blockingConfig) getCalls() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls
+}
+
+func TestCached(t *testing.T) {
+	mock := &blockingConfig{Config: memory.NewConfig(nil)}
+	c := config.NewCached(mock, 50*time.Millisecond)
+
+	_, err := c.Get(context.Background())
+	assert.Equal(t, config.ErrNoValue, err)
+
+	// The cached value is returned until the TTL has elapsed.
+	mock.SetValue([]byte("a"))
+	_, err = c.Get(context.Background())
+	assert.Equal(t, config.ErrNoValue, err)
+	assert.Equal(t, 1, mock.getCalls())
+
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, testutil.WaitFor(time.Second, 10*time.Millisecond, func() bool {
+		val, err := c.Get(context.Background())
+		return err == nil && string(val.([]byte)) == "a"
+	}))
+
+	// Stale values are returned without blocking while the underlying config
+	// is refreshed.
+	mock.mu.Lock()
+	mock.blockCh = make(chan struct{})
+	mock.mu.Unlock()
+	mock.SetValue([]byte("b"))
+	time.Sleep(50 * time.Millisecond)
+
+	calls := mock.getCalls()
+	for i := 0; i < 10; i++ {
+		val, err := c.Get(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []byte("a"), val)
+	}
+	require.NoError(t, testutil.WaitFor(time.Second, 10*time.Millisecond, func() bool {
+		return mock.getCalls() == calls+1
+	}))
+
+	mock.mu.Lock()
+	close(mock.blockCh)
+	mock.blockCh = nil
+	mock.mu.Unlock()
+	require.NoError(t, testutil.WaitFor(time.Second, 10*time.Millisecond, func() bool {
+		val, err := c.Get(context.Background())
+		return err == nil && string(val.([]byte)) == "b"
+	}))
+
+	// Stale values are retained on error.
+	mock.InduceErrors()
+	time.Sleep(50 * time.Millisecond)
+	for i := 0; i < 5; i++ {
+		val, err := c.Get(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []byte("b"), val)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	c.Shutdown()
+	_, err = c.Get(context.Background())
+	assert.Equal(t, config.ErrShutdown, err)
+}
+
+func TestCached_InitialError(t *testing.T) {
+	mock := memory.NewConfig([]byte("a"))
+	c := config.NewCached(mock, time.Minute)
+
+	// Errors are not cached if no value has been loaded.
+	mock.InduceErrors()
+	_, err := c.Get(context.Background())
+	assert.Error(t, err)
+
+	mock.StopInducingErrors()
+	val, err := c.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []byte("a"), val)
+}
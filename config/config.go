@@ -71,6 +71,13 @@ type Float64 interface {
 	Shutdown()
 }
 
+// Int64Slice provides an []int64 typed config.Config.
+type Int64Slice interface {
+	Get(ctx context.Context) []int64
+	GetSafe(ctx context.Context) ([]int64, error)
+	Shutdown()
+}
+
 // Int64 provides an int64 typed config.Config.
 type Int64 interface {
 	Get(ctx context.Context) int64
@@ -85,9 +92,39 @@ type Uint64 interface {
 	Shutdown()
 }
 
+// SecretValue provides a Secret typed config.Config.
+type SecretValue interface {
+	Get(ctx context.Context) Secret
+	GetSafe(ctx context.Context) (Secret, error)
+	Shutdown()
+}
+
 // String provides a string typed config.Config.
 type String interface {
 	Get(ctx context.Context) string
 	GetSafe(ctx context.Context) (string, error)
 	Shutdown()
 }
+
+// StringMap provides a map[string]string typed config.Config.
+type StringMap interface {
+	Get(ctx context.Context) map[string]string
+	GetSafe(ctx context.Context) (map[string]string, error)
+	Shutdown()
+}
+
+// StringSlice provides a []string typed config.Config.
+type StringSlice interface {
+	Get(ctx context.Context) []string
+	GetSafe(ctx context.Context) ([]string, error)
+	Shutdown()
+}
+
+// Struct provides a config.Config that is unmarshalled into a struct type.
+//
+// The returned values are shared snapshots, and must not be modified.
+type Struct interface {
+	Load(ctx context.Context) interface{}
+	LoadSafe(ctx context.Context) (interface{}, error)
+	Shutdown()
+}
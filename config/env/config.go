@@ -69,3 +69,9 @@ func NewStringConfig(key string, defaultValue string) config.String {
 func NewBoolConfig(key string, defaultValue bool) config.Bool {
 	return wrapper.NewBoolConfig(NewConfig(key), defaultValue)
 }
+
+// NewSecretConfig creates a env-based secret config, whose value is redacted
+// when logged
+func NewSecretConfig(key string, defaultValue config.Secret) config.SecretValue {
+	return wrapper.NewSecretConfig(NewConfig(key), defaultValue)
+}
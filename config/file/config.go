@@ -0,0 +1,201 @@
+// Package file provides a config.Config backed by a local file, such as a
+// mounted Kubernetes ConfigMap, which is reloaded whenever the file changes.
+package file
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/kinecosystem/agora-common/config"
+	"github.com/kinecosystem/agora-common/config/wrapper"
+	"github.com/kinecosystem/agora-common/logging"
+)
+
+type conf struct {
+	log  logging.Logger
+	path string
+	opts options
+
+	stateMu  sync.RWMutex
+	val      []byte
+	err      error
+	shutdown bool
+
+	watcher      *fsnotify.Watcher
+	shutdownCh   chan struct{}
+	shutdownOnce sync.Once
+}
+
+// NewConfig returns a config.Config whose value is the contents of the file at
+// the provided path, with any trailing newlines removed. If the file does not
+// exist, config.ErrNoValue is returned.
+//
+// The file is reloaded whenever a change to its directory is observed, as
+// well as periodically. Watching the directory (rather than the file) ensures
+// files that are atomically replaced, such as mounted ConfigMaps, are reloaded.
+func NewConfig(path string, opts ...Option) config.Config {
+	c := &conf{
+		path:       path,
+		opts:       defaultOptions,
+		shutdownCh: make(chan struct{}),
+	}
+
+	for _, o := range opts {
+		o(&c.opts)
+	}
+	if c.opts.PollInterval <= 0 {
+		c.opts.PollInterval = defaultOptions.PollInterval
+	}
+	c.log = logging.OrDefault(c.opts.Log).WithFields(logging.Fields{
+		"type": "config/file",
+		"path": path,
+	})
+
+	c.reload()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		c.log.WithError(err).Warn("failed to create file watcher, falling back to polling")
+	} else if err := watcher.Add(filepath.Dir(path)); err != nil {
+		c.log.WithError(err).Warn("failed to watch config directory, falling back to polling")
+		watcher.Close()
+	} else {
+		c.watcher = watcher
+	}
+
+	go c.watch()
+
+	return c
+}
+
+// Get implements Config.Get
+func (c *conf) Get(_ context.Context) (interface{}, error) {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+
+	if c.shutdown {
+		return nil, config.ErrShutdown
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	return c.val, nil
+}
+
+// Shutdown implements Config.Shutdown
+func (c *conf) Shutdown() {
+	c.shutdownOnce.Do(func() {
+		c.stateMu.Lock()
+		c.shutdown = true
+		c.stateMu.Unlock()
+
+		close(c.shutdownCh)
+		if c.watcher != nil {
+			c.watcher.Close()
+		}
+	})
+}
+
+func (c *conf) watch() {
+	ticker := time.NewTicker(c.opts.PollInterval)
+	defer ticker.Stop()
+
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+	if c.watcher != nil {
+		events = c.watcher.Events
+		errs = c.watcher.Errors
+	}
+
+	for {
+		select {
+		case <-c.shutdownCh:
+			return
+		case <-ticker.C:
+		case _, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			c.log.WithError(err).Warn("file watcher error")
+			config.ObserveWatchError("file", c.path)
+			continue
+		}
+
+		c.reload()
+	}
+}
+
+func (c *conf) reload() {
+	b, err := ioutil.ReadFile(c.path)
+	if os.IsNotExist(err) {
+		err = config.ErrNoValue
+	} else if err == nil {
+		b = bytes.TrimRight(b, "\r\n")
+	}
+
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	if err != nil && err != config.ErrNoValue {
+		c.log.WithError(err).Warn("failed to load config file")
+		config.ObserveFetchError("file", c.path)
+	} else {
+		config.ObserveRefresh("file", c.path, err != c.err || !bytes.Equal(b, c.val))
+	}
+	c.val, c.err = b, err
+}
+
+// NewBytesConfig creates a file-based byte array config
+func NewBytesConfig(path string, defaultValue []byte, opts ...Option) config.Bytes {
+	return wrapper.NewBytesConfig(NewConfig(path, opts...), defaultValue)
+}
+
+// NewInt64Config creates a file-based int64 config
+func NewInt64Config(path string, defaultValue int64, opts ...Option) config.Int64 {
+	return wrapper.NewInt64Config(NewConfig(path, opts...), defaultValue)
+}
+
+// NewUint64Config creates a file-based uint64 config
+func NewUint64Config(path string, defaultValue uint64, opts ...Option) config.Uint64 {
+	return wrapper.NewUint64Config(NewConfig(path, opts...), defaultValue)
+}
+
+// NewFloat64Config creates a file-based float64 config
+func NewFloat64Config(path string, defaultValue float64, opts ...Option) config.Float64 {
+	return wrapper.NewFloat64Config(NewConfig(path, opts...), defaultValue)
+}
+
+// NewDurationConfig creates a file-based duration config
+func NewDurationConfig(path string, defaultValue time.Duration, opts ...Option) config.Duration {
+	return wrapper.NewDurationConfig(NewConfig(path, opts...), defaultValue)
+}
+
+// NewStringConfig creates a file-based string config
+func NewStringConfig(path string, defaultValue string, opts ...Option) config.String {
+	return wrapper.NewStringConfig(NewConfig(path, opts...), defaultValue)
+}
+
+// NewBoolConfig creates a file-based bool config
+func NewBoolConfig(path string, defaultValue bool, opts ...Option) config.Bool {
+	return wrapper.NewBoolConfig(NewConfig(path, opts...), defaultValue)
+}
+
+// NewSecretConfig creates a file-based secret config, whose value is redacted
+// when logged
+func NewSecretConfig(path string, defaultValue config.Secret, opts ...Option) config.SecretValue {
+	return wrapper.NewSecretConfig(NewConfig(path, opts...), defaultValue)
+}
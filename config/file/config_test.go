@@ -0,0 +1,116 @@
+package file
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kinecosystem/agora-common/config"
+	"github.com/kinecosystem/agora-common/testutil"
+)
+
+func TestConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config-file")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "value")
+
+	// Rely on notifications, rather than polling.
+	c := NewConfig(path, WithPollInterval(time.Hour))
+	defer c.Shutdown()
+
+	_, err = c.Get(context.Background())
+	assert.Equal(t, config.ErrNoValue, err)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("a\n"), 0644))
+	require.NoError(t, testutil.WaitFor(time.Second, 10*time.Millisecond, func() bool {
+		v, err := c.Get(context.Background())
+		return err == nil && string(v.([]byte)) == "a"
+	}))
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("b"), 0644))
+	require.NoError(t, testutil.WaitFor(time.Second, 10*time.Millisecond, func() bool {
+		v, err := c.Get(context.Background())
+		return err == nil && string(v.([]byte)) == "b"
+	}))
+
+	require.NoError(t, os.Remove(path))
+	require.NoError(t, testutil.WaitFor(time.Second, 10*time.Millisecond, func() bool {
+		_, err := c.Get(context.Background())
+		return err == config.ErrNoValue
+	}))
+
+	c.Shutdown()
+	_, err = c.Get(context.Background())
+	assert.Equal(t, config.ErrShutdown, err)
+}
+
+func TestConfig_Symlink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config-file")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Mimic a mounted ConfigMap, whose files are symlinks into a data
+	// directory that is atomically replaced on update.
+	writeData := func(name, value string) {
+		dataDir := filepath.Join(dir, name)
+		require.NoError(t, os.Mkdir(dataDir, 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dataDir, "value"), []byte(value), 0644))
+
+		tmpLink := filepath.Join(dir, "..data_tmp")
+		require.NoError(t, os.Symlink(dataDir, tmpLink))
+		require.NoError(t, os.Rename(tmpLink, filepath.Join(dir, "..data")))
+	}
+
+	writeData("v1", "10")
+	require.NoError(t, os.Symlink(filepath.Join(dir, "..data", "value"), filepath.Join(dir, "value")))
+
+	c := NewInt64Config(filepath.Join(dir, "value"), 0, WithPollInterval(time.Hour))
+	defer c.Shutdown()
+	assert.EqualValues(t, 10, c.Get(context.Background()))
+
+	writeData("v2", "20")
+	require.NoError(t, testutil.WaitFor(time.Second, 10*time.Millisecond, func() bool {
+		return c.Get(context.Background()) == 20
+	}))
+}
+
+func TestConfig_InvalidPollInterval(t *testing.T) {
+	c := NewConfig(filepath.Join(os.TempDir(), "does-not-exist"), WithPollInterval(0)).(*conf)
+	defer c.Shutdown()
+
+	assert.Equal(t, defaultOptions.PollInterval, c.opts.PollInterval)
+}
+
+func TestConfig_Polling(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config-file")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "value")
+	require.NoError(t, ioutil.WriteFile(path, []byte("a"), 0644))
+
+	c := NewConfig(path, WithPollInterval(10*time.Millisecond)).(*conf)
+	defer c.Shutdown()
+
+	v, err := c.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []byte("a"), v)
+
+	// Disable notifications, so that the change is only observed by polling.
+	require.NotNil(t, c.watcher)
+	require.NoError(t, c.watcher.Remove(dir))
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("b"), 0644))
+	require.NoError(t, testutil.WaitFor(time.Second, 10*time.Millisecond, func() bool {
+		v, err := c.Get(context.Background())
+		return err == nil && string(v.([]byte)) == "b"
+	}))
+}
@@ -0,0 +1,41 @@
+package file
+
+import (
+	"time"
+
+	"github.com/kinecosystem/agora-common/logging"
+)
+
+type options struct {
+	// PollInterval is the interval at which the file is reloaded, regardless
+	// of file system notifications. This serves as a fallback for file systems
+	// (such as network mounts) that do not support notifications.
+	PollInterval time.Duration
+
+	// Log is the logger used to report failures. If nil, logging.Default is
+	// used.
+	Log logging.Logger
+}
+
+// Option configures a file config.
+type Option func(o *options)
+
+// WithPollInterval configures the interval at which the file is reloaded,
+// regardless of file system notifications. Non-positive intervals are ignored.
+func WithPollInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.PollInterval = interval
+	}
+}
+
+// WithLogger configures the logger used to report failures, instead of the
+// standard logrus logger.
+func WithLogger(log logging.Logger) Option {
+	return func(o *options) {
+		o.Log = log
+	}
+}
+
+var defaultOptions = options{
+	PollInterval: 10 * time.Second,
+}
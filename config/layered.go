@@ -0,0 +1,66 @@
+package config
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// Layer is a named Config consulted by a Layered config.
+type Layer struct {
+	Name   string
+	Config Config
+}
+
+// Layered is a Config that consults a set of layers in order of precedence,
+// returning the value of the first layer that has a value set.
+//
+// For example, an etcd override layer may take precedence over a file layer,
+// with the default value of a typed wrapper used if neither is set.
+type Layered struct {
+	layers []Layer
+}
+
+// NewLayered returns a Layered config that consults the provided layers in
+// order, with the first layer having the highest precedence.
+func NewLayered(layers ...Layer) *Layered {
+	return &Layered{
+		layers: layers,
+	}
+}
+
+// Get implements Config.Get
+func (l *Layered) Get(ctx context.Context) (interface{}, error) {
+	val, _, err := l.GetWithLayer(ctx)
+	return val, err
+}
+
+// GetWithLayer returns the latest config value, along with the name of the
+// layer that satisfied the lookup.
+//
+// If no layer has a value set, ErrNoValue is returned. If a layer fails with
+// any other error, the error is returned rather than falling back to a layer
+// of lower precedence, which could otherwise silently revert an override.
+func (l *Layered) GetWithLayer(ctx context.Context) (interface{}, string, error) {
+	for _, layer := range l.layers {
+		val, err := layer.Config.Get(ctx)
+		if err == ErrNoValue {
+			continue
+		} else if err == ErrShutdown {
+			return nil, "", err
+		} else if err != nil {
+			return nil, layer.Name, errors.Wrapf(err, "failed to get value from layer %s", layer.Name)
+		}
+
+		return val, layer.Name, nil
+	}
+
+	return nil, "", ErrNoValue
+}
+
+// Shutdown implements Config.Shutdown, shutting down all layers.
+func (l *Layered) Shutdown() {
+	for _, layer := range l.layers {
+		layer.Config.Shutdown()
+	}
+}
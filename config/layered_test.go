@@ -0,0 +1,65 @@
+package config_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kinecosystem/agora-common/config"
+	"github.com/kinecosystem/agora-common/config/memory"
+	"github.com/kinecosystem/agora-common/config/wrapper"
+)
+
+func TestLayered(t *testing.T) {
+	override := memory.NewConfig(nil)
+	file := memory.NewConfig(nil)
+	l := config.NewLayered(
+		config.Layer{Name: "override", Config: override},
+		config.Layer{Name: "file", Config: file},
+	)
+
+	// No layer has a value set
+	_, layer, err := l.GetWithLayer(context.Background())
+	assert.Equal(t, config.ErrNoValue, err)
+	assert.Empty(t, layer)
+
+	// The default value of a typed wrapper is used if no layer is set
+	s := wrapper.NewStringConfig(l, "default")
+	assert.Equal(t, "default", s.Get(context.Background()))
+
+	file.SetValue([]byte("file"))
+	val, layer, err := l.GetWithLayer(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []byte("file"), val)
+	assert.Equal(t, "file", layer)
+	assert.Equal(t, "file", s.Get(context.Background()))
+
+	override.SetValue([]byte("override"))
+	val, layer, err = l.GetWithLayer(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []byte("override"), val)
+	assert.Equal(t, "override", layer)
+	assert.Equal(t, "override", s.Get(context.Background()))
+
+	// Errors from a layer are not masked by layers of lower precedence
+	override.InduceErrors()
+	_, layer, err = l.GetWithLayer(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, "override", layer)
+	assert.Equal(t, "override", s.Get(context.Background()))
+
+	override.StopInducingErrors()
+	override.ClearValue()
+	val, err = l.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []byte("file"), val)
+
+	// All layers are shutdown
+	l.Shutdown()
+	_, err = l.Get(context.Background())
+	assert.Equal(t, config.ErrShutdown, err)
+	_, err = file.Get(context.Background())
+	assert.Equal(t, config.ErrShutdown, err)
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/kinecosystem/agora-common/metrics"
+)
+
+var (
+	updateCounterVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "config",
+		Name:      "updates_total",
+		Help:      "Number of config value changes observed by a config backend",
+	}, []string{"backend", "key"})
+	fetchErrorCounterVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "config",
+		Name:      "fetch_errors_total",
+		Help:      "Number of failed attempts to fetch a config value by a config backend",
+	}, []string{"backend", "key"})
+	watchErrorCounterVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "config",
+		Name:      "watch_errors_total",
+		Help:      "Number of errors encountered while watching a config value for changes",
+	}, []string{"backend", "key"})
+	lastRefreshGaugeVec = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "config",
+		Name:      "last_refresh_timestamp_seconds",
+		Help:      "Unix time at which a config value was last successfully refreshed",
+	}, []string{"backend", "key"})
+)
+
+func init() {
+	updateCounterVec = metrics.Register(updateCounterVec).(*prometheus.CounterVec)
+	fetchErrorCounterVec = metrics.Register(fetchErrorCounterVec).(*prometheus.CounterVec)
+	watchErrorCounterVec = metrics.Register(watchErrorCounterVec).(*prometheus.CounterVec)
+	lastRefreshGaugeVec = metrics.Register(lastRefreshGaugeVec).(*prometheus.GaugeVec)
+}
+
+// ObserveRefresh records a successful refresh of a config value by a Config
+// implementation, and whether or not the value changed as a result.
+//
+// The time since the last refresh can be alerted on using
+// time() - config_last_refresh_timestamp_seconds.
+func ObserveRefresh(backend, key string, changed bool) {
+	lastRefreshGaugeVec.WithLabelValues(backend, key).Set(float64(time.Now().UnixNano()) / float64(time.Second))
+	if changed {
+		updateCounterVec.WithLabelValues(backend, key).Inc()
+	}
+}
+
+// ObserveFetchError records a failed attempt to fetch a config value by a
+// Config implementation.
+func ObserveFetchError(backend, key string) {
+	fetchErrorCounterVec.WithLabelValues(backend, key).Inc()
+}
+
+// ObserveWatchError records an error encountered by a Config implementation
+// while watching a config value for changes.
+func ObserveWatchError(backend, key string) {
+	watchErrorCounterVec.WithLabelValues(backend, key).Inc()
+}
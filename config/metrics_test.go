@@ -0,0 +1,29 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	start := float64(time.Now().Unix())
+	updates := promtestutil.ToFloat64(updateCounterVec.WithLabelValues("test", "key"))
+	fetchErrors := promtestutil.ToFloat64(fetchErrorCounterVec.WithLabelValues("test", "key"))
+	watchErrors := promtestutil.ToFloat64(watchErrorCounterVec.WithLabelValues("test", "key"))
+
+	ObserveRefresh("test", "key", false)
+	assert.Equal(t, updates, promtestutil.ToFloat64(updateCounterVec.WithLabelValues("test", "key")))
+	assert.True(t, promtestutil.ToFloat64(lastRefreshGaugeVec.WithLabelValues("test", "key")) >= start)
+
+	ObserveRefresh("test", "key", true)
+	assert.Equal(t, updates+1, promtestutil.ToFloat64(updateCounterVec.WithLabelValues("test", "key")))
+
+	ObserveFetchError("test", "key")
+	assert.Equal(t, fetchErrors+1, promtestutil.ToFloat64(fetchErrorCounterVec.WithLabelValues("test", "key")))
+
+	ObserveWatchError("test", "key")
+	assert.Equal(t, watchErrors+1, promtestutil.ToFloat64(watchErrorCounterVec.WithLabelValues("test", "key")))
+}
@@ -0,0 +1,265 @@
+// Package s3 provides a config.Config backed by an S3 object, which is polled
+// for changes.
+package s3
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/awserr"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/s3iface"
+	"github.com/pkg/errors"
+
+	"github.com/kinecosystem/agora-common/config"
+	"github.com/kinecosystem/agora-common/config/wrapper"
+	"github.com/kinecosystem/agora-common/logging"
+)
+
+type conf struct {
+	log    logging.Logger
+	client s3iface.ClientAPI
+	url    string
+	bucket string
+	key    string
+	opts   options
+
+	stateMu  sync.RWMutex
+	val      []byte
+	etag     string
+	err      error
+	shutdown bool
+
+	shutdownCh   chan struct{}
+	shutdownOnce sync.Once
+}
+
+// NewConfig returns a config.Config whose value is the contents of the S3
+// object at the provided s3://bucket/key URL. If the object does not exist,
+// config.ErrNoValue is returned.
+//
+// The object is polled for changes using its ETag, so unchanged objects are
+// not downloaded again. If the object cannot be loaded, the last loaded value
+// continues to be returned.
+func NewConfig(client s3iface.ClientAPI, rawURL string, opts ...Option) (config.Config, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid url")
+	}
+	if u.Scheme != "s3" {
+		return nil, errors.Errorf("invalid scheme: %s", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, errors.New("missing bucket")
+	}
+	if len(u.Path) <= 1 {
+		return nil, errors.New("missing key")
+	}
+
+	c := &conf{
+		client: client,
+		url:    rawURL,
+		bucket: u.Host,
+		// The path component of a URL includes the prefixed '/', which S3
+		// does not expect.
+		key:        u.Path[1:],
+		opts:       defaultOptions,
+		err:        config.ErrNoValue,
+		shutdownCh: make(chan struct{}),
+	}
+
+	for _, o := range opts {
+		o(&c.opts)
+	}
+	if c.opts.PollInterval <= 0 {
+		c.opts.PollInterval = defaultOptions.PollInterval
+	}
+	c.log = logging.OrDefault(c.opts.Log).WithFields(logging.Fields{
+		"type": "config/s3",
+		"url":  rawURL,
+	})
+
+	if err := c.reload(); err != nil {
+		c.log.WithError(err).Warn("failed to load config object")
+		config.ObserveFetchError("s3", c.url)
+	}
+
+	go c.poll()
+
+	return c, nil
+}
+
+// Get implements Config.Get
+func (c *conf) Get(_ context.Context) (interface{}, error) {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+
+	if c.shutdown {
+		return nil, config.ErrShutdown
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	return c.val, nil
+}
+
+// Shutdown implements Config.Shutdown
+func (c *conf) Shutdown() {
+	c.shutdownOnce.Do(func() {
+		c.stateMu.Lock()
+		c.shutdown = true
+		c.stateMu.Unlock()
+
+		close(c.shutdownCh)
+	})
+}
+
+func (c *conf) poll() {
+	ticker := time.NewTicker(c.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.shutdownCh:
+			return
+		case <-ticker.C:
+		}
+
+		if err := c.reload(); err != nil {
+			c.log.WithError(err).Warn("failed to reload config object")
+			config.ObserveFetchError("s3", c.url)
+		}
+	}
+}
+
+// reload loads the object if it has changed since it was last loaded.
+func (c *conf) reload() error {
+	c.stateMu.RLock()
+	etag := c.etag
+	c.stateMu.RUnlock()
+
+	input := &awss3.GetObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(c.key),
+	}
+	if etag != "" {
+		input.IfNoneMatch = aws.String(etag)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	resp, err := c.client.GetObjectRequest(input).Send(ctx)
+	if err != nil {
+		if aerr, ok := err.(awserr.RequestFailure); ok && aerr.StatusCode() == http.StatusNotModified {
+			config.ObserveRefresh("s3", c.url, false)
+			return nil
+		}
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == awss3.ErrCodeNoSuchKey {
+			c.stateMu.Lock()
+			changed := c.err != config.ErrNoValue
+			c.val, c.etag, c.err = nil, "", config.ErrNoValue
+			c.stateMu.Unlock()
+
+			config.ObserveRefresh("s3", c.url, changed)
+			return nil
+		}
+
+		return errors.Wrap(err, "failed to get object")
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read object")
+	}
+
+	c.stateMu.Lock()
+	changed := c.err != nil || !bytes.Equal(b, c.val)
+	c.val, c.etag, c.err = b, aws.StringValue(resp.ETag), nil
+	c.stateMu.Unlock()
+
+	config.ObserveRefresh("s3", c.url, changed)
+
+	return nil
+}
+
+// NewBytesConfig creates an S3-based byte array config
+func NewBytesConfig(client s3iface.ClientAPI, url string, defaultValue []byte, opts ...Option) (config.Bytes, error) {
+	c, err := NewConfig(client, url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return wrapper.NewBytesConfig(c, defaultValue), nil
+}
+
+// NewInt64Config creates an S3-based int64 config
+func NewInt64Config(client s3iface.ClientAPI, url string, defaultValue int64, opts ...Option) (config.Int64, error) {
+	c, err := NewConfig(client, url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return wrapper.NewInt64Config(c, defaultValue), nil
+}
+
+// NewUint64Config creates an S3-based uint64 config
+func NewUint64Config(client s3iface.ClientAPI, url string, defaultValue uint64, opts ...Option) (config.Uint64, error) {
+	c, err := NewConfig(client, url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return wrapper.NewUint64Config(c, defaultValue), nil
+}
+
+// NewFloat64Config creates an S3-based float64 config
+func NewFloat64Config(client s3iface.ClientAPI, url string, defaultValue float64, opts ...Option) (config.Float64, error) {
+	c, err := NewConfig(client, url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return wrapper.NewFloat64Config(c, defaultValue), nil
+}
+
+// NewDurationConfig creates an S3-based duration config
+func NewDurationConfig(client s3iface.ClientAPI, url string, defaultValue time.Duration, opts ...Option) (config.Duration, error) {
+	c, err := NewConfig(client, url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return wrapper.NewDurationConfig(c, defaultValue), nil
+}
+
+// NewStringConfig creates an S3-based string config
+func NewStringConfig(client s3iface.ClientAPI, url string, defaultValue string, opts ...Option) (config.String, error) {
+	c, err := NewConfig(client, url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return wrapper.NewStringConfig(c, defaultValue), nil
+}
+
+// NewBoolConfig creates an S3-based bool config
+func NewBoolConfig(client s3iface.ClientAPI, url string, defaultValue bool, opts ...Option) (config.Bool, error) {
+	c, err := NewConfig(client, url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return wrapper.NewBoolConfig(c, defaultValue), nil
+}
+
+// NewSecretConfig creates an S3-based secret config, whose value is redacted
+// when logged
+func NewSecretConfig(client s3iface.ClientAPI, url string, defaultValue config.Secret, opts ...Option) (config.SecretValue, error) {
+	c, err := NewConfig(client, url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return wrapper.NewSecretConfig(c, defaultValue), nil
+}
@@ -0,0 +1,134 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/s3iface"
+	"github.com/ory/dockertest"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	s3test "github.com/kinecosystem/agora-common/aws/s3/test"
+	"github.com/kinecosystem/agora-common/config"
+	"github.com/kinecosystem/agora-common/testutil"
+)
+
+const testBucket = "config-bucket"
+
+var s3Client s3iface.ClientAPI
+
+func TestMain(m *testing.M) {
+	log := logrus.StandardLogger()
+
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		log.WithError(err).Error("Error creating docker pool")
+		os.Exit(1)
+	}
+
+	var cleanUpFunc func()
+	s3Client, cleanUpFunc, err = s3test.StartS3(pool)
+	if err != nil {
+		log.WithError(err).Error("Error starting S3 image")
+		os.Exit(1)
+	}
+
+	_, err = s3Client.CreateBucketRequest(&awss3.CreateBucketInput{
+		Bucket: aws.String(testBucket),
+	}).Send(context.Background())
+	if err != nil {
+		cleanUpFunc()
+		log.WithError(err).Error("Error creating bucket")
+		os.Exit(1)
+	}
+
+	code := m.Run()
+	cleanUpFunc()
+	os.Exit(code)
+}
+
+func TestConfig(t *testing.T) {
+	c, err := NewConfig(s3Client, "s3://"+testBucket+"/configs/example", WithPollInterval(50*time.Millisecond))
+	require.NoError(t, err)
+	defer c.Shutdown()
+
+	_, err = c.Get(context.Background())
+	assert.Equal(t, config.ErrNoValue, err)
+
+	putObject(t, "configs/example", "hello")
+	require.NoError(t, testutil.WaitFor(5*time.Second, 50*time.Millisecond, func() bool {
+		v, err := c.Get(context.Background())
+		return err == nil && bytes.Equal([]byte("hello"), v.([]byte))
+	}))
+
+	putObject(t, "configs/example", "world")
+	require.NoError(t, testutil.WaitFor(5*time.Second, 50*time.Millisecond, func() bool {
+		v, err := c.Get(context.Background())
+		return err == nil && bytes.Equal([]byte("world"), v.([]byte))
+	}))
+
+	_, err = s3Client.DeleteObjectRequest(&awss3.DeleteObjectInput{
+		Bucket: aws.String(testBucket),
+		Key:    aws.String("configs/example"),
+	}).Send(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, testutil.WaitFor(5*time.Second, 50*time.Millisecond, func() bool {
+		_, err := c.Get(context.Background())
+		return err == config.ErrNoValue
+	}))
+
+	c.Shutdown()
+	_, err = c.Get(context.Background())
+	assert.Equal(t, config.ErrShutdown, err)
+}
+
+func TestConfig_Wrappers(t *testing.T) {
+	putObject(t, "configs/int", "123")
+
+	i, err := NewInt64Config(s3Client, "s3://"+testBucket+"/configs/int", 0)
+	require.NoError(t, err)
+	defer i.Shutdown()
+	assert.EqualValues(t, 123, i.Get(context.Background()))
+
+	s, err := NewStringConfig(s3Client, "s3://"+testBucket+"/configs/missing", "default")
+	require.NoError(t, err)
+	defer s.Shutdown()
+	assert.Equal(t, "default", s.Get(context.Background()))
+}
+
+func TestConfig_BadURL(t *testing.T) {
+	for _, u := range []string{
+		"file:///bucket/key",
+		"s3:///key",
+		"s3://bucket",
+		"s3://bucket/",
+		"://bucket/key",
+	} {
+		_, err := NewConfig(s3Client, u)
+		assert.Error(t, err, u)
+	}
+}
+
+func TestConfig_InvalidPollInterval(t *testing.T) {
+	c, err := NewConfig(s3Client, "s3://"+testBucket+"/configs/example", WithPollInterval(0))
+	require.NoError(t, err)
+	defer c.Shutdown()
+
+	assert.Equal(t, defaultOptions.PollInterval, c.(*conf).opts.PollInterval)
+}
+
+func putObject(t *testing.T, key, value string) {
+	_, err := s3Client.PutObjectRequest(&awss3.PutObjectInput{
+		Body:   bytes.NewReader([]byte(value)),
+		Bucket: aws.String(testBucket),
+		Key:    aws.String(key),
+	}).Send(context.Background())
+	require.NoError(t, err)
+}
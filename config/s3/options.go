@@ -0,0 +1,39 @@
+package s3
+
+import (
+	"time"
+
+	"github.com/kinecosystem/agora-common/logging"
+)
+
+type options struct {
+	// PollInterval is the interval at which the object is checked for changes.
+	PollInterval time.Duration
+
+	// Log is the logger used to report failures. If nil, logging.Default is
+	// used.
+	Log logging.Logger
+}
+
+// Option configures an S3 config.
+type Option func(o *options)
+
+// WithPollInterval configures the interval at which the object is checked for changes.
+// Non-positive intervals are ignored.
+func WithPollInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.PollInterval = interval
+	}
+}
+
+// WithLogger configures the logger used to report failures, instead of the
+// standard logrus logger.
+func WithLogger(log logging.Logger) Option {
+	return func(o *options) {
+		o.Log = log
+	}
+}
+
+var defaultOptions = options{
+	PollInterval: time.Minute,
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+)
+
+const redacted = "[REDACTED]"
+
+// Secret is a sensitive config value, such as a credential or private key.
+//
+// Secrets are redacted when formatted or marshalled, so that logging
+// effective config values cannot leak them. Use Reveal to access the value.
+type Secret []byte
+
+// Reveal returns the secret value.
+func (s Secret) Reveal() []byte {
+	return s
+}
+
+// String implements fmt.Stringer, returning a redacted value.
+func (s Secret) String() string {
+	return redacted
+}
+
+// GoString implements fmt.GoStringer, returning a redacted value.
+func (s Secret) GoString() string {
+	return redacted
+}
+
+// Format implements fmt.Formatter, formatting a redacted value for all verbs.
+func (s Secret) Format(f fmt.State, _ rune) {
+	_, _ = f.Write([]byte(redacted))
+}
+
+// MarshalText implements encoding.TextMarshaler, returning a redacted value.
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(redacted), nil
+}
+
+// MarshalJSON implements json.Marshaler, returning a redacted value.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + redacted + `"`), nil
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecret(t *testing.T) {
+	s := Secret("password")
+	assert.Equal(t, []byte("password"), s.Reveal())
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x", "%X"} {
+		assert.Equal(t, redacted, fmt.Sprintf(format, s), format)
+	}
+
+	b, err := json.Marshal(struct {
+		Password Secret `json:"password"`
+	}{s})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"password": "[REDACTED]"}`, string(b))
+
+	for _, formatter := range []logrus.Formatter{&logrus.TextFormatter{}, &logrus.JSONFormatter{}} {
+		buf := &bytes.Buffer{}
+		log := logrus.New()
+		log.SetOutput(buf)
+		log.SetFormatter(formatter)
+		log.WithField("credential", s).Info("config")
+
+		assert.NotContains(t, buf.String(), "password")
+		assert.Contains(t, buf.String(), redacted)
+	}
+}
@@ -0,0 +1,198 @@
+// Package secretsmanager provides a config.Config backed by an AWS Secrets
+// Manager secret.
+package secretsmanager
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/awserr"
+	awssm "github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/secretsmanageriface"
+	"github.com/pkg/errors"
+
+	"github.com/kinecosystem/agora-common/config"
+	"github.com/kinecosystem/agora-common/config/wrapper"
+	"github.com/kinecosystem/agora-common/logging"
+)
+
+type conf struct {
+	log    logging.Logger
+	client secretsmanageriface.ClientAPI
+	id     string
+	opts   options
+
+	stateMu  sync.RWMutex
+	val      []byte
+	err      error
+	shutdown bool
+
+	shutdownCh   chan struct{}
+	shutdownOnce sync.Once
+}
+
+// NewConfig returns a config.Config whose value is the current version of the
+// secret with the provided ID (name or ARN). Either the SecretString or the
+// SecretBinary of the secret is returned, as a byte array. If the secret does
+// not exist, config.ErrNoValue is returned.
+//
+// The value is cached, and refreshed in the background. If the secret cannot
+// be refreshed, the last loaded value continues to be returned.
+func NewConfig(client secretsmanageriface.ClientAPI, id string, opts ...Option) config.Config {
+	c := &conf{
+		client:     client,
+		id:         id,
+		opts:       defaultOptions,
+		err:        config.ErrNoValue,
+		shutdownCh: make(chan struct{}),
+	}
+
+	for _, o := range opts {
+		o(&c.opts)
+	}
+	if c.opts.RefreshInterval <= 0 {
+		c.opts.RefreshInterval = defaultOptions.RefreshInterval
+	}
+	c.log = logging.OrDefault(c.opts.Log).WithFields(logging.Fields{
+		"type":      "config/secretsmanager",
+		"secret_id": id,
+	})
+
+	if err := c.refresh(); err != nil {
+		c.log.WithError(err).Warn("failed to load secret")
+		config.ObserveFetchError("secretsmanager", c.id)
+	}
+
+	go c.refreshPeriodically()
+
+	return c
+}
+
+// Get implements Config.Get
+func (c *conf) Get(_ context.Context) (interface{}, error) {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+
+	if c.shutdown {
+		return nil, config.ErrShutdown
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	return c.val, nil
+}
+
+// Shutdown implements Config.Shutdown
+func (c *conf) Shutdown() {
+	c.shutdownOnce.Do(func() {
+		c.stateMu.Lock()
+		c.shutdown = true
+		c.stateMu.Unlock()
+
+		close(c.shutdownCh)
+	})
+}
+
+func (c *conf) refreshPeriodically() {
+	ticker := time.NewTicker(c.opts.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.shutdownCh:
+			return
+		case <-ticker.C:
+		}
+
+		if err := c.refresh(); err != nil {
+			c.log.WithError(err).Warn("failed to refresh secret")
+			config.ObserveFetchError("secretsmanager", c.id)
+		}
+	}
+}
+
+func (c *conf) refresh() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	resp, err := c.client.GetSecretValueRequest(&awssm.GetSecretValueInput{
+		SecretId: aws.String(c.id),
+	}).Send(ctx)
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == awssm.ErrCodeResourceNotFoundException {
+			c.stateMu.Lock()
+			changed := c.err != config.ErrNoValue
+			c.val, c.err = nil, config.ErrNoValue
+			c.stateMu.Unlock()
+
+			config.ObserveRefresh("secretsmanager", c.id, changed)
+			return nil
+		}
+
+		return errors.Wrap(err, "failed to get secret value")
+	}
+
+	var val []byte
+	switch {
+	case resp.SecretString != nil:
+		val = []byte(*resp.SecretString)
+	case resp.SecretBinary != nil:
+		val = resp.SecretBinary
+	default:
+		return errors.New("secret has no value")
+	}
+
+	c.stateMu.Lock()
+	changed := c.err != nil || !bytes.Equal(val, c.val)
+	c.val, c.err = val, nil
+	c.stateMu.Unlock()
+
+	config.ObserveRefresh("secretsmanager", c.id, changed)
+
+	return nil
+}
+
+// NewBytesConfig creates a Secrets Manager-based byte array config
+func NewBytesConfig(client secretsmanageriface.ClientAPI, id string, defaultValue []byte, opts ...Option) config.Bytes {
+	return wrapper.NewBytesConfig(NewConfig(client, id, opts...), defaultValue)
+}
+
+// NewInt64Config creates a Secrets Manager-based int64 config
+func NewInt64Config(client secretsmanageriface.ClientAPI, id string, defaultValue int64, opts ...Option) config.Int64 {
+	return wrapper.NewInt64Config(NewConfig(client, id, opts...), defaultValue)
+}
+
+// NewUint64Config creates a Secrets Manager-based uint64 config
+func NewUint64Config(client secretsmanageriface.ClientAPI, id string, defaultValue uint64, opts ...Option) config.Uint64 {
+	return wrapper.NewUint64Config(NewConfig(client, id, opts...), defaultValue)
+}
+
+// NewFloat64Config creates a Secrets Manager-based float64 config
+func NewFloat64Config(client secretsmanageriface.ClientAPI, id string, defaultValue float64, opts ...Option) config.Float64 {
+	return wrapper.NewFloat64Config(NewConfig(client, id, opts...), defaultValue)
+}
+
+// NewDurationConfig creates a Secrets Manager-based duration config
+func NewDurationConfig(client secretsmanageriface.ClientAPI, id string, defaultValue time.Duration, opts ...Option) config.Duration {
+	return wrapper.NewDurationConfig(NewConfig(client, id, opts...), defaultValue)
+}
+
+// NewStringConfig creates a Secrets Manager-based string config
+func NewStringConfig(client secretsmanageriface.ClientAPI, id string, defaultValue string, opts ...Option) config.String {
+	return wrapper.NewStringConfig(NewConfig(client, id, opts...), defaultValue)
+}
+
+// NewBoolConfig creates a Secrets Manager-based bool config
+func NewBoolConfig(client secretsmanageriface.ClientAPI, id string, defaultValue bool, opts ...Option) config.Bool {
+	return wrapper.NewBoolConfig(NewConfig(client, id, opts...), defaultValue)
+}
+
+// NewSecretConfig creates a Secrets Manager-based secret config, whose value
+// is redacted when logged
+func NewSecretConfig(client secretsmanageriface.ClientAPI, id string, defaultValue config.Secret, opts ...Option) config.SecretValue {
+	return wrapper.NewSecretConfig(NewConfig(client, id, opts...), defaultValue)
+}
@@ -0,0 +1,164 @@
+package secretsmanager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/defaults"
+	awssm "github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kinecosystem/agora-common/config"
+	"github.com/kinecosystem/agora-common/testutil"
+)
+
+// testServer is a minimal Secrets Manager server.
+type testServer struct {
+	sync.Mutex
+	secrets map[string]string
+	failing bool
+}
+
+func (s *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		SecretId string
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+
+	if s.failing {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"__type":  awssm.ErrCodeInternalServiceError,
+			"message": "internal error",
+		})
+		return
+	}
+
+	val, ok := s.secrets[input.SecretId]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"__type":  awssm.ErrCodeResourceNotFoundException,
+			"message": "not found",
+		})
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"Name":         input.SecretId,
+		"SecretString": val,
+	})
+}
+
+func (s *testServer) set(name, val string) {
+	s.Lock()
+	defer s.Unlock()
+	s.secrets[name] = val
+}
+
+func (s *testServer) setFailing(failing bool) {
+	s.Lock()
+	defer s.Unlock()
+	s.failing = failing
+}
+
+func (s *testServer) remove(name string) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.secrets, name)
+}
+
+func newTestClient() (*awssm.Client, *testServer, func()) {
+	ts := &testServer{secrets: make(map[string]string)}
+	server := httptest.NewServer(ts)
+
+	cfg := defaults.Config()
+	cfg.Region = "test-region-1"
+	cfg.Credentials = aws.NewStaticCredentialsProvider("test", "test", "test")
+	cfg.EndpointResolver = aws.ResolveWithEndpointURL(server.URL)
+	cfg.Retryer = aws.NoOpRetryer{}
+
+	return awssm.New(cfg), ts, server.Close
+}
+
+func TestConfig(t *testing.T) {
+	client, ts, cleanup := newTestClient()
+	defer cleanup()
+
+	c := NewConfig(client, "app/secret", WithRefreshInterval(10*time.Millisecond))
+	defer c.Shutdown()
+
+	_, err := c.Get(context.Background())
+	assert.Equal(t, config.ErrNoValue, err)
+
+	ts.set("app/secret", "hello")
+	require.NoError(t, testutil.WaitFor(time.Second, 10*time.Millisecond, func() bool {
+		v, err := c.Get(context.Background())
+		return err == nil && string(v.([]byte)) == "hello"
+	}))
+
+	// The last value should be retained while the secret cannot be
+	// refreshed.
+	ts.setFailing(true)
+	ts.set("app/secret", "world")
+	time.Sleep(50 * time.Millisecond)
+	v, err := c.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hello"), v)
+
+	ts.setFailing(false)
+	require.NoError(t, testutil.WaitFor(time.Second, 10*time.Millisecond, func() bool {
+		v, err := c.Get(context.Background())
+		return err == nil && string(v.([]byte)) == "world"
+	}))
+
+	ts.remove("app/secret")
+	require.NoError(t, testutil.WaitFor(time.Second, 10*time.Millisecond, func() bool {
+		_, err := c.Get(context.Background())
+		return err == config.ErrNoValue
+	}))
+
+	c.Shutdown()
+	_, err = c.Get(context.Background())
+	assert.Equal(t, config.ErrShutdown, err)
+}
+
+func TestConfig_Wrappers(t *testing.T) {
+	client, ts, cleanup := newTestClient()
+	defer cleanup()
+
+	ts.set("app/int", "123")
+
+	i := NewInt64Config(client, "app/int", 0)
+	defer i.Shutdown()
+	assert.EqualValues(t, 123, i.Get(context.Background()))
+
+	s := NewStringConfig(client, "app/missing", "default")
+	defer s.Shutdown()
+	assert.Equal(t, "default", s.Get(context.Background()))
+}
+
+func TestConfig_InvalidRefreshInterval(t *testing.T) {
+	client, _, cleanup := newTestClient()
+	defer cleanup()
+
+	c := NewConfig(client, "app/secret", WithRefreshInterval(0))
+	defer c.Shutdown()
+
+	assert.Equal(t, defaultOptions.RefreshInterval, c.(*conf).opts.RefreshInterval)
+}
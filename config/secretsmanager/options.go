@@ -0,0 +1,39 @@
+package secretsmanager
+
+import (
+	"time"
+
+	"github.com/kinecosystem/agora-common/logging"
+)
+
+type options struct {
+	// RefreshInterval is the interval at which the secret is refreshed.
+	RefreshInterval time.Duration
+
+	// Log is the logger used to report failures. If nil, logging.Default is
+	// used.
+	Log logging.Logger
+}
+
+// Option configures a Secrets Manager config.
+type Option func(o *options)
+
+// WithRefreshInterval configures the interval at which the secret is refreshed.
+// Non-positive intervals are ignored.
+func WithRefreshInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.RefreshInterval = interval
+	}
+}
+
+// WithLogger configures the logger used to report failures, instead of the
+// standard logrus logger.
+func WithLogger(log logging.Logger) Option {
+	return func(o *options) {
+		o.Log = log
+	}
+}
+
+var defaultOptions = options{
+	RefreshInterval: 5 * time.Minute,
+}
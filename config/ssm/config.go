@@ -0,0 +1,193 @@
+// Package ssm provides a config.Config backed by an AWS Systems Manager
+// Parameter Store parameter.
+package ssm
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/awserr"
+	awsssm "github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/ssmiface"
+	"github.com/pkg/errors"
+
+	"github.com/kinecosystem/agora-common/config"
+	"github.com/kinecosystem/agora-common/config/wrapper"
+	"github.com/kinecosystem/agora-common/logging"
+)
+
+type conf struct {
+	log    logging.Logger
+	client ssmiface.ClientAPI
+	name   string
+	opts   options
+
+	stateMu  sync.RWMutex
+	val      []byte
+	err      error
+	shutdown bool
+
+	shutdownCh   chan struct{}
+	shutdownOnce sync.Once
+}
+
+// NewConfig returns a config.Config whose value is the value of the named
+// parameter. SecureString parameters are decrypted. If the parameter does not
+// exist, config.ErrNoValue is returned.
+//
+// The value is cached, and refreshed in the background. If the parameter
+// cannot be refreshed, the last loaded value continues to be returned.
+func NewConfig(client ssmiface.ClientAPI, name string, opts ...Option) config.Config {
+	c := &conf{
+		client:     client,
+		name:       name,
+		opts:       defaultOptions,
+		err:        config.ErrNoValue,
+		shutdownCh: make(chan struct{}),
+	}
+
+	for _, o := range opts {
+		o(&c.opts)
+	}
+	if c.opts.RefreshInterval <= 0 {
+		c.opts.RefreshInterval = defaultOptions.RefreshInterval
+	}
+	c.log = logging.OrDefault(c.opts.Log).WithFields(logging.Fields{
+		"type": "config/ssm",
+		"name": name,
+	})
+
+	if err := c.refresh(); err != nil {
+		c.log.WithError(err).Warn("failed to load parameter")
+		config.ObserveFetchError("ssm", c.name)
+	}
+
+	go c.refreshPeriodically()
+
+	return c
+}
+
+// Get implements Config.Get
+func (c *conf) Get(_ context.Context) (interface{}, error) {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+
+	if c.shutdown {
+		return nil, config.ErrShutdown
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	return c.val, nil
+}
+
+// Shutdown implements Config.Shutdown
+func (c *conf) Shutdown() {
+	c.shutdownOnce.Do(func() {
+		c.stateMu.Lock()
+		c.shutdown = true
+		c.stateMu.Unlock()
+
+		close(c.shutdownCh)
+	})
+}
+
+func (c *conf) refreshPeriodically() {
+	ticker := time.NewTicker(c.opts.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.shutdownCh:
+			return
+		case <-ticker.C:
+		}
+
+		if err := c.refresh(); err != nil {
+			c.log.WithError(err).Warn("failed to refresh parameter")
+			config.ObserveFetchError("ssm", c.name)
+		}
+	}
+}
+
+func (c *conf) refresh() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	resp, err := c.client.GetParameterRequest(&awsssm.GetParameterInput{
+		Name:           aws.String(c.name),
+		WithDecryption: aws.Bool(true),
+	}).Send(ctx)
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == awsssm.ErrCodeParameterNotFound {
+			c.stateMu.Lock()
+			changed := c.err != config.ErrNoValue
+			c.val, c.err = nil, config.ErrNoValue
+			c.stateMu.Unlock()
+
+			config.ObserveRefresh("ssm", c.name, changed)
+			return nil
+		}
+
+		return errors.Wrap(err, "failed to get parameter")
+	}
+	if resp.Parameter == nil || resp.Parameter.Value == nil {
+		return errors.New("parameter has no value")
+	}
+
+	val := []byte(*resp.Parameter.Value)
+
+	c.stateMu.Lock()
+	changed := c.err != nil || !bytes.Equal(val, c.val)
+	c.val, c.err = val, nil
+	c.stateMu.Unlock()
+
+	config.ObserveRefresh("ssm", c.name, changed)
+
+	return nil
+}
+
+// NewBytesConfig creates a Parameter Store-based byte array config
+func NewBytesConfig(client ssmiface.ClientAPI, name string, defaultValue []byte, opts ...Option) config.Bytes {
+	return wrapper.NewBytesConfig(NewConfig(client, name, opts...), defaultValue)
+}
+
+// NewInt64Config creates a Parameter Store-based int64 config
+func NewInt64Config(client ssmiface.ClientAPI, name string, defaultValue int64, opts ...Option) config.Int64 {
+	return wrapper.NewInt64Config(NewConfig(client, name, opts...), defaultValue)
+}
+
+// NewUint64Config creates a Parameter Store-based uint64 config
+func NewUint64Config(client ssmiface.ClientAPI, name string, defaultValue uint64, opts ...Option) config.Uint64 {
+	return wrapper.NewUint64Config(NewConfig(client, name, opts...), defaultValue)
+}
+
+// NewFloat64Config creates a Parameter Store-based float64 config
+func NewFloat64Config(client ssmiface.ClientAPI, name string, defaultValue float64, opts ...Option) config.Float64 {
+	return wrapper.NewFloat64Config(NewConfig(client, name, opts...), defaultValue)
+}
+
+// NewDurationConfig creates a Parameter Store-based duration config
+func NewDurationConfig(client ssmiface.ClientAPI, name string, defaultValue time.Duration, opts ...Option) config.Duration {
+	return wrapper.NewDurationConfig(NewConfig(client, name, opts...), defaultValue)
+}
+
+// NewStringConfig creates a Parameter Store-based string config
+func NewStringConfig(client ssmiface.ClientAPI, name string, defaultValue string, opts ...Option) config.String {
+	return wrapper.NewStringConfig(NewConfig(client, name, opts...), defaultValue)
+}
+
+// NewBoolConfig creates a Parameter Store-based bool config
+func NewBoolConfig(client ssmiface.ClientAPI, name string, defaultValue bool, opts ...Option) config.Bool {
+	return wrapper.NewBoolConfig(NewConfig(client, name, opts...), defaultValue)
+}
+
+// NewSecretConfig creates a Parameter Store-based secret config, whose value
+// is redacted when logged
+func NewSecretConfig(client ssmiface.ClientAPI, name string, defaultValue config.Secret, opts ...Option) config.SecretValue {
+	return wrapper.NewSecretConfig(NewConfig(client, name, opts...), defaultValue)
+}
@@ -0,0 +1,173 @@
+package ssm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/defaults"
+	awsssm "github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kinecosystem/agora-common/config"
+	"github.com/kinecosystem/agora-common/testutil"
+)
+
+// testServer is a minimal Parameter Store server.
+type testServer struct {
+	sync.Mutex
+	params    map[string]string
+	decrypted bool
+	failing   bool
+}
+
+func (s *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Name           string
+		WithDecryption bool
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.decrypted = input.WithDecryption
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+
+	if s.failing {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"__type":  awsssm.ErrCodeInternalServerError,
+			"message": "internal error",
+		})
+		return
+	}
+
+	val, ok := s.params[input.Name]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"__type":  awsssm.ErrCodeParameterNotFound,
+			"message": "not found",
+		})
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"Parameter": map[string]interface{}{
+			"Name":  input.Name,
+			"Value": val,
+		},
+	})
+}
+
+func (s *testServer) set(name, val string) {
+	s.Lock()
+	defer s.Unlock()
+	s.params[name] = val
+}
+
+func (s *testServer) setFailing(failing bool) {
+	s.Lock()
+	defer s.Unlock()
+	s.failing = failing
+}
+
+func (s *testServer) remove(name string) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.params, name)
+}
+
+func newTestClient() (*awsssm.Client, *testServer, func()) {
+	ts := &testServer{params: make(map[string]string)}
+	server := httptest.NewServer(ts)
+
+	cfg := defaults.Config()
+	cfg.Region = "test-region-1"
+	cfg.Credentials = aws.NewStaticCredentialsProvider("test", "test", "test")
+	cfg.EndpointResolver = aws.ResolveWithEndpointURL(server.URL)
+	cfg.Retryer = aws.NoOpRetryer{}
+
+	return awsssm.New(cfg), ts, server.Close
+}
+
+func TestConfig(t *testing.T) {
+	client, ts, cleanup := newTestClient()
+	defer cleanup()
+
+	c := NewConfig(client, "/app/secret", WithRefreshInterval(10*time.Millisecond))
+	defer c.Shutdown()
+
+	_, err := c.Get(context.Background())
+	assert.Equal(t, config.ErrNoValue, err)
+
+	ts.set("/app/secret", "hello")
+	require.NoError(t, testutil.WaitFor(time.Second, 10*time.Millisecond, func() bool {
+		v, err := c.Get(context.Background())
+		return err == nil && string(v.([]byte)) == "hello"
+	}))
+
+	ts.Lock()
+	assert.True(t, ts.decrypted)
+	ts.Unlock()
+
+	// The last value should be retained while the parameter cannot be
+	// refreshed.
+	ts.setFailing(true)
+	ts.set("/app/secret", "world")
+	time.Sleep(50 * time.Millisecond)
+	v, err := c.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hello"), v)
+
+	ts.setFailing(false)
+	require.NoError(t, testutil.WaitFor(time.Second, 10*time.Millisecond, func() bool {
+		v, err := c.Get(context.Background())
+		return err == nil && string(v.([]byte)) == "world"
+	}))
+
+	ts.remove("/app/secret")
+	require.NoError(t, testutil.WaitFor(time.Second, 10*time.Millisecond, func() bool {
+		_, err := c.Get(context.Background())
+		return err == config.ErrNoValue
+	}))
+
+	c.Shutdown()
+	_, err = c.Get(context.Background())
+	assert.Equal(t, config.ErrShutdown, err)
+}
+
+func TestConfig_Wrappers(t *testing.T) {
+	client, ts, cleanup := newTestClient()
+	defer cleanup()
+
+	ts.set("/app/int", "123")
+
+	i := NewInt64Config(client, "/app/int", 0)
+	defer i.Shutdown()
+	assert.EqualValues(t, 123, i.Get(context.Background()))
+
+	s := NewStringConfig(client, "/app/missing", "default")
+	defer s.Shutdown()
+	assert.Equal(t, "default", s.Get(context.Background()))
+}
+
+func TestConfig_InvalidRefreshInterval(t *testing.T) {
+	client, _, cleanup := newTestClient()
+	defer cleanup()
+
+	c := NewConfig(client, "/app/secret", WithRefreshInterval(0))
+	defer c.Shutdown()
+
+	assert.Equal(t, defaultOptions.RefreshInterval, c.(*conf).opts.RefreshInterval)
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// defaultWatchInterval is the interval used by Watchers that are created with
+// a non-positive interval.
+const defaultWatchInterval = 10 * time.Second
+
+// ChangeFunc is invoked with the previous and current values of a watched
+// Config when its value changes. A nil value indicates that no value was set.
+type ChangeFunc func(old, new interface{})
+
+// Watcher is a Config that polls an underlying Config, and notifies
+// subscribers when its value changes.
+type Watcher struct {
+	config   Config
+	interval time.Duration
+
+	stateMu     sync.Mutex
+	value       interface{}
+	nextID      int
+	subscribers map[int]ChangeFunc
+
+	// ctx is cancelled on shutdown, so that an in-flight poll of the
+	// underlying Config does not block shutdown.
+	ctx          context.Context
+	cancel       context.CancelFunc
+	shutdownOnce sync.Once
+}
+
+// NewWatcher returns a Watcher that checks the provided Config for changes at
+// the specified interval. If the interval is not positive, a default of 10
+// seconds is used.
+//
+// Errors other than ErrNoValue are ignored, in which case the last known value
+// is retained. Values are compared using reflect.DeepEqual.
+func NewWatcher(config Config, interval time.Duration) *Watcher {
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	w := &Watcher{
+		config:      config,
+		interval:    interval,
+		subscribers: make(map[int]ChangeFunc),
+	}
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+
+	w.value, _ = w.get()
+
+	go w.watch()
+
+	return w
+}
+
+// Get implements Config.Get
+func (w *Watcher) Get(ctx context.Context) (interface{}, error) {
+	return w.config.Get(ctx)
+}
+
+// Shutdown stops watching for changes, and shuts down the underlying Config.
+func (w *Watcher) Shutdown() {
+	w.shutdownOnce.Do(func() {
+		w.cancel()
+		w.config.Shutdown()
+	})
+}
+
+// Subscribe registers a ChangeFunc that is invoked whenever the value changes.
+// ChangeFuncs are invoked sequentially, from a single goroutine.
+//
+// The returned function unsubscribes the ChangeFunc.
+func (w *Watcher) Subscribe(fn ChangeFunc) (unsubscribe func()) {
+	w.stateMu.Lock()
+	id := w.nextID
+	w.nextID++
+	w.subscribers[id] = fn
+	w.stateMu.Unlock()
+
+	return func() {
+		w.stateMu.Lock()
+		delete(w.subscribers, id)
+		w.stateMu.Unlock()
+	}
+}
+
+func (w *Watcher) watch() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		w.check()
+	}
+}
+
+// check notifies the subscribers if the value has changed.
+func (w *Watcher) check() {
+	value, ok := w.get()
+	if !ok {
+		return
+	}
+
+	w.stateMu.Lock()
+	old := w.value
+	if reflect.DeepEqual(old, value) {
+		w.stateMu.Unlock()
+		return
+	}
+	w.value = value
+
+	subscribers := make([]ChangeFunc, 0, len(w.subscribers))
+	for id := 0; id < w.nextID; id++ {
+		if fn, ok := w.subscribers[id]; ok {
+			subscribers = append(subscribers, fn)
+		}
+	}
+	w.stateMu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(old, value)
+	}
+}
+
+// get returns the current value of the underlying config, and whether or not
+// it was successfully retrieved.
+func (w *Watcher) get() (interface{}, bool) {
+	value, err := w.config.Get(w.ctx)
+	if err == ErrNoValue {
+		return nil, true
+	} else if err != nil {
+		return nil, false
+	}
+
+	return value, true
+}
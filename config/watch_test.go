@@ -0,0 +1,82 @@
+package config_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kinecosystem/agora-common/config"
+	"github.com/kinecosystem/agora-common/config/memory"
+	"github.com/kinecosystem/agora-common/testutil"
+)
+
+type change struct {
+	old, new interface{}
+}
+
+func TestWatcher(t *testing.T) {
+	mock := memory.NewConfig([]byte("a"))
+	w := config.NewWatcher(mock, 10*time.Millisecond)
+	defer w.Shutdown()
+
+	var mu sync.Mutex
+	var changes []change
+	getChanges := func() []change {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]change(nil), changes...)
+	}
+
+	unsubscribe := w.Subscribe(func(old, new interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, change{old, new})
+	})
+
+	// Setting an identical value is not a change.
+	mock.SetValue([]byte("a"))
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, getChanges())
+
+	mock.SetValue([]byte("b"))
+	require.NoError(t, testutil.WaitFor(time.Second, 10*time.Millisecond, func() bool { return len(getChanges()) == 1 }))
+	assert.Equal(t, change{[]byte("a"), []byte("b")}, getChanges()[0])
+
+	// Errors are ignored, retaining the last value.
+	mock.InduceErrors()
+	time.Sleep(50 * time.Millisecond)
+	mock.StopInducingErrors()
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, getChanges(), 1)
+
+	mock.ClearValue()
+	require.NoError(t, testutil.WaitFor(time.Second, 10*time.Millisecond, func() bool { return len(getChanges()) == 2 }))
+	assert.Equal(t, change{[]byte("b"), nil}, getChanges()[1])
+
+	val, err := w.Get(context.Background())
+	assert.Equal(t, config.ErrNoValue, err)
+	assert.Nil(t, val)
+
+	unsubscribe()
+	mock.SetValue([]byte("c"))
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, getChanges(), 2)
+
+	// Shutting down the watcher shuts down the underlying config.
+	w.Shutdown()
+	_, err = mock.Get(context.Background())
+	assert.Equal(t, config.ErrShutdown, err)
+}
+
+func TestWatcher_InvalidInterval(t *testing.T) {
+	w := config.NewWatcher(memory.NewConfig([]byte("a")), 0)
+	defer w.Shutdown()
+
+	v, err := w.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []byte("a"), v)
+}
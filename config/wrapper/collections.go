@@ -0,0 +1,287 @@
+package wrapper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/kinecosystem/agora-common/config"
+)
+
+// StringSliceConfig is a utility wrapper for a string slice config.
+//
+// Byte array values may either be a JSON array (e.g. `["a", "b"]`), or a comma
+// separated list (e.g. `a,b`).
+type StringSliceConfig struct {
+	override     config.Config
+	defaultValue []string
+
+	stateMu   sync.RWMutex
+	lastValue []string
+}
+
+// NewStringSliceConfig returns a new string slice config utility wrapper
+func NewStringSliceConfig(override config.Config, defaultValue []string) config.StringSlice {
+	return &StringSliceConfig{
+		override:     override,
+		defaultValue: defaultValue,
+		lastValue:    defaultValue,
+	}
+}
+
+// GetSafe gets a config value and propagates any errors that arise. A best-effort
+// attempt is made to return the last known value
+func (c *StringSliceConfig) GetSafe(ctx context.Context) ([]string, error) {
+	override, err := c.override.Get(ctx)
+	c.stateMu.RLock()
+	lastValue := c.lastValue
+	c.stateMu.RUnlock()
+	if err == config.ErrNoValue {
+		c.stateMu.Lock()
+		c.lastValue = c.defaultValue
+		c.stateMu.Unlock()
+		return c.defaultValue, nil
+	} else if err != nil {
+		return lastValue, err
+	}
+	switch override := override.(type) {
+	case []byte:
+		newValue, err := parseStringSlice(override)
+		if err != nil {
+			return lastValue, err
+		}
+		c.stateMu.Lock()
+		c.lastValue = newValue
+		c.stateMu.Unlock()
+		return newValue, nil
+	case []string:
+		newValue := override
+		c.stateMu.Lock()
+		c.lastValue = newValue
+		c.stateMu.Unlock()
+		return newValue, nil
+	default:
+		return lastValue, ErrUnsuportedConversion
+	}
+}
+
+// Get is a wrapper for GetSafe that ignores the returned error
+func (c *StringSliceConfig) Get(ctx context.Context) []string {
+	val, _ := c.GetSafe(ctx)
+	return val
+}
+
+// Shutdown signals the config to stop all underlying resources
+func (c *StringSliceConfig) Shutdown() {
+	c.override.Shutdown()
+}
+
+// Int64SliceConfig is a utility wrapper for an int64 slice config.
+//
+// Byte array values may either be a JSON array (e.g. `[1, 2]`), or a comma
+// separated list (e.g. `1,2`).
+type Int64SliceConfig struct {
+	override     config.Config
+	defaultValue []int64
+
+	stateMu   sync.RWMutex
+	lastValue []int64
+}
+
+// NewInt64SliceConfig returns a new int64 slice config utility wrapper
+func NewInt64SliceConfig(override config.Config, defaultValue []int64) config.Int64Slice {
+	return &Int64SliceConfig{
+		override:     override,
+		defaultValue: defaultValue,
+		lastValue:    defaultValue,
+	}
+}
+
+// GetSafe gets a config value and propagates any errors that arise. A best-effort
+// attempt is made to return the last known value
+func (c *Int64SliceConfig) GetSafe(ctx context.Context) ([]int64, error) {
+	override, err := c.override.Get(ctx)
+	c.stateMu.RLock()
+	lastValue := c.lastValue
+	c.stateMu.RUnlock()
+	if err == config.ErrNoValue {
+		c.stateMu.Lock()
+		c.lastValue = c.defaultValue
+		c.stateMu.Unlock()
+		return c.defaultValue, nil
+	} else if err != nil {
+		return lastValue, err
+	}
+	switch override := override.(type) {
+	case []byte:
+		newValue, err := parseInt64Slice(override)
+		if err != nil {
+			return lastValue, err
+		}
+		c.stateMu.Lock()
+		c.lastValue = newValue
+		c.stateMu.Unlock()
+		return newValue, nil
+	case []int64:
+		newValue := override
+		c.stateMu.Lock()
+		c.lastValue = newValue
+		c.stateMu.Unlock()
+		return newValue, nil
+	default:
+		return lastValue, ErrUnsuportedConversion
+	}
+}
+
+// Get is a wrapper for GetSafe that ignores the returned error
+func (c *Int64SliceConfig) Get(ctx context.Context) []int64 {
+	val, _ := c.GetSafe(ctx)
+	return val
+}
+
+// Shutdown signals the config to stop all underlying resources
+func (c *Int64SliceConfig) Shutdown() {
+	c.override.Shutdown()
+}
+
+// StringMapConfig is a utility wrapper for a string map config.
+//
+// Byte array values may either be a JSON object (e.g. `{"a": "1"}`), or a
+// comma separated list of key=value pairs (e.g. `a=1,b=2`).
+type StringMapConfig struct {
+	override     config.Config
+	defaultValue map[string]string
+
+	stateMu   sync.RWMutex
+	lastValue map[string]string
+}
+
+// NewStringMapConfig returns a new string map config utility wrapper
+func NewStringMapConfig(override config.Config, defaultValue map[string]string) config.StringMap {
+	return &StringMapConfig{
+		override:     override,
+		defaultValue: defaultValue,
+		lastValue:    defaultValue,
+	}
+}
+
+// GetSafe gets a config value and propagates any errors that arise. A best-effort
+// attempt is made to return the last known value
+func (c *StringMapConfig) GetSafe(ctx context.Context) (map[string]string, error) {
+	override, err := c.override.Get(ctx)
+	c.stateMu.RLock()
+	lastValue := c.lastValue
+	c.stateMu.RUnlock()
+	if err == config.ErrNoValue {
+		c.stateMu.Lock()
+		c.lastValue = c.defaultValue
+		c.stateMu.Unlock()
+		return c.defaultValue, nil
+	} else if err != nil {
+		return lastValue, err
+	}
+	switch override := override.(type) {
+	case []byte:
+		newValue, err := parseStringMap(override)
+		if err != nil {
+			return lastValue, err
+		}
+		c.stateMu.Lock()
+		c.lastValue = newValue
+		c.stateMu.Unlock()
+		return newValue, nil
+	case map[string]string:
+		newValue := override
+		c.stateMu.Lock()
+		c.lastValue = newValue
+		c.stateMu.Unlock()
+		return newValue, nil
+	default:
+		return lastValue, ErrUnsuportedConversion
+	}
+}
+
+// Get is a wrapper for GetSafe that ignores the returned error
+func (c *StringMapConfig) Get(ctx context.Context) map[string]string {
+	val, _ := c.GetSafe(ctx)
+	return val
+}
+
+// Shutdown signals the config to stop all underlying resources
+func (c *StringMapConfig) Shutdown() {
+	c.override.Shutdown()
+}
+
+func parseStringSlice(b []byte) ([]string, error) {
+	b = bytes.TrimSpace(b)
+	if len(b) > 0 && b[0] == '[' {
+		var val []string
+		if err := json.Unmarshal(b, &val); err != nil {
+			return nil, errors.Wrap(err, "invalid json array")
+		}
+		return val, nil
+	}
+
+	return splitList(string(b)), nil
+}
+
+func parseInt64Slice(b []byte) ([]int64, error) {
+	b = bytes.TrimSpace(b)
+	if len(b) > 0 && b[0] == '[' {
+		var val []int64
+		if err := json.Unmarshal(b, &val); err != nil {
+			return nil, errors.Wrap(err, "invalid json array")
+		}
+		return val, nil
+	}
+
+	items := splitList(string(b))
+	val := make([]int64, len(items))
+	for i, item := range items {
+		n, err := strconv.ParseInt(item, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		val[i] = n
+	}
+	return val, nil
+}
+
+func parseStringMap(b []byte) (map[string]string, error) {
+	b = bytes.TrimSpace(b)
+	if len(b) > 0 && b[0] == '{' {
+		var val map[string]string
+		if err := json.Unmarshal(b, &val); err != nil {
+			return nil, errors.Wrap(err, "invalid json object")
+		}
+		return val, nil
+	}
+
+	items := splitList(string(b))
+	val := make(map[string]string, len(items))
+	for _, item := range items {
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return nil, errors.Errorf("invalid key=value pair: %s", item)
+		}
+		val[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return val, nil
+}
+
+// splitList splits a comma separated list, trimming whitespace around each
+// item and omitting empty items.
+func splitList(s string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,196 @@
+package wrapper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kinecosystem/agora-common/config"
+	"github.com/kinecosystem/agora-common/config/memory"
+)
+
+func TestStringSliceConfig(t *testing.T) {
+	defaultValue := []string{"default"}
+	overridenValue := []string{"a", "b"}
+	mock := memory.NewConfig(nil)
+	wrapper := NewStringSliceConfig(mock, defaultValue)
+
+	// Return the default value when no override is set
+	val, err := wrapper.GetSafe(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, defaultValue, val)
+	assert.Equal(t, defaultValue, wrapper.Get(context.Background()))
+
+	// The overriden value is returned when set
+	mock.SetValue(overridenValue)
+	val, err = wrapper.GetSafe(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, overridenValue, val)
+	assert.Equal(t, overridenValue, wrapper.Get(context.Background()))
+
+	// The last observed config value is returned on error
+	mock.InduceErrors()
+	val, err = wrapper.GetSafe(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, overridenValue, val)
+
+	// The default value is returned when the override no longer has a value
+	mock.StopInducingErrors()
+	mock.ClearValue()
+	val, err = wrapper.GetSafe(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, defaultValue, val)
+
+	// Verify conversion from byte arrays
+	for _, tc := range []struct {
+		raw      string
+		expected []string
+	}{
+		{`["a", "b,c"]`, []string{"a", "b,c"}},
+		{"a, b ,,c", []string{"a", "b", "c"}},
+		{"", []string{}},
+	} {
+		mock.SetValue([]byte(tc.raw))
+		val, err = wrapper.GetSafe(context.Background())
+		require.NoError(t, err, tc.raw)
+		assert.Equal(t, tc.expected, val, tc.raw)
+	}
+
+	// Invalid byte array value
+	mock.SetValue([]byte(`["a"`))
+	val, err = wrapper.GetSafe(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, []string{}, val)
+
+	// Return an unsupported source value type
+	mock.SetValue("not supported")
+	_, err = wrapper.GetSafe(context.Background())
+	assert.Equal(t, err, ErrUnsuportedConversion)
+
+	// Shutdown via the wrapper
+	wrapper.Shutdown()
+	_, err = wrapper.GetSafe(context.Background())
+	assert.Equal(t, config.ErrShutdown, err)
+}
+
+func TestInt64SliceConfig(t *testing.T) {
+	defaultValue := []int64{1}
+	overridenValue := []int64{-1, 2}
+	mock := memory.NewConfig(nil)
+	wrapper := NewInt64SliceConfig(mock, defaultValue)
+
+	// Return the default value when no override is set
+	val, err := wrapper.GetSafe(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, defaultValue, val)
+	assert.Equal(t, defaultValue, wrapper.Get(context.Background()))
+
+	// The overriden value is returned when set
+	mock.SetValue(overridenValue)
+	val, err = wrapper.GetSafe(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, overridenValue, val)
+	assert.Equal(t, overridenValue, wrapper.Get(context.Background()))
+
+	// The last observed config value is returned on error
+	mock.InduceErrors()
+	val, err = wrapper.GetSafe(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, overridenValue, val)
+
+	// Verify conversion from byte arrays
+	mock.StopInducingErrors()
+	for _, tc := range []struct {
+		raw      string
+		expected []int64
+	}{
+		{"[3, -4]", []int64{3, -4}},
+		{" 5 , 6 ,", []int64{5, 6}},
+		{"", []int64{}},
+	} {
+		mock.SetValue([]byte(tc.raw))
+		val, err = wrapper.GetSafe(context.Background())
+		require.NoError(t, err, tc.raw)
+		assert.Equal(t, tc.expected, val, tc.raw)
+	}
+
+	// Invalid byte array values
+	for _, raw := range []string{"1,a", `["1"]`} {
+		mock.SetValue([]byte(raw))
+		val, err = wrapper.GetSafe(context.Background())
+		require.Error(t, err, raw)
+		assert.Equal(t, []int64{}, val)
+	}
+
+	// Return an unsupported source value type
+	mock.SetValue("not supported")
+	_, err = wrapper.GetSafe(context.Background())
+	assert.Equal(t, err, ErrUnsuportedConversion)
+
+	// Shutdown via the wrapper
+	wrapper.Shutdown()
+	_, err = wrapper.GetSafe(context.Background())
+	assert.Equal(t, config.ErrShutdown, err)
+}
+
+func TestStringMapConfig(t *testing.T) {
+	defaultValue := map[string]string{"default": "value"}
+	overridenValue := map[string]string{"a": "1"}
+	mock := memory.NewConfig(nil)
+	wrapper := NewStringMapConfig(mock, defaultValue)
+
+	// Return the default value when no override is set
+	val, err := wrapper.GetSafe(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, defaultValue, val)
+	assert.Equal(t, defaultValue, wrapper.Get(context.Background()))
+
+	// The overriden value is returned when set
+	mock.SetValue(overridenValue)
+	val, err = wrapper.GetSafe(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, overridenValue, val)
+	assert.Equal(t, overridenValue, wrapper.Get(context.Background()))
+
+	// The last observed config value is returned on error
+	mock.InduceErrors()
+	val, err = wrapper.GetSafe(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, overridenValue, val)
+
+	// Verify conversion from byte arrays
+	mock.StopInducingErrors()
+	for _, tc := range []struct {
+		raw      string
+		expected map[string]string
+	}{
+		{`{"a": "1", "b": "x=y"}`, map[string]string{"a": "1", "b": "x=y"}},
+		{"a=1, b = 2,", map[string]string{"a": "1", "b": "2"}},
+		{"", map[string]string{}},
+	} {
+		mock.SetValue([]byte(tc.raw))
+		val, err = wrapper.GetSafe(context.Background())
+		require.NoError(t, err, tc.raw)
+		assert.Equal(t, tc.expected, val, tc.raw)
+	}
+
+	// Invalid byte array values
+	for _, raw := range []string{"a=1,b", `{"a": 1}`} {
+		mock.SetValue([]byte(raw))
+		val, err = wrapper.GetSafe(context.Background())
+		require.Error(t, err, raw)
+		assert.Equal(t, map[string]string{}, val)
+	}
+
+	// Return an unsupported source value type
+	mock.SetValue("not supported")
+	_, err = wrapper.GetSafe(context.Background())
+	assert.Equal(t, err, ErrUnsuportedConversion)
+
+	// Shutdown via the wrapper
+	wrapper.Shutdown()
+	_, err = wrapper.GetSafe(context.Background())
+	assert.Equal(t, config.ErrShutdown, err)
+}
@@ -0,0 +1,69 @@
+package wrapper
+
+import (
+	"context"
+	"sync"
+
+	"github.com/kinecosystem/agora-common/config"
+)
+
+// SecretConfig is a utility wrapper for a secret config
+type SecretConfig struct {
+	override     config.Config
+	defaultValue config.Secret
+
+	stateMu   sync.RWMutex
+	lastValue config.Secret
+}
+
+// NewSecretConfig returns a new secret config utility wrapper
+func NewSecretConfig(override config.Config, defaultValue config.Secret) config.SecretValue {
+	return &SecretConfig{
+		override:     override,
+		defaultValue: defaultValue,
+		lastValue:    defaultValue,
+	}
+}
+
+// GetSafe gets a config value and propagates any errors that arise. A best-effort
+// attempt is made to return the last known value
+func (c *SecretConfig) GetSafe(ctx context.Context) (config.Secret, error) {
+	override, err := c.override.Get(ctx)
+	c.stateMu.RLock()
+	lastValue := c.lastValue
+	c.stateMu.RUnlock()
+	if err == config.ErrNoValue {
+		c.stateMu.Lock()
+		c.lastValue = c.defaultValue
+		c.stateMu.Unlock()
+		return c.defaultValue, nil
+	} else if err != nil {
+		return lastValue, err
+	}
+	var newValue config.Secret
+	switch override := override.(type) {
+	case []byte:
+		newValue = config.Secret(override)
+	case string:
+		newValue = config.Secret(override)
+	case config.Secret:
+		newValue = override
+	default:
+		return lastValue, ErrUnsuportedConversion
+	}
+	c.stateMu.Lock()
+	c.lastValue = newValue
+	c.stateMu.Unlock()
+	return newValue, nil
+}
+
+// Get is a wrapper for GetSafe that ignores the returned error
+func (c *SecretConfig) Get(ctx context.Context) config.Secret {
+	val, _ := c.GetSafe(ctx)
+	return val
+}
+
+// Shutdown signals the config to stop all underlying resources
+func (c *SecretConfig) Shutdown() {
+	c.override.Shutdown()
+}
@@ -0,0 +1,48 @@
+package wrapper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kinecosystem/agora-common/config"
+	"github.com/kinecosystem/agora-common/config/memory"
+)
+
+func TestSecretConfig(t *testing.T) {
+	defaultValue := config.Secret("default")
+	mock := memory.NewConfig(nil)
+	wrapper := NewSecretConfig(mock, defaultValue)
+
+	// Return the default value when no override is set
+	val, err := wrapper.GetSafe(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, defaultValue, val)
+
+	// Verify conversion from supported types
+	for _, v := range []interface{}{[]byte("secret"), "secret", config.Secret("secret")} {
+		mock.SetValue(v)
+		val, err = wrapper.GetSafe(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []byte("secret"), val.Reveal())
+	}
+
+	// The last observed config value is returned on error
+	mock.InduceErrors()
+	val, err = wrapper.GetSafe(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, []byte("secret"), val.Reveal())
+
+	// Return an unsupported source value type
+	mock.StopInducingErrors()
+	mock.SetValue(1)
+	_, err = wrapper.GetSafe(context.Background())
+	assert.Equal(t, err, ErrUnsuportedConversion)
+
+	// Shutdown via the wrapper
+	wrapper.Shutdown()
+	_, err = wrapper.GetSafe(context.Background())
+	assert.Equal(t, config.ErrShutdown, err)
+}
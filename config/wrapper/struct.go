@@ -0,0 +1,133 @@
+package wrapper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"github.com/kinecosystem/agora-common/config"
+)
+
+// UnmarshalFunc unmarshals a raw config value into v.
+type UnmarshalFunc func(data []byte, v interface{}) error
+
+var (
+	// JSONUnmarshal unmarshals JSON config values.
+	JSONUnmarshal UnmarshalFunc = json.Unmarshal
+
+	// YAMLUnmarshal unmarshals YAML config values.
+	YAMLUnmarshal UnmarshalFunc = yaml.Unmarshal
+)
+
+// Validator may be implemented by struct config types in order to reject
+// invalid config values.
+type Validator interface {
+	Validate() error
+}
+
+// StructConfig is a utility wrapper for a config unmarshalled into a struct
+type StructConfig struct {
+	override     config.Config
+	valueType    reflect.Type
+	unmarshal    UnmarshalFunc
+	defaultValue interface{}
+
+	stateMu   sync.RWMutex
+	lastRaw   []byte
+	lastValue interface{}
+}
+
+// NewStructConfig returns a new struct config utility wrapper. The defaultValue
+// must be a pointer to a struct, and all values returned by the config are of
+// the same type.
+//
+// Byte array values are unmarshalled into a new value using unmarshal (or
+// JSONUnmarshal if nil) whenever the raw value changes. If the type implements
+// Validator, values that fail validation are rejected, and the last known value
+// continues to be returned.
+func NewStructConfig(override config.Config, defaultValue interface{}, unmarshal UnmarshalFunc) config.Struct {
+	t := reflect.TypeOf(defaultValue)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct || reflect.ValueOf(defaultValue).IsNil() {
+		panic(fmt.Sprintf("struct config default value must be a non-nil pointer to a struct, got %T", defaultValue))
+	}
+	if unmarshal == nil {
+		unmarshal = JSONUnmarshal
+	}
+
+	return &StructConfig{
+		override:     override,
+		valueType:    t.Elem(),
+		unmarshal:    unmarshal,
+		defaultValue: defaultValue,
+		lastValue:    defaultValue,
+	}
+}
+
+// LoadSafe loads the latest config value and propagates any errors that arise.
+// A best-effort attempt is made to return the last known value
+func (c *StructConfig) LoadSafe(ctx context.Context) (interface{}, error) {
+	override, err := c.override.Get(ctx)
+	c.stateMu.RLock()
+	lastRaw := c.lastRaw
+	lastValue := c.lastValue
+	c.stateMu.RUnlock()
+	if err == config.ErrNoValue {
+		c.stateMu.Lock()
+		c.lastRaw = nil
+		c.lastValue = c.defaultValue
+		c.stateMu.Unlock()
+		return c.defaultValue, nil
+	} else if err != nil {
+		return lastValue, err
+	}
+	switch override := override.(type) {
+	case []byte:
+		if lastRaw != nil && bytes.Equal(lastRaw, override) {
+			return lastValue, nil
+		}
+
+		newValue := reflect.New(c.valueType).Interface()
+		if err := c.unmarshal(override, newValue); err != nil {
+			return lastValue, errors.Wrap(err, "failed to unmarshal config value")
+		}
+		if v, ok := newValue.(Validator); ok {
+			if err := v.Validate(); err != nil {
+				return lastValue, errors.Wrap(err, "invalid config value")
+			}
+		}
+
+		c.stateMu.Lock()
+		c.lastRaw = append([]byte(nil), override...)
+		c.lastValue = newValue
+		c.stateMu.Unlock()
+		return newValue, nil
+	default:
+		if reflect.TypeOf(override) != reflect.PtrTo(c.valueType) {
+			return lastValue, ErrUnsuportedConversion
+		}
+
+		newValue := override
+		c.stateMu.Lock()
+		c.lastRaw = nil
+		c.lastValue = newValue
+		c.stateMu.Unlock()
+		return newValue, nil
+	}
+}
+
+// Load is a wrapper for LoadSafe that ignores the returned error
+func (c *StructConfig) Load(ctx context.Context) interface{} {
+	val, _ := c.LoadSafe(ctx)
+	return val
+}
+
+// Shutdown signals the config to stop all underlying resources
+func (c *StructConfig) Shutdown() {
+	c.override.Shutdown()
+}
@@ -0,0 +1,102 @@
+package wrapper
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kinecosystem/agora-common/config"
+	"github.com/kinecosystem/agora-common/config/memory"
+)
+
+type testStruct struct {
+	Name  string `json:"name" yaml:"name"`
+	Limit int    `json:"limit" yaml:"limit"`
+}
+
+func (s *testStruct) Validate() error {
+	if s.Limit < 0 {
+		return errors.New("limit must be non-negative")
+	}
+	return nil
+}
+
+func TestStructConfig(t *testing.T) {
+	defaultValue := &testStruct{Name: "default", Limit: 1}
+	mock := memory.NewConfig(nil)
+	wrapper := NewStructConfig(mock, defaultValue, nil)
+
+	// Return the default value when no override is set
+	val, err := wrapper.LoadSafe(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, defaultValue, val)
+	assert.Equal(t, defaultValue, wrapper.Load(context.Background()))
+
+	// Verify conversion from a byte array
+	mock.SetValue([]byte(`{"name": "override", "limit": 10}`))
+	val, err = wrapper.LoadSafe(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &testStruct{Name: "override", Limit: 10}, val)
+
+	// The same snapshot is returned while the raw value is unchanged
+	assert.True(t, val == wrapper.Load(context.Background()))
+
+	// Invalid values are rejected, and the last value is returned
+	mock.SetValue([]byte(`{"name": "invalid", "limit": -1}`))
+	val, err = wrapper.LoadSafe(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, &testStruct{Name: "override", Limit: 10}, val)
+
+	mock.SetValue([]byte(`{"name": `))
+	val, err = wrapper.LoadSafe(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, &testStruct{Name: "override", Limit: 10}, val)
+
+	// The last observed config value is returned on error
+	mock.InduceErrors()
+	val, err = wrapper.LoadSafe(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, &testStruct{Name: "override", Limit: 10}, val)
+
+	// The default value is returned when the override no longer has a value
+	mock.StopInducingErrors()
+	mock.ClearValue()
+	val, err = wrapper.LoadSafe(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, defaultValue, val)
+
+	// Values of the struct type are returned as is
+	expected := &testStruct{Name: "struct"}
+	mock.SetValue(expected)
+	val, err = wrapper.LoadSafe(context.Background())
+	require.NoError(t, err)
+	assert.True(t, expected == val)
+
+	// Return an unsupported source value type
+	mock.SetValue(testStruct{})
+	_, err = wrapper.LoadSafe(context.Background())
+	assert.Equal(t, err, ErrUnsuportedConversion)
+
+	// Shutdown via the wrapper
+	wrapper.Shutdown()
+	_, err = wrapper.LoadSafe(context.Background())
+	assert.Equal(t, config.ErrShutdown, err)
+}
+
+func TestStructConfig_YAML(t *testing.T) {
+	mock := memory.NewConfig([]byte("name: yaml\nlimit: 5\n"))
+	wrapper := NewStructConfig(mock, &testStruct{}, YAMLUnmarshal)
+
+	val, err := wrapper.LoadSafe(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &testStruct{Name: "yaml", Limit: 5}, val)
+}
+
+func TestStructConfig_InvalidDefault(t *testing.T) {
+	for _, v := range []interface{}{nil, testStruct{}, (*testStruct)(nil), new(int)} {
+		assert.Panics(t, func() { NewStructConfig(config.NoopConfig, v, nil) })
+	}
+}
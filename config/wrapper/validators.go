@@ -0,0 +1,154 @@
+package wrapper
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/kinecosystem/agora-common/metrics"
+)
+
+var invalidValueCounterVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "config",
+	Name:      "invalid_values_total",
+	Help:      "Number of config values rejected by a validator, and replaced with the default value",
+}, []string{"type"})
+
+func init() {
+	invalidValueCounterVec = metrics.Register(invalidValueCounterVec).(*prometheus.CounterVec)
+}
+
+// Int64Validator validates an int64 config value.
+type Int64Validator func(val int64) error
+
+// Uint64Validator validates a uint64 config value.
+type Uint64Validator func(val uint64) error
+
+// Float64Validator validates a float64 config value.
+type Float64Validator func(val float64) error
+
+// DurationValidator validates a time.Duration config value.
+type DurationValidator func(val time.Duration) error
+
+// StringValidator validates a string config value.
+type StringValidator func(val string) error
+
+// Int64Range returns an Int64Validator that requires values to be within
+// [min, max].
+func Int64Range(min, max int64) Int64Validator {
+	return func(val int64) error {
+		if val < min || val > max {
+			return errors.Errorf("value %d not in range [%d, %d]", val, min, max)
+		}
+		return nil
+	}
+}
+
+// Uint64Range returns a Uint64Validator that requires values to be within
+// [min, max].
+func Uint64Range(min, max uint64) Uint64Validator {
+	return func(val uint64) error {
+		if val < min || val > max {
+			return errors.Errorf("value %d not in range [%d, %d]", val, min, max)
+		}
+		return nil
+	}
+}
+
+// Float64Range returns a Float64Validator that requires values to be within
+// [min, max].
+func Float64Range(min, max float64) Float64Validator {
+	return func(val float64) error {
+		if val < min || val > max {
+			return errors.Errorf("value %v not in range [%v, %v]", val, min, max)
+		}
+		return nil
+	}
+}
+
+// DurationRange returns a DurationValidator that requires values to be within
+// [min, max].
+func DurationRange(min, max time.Duration) DurationValidator {
+	return func(val time.Duration) error {
+		if val < min || val > max {
+			return errors.Errorf("value %v not in range [%v, %v]", val, min, max)
+		}
+		return nil
+	}
+}
+
+// StringOneOf returns a StringValidator that requires values to be one of the
+// provided values.
+func StringOneOf(values ...string) StringValidator {
+	return func(val string) error {
+		for _, v := range values {
+			if val == v {
+				return nil
+			}
+		}
+		return errors.Errorf("value %q not one of %q", val, values)
+	}
+}
+
+// StringMatches returns a StringValidator that requires values to match the
+// provided regular expression.
+func StringMatches(re *regexp.Regexp) StringValidator {
+	return func(val string) error {
+		if !re.MatchString(val) {
+			return errors.Errorf("value %q does not match %s", val, re)
+		}
+		return nil
+	}
+}
+
+func validateInt64(val int64, validators []Int64Validator) error {
+	for _, v := range validators {
+		if err := v(val); err != nil {
+			invalidValueCounterVec.WithLabelValues("int64").Inc()
+			return errors.Wrap(err, "invalid config value")
+		}
+	}
+	return nil
+}
+
+func validateUint64(val uint64, validators []Uint64Validator) error {
+	for _, v := range validators {
+		if err := v(val); err != nil {
+			invalidValueCounterVec.WithLabelValues("uint64").Inc()
+			return errors.Wrap(err, "invalid config value")
+		}
+	}
+	return nil
+}
+
+func validateFloat64(val float64, validators []Float64Validator) error {
+	for _, v := range validators {
+		if err := v(val); err != nil {
+			invalidValueCounterVec.WithLabelValues("float64").Inc()
+			return errors.Wrap(err, "invalid config value")
+		}
+	}
+	return nil
+}
+
+func validateDuration(val time.Duration, validators []DurationValidator) error {
+	for _, v := range validators {
+		if err := v(val); err != nil {
+			invalidValueCounterVec.WithLabelValues("duration").Inc()
+			return errors.Wrap(err, "invalid config value")
+		}
+	}
+	return nil
+}
+
+func validateString(val string, validators []StringValidator) error {
+	for _, v := range validators {
+		if err := v(val); err != nil {
+			invalidValueCounterVec.WithLabelValues("string").Inc()
+			return errors.Wrap(err, "invalid config value")
+		}
+	}
+	return nil
+}
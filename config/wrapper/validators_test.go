@@ -0,0 +1,70 @@
+package wrapper
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kinecosystem/agora-common/config/memory"
+)
+
+func TestValidators(t *testing.T) {
+	assert.NoError(t, Int64Range(-1, 1)(-1))
+	assert.NoError(t, Int64Range(-1, 1)(1))
+	assert.Error(t, Int64Range(-1, 1)(2))
+
+	assert.NoError(t, Uint64Range(1, 2)(2))
+	assert.Error(t, Uint64Range(1, 2)(0))
+
+	assert.NoError(t, Float64Range(0, 1)(0.5))
+	assert.Error(t, Float64Range(0, 1)(1.5))
+
+	assert.NoError(t, DurationRange(time.Second, time.Minute)(time.Second))
+	assert.Error(t, DurationRange(time.Second, time.Minute)(time.Hour))
+
+	assert.NoError(t, StringOneOf("a", "b")("b"))
+	assert.Error(t, StringOneOf("a", "b")("c"))
+
+	assert.NoError(t, StringMatches(regexp.MustCompile(`^[a-z]+$`))("abc"))
+	assert.Error(t, StringMatches(regexp.MustCompile(`^[a-z]+$`))("ABC"))
+}
+
+func TestInt64Config_Validators(t *testing.T) {
+	mock := memory.NewConfig([]byte("5"))
+	wrapper := NewInt64Config(mock, 1, Int64Range(0, 10))
+
+	val, err := wrapper.GetSafe(context.Background())
+	require.NoError(t, err)
+	assert.EqualValues(t, 5, val)
+
+	// Invalid values fall back to the default value
+	invalid := promtestutil.ToFloat64(invalidValueCounterVec.WithLabelValues("int64"))
+	mock.SetValue([]byte("11"))
+	val, err = wrapper.GetSafe(context.Background())
+	assert.Error(t, err)
+	assert.EqualValues(t, 1, val)
+	assert.EqualValues(t, 1, wrapper.Get(context.Background()))
+	assert.Equal(t, invalid+2, promtestutil.ToFloat64(invalidValueCounterVec.WithLabelValues("int64")))
+
+	mock.SetValue(int64(10))
+	val, err = wrapper.GetSafe(context.Background())
+	require.NoError(t, err)
+	assert.EqualValues(t, 10, val)
+}
+
+func TestStringConfig_Validators(t *testing.T) {
+	mock := memory.NewConfig([]byte("info"))
+	wrapper := NewStringConfig(mock, "warn", StringOneOf("debug", "info", "warn"))
+
+	assert.Equal(t, "info", wrapper.Get(context.Background()))
+
+	mock.SetValue("verbose")
+	val, err := wrapper.GetSafe(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, "warn", val)
+}
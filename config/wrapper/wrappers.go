@@ -140,17 +140,22 @@ func (c *BoolConfig) Shutdown() {
 type Int64Config struct {
 	override     config.Config
 	defaultValue int64
+	validators   []Int64Validator
 
 	stateMu   sync.RWMutex
 	lastValue int64
 }
 
 // NewInt64Config returns a new int64 config utility wrapper
-func NewInt64Config(override config.Config, defaultValue int64) config.Int64 {
+//
+// Values rejected by any of the provided validators are replaced with the
+// default value.
+func NewInt64Config(override config.Config, defaultValue int64, validators ...Int64Validator) config.Int64 {
 	return &Int64Config{
 		override:     override,
 		defaultValue: defaultValue,
 		lastValue:    defaultValue,
+		validators:   validators,
 	}
 }
 
@@ -169,31 +174,30 @@ func (c *Int64Config) GetSafe(ctx context.Context) (int64, error) {
 	} else if err != nil {
 		return lastValue, err
 	}
+	var newValue int64
 	switch override := override.(type) {
 	case []byte:
-		newValue, err := strconv.ParseInt(string(override), 10, 64)
+		newValue, err = strconv.ParseInt(string(override), 10, 64)
 		if err != nil {
 			return lastValue, err
 		}
-		c.stateMu.Lock()
-		c.lastValue = newValue
-		c.stateMu.Unlock()
-		return newValue, nil
 	case int64:
-		newValue := override
-		c.stateMu.Lock()
-		c.lastValue = newValue
-		c.stateMu.Unlock()
-		return newValue, nil
+		newValue = override
 	case int:
-		newValue := int64(override)
-		c.stateMu.Lock()
-		c.lastValue = newValue
-		c.stateMu.Unlock()
-		return newValue, nil
+		newValue = int64(override)
 	default:
 		return lastValue, ErrUnsuportedConversion
 	}
+	if err := validateInt64(newValue, c.validators); err != nil {
+		c.stateMu.Lock()
+		c.lastValue = c.defaultValue
+		c.stateMu.Unlock()
+		return c.defaultValue, err
+	}
+	c.stateMu.Lock()
+	c.lastValue = newValue
+	c.stateMu.Unlock()
+	return newValue, nil
 }
 
 // Get is a wrapper for GetSafe that ignores the returned error
@@ -211,17 +215,22 @@ func (c *Int64Config) Shutdown() {
 type Uint64Config struct {
 	override     config.Config
 	defaultValue uint64
+	validators   []Uint64Validator
 
 	stateMu   sync.RWMutex
 	lastValue uint64
 }
 
 // NewUint64Config returns a new uint64 config utility wrapper
-func NewUint64Config(override config.Config, defaultValue uint64) config.Uint64 {
+//
+// Values rejected by any of the provided validators are replaced with the
+// default value.
+func NewUint64Config(override config.Config, defaultValue uint64, validators ...Uint64Validator) config.Uint64 {
 	return &Uint64Config{
 		override:     override,
 		defaultValue: defaultValue,
 		lastValue:    defaultValue,
+		validators:   validators,
 	}
 }
 
@@ -240,31 +249,30 @@ func (c *Uint64Config) GetSafe(ctx context.Context) (uint64, error) {
 	} else if err != nil {
 		return lastValue, err
 	}
+	var newValue uint64
 	switch override := override.(type) {
 	case []byte:
-		newValue, err := strconv.ParseUint(string(override), 10, 64)
+		newValue, err = strconv.ParseUint(string(override), 10, 64)
 		if err != nil {
 			return lastValue, err
 		}
-		c.stateMu.Lock()
-		c.lastValue = newValue
-		c.stateMu.Unlock()
-		return newValue, nil
 	case uint64:
-		newValue := override
-		c.stateMu.Lock()
-		c.lastValue = newValue
-		c.stateMu.Unlock()
-		return newValue, nil
+		newValue = override
 	case uint:
-		newValue := uint64(override)
-		c.stateMu.Lock()
-		c.lastValue = newValue
-		c.stateMu.Unlock()
-		return newValue, nil
+		newValue = uint64(override)
 	default:
 		return lastValue, ErrUnsuportedConversion
 	}
+	if err := validateUint64(newValue, c.validators); err != nil {
+		c.stateMu.Lock()
+		c.lastValue = c.defaultValue
+		c.stateMu.Unlock()
+		return c.defaultValue, err
+	}
+	c.stateMu.Lock()
+	c.lastValue = newValue
+	c.stateMu.Unlock()
+	return newValue, nil
 }
 
 // Get is a wrapper for GetSafe that ignores the returned error
@@ -282,17 +290,22 @@ func (c *Uint64Config) Shutdown() {
 type Float64Config struct {
 	override     config.Config
 	defaultValue float64
+	validators   []Float64Validator
 
 	stateMu   sync.RWMutex
 	lastValue float64
 }
 
 // NewFloat64Config returns a new float64 config utility wrapper
-func NewFloat64Config(override config.Config, defaultValue float64) config.Float64 {
+//
+// Values rejected by any of the provided validators are replaced with the
+// default value.
+func NewFloat64Config(override config.Config, defaultValue float64, validators ...Float64Validator) config.Float64 {
 	return &Float64Config{
 		override:     override,
 		defaultValue: defaultValue,
 		lastValue:    defaultValue,
+		validators:   validators,
 	}
 }
 
@@ -311,25 +324,28 @@ func (c *Float64Config) GetSafe(ctx context.Context) (float64, error) {
 	} else if err != nil {
 		return lastValue, err
 	}
+	var newValue float64
 	switch override := override.(type) {
 	case []byte:
-		newValue, err := strconv.ParseFloat(string(override), 64)
+		newValue, err = strconv.ParseFloat(string(override), 64)
 		if err != nil {
 			return lastValue, err
 		}
-		c.stateMu.Lock()
-		c.lastValue = newValue
-		c.stateMu.Unlock()
-		return newValue, nil
 	case float64:
-		newValue := override
-		c.stateMu.Lock()
-		c.lastValue = newValue
-		c.stateMu.Unlock()
-		return newValue, nil
+		newValue = override
 	default:
 		return lastValue, ErrUnsuportedConversion
 	}
+	if err := validateFloat64(newValue, c.validators); err != nil {
+		c.stateMu.Lock()
+		c.lastValue = c.defaultValue
+		c.stateMu.Unlock()
+		return c.defaultValue, err
+	}
+	c.stateMu.Lock()
+	c.lastValue = newValue
+	c.stateMu.Unlock()
+	return newValue, nil
 }
 
 // Get is a wrapper for GetSafe that ignores the returned error
@@ -347,17 +363,22 @@ func (c *Float64Config) Shutdown() {
 type DurationConfig struct {
 	override     config.Config
 	defaultValue time.Duration
+	validators   []DurationValidator
 
 	stateMu   sync.RWMutex
 	lastValue time.Duration
 }
 
 // NewDurationConfig returns a new duration config utility wrapper
-func NewDurationConfig(override config.Config, defaultValue time.Duration) config.Duration {
+//
+// Values rejected by any of the provided validators are replaced with the
+// default value.
+func NewDurationConfig(override config.Config, defaultValue time.Duration, validators ...DurationValidator) config.Duration {
 	return &DurationConfig{
 		override:     override,
 		defaultValue: defaultValue,
 		lastValue:    defaultValue,
+		validators:   validators,
 	}
 }
 
@@ -376,33 +397,28 @@ func (c *DurationConfig) GetSafe(ctx context.Context) (time.Duration, error) {
 	} else if err != nil {
 		return lastValue, err
 	}
+	var newValue time.Duration
 	switch override := override.(type) {
 	case []byte:
-		var newValue time.Duration
-		strValue := string(override)
-
-		if timeutil.IsISO8601(strValue) {
-			newValue, err = timeutil.ParseISO8601(strValue)
-		} else {
-			newValue, err = time.ParseDuration(strValue)
-		}
-
+		newValue, err = timeutil.ParseDuration(string(override))
 		if err != nil {
 			return lastValue, err
 		}
-		c.stateMu.Lock()
-		c.lastValue = newValue
-		c.stateMu.Unlock()
-		return newValue, nil
 	case time.Duration:
-		newValue := override
-		c.stateMu.Lock()
-		c.lastValue = newValue
-		c.stateMu.Unlock()
-		return newValue, nil
+		newValue = override
 	default:
 		return lastValue, ErrUnsuportedConversion
 	}
+	if err := validateDuration(newValue, c.validators); err != nil {
+		c.stateMu.Lock()
+		c.lastValue = c.defaultValue
+		c.stateMu.Unlock()
+		return c.defaultValue, err
+	}
+	c.stateMu.Lock()
+	c.lastValue = newValue
+	c.stateMu.Unlock()
+	return newValue, nil
 }
 
 // Get is a wrapper for GetSafe that ignores the returned error
@@ -420,17 +436,22 @@ func (c *DurationConfig) Shutdown() {
 type StringConfig struct {
 	config       config.Config
 	defaultValue string
+	validators   []StringValidator
 
 	stateMu   sync.RWMutex
 	lastValue string
 }
 
 // NewStringConfig returns a new duration string utility wrapper
-func NewStringConfig(config config.Config, defaultValue string) config.String {
+//
+// Values rejected by any of the provided validators are replaced with the
+// default value.
+func NewStringConfig(config config.Config, defaultValue string, validators ...StringValidator) config.String {
 	return &StringConfig{
 		config:       config,
 		defaultValue: defaultValue,
 		lastValue:    defaultValue,
+		validators:   validators,
 	}
 }
 
@@ -449,22 +470,25 @@ func (c *StringConfig) GetSafe(ctx context.Context) (string, error) {
 	} else if err != nil {
 		return lastValue, err
 	}
+	var newValue string
 	switch override := override.(type) {
 	case []byte:
-		newValue := string(override)
-		c.stateMu.Lock()
-		c.lastValue = newValue
-		c.stateMu.Unlock()
-		return newValue, nil
+		newValue = string(override)
 	case string:
-		newValue := override
-		c.stateMu.Lock()
-		c.lastValue = newValue
-		c.stateMu.Unlock()
-		return newValue, nil
+		newValue = override
 	default:
 		return lastValue, ErrUnsuportedConversion
 	}
+	if err := validateString(newValue, c.validators); err != nil {
+		c.stateMu.Lock()
+		c.lastValue = c.defaultValue
+		c.stateMu.Unlock()
+		return c.defaultValue, err
+	}
+	c.stateMu.Lock()
+	c.lastValue = newValue
+	c.stateMu.Unlock()
+	return newValue, nil
 }
 
 // Get is a wrapper for GetSafe that ignores the returned error
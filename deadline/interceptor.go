@@ -0,0 +1,96 @@
+// Package deadline provides gRPC server interceptors that bound the deadlines
+// of incoming requests.
+package deadline
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type options struct {
+	defaultTimeout time.Duration
+	maxTimeout     time.Duration
+}
+
+// Option configures the interceptors.
+type Option func(o *options)
+
+// WithDefaultTimeout configures the timeout applied to unary requests that
+// do not have a deadline. A timeout of 0 leaves such requests unbounded.
+func WithDefaultTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.defaultTimeout = timeout
+	}
+}
+
+// WithMaxTimeout configures the maximum remaining time of a request's
+// deadline. Requests with a later deadline are rejected with
+// codes.InvalidArgument. A timeout of 0 disables the limit.
+func WithMaxTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.maxTimeout = timeout
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// check returns an error if the deadline of the context exceeds the maximum
+// timeout.
+func (o *options) check(ctx context.Context) error {
+	if o.maxTimeout <= 0 {
+		return nil
+	}
+
+	deadline, ok := ctx.Deadline()
+	if ok && time.Until(deadline) > o.maxTimeout {
+		return status.Errorf(codes.InvalidArgument, "deadline exceeds the maximum timeout of %v", o.maxTimeout)
+	}
+	return nil
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that applies the
+// default timeout to requests without a deadline, and rejects requests whose
+// deadline exceeds the maximum timeout.
+func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
+	o := newOptions(opts)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := o.check(ctx); err != nil {
+			return nil, err
+		}
+
+		if _, ok := ctx.Deadline(); !ok && o.defaultTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, o.defaultTimeout)
+			defer cancel()
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that rejects
+// streams whose deadline exceeds the maximum timeout.
+//
+// The default timeout is not applied to streams, since they are often
+// intentionally long lived.
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	o := newOptions(opts)
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := o.check(ss.Context()); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,100 @@
+package deadline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor(
+		WithDefaultTimeout(time.Second),
+		WithMaxTimeout(time.Minute),
+	)
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+
+	var remaining time.Duration
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		deadline, ok := ctx.Deadline()
+		require.True(t, ok)
+		remaining = time.Until(deadline)
+		return "resp", nil
+	}
+
+	// Requests without a deadline have the default timeout applied.
+	resp, err := interceptor(context.Background(), "req", info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "resp", resp)
+	assert.True(t, remaining <= time.Second && remaining > 0)
+
+	// Requests with a deadline within the maximum are left as is.
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	_, err = interceptor(ctx, "req", info, handler)
+	require.NoError(t, err)
+	assert.True(t, remaining > time.Second)
+
+	// Requests with a deadline beyond the maximum are rejected.
+	ctx, cancel = context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	_, err = interceptor(ctx, "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		t.Fatal("handler should not be called")
+		return nil, nil
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestUnaryServerInterceptor_Unbounded(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 24*time.Hour)
+	defer cancel()
+
+	for _, ctx := range []context.Context{context.Background(), ctx} {
+		_, err := interceptor(ctx, "req", info, func(handlerCtx context.Context, req interface{}) (interface{}, error) {
+			assert.Equal(t, ctx, handlerCtx)
+			return nil, nil
+		})
+		assert.NoError(t, err)
+	}
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	interceptor := StreamServerInterceptor(
+		WithDefaultTimeout(time.Second),
+		WithMaxTimeout(time.Minute),
+	)
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Stream"}
+
+	// The default timeout is not applied to streams.
+	err := interceptor(nil, &testServerStream{ctx: context.Background()}, info, func(srv interface{}, ss grpc.ServerStream) error {
+		_, ok := ss.Context().Deadline()
+		assert.False(t, ok)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	err = interceptor(nil, &testServerStream{ctx: ctx}, info, func(srv interface{}, ss grpc.ServerStream) error {
+		t.Fatal("handler should not be called")
+		return nil
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,158 @@
+// Package flags provides feature flags backed by config.Config sources.
+//
+// Each flag is defined by a config value, which is either a boolean (e.g.
+// "true"), or a JSON encoded Definition supporting percentage rollouts and
+// per-key overrides:
+//
+//	{"percentage": 25, "overrides": {"app-1": true, "app-2": false}}
+//
+// Flags are evaluated for a key, such as an app index or account, so that
+// percentage rollouts consistently enable a flag for the same keys.
+package flags
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/kinecosystem/agora-common/config"
+	"github.com/kinecosystem/agora-common/config/wrapper"
+)
+
+// rolloutBuckets is the number of buckets keys are hashed into for percentage
+// rollouts, allowing for a precision of 0.01%.
+const rolloutBuckets = 10000
+
+// Definition defines the state of a flag.
+type Definition struct {
+	// Enabled enables the flag for all keys, unless overridden.
+	Enabled bool `json:"enabled"`
+
+	// Percentage is the percentage of keys, in [0, 100], for which the flag is
+	// enabled, unless overridden.
+	Percentage float64 `json:"percentage"`
+
+	// Overrides explicitly enables or disables the flag for specific keys.
+	Overrides map[string]bool `json:"overrides"`
+}
+
+// Validate implements wrapper.Validator.
+func (d *Definition) Validate() error {
+	if d.Percentage < 0 || d.Percentage > 100 {
+		return errors.Errorf("percentage %v not in range [0, 100]", d.Percentage)
+	}
+	return nil
+}
+
+// Evaluate returns whether or not the named flag is enabled for the provided
+// key.
+func (d *Definition) Evaluate(name, key string) bool {
+	if enabled, ok := d.Overrides[key]; ok {
+		return enabled
+	}
+	if d.Enabled {
+		return true
+	}
+	if d.Percentage <= 0 {
+		return false
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(key))
+	return float64(h.Sum32()%rolloutBuckets) < d.Percentage*rolloutBuckets/100
+}
+
+// Flags is a set of named feature flags.
+type Flags struct {
+	flags map[string]config.Struct
+}
+
+// New returns a set of flags, each backed by the config.Config of the same
+// name. Flags without a value set are disabled.
+func New(sources map[string]config.Config) *Flags {
+	f := &Flags{
+		flags: make(map[string]config.Struct, len(sources)),
+	}
+	for name, source := range sources {
+		f.flags[name] = wrapper.NewStructConfig(source, &Definition{}, unmarshalDefinition)
+	}
+
+	return f
+}
+
+// Enabled returns whether or not the named flag is enabled for the provided
+// key. If the context contains a Snapshot, the flag is evaluated against it.
+//
+// Unknown flags are disabled.
+func (f *Flags) Enabled(ctx context.Context, name, key string) bool {
+	if s, ok := ctx.Value(snapshotKey{}).(*Snapshot); ok {
+		return s.Enabled(name, key)
+	}
+
+	c, ok := f.flags[name]
+	if !ok {
+		return false
+	}
+	return c.Load(ctx).(*Definition).Evaluate(name, key)
+}
+
+// Snapshot returns a snapshot of the current definitions of all flags.
+func (f *Flags) Snapshot(ctx context.Context) *Snapshot {
+	s := &Snapshot{
+		definitions: make(map[string]*Definition, len(f.flags)),
+	}
+	for name, c := range f.flags {
+		s.definitions[name] = c.Load(ctx).(*Definition)
+	}
+
+	return s
+}
+
+// Shutdown shuts down the configs backing the flags.
+func (f *Flags) Shutdown() {
+	for _, c := range f.flags {
+		c.Shutdown()
+	}
+}
+
+// Snapshot is an immutable snapshot of flag definitions, which can be used to
+// consistently evaluate flags over the course of a request.
+type Snapshot struct {
+	definitions map[string]*Definition
+}
+
+// Enabled returns whether or not the named flag is enabled for the provided
+// key. Unknown flags are disabled.
+func (s *Snapshot) Enabled(name, key string) bool {
+	d, ok := s.definitions[name]
+	if !ok {
+		return false
+	}
+	return d.Evaluate(name, key)
+}
+
+type snapshotKey struct{}
+
+// ContextWithSnapshot returns a context containing the provided snapshot, which
+// is used by Flags.Enabled to evaluate flags.
+func ContextWithSnapshot(ctx context.Context, s *Snapshot) context.Context {
+	return context.WithValue(ctx, snapshotKey{}, s)
+}
+
+func unmarshalDefinition(data []byte, v interface{}) error {
+	d := v.(*Definition)
+
+	data = bytes.TrimSpace(data)
+	if enabled, err := strconv.ParseBool(string(data)); err == nil {
+		d.Enabled = enabled
+		return nil
+	}
+
+	return json.Unmarshal(data, d)
+}
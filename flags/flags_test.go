@@ -0,0 +1,91 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kinecosystem/agora-common/config"
+	"github.com/kinecosystem/agora-common/config/memory"
+)
+
+func TestFlags(t *testing.T) {
+	boolFlag := memory.NewConfig([]byte("true"))
+	rollout := memory.NewConfig([]byte(`{"percentage": 50, "overrides": {"app-1": true, "app-2": false}}`))
+	unset := memory.NewConfig(nil)
+
+	f := New(map[string]config.Config{
+		"bool":    boolFlag,
+		"rollout": rollout,
+		"unset":   unset,
+	})
+	defer f.Shutdown()
+
+	ctx := context.Background()
+
+	assert.True(t, f.Enabled(ctx, "bool", "app-1"))
+	assert.False(t, f.Enabled(ctx, "unset", "app-1"))
+	assert.False(t, f.Enabled(ctx, "unknown", "app-1"))
+
+	// Overrides take precedence over rollouts.
+	assert.True(t, f.Enabled(ctx, "rollout", "app-1"))
+	assert.False(t, f.Enabled(ctx, "rollout", "app-2"))
+
+	// Roughly half of the keys should be enabled, consistently.
+	var enabled int
+	for i := 0; i < 10000; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		if f.Enabled(ctx, "rollout", key) {
+			enabled++
+		}
+		assert.Equal(t, f.Enabled(ctx, "rollout", key), f.Enabled(ctx, "rollout", key))
+	}
+	assert.InDelta(t, 5000, enabled, 300)
+
+	// Invalid definitions are rejected, retaining the last definition.
+	rollout.SetValue([]byte(`{"percentage": 101}`))
+	assert.True(t, f.Enabled(ctx, "rollout", "app-1"))
+
+	boolFlag.SetValue([]byte("false"))
+	assert.False(t, f.Enabled(ctx, "bool", "app-1"))
+}
+
+func TestFlags_Snapshot(t *testing.T) {
+	c := memory.NewConfig([]byte("true"))
+	f := New(map[string]config.Config{"flag": c})
+
+	ctx := ContextWithSnapshot(context.Background(), f.Snapshot(context.Background()))
+	assert.True(t, f.Enabled(ctx, "flag", "key"))
+
+	// Changes are not observed within the snapshot.
+	c.SetValue([]byte("false"))
+	assert.True(t, f.Enabled(ctx, "flag", "key"))
+	assert.False(t, f.Enabled(context.Background(), "flag", "key"))
+	assert.False(t, f.Enabled(ctx, "unknown", "key"))
+}
+
+func TestDefinition(t *testing.T) {
+	d := &Definition{Percentage: 0}
+	assert.False(t, d.Evaluate("flag", "key"))
+
+	d = &Definition{Percentage: 100}
+	for i := 0; i < 100; i++ {
+		assert.True(t, d.Evaluate("flag", fmt.Sprintf("key-%d", i)))
+	}
+
+	// Rollouts are independent across flags.
+	d = &Definition{Percentage: 50}
+	var same int
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		if d.Evaluate("a", key) == d.Evaluate("b", key) {
+			same++
+		}
+	}
+	assert.InDelta(t, 500, same, 100)
+
+	assert.Error(t, (&Definition{Percentage: -1}).Validate())
+	assert.NoError(t, (&Definition{Percentage: 100}).Validate())
+}
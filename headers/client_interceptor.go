@@ -32,6 +32,21 @@ func StreamClientInterceptor() grpc.StreamClientInterceptor {
 // setAllHeaders Take all the headers currently in the context, except for the incoming Type,
 // and put them into the metadata to be passed on to the next service
 func setAllHeaders(ctx context.Context, log *logrus.Entry) context.Context {
+	for k, v := range outgoingHeaders(ctx, log) {
+		if val, ok := v.([]byte); ok {
+			ctx = metadata.AppendToOutgoingContext(ctx, k, string(val))
+		} else if val, ok := v.(string); ok {
+			ctx = metadata.AppendToOutgoingContext(ctx, k, val)
+		} else {
+			log.WithField("name", k).Warnf("Unexpected header value type: %T", v)
+		}
+	}
+
+	return ctx
+}
+
+// outgoingHeaders returns all the headers currently in the context, except for the incoming Type.
+func outgoingHeaders(ctx context.Context, log *logrus.Entry) Headers {
 	allHeader := Headers{}
 
 	if rootHeader, ok := (ctx).Value(rootHeaderKey).(Headers); ok {
@@ -51,19 +66,12 @@ func setAllHeaders(ctx context.Context, log *logrus.Entry) context.Context {
 		allHeader.merge(asciiHeader)
 	}
 
-	for k, v := range allHeader {
+	for k := range allHeader {
 		switch strings.ToLower(k) {
 		case "content-type", "user-agent", ":authority":
-			continue
-		}
-		if val, ok := v.([]byte); ok {
-			ctx = metadata.AppendToOutgoingContext(ctx, k, string(val))
-		} else if val, ok := v.(string); ok {
-			ctx = metadata.AppendToOutgoingContext(ctx, k, val)
-		} else {
-			log.WithField("name", k).Warnf("Unexpected header value type: %T", v)
+			delete(allHeader, k)
 		}
 	}
 
-	return ctx
+	return allHeader
 }
@@ -266,3 +266,30 @@ func ContextWithHeaders(ctx context.Context, headersSetters ...HeaderSetter) (co
 
 	return ctx, nil
 }
+
+// ExtractHeaders returns the headers in the context that would be sent on the next service
+// call, for propagation over transports other than gRPC (for example, task queues).
+//
+// The headers can be restored on the receiving side with ContextWithExtractedHeaders.
+func ExtractHeaders(ctx context.Context) map[string][]byte {
+	log := logrus.StandardLogger().WithField("type", "headers")
+
+	extracted := make(map[string][]byte)
+	for k, v := range outgoingHeaders(ctx, log) {
+		if val, ok := v.([]byte); ok {
+			extracted[k] = val
+		} else if val, ok := v.(string); ok {
+			extracted[k] = []byte(val)
+		} else {
+			log.WithField("name", k).Warnf("Unexpected header value type: %T", v)
+		}
+	}
+
+	return extracted
+}
+
+// ContextWithExtractedHeaders returns a new context containing headers returned by ExtractHeaders,
+// as they would have been received by a gRPC service from the previous service call.
+func ContextWithExtractedHeaders(ctx context.Context, extracted map[string][]byte) context.Context {
+	return contextWithIncomingHeaders(ctx, extracted)
+}
@@ -54,10 +54,7 @@ func (s *streamWrapper) Context() context.Context {
 // getAllHeaders retrieve all the headerPrefixes from the contexts metadata, and puts them into the
 // context to be easily accessible
 func getAllHeaders(ctx context.Context, log *logrus.Entry) context.Context {
-	var rootHeader = Headers{}
-	var propagatingHeader = Headers{}
-	var incomingHeader = Headers{}
-	var asciiHeader = Headers{}
+	values := make(map[string][]byte)
 
 	if md, ok := metadata.FromIncomingContext(ctx); ok {
 		for key := range md {
@@ -67,17 +64,32 @@ func getAllHeaders(ctx context.Context, log *logrus.Entry) context.Context {
 				continue
 			}
 
-			if strings.HasPrefix(key, Root.prefix()) {
-				rootHeader[key] = []byte(md[key][0])
-			} else if strings.HasPrefix(key, Propagating.prefix()) {
-				propagatingHeader[key] = []byte(md[key][0])
+			values[key] = []byte(md[key][0])
+		}
+	}
+
+	return contextWithIncomingHeaders(ctx, values)
+}
+
+// contextWithIncomingHeaders puts the provided header values into the context, as
+// they would be received from the previous service call.
+func contextWithIncomingHeaders(ctx context.Context, values map[string][]byte) context.Context {
+	var rootHeader = Headers{}
+	var propagatingHeader = Headers{}
+	var incomingHeader = Headers{}
+	var asciiHeader = Headers{}
+
+	for key, value := range values {
+		if strings.HasPrefix(key, Root.prefix()) {
+			rootHeader[key] = value
+		} else if strings.HasPrefix(key, Propagating.prefix()) {
+			propagatingHeader[key] = value
+		} else {
+			// Note root and propagating headers can only be binary. Only the default header has a chance to be an ascii header
+			if strings.HasSuffix(key, "-bin") {
+				incomingHeader[key] = value
 			} else {
-				// Note root and propagating headers can only be binary. Only the default header has a chance to be an ascii header
-				if strings.HasSuffix(key, "-bin") {
-					incomingHeader[key] = []byte(md[key][0])
-				} else {
-					asciiHeader[key] = md[key][0]
-				}
+				asciiHeader[key] = string(value)
 			}
 		}
 	}
@@ -103,34 +103,30 @@ func New(serv *grpc.Server, cc *grpc.ClientConn, opts ...MuxOption) *Mux {
 	return m
 }
 
-// ServeHTTP serves HTTP on the provided listener, forwarding requests
-// to the gRPC server.
-func (m *Mux) ServeHTTP(l net.Listener) error {
+// Handler returns an http.Handler that forwards requests to the gRPC server.
+func (m *Mux) Handler() http.Handler {
 	if m.corsEnabled {
 		corsHandler := handlers.CORS(
 			handlers.AllowedOrigins([]string{"*"}),
 			handlers.AllowedHeaders([]string{"Content-Type"}),
 			handlers.AllowedMethods([]string{"POST", "OPTIONS"}),
 		)
-		return http.Serve(l, corsHandler(m.router))
+		return corsHandler(m.router)
 	}
 
-	return http.Serve(l, m.router)
+	return m.router
+}
+
+// ServeHTTP serves HTTP on the provided listener, forwarding requests
+// to the gRPC server.
+func (m *Mux) ServeHTTP(l net.Listener) error {
+	return http.Serve(l, m.Handler())
 }
 
 // ListenAndServeHTTP listens on the specified address, and forwards requests
 // to the gRPC server.
 func (m *Mux) ListenAndServeHTTP(listenAddr string) error {
-	if m.corsEnabled {
-		corsHandler := handlers.CORS(
-			handlers.AllowedOrigins([]string{"*"}),
-			handlers.AllowedHeaders([]string{"Content-Type"}),
-			handlers.AllowedMethods([]string{"POST", "OPTIONS"}),
-		)
-		return http.ListenAndServe(listenAddr, corsHandler(m.router))
-	}
-
-	return http.ListenAndServe(listenAddr, m.router)
+	return http.ListenAndServe(listenAddr, m.Handler())
 }
 
 func (m *Mux) unaryHandler(fullMethod string) http.HandlerFunc {
@@ -0,0 +1,122 @@
+// Package airdrop provides a client for the airdrop service of the
+// Kin 4 test environment.
+package airdrop
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/tls"
+	"sync"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	airdroppb "github.com/kinecosystem/agora-api/genproto/airdrop/v4"
+	commonpb "github.com/kinecosystem/agora-api/genproto/common/v4"
+
+	"github.com/kinecosystem/agora-common/solana"
+)
+
+const (
+	// airdropEndpoint is the endpoint of the Agora service in the Kin 4 test environment.
+	airdropEndpoint = "api.agorainfra.dev:443"
+
+	// The minimum amount that can be requested from the airdrop service, in quarks.
+	minQuarks = 1
+
+	// The maximum amount that can be requested from the airdrop service, in quarks. Equivalent to 10000 kin.
+	maxQuarks = 1000000000
+)
+
+var (
+	// ErrInvalidAmount occurs when the amount for an airdrop request is out of bounds.
+	ErrInvalidAmount = errors.New("airdrop request quark amount must be in the range [1, 1000000000]")
+
+	// ErrAccountNotFound occurs when the account to be funded does not exist.
+	ErrAccountNotFound = errors.New("airdrop account not found")
+
+	// ErrInsufficientKin occurs when the airdrop service does not have enough kin to fund the request.
+	ErrInsufficientKin = errors.New("airdrop service has insufficient kin")
+)
+
+// Client requests airdrops from the Kin 4 test environment.
+type Client struct {
+	client airdroppb.AirdropClient
+}
+
+// NewClient returns a Client that uses the provided connection.
+func NewClient(cc *grpc.ClientConn) *Client {
+	return &Client{
+		client: airdroppb.NewAirdropClient(cc),
+	}
+}
+
+// FundAccount funds an existing token account with the requested amount, returning the
+// signature of the funding transaction.
+func (c *Client) FundAccount(ctx context.Context, account ed25519.PublicKey, quarkAmount uint64) (sig solana.Signature, err error) {
+	if quarkAmount < minQuarks || quarkAmount > maxQuarks {
+		return sig, ErrInvalidAmount
+	}
+
+	resp, err := c.client.RequestAirdrop(ctx, &airdroppb.RequestAirdropRequest{
+		AccountId: &commonpb.SolanaAccountId{
+			Value: account,
+		},
+		Quarks:     quarkAmount,
+		Commitment: commonpb.Commitment_SINGLE,
+	})
+	if err != nil {
+		return sig, errors.Wrap(err, "failed to request airdrop")
+	}
+
+	switch resp.Result {
+	case airdroppb.RequestAirdropResponse_OK:
+	case airdroppb.RequestAirdropResponse_NOT_FOUND:
+		return sig, ErrAccountNotFound
+	case airdroppb.RequestAirdropResponse_INSUFFICIENT_KIN:
+		return sig, ErrInsufficientKin
+	default:
+		return sig, errors.Errorf("unexpected result from airdrop service: %v", resp.Result)
+	}
+
+	if len(resp.Signature.GetValue()) != len(sig) {
+		return sig, errors.New("invalid signature in airdrop response")
+	}
+
+	copy(sig[:], resp.Signature.Value)
+	return sig, nil
+}
+
+var (
+	defaultMu     sync.Mutex
+	defaultClient *Client
+)
+
+// FundAccount funds an existing token account on the Kin 4 test environment with the
+// requested amount, using a shared connection to the Agora test service.
+func FundAccount(ctx context.Context, account ed25519.PublicKey, quarkAmount uint64) (sig solana.Signature, err error) {
+	c, err := getDefaultClient()
+	if err != nil {
+		return sig, err
+	}
+
+	return c.FundAccount(ctx, account, quarkAmount)
+}
+
+func getDefaultClient() (*Client, error) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	if defaultClient != nil {
+		return defaultClient, nil
+	}
+
+	cc, err := grpc.Dial(airdropEndpoint, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{})))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to dial airdrop service")
+	}
+
+	defaultClient = NewClient(cc)
+	return defaultClient, nil
+}
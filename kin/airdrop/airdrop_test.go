@@ -0,0 +1,80 @@
+package airdrop
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	airdroppb "github.com/kinecosystem/agora-api/genproto/airdrop/v4"
+	commonpb "github.com/kinecosystem/agora-api/genproto/common/v4"
+
+	"github.com/kinecosystem/agora-common/testutil"
+)
+
+type server struct {
+	airdroppb.UnimplementedAirdropServer
+
+	known    ed25519.PublicKey
+	balance  uint64
+	received []*airdroppb.RequestAirdropRequest
+}
+
+func (s *server) RequestAirdrop(_ context.Context, req *airdroppb.RequestAirdropRequest) (*airdroppb.RequestAirdropResponse, error) {
+	s.received = append(s.received, req)
+
+	if !bytes.Equal(req.AccountId.Value, s.known) {
+		return &airdroppb.RequestAirdropResponse{Result: airdroppb.RequestAirdropResponse_NOT_FOUND}, nil
+	}
+	if req.Quarks > s.balance {
+		return &airdroppb.RequestAirdropResponse{Result: airdroppb.RequestAirdropResponse_INSUFFICIENT_KIN}, nil
+	}
+
+	s.balance -= req.Quarks
+	return &airdroppb.RequestAirdropResponse{
+		Signature: &commonpb.TransactionSignature{Value: make([]byte, ed25519.SignatureSize)},
+	}, nil
+}
+
+func TestClient_FundAccount(t *testing.T) {
+	known, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	unknown, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	cc, serv, err := testutil.NewServer()
+	require.NoError(t, err)
+
+	s := &server{known: known, balance: 100}
+	serv.RegisterService(func(server *grpc.Server) {
+		airdroppb.RegisterAirdropServer(server, s)
+	})
+
+	stopFunc, err := serv.Serve()
+	require.NoError(t, err)
+	defer stopFunc()
+
+	c := NewClient(cc)
+
+	_, err = c.FundAccount(context.Background(), known, 10)
+	require.NoError(t, err)
+	require.Len(t, s.received, 1)
+	assert.EqualValues(t, 10, s.received[0].Quarks)
+	assert.Equal(t, commonpb.Commitment_SINGLE, s.received[0].Commitment)
+
+	_, err = c.FundAccount(context.Background(), unknown, 10)
+	assert.Equal(t, ErrAccountNotFound, err)
+
+	_, err = c.FundAccount(context.Background(), known, 100)
+	assert.Equal(t, ErrInsufficientKin, err)
+
+	for _, amount := range []uint64{0, maxQuarks + 1} {
+		_, err = c.FundAccount(context.Background(), known, amount)
+		assert.Equal(t, ErrInvalidAmount, err)
+	}
+	assert.Len(t, s.received, 3)
+}
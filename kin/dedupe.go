@@ -0,0 +1,98 @@
+package kin
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	commonpb "github.com/kinecosystem/agora-api/genproto/common/v3"
+
+	"github.com/kinecosystem/agora-common/solana"
+)
+
+// dedupeMarker is the value of the last foreign key byte for memos that
+// contain a DedupeID. Invoice referencing memos always have a last foreign
+// key byte of 0, so the two cannot be confused.
+const dedupeMarker = 0x1
+
+// DedupeID is a stable identifier for a kin submission, used to detect
+// replays of the same logical submission.
+type DedupeID [sha256.Size224]byte
+
+// NewDedupeID derives a DedupeID from a (transaction, invoice list, app index) tuple.
+//
+// The ID is stable across retries of the same submission. That is, it does not
+// depend on the signatures, recent blockhash, or memo instructions of the transaction,
+// all of which may change when a submission is retried (or when the ID itself is
+// embedded in the memo).
+func NewDedupeID(txn solana.Transaction, il *commonpb.InvoiceList, appIndex uint16) (id DedupeID, err error) {
+	if len(txn.Message.Accounts) == 0 {
+		return id, errors.New("transaction has no accounts")
+	}
+
+	h := sha256.New224()
+
+	var idx [2]byte
+	binary.BigEndian.PutUint16(idx[:], appIndex)
+	h.Write(idx[:])
+
+	if il != nil {
+		raw, err := proto.Marshal(il)
+		if err != nil {
+			return id, errors.Wrap(err, "failed to marshal invoice list")
+		}
+		h.Write(raw)
+	}
+
+	// Fee payer
+	h.Write(txn.Message.Accounts[0])
+
+	for i, instruction := range txn.Message.Instructions {
+		if int(instruction.ProgramIndex) >= len(txn.Message.Accounts) {
+			return id, errors.Errorf("invalid program index at %d", i)
+		}
+		if isMemo(&txn, i) {
+			continue
+		}
+
+		h.Write(txn.Message.Accounts[instruction.ProgramIndex])
+		for _, a := range instruction.Accounts {
+			if int(a) >= len(txn.Message.Accounts) {
+				return id, errors.Errorf("invalid account index at %d", i)
+			}
+			h.Write(txn.Message.Accounts[a])
+		}
+
+		var dataLen [4]byte
+		binary.BigEndian.PutUint32(dataLen[:], uint32(len(instruction.Data)))
+		h.Write(dataLen[:])
+		h.Write(instruction.Data)
+	}
+
+	copy(id[:], h.Sum(nil))
+	return id, nil
+}
+
+// NewDedupeMemo returns a Memo whose foreign key embeds the provided DedupeID.
+func NewDedupeMemo(t TransactionType, appIndex uint16, id DedupeID) (Memo, error) {
+	fk := make([]byte, 29)
+	copy(fk, id[:])
+	fk[28] = dedupeMarker
+
+	return NewMemo(1, t, appIndex, fk)
+}
+
+// DedupeIDFromMemo returns the DedupeID embedded in the memo's foreign key.
+//
+// If the memo does not contain a DedupeID, ok will be false.
+func DedupeIDFromMemo(m Memo) (id DedupeID, ok bool) {
+	fk := m.ForeignKey()
+	if fk[28] != dedupeMarker {
+		return id, false
+	}
+
+	copy(id[:], fk[:28])
+	return id, true
+}
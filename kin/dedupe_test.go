@@ -0,0 +1,81 @@
+package kin
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	commonpb "github.com/kinecosystem/agora-api/genproto/common/v3"
+
+	"github.com/kinecosystem/agora-common/solana"
+	"github.com/kinecosystem/agora-common/solana/memo"
+	"github.com/kinecosystem/agora-common/solana/token"
+)
+
+func TestDedupeID(t *testing.T) {
+	keys := generateKeys(t, 4)
+	il := &commonpb.InvoiceList{
+		Invoices: []*commonpb.Invoice{
+			{
+				Items: []*commonpb.Invoice_LineItem{{Title: "Item1"}},
+			},
+		},
+	}
+
+	transfer := token.Transfer(keys[1], keys[2], keys[3], 10)
+	txn := solana.NewTransaction(keys[0], transfer)
+
+	id, err := NewDedupeID(txn, il, 1)
+	require.NoError(t, err)
+
+	// Blockhash and signatures should not affect the ID
+	retry := solana.NewTransaction(keys[0], transfer)
+	retry.SetBlockhash(solana.Blockhash{1})
+	retry.Signatures[0][0] = 1
+
+	retryID, err := NewDedupeID(retry, il, 1)
+	require.NoError(t, err)
+	assert.Equal(t, id, retryID)
+
+	// Embedding the ID in the memo should not affect the ID
+	m, err := NewDedupeMemo(TransactionTypeSpend, 1, id)
+	require.NoError(t, err)
+	withMemo := solana.NewTransaction(keys[0], memo.Instruction(base64.StdEncoding.EncodeToString(m[:])), transfer)
+
+	memoID, err := NewDedupeID(withMemo, il, 1)
+	require.NoError(t, err)
+	assert.Equal(t, id, memoID)
+
+	recovered, ok := DedupeIDFromMemo(m)
+	assert.True(t, ok)
+	assert.Equal(t, id, recovered)
+	assert.EqualValues(t, 1, m.AppIndex())
+	assert.Equal(t, TransactionTypeSpend, m.TransactionType())
+
+	// Differing inputs should result in different IDs
+	for _, tc := range []struct {
+		txn      solana.Transaction
+		il       *commonpb.InvoiceList
+		appIndex uint16
+	}{
+		{txn, nil, 1},
+		{txn, il, 2},
+		{solana.NewTransaction(keys[0], token.Transfer(keys[1], keys[2], keys[3], 11)), il, 1},
+		{solana.NewTransaction(keys[1], transfer), il, 1},
+	} {
+		other, err := NewDedupeID(tc.txn, tc.il, tc.appIndex)
+		require.NoError(t, err)
+		assert.NotEqual(t, id, other)
+	}
+}
+
+func TestDedupeIDFromMemo_Invoice(t *testing.T) {
+	// Invoice referencing memos have a zero last foreign key byte
+	m, err := NewMemo(1, TransactionTypeSpend, 1, make([]byte, 28))
+	require.NoError(t, err)
+
+	_, ok := DedupeIDFromMemo(m)
+	assert.False(t, ok)
+}
@@ -6,8 +6,8 @@ import (
 
 	"github.com/stellar/go/clients/horizonclient"
 
-	agoraenv "github.com/kinecosystem/agora-common/env"
 	"github.com/kinecosystem/agora-common/kin/network"
+	"github.com/kinecosystem/agora-common/kin/version"
 	"github.com/kinecosystem/go/build"
 	"github.com/kinecosystem/go/clients/horizon"
 )
@@ -94,15 +94,6 @@ var (
 		HorizonURL: kin2TestHorizonURL,
 		HTTP:       http.DefaultClient,
 	}
-
-	// prodNetwork is the Network modifier that should be used in transactions on the production Kin network
-	prodNetwork = build.Network{Passphrase: prodHorizonPassphrase}
-	// testNetwork is the Network modifier that should be used in transactions on the test Kin network
-	testNetwork = build.Network{Passphrase: testHorizonPassphrase}
-	// kin2ProdNetwork is the Network modifier that should be used in transactions on the production Kin 2 network
-	kin2ProdNetwork = build.Network{Passphrase: kin2ProdPassphrase}
-	// kin2TestNetwork is the Network modifier that should be used in transactions on the test Kin 2 network
-	kin2TestNetwork = build.Network{Passphrase: kin2TestPassphrase}
 )
 
 var (
@@ -112,17 +103,12 @@ var (
 
 // GetClient returns the default Horizon client based on which environment the application is running in.
 func GetClient() (client *horizon.Client, err error) {
-	env, err := agoraenv.FromEnvVariable()
+	d, err := GetCurrentNetworkDescriptor(version.KinVersion3)
 	if err != nil {
 		return nil, err
 	}
 
-	switch env {
-	case agoraenv.AgoraEnvironmentProd:
-		return kinProdHorizonClient, nil
-	default:
-		return kinTestHorizonClient, nil
-	}
+	return d.HorizonClient(), nil
 }
 
 // GetClientV2 returns the default stellar based Horizon client based on which environment the application is running in.
@@ -132,32 +118,22 @@ func GetClient() (client *horizon.Client, err error) {
 // functionality _may_ have some divergent behaviour from the kin fork. Therefore, any
 // use of this client should be tested thoroughly.
 func GetClientV2() (client *horizonclient.Client, err error) {
-	env, err := agoraenv.FromEnvVariable()
+	d, err := GetCurrentNetworkDescriptor(version.KinVersion3)
 	if err != nil {
 		return nil, err
 	}
 
-	switch env {
-	case agoraenv.AgoraEnvironmentProd:
-		return kinProdHorizonClientV2, nil
-	default:
-		return kinTestHorizonClientV2, nil
-	}
+	return d.HorizonClientV2(), nil
 }
 
 // GetKin2Client returns the default Kin 2 Horizon client based on which environment the environment is running in
 func GetKin2Client() (client *horizon.Client, err error) {
-	env, err := agoraenv.FromEnvVariable()
+	d, err := GetCurrentNetworkDescriptor(version.KinVersion2)
 	if err != nil {
 		return nil, err
 	}
 
-	switch env {
-	case agoraenv.AgoraEnvironmentProd:
-		return kin2ProdHorizonClient, nil
-	default:
-		return kin2TestHorizonClient, nil
-	}
+	return d.HorizonClient(), nil
 }
 
 // GetKin2ClientV2 returns the default stellar-based Kin 2 Horizon client based on which environment the application
@@ -168,88 +144,68 @@ func GetKin2Client() (client *horizon.Client, err error) {
 // functionality _may_ have some divergent behaviour from the kin fork. Therefore, any
 // use of this client should be tested thoroughly.
 func GetKin2ClientV2() (client *horizonclient.Client, err error) {
-	env, err := agoraenv.FromEnvVariable()
+	d, err := GetCurrentNetworkDescriptor(version.KinVersion2)
 	if err != nil {
 		return nil, err
 	}
 
-	switch env {
-	case agoraenv.AgoraEnvironmentProd:
-		return kin2ProdHorizonClientV2, nil
-	default:
-		return kin2TestHorizonClientV2, nil
-	}
+	return d.HorizonClientV2(), nil
 }
 
 // GetNetwork returns the default Network modifier based on which environment the application is running in.
 func GetNetwork() (network build.Network, err error) {
-	env, err := agoraenv.FromEnvVariable()
+	d, err := GetCurrentNetworkDescriptor(version.KinVersion3)
 	if err != nil {
 		return build.Network{}, err
 	}
 
-	switch env {
-	case agoraenv.AgoraEnvironmentProd:
-		return prodNetwork, nil
-	default:
-		return testNetwork, nil
-	}
+	return d.Network(), nil
 }
 
 // GetKin2Network returns the default Kin 2 Network modifier based on which environment the application is running in.
 func GetKin2Network() (network build.Network, err error) {
-	env, err := agoraenv.FromEnvVariable()
+	d, err := GetCurrentNetworkDescriptor(version.KinVersion2)
 	if err != nil {
 		return build.Network{}, err
 	}
 
-	switch env {
-	case agoraenv.AgoraEnvironmentProd:
-		return kin2ProdNetwork, nil
-	default:
-		return kin2TestNetwork, nil
-	}
+	return d.Network(), nil
 }
 
 // GetClientByKinNetwork returns a Horizon client for the provided Kin network
 func GetClientByKinNetwork(net network.KinNetwork) (client *horizon.Client, err error) {
-	if !net.IsValid() {
-		return nil, ErrInvalidKinNetwork
+	d, err := descriptorByKinNetwork(net)
+	if err != nil {
+		return nil, err
 	}
 
-	switch net {
-	case network.MainNetwork:
-		return kinProdHorizonClient, nil
-	default:
-		return kinTestHorizonClient, nil
-	}
+	return d.HorizonClient(), nil
 }
 
 // GetNetworkByKinNetwork returns a Network modifier for the provided Kin network
 func GetNetworkByKinNetwork(net network.KinNetwork) (buildNetwork build.Network, err error) {
-	if !net.IsValid() {
-		return build.Network{}, ErrInvalidKinNetwork
+	d, err := descriptorByKinNetwork(net)
+	if err != nil {
+		return build.Network{}, err
 	}
 
-	switch net {
-	case network.MainNetwork:
-		return prodNetwork, nil
-	default:
-		return testNetwork, nil
-	}
+	return d.Network(), nil
 }
 
 // GetKin2Issuer returns the Kin issuer address based on which environment the application is running in.
 func GetKin2Issuer() (issuer string, err error) {
-	env, err := agoraenv.FromEnvVariable()
+	d, err := GetCurrentNetworkDescriptor(version.KinVersion2)
 	if err != nil {
 		return "", err
 	}
 
-	switch env {
-	case agoraenv.AgoraEnvironmentProd:
-		return Kin2ProdIssuer, nil
-	default:
-		return Kin2TestIssuer, nil
+	return d.Issuer, nil
+}
+
+func descriptorByKinNetwork(net network.KinNetwork) (NetworkDescriptor, error) {
+	if !net.IsValid() {
+		return NetworkDescriptor{}, ErrInvalidKinNetwork
 	}
+
+	return networks[networkKey{version: version.KinVersion3, production: net == network.MainNetwork}], nil
 }
@@ -0,0 +1,157 @@
+package kin
+
+import (
+	"crypto/ed25519"
+
+	"github.com/mr-tron/base58"
+	"github.com/pkg/errors"
+	"github.com/stellar/go/clients/horizonclient"
+
+	agoraenv "github.com/kinecosystem/agora-common/env"
+	"github.com/kinecosystem/agora-common/kin/version"
+	"github.com/kinecosystem/go/build"
+	"github.com/kinecosystem/go/clients/horizon"
+)
+
+const (
+	// kinProdMint is the address of the Kin token mint on the production Solana network.
+	kinProdMint = "kinXdEcpDQeHPEuQnqmUgtYykqKGVFq6CeVX5iAHJq6"
+
+	// testFriendbotURL is the base URL of the friendbot service for the test Kin network.
+	testFriendbotURL = "http://friendbot-testnet.kininfrastructure.com"
+)
+
+// ErrNetworkNotFound occurs when there is no known network for the requested
+// (KinVersion, AgoraEnvironment) pair.
+var ErrNetworkNotFound = errors.New("no network for the provided kin version and environment")
+
+// NetworkDescriptor describes a Kin network for a specific Kin version and environment.
+//
+// Fields that are not relevant to the network's Kin version are left empty. For example,
+// Kin 4 networks have no Horizon URL, and only Kin 2 networks have an Issuer.
+type NetworkDescriptor struct {
+	KinVersion version.KinVersion
+	Production bool
+
+	HorizonURL string
+	Passphrase string
+
+	// Issuer is the Kin asset issuer (Kin 2 only).
+	Issuer string
+
+	// SolanaMint is the Kin token mint (Kin 4 only). It is nil if the
+	// mint is not known ahead of time, in which case it should be
+	// retrieved from the Agora service config.
+	SolanaMint ed25519.PublicKey
+
+	// FriendbotURL is the base URL of the friendbot service, if the
+	// network has one.
+	FriendbotURL string
+
+	horizonClient   *horizon.Client
+	horizonClientV2 *horizonclient.Client
+}
+
+// Network returns the Network modifier that should be used in transactions
+// on the network (Kin 2 and Kin 3 only).
+func (d NetworkDescriptor) Network() build.Network {
+	return build.Network{Passphrase: d.Passphrase}
+}
+
+// HorizonClient returns the Horizon client for the network, or nil if the
+// network does not have a Horizon server.
+func (d NetworkDescriptor) HorizonClient() *horizon.Client {
+	return d.horizonClient
+}
+
+// HorizonClientV2 returns the stellar based Horizon client for the network, or
+// nil if the network does not have a Horizon server.
+func (d NetworkDescriptor) HorizonClientV2() *horizonclient.Client {
+	return d.horizonClientV2
+}
+
+type networkKey struct {
+	version    version.KinVersion
+	production bool
+}
+
+var networks = map[networkKey]NetworkDescriptor{
+	{version.KinVersion2, true}: {
+		KinVersion:      version.KinVersion2,
+		Production:      true,
+		HorizonURL:      kin2ProdHorizonURL,
+		Passphrase:      kin2ProdPassphrase,
+		Issuer:          Kin2ProdIssuer,
+		horizonClient:   kin2ProdHorizonClient,
+		horizonClientV2: kin2ProdHorizonClientV2,
+	},
+	{version.KinVersion2, false}: {
+		KinVersion:      version.KinVersion2,
+		HorizonURL:      kin2TestHorizonURL,
+		Passphrase:      kin2TestPassphrase,
+		Issuer:          Kin2TestIssuer,
+		horizonClient:   kin2TestHorizonClient,
+		horizonClientV2: kin2TestHorizonClientV2,
+	},
+	{version.KinVersion3, true}: {
+		KinVersion:      version.KinVersion3,
+		Production:      true,
+		HorizonURL:      prodHorizonURL,
+		Passphrase:      prodHorizonPassphrase,
+		horizonClient:   kinProdHorizonClient,
+		horizonClientV2: kinProdHorizonClientV2,
+	},
+	{version.KinVersion3, false}: {
+		KinVersion:      version.KinVersion3,
+		HorizonURL:      testHorizonURL,
+		Passphrase:      testHorizonPassphrase,
+		FriendbotURL:    testFriendbotURL,
+		horizonClient:   kinTestHorizonClient,
+		horizonClientV2: kinTestHorizonClientV2,
+	},
+	{version.KinVersion4, true}: {
+		KinVersion: version.KinVersion4,
+		Production: true,
+		SolanaMint: mustDecodeKey(kinProdMint),
+	},
+	{version.KinVersion4, false}: {
+		KinVersion: version.KinVersion4,
+	},
+}
+
+// GetNetworkDescriptor returns the NetworkDescriptor for the provided Kin version and environment.
+//
+// The production environment maps to the production networks, while all other
+// environments map to the test networks.
+func GetNetworkDescriptor(v version.KinVersion, env agoraenv.AgoraEnvironment) (NetworkDescriptor, error) {
+	if !env.IsValid() {
+		return NetworkDescriptor{}, agoraenv.ErrBadEnvironmentVariableSet
+	}
+
+	d, ok := networks[networkKey{version: v, production: env == agoraenv.AgoraEnvironmentProd}]
+	if !ok {
+		return NetworkDescriptor{}, ErrNetworkNotFound
+	}
+
+	return d, nil
+}
+
+// GetCurrentNetworkDescriptor returns the NetworkDescriptor for the provided Kin version, based on
+// which environment the application is running in.
+func GetCurrentNetworkDescriptor(v version.KinVersion) (NetworkDescriptor, error) {
+	env, err := agoraenv.FromEnvVariable()
+	if err != nil {
+		return NetworkDescriptor{}, err
+	}
+
+	return GetNetworkDescriptor(v, env)
+}
+
+func mustDecodeKey(s string) ed25519.PublicKey {
+	b, err := base58.Decode(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return b
+}
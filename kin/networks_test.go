@@ -0,0 +1,80 @@
+package kin
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	agoraenv "github.com/kinecosystem/agora-common/env"
+	"github.com/kinecosystem/agora-common/kin/network"
+	"github.com/kinecosystem/agora-common/kin/version"
+)
+
+func TestGetNetworkDescriptor(t *testing.T) {
+	d, err := GetNetworkDescriptor(version.KinVersion2, agoraenv.AgoraEnvironmentProd)
+	require.NoError(t, err)
+	assert.True(t, d.Production)
+	assert.Equal(t, Kin2ProdIssuer, d.Issuer)
+	assert.Equal(t, kin2ProdPassphrase, d.Network().Passphrase)
+	assert.Equal(t, kin2ProdHorizonClient, d.HorizonClient())
+
+	for _, env := range []agoraenv.AgoraEnvironment{agoraenv.AgoraEnvironmentDev, agoraenv.AgoraEnvironmentTest} {
+		d, err = GetNetworkDescriptor(version.KinVersion3, env)
+		require.NoError(t, err)
+		assert.False(t, d.Production)
+		assert.Equal(t, testHorizonURL, d.HorizonURL)
+		assert.Equal(t, kinTestHorizonClientV2, d.HorizonClientV2())
+		assert.NotEmpty(t, d.FriendbotURL)
+	}
+
+	d, err = GetNetworkDescriptor(version.KinVersion4, agoraenv.AgoraEnvironmentProd)
+	require.NoError(t, err)
+	assert.Len(t, d.SolanaMint, 32)
+	assert.Nil(t, d.HorizonClient())
+
+	_, err = GetNetworkDescriptor(version.KinVersionReserved, agoraenv.AgoraEnvironmentProd)
+	assert.Equal(t, ErrNetworkNotFound, err)
+
+	_, err = GetNetworkDescriptor(version.KinVersion3, "invalid")
+	assert.Equal(t, agoraenv.ErrBadEnvironmentVariableSet, err)
+}
+
+func TestLegacyGetters(t *testing.T) {
+	prev, set := os.LookupEnv("AGORA_ENVIRONMENT")
+	defer func() {
+		if set {
+			os.Setenv("AGORA_ENVIRONMENT", prev)
+		} else {
+			os.Unsetenv("AGORA_ENVIRONMENT")
+		}
+	}()
+
+	require.NoError(t, os.Setenv("AGORA_ENVIRONMENT", string(agoraenv.AgoraEnvironmentProd)))
+
+	client, err := GetClient()
+	require.NoError(t, err)
+	assert.Equal(t, kinProdHorizonClient, client)
+
+	issuer, err := GetKin2Issuer()
+	require.NoError(t, err)
+	assert.Equal(t, Kin2ProdIssuer, issuer)
+
+	n, err := GetKin2Network()
+	require.NoError(t, err)
+	assert.Equal(t, kin2ProdPassphrase, n.Passphrase)
+
+	require.NoError(t, os.Setenv("AGORA_ENVIRONMENT", string(agoraenv.AgoraEnvironmentDev)))
+
+	n, err = GetNetwork()
+	require.NoError(t, err)
+	assert.Equal(t, testHorizonPassphrase, n.Passphrase)
+
+	client, err = GetClientByKinNetwork(network.MainNetwork)
+	require.NoError(t, err)
+	assert.Equal(t, kinProdHorizonClient, client)
+
+	_, err = GetNetworkByKinNetwork("invalid")
+	assert.Equal(t, ErrInvalidKinNetwork, err)
+}
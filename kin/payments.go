@@ -0,0 +1,123 @@
+package kin
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/kinecosystem/agora-common/solana"
+	"github.com/kinecosystem/agora-common/solana/token"
+)
+
+// PaymentType is the type of balance affecting entry contained
+// within a transaction.
+type PaymentType int
+
+const (
+	PaymentTypeTransfer PaymentType = iota
+	PaymentTypeCreation
+	PaymentTypeClosure
+)
+
+// Payment is a flattened entry of a confirmed kin transaction.
+//
+// Exactly one of Transfer, Creation, or Closure is set, as
+// indicated by Type.
+type Payment struct {
+	Type PaymentType
+
+	Slot      uint64
+	BlockTime *time.Time
+	Signature solana.Signature
+	Err       *solana.TransactionError
+
+	AppIndex uint16
+	AppID    string
+
+	// Region is the index of the region within the transaction
+	// that the payment was contained in.
+	Region   int
+	MemoData []byte
+	Memo     *Memo
+
+	Transfer *token.DecompiledTransfer
+	Creation *Creation
+	Closure  *token.DecompiledCloseAccount
+}
+
+// PaymentsFromBlock returns the payments contained within all of the
+// kin transactions in the provided block.
+//
+// Transactions that are not valid kin transactions (as determined by
+// ParseTransaction) are skipped. Failed transactions are included, with
+// Err set on each of the resulting payments.
+func PaymentsFromBlock(block *solana.Block) []Payment {
+	if block == nil {
+		return nil
+	}
+
+	var payments []Payment
+	for _, txn := range block.Transactions {
+		p, err := paymentsFromTransaction(block.Slot, block.BlockTime, txn.Transaction, txn.Err)
+		if err != nil {
+			continue
+		}
+
+		payments = append(payments, p...)
+	}
+
+	return payments
+}
+
+// PaymentsFromConfirmedTransaction returns the payments contained within the
+// provided confirmed transaction.
+//
+// An error is returned if the transaction is not a valid kin transaction, as
+// determined by ParseTransaction.
+func PaymentsFromConfirmedTransaction(txn solana.ConfirmedTransaction) ([]Payment, error) {
+	return paymentsFromTransaction(txn.Slot, txn.BlockTime, txn.Transaction, txn.Err)
+}
+
+func paymentsFromTransaction(slot uint64, blockTime *time.Time, txn solana.Transaction, txErr *solana.TransactionError) ([]Payment, error) {
+	parsed, err := ParseTransaction(txn, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse transaction")
+	}
+
+	base := Payment{
+		Slot:      slot,
+		BlockTime: blockTime,
+		Signature: txn.Signatures[0],
+		Err:       txErr,
+		AppIndex:  parsed.AppIndex,
+		AppID:     parsed.AppID,
+	}
+
+	var payments []Payment
+	for r, region := range parsed.Regions {
+		base.Region = r
+		base.MemoData = region.MemoData
+		base.Memo = region.Memo
+
+		for i := range region.Creations {
+			p := base
+			p.Type = PaymentTypeCreation
+			p.Creation = &region.Creations[i]
+			payments = append(payments, p)
+		}
+		for _, transfer := range region.Transfers {
+			p := base
+			p.Type = PaymentTypeTransfer
+			p.Transfer = transfer
+			payments = append(payments, p)
+		}
+		for _, closure := range region.Closures {
+			p := base
+			p.Type = PaymentTypeClosure
+			p.Closure = closure
+			payments = append(payments, p)
+		}
+	}
+
+	return payments, nil
+}
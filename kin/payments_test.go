@@ -0,0 +1,96 @@
+package kin
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kinecosystem/agora-common/solana"
+	"github.com/kinecosystem/agora-common/solana/memo"
+	"github.com/kinecosystem/agora-common/solana/token"
+)
+
+func TestPaymentsFromConfirmedTransaction(t *testing.T) {
+	keys := generateKeys(t, 5)
+
+	m, err := NewMemo(1, TransactionTypeP2P, 10, make([]byte, 29))
+	require.NoError(t, err)
+
+	instructions := generateCreate(t, keys[0], keys[1], keys[2])
+	instructions = append(instructions,
+		memo.Instruction(base64.StdEncoding.EncodeToString(m[:])),
+		token.Transfer(keys[3], keys[4], keys[1], 10),
+		token.CloseAccount(keys[3], keys[0], keys[1]),
+	)
+
+	txn := solana.NewTransaction(keys[0], instructions...)
+	txn.Signatures[0][0] = 1
+
+	blockTime := time.Unix(1000, 0)
+	payments, err := PaymentsFromConfirmedTransaction(solana.ConfirmedTransaction{
+		Slot:        10,
+		BlockTime:   &blockTime,
+		Transaction: txn,
+		Err:         solana.NewTransactionError(solana.TransactionErrorAccountInUse),
+	})
+	require.NoError(t, err)
+	require.Len(t, payments, 3)
+
+	for _, p := range payments {
+		assert.EqualValues(t, 10, p.Slot)
+		assert.Equal(t, blockTime, *p.BlockTime)
+		assert.Equal(t, txn.Signatures[0], p.Signature)
+		assert.NotNil(t, p.Err)
+		assert.EqualValues(t, 10, p.AppIndex)
+	}
+
+	assert.Equal(t, PaymentTypeCreation, payments[0].Type)
+	assert.Equal(t, 0, payments[0].Region)
+	assert.Nil(t, payments[0].Memo)
+	assert.NotNil(t, payments[0].Creation)
+
+	assert.Equal(t, PaymentTypeTransfer, payments[1].Type)
+	assert.Equal(t, 1, payments[1].Region)
+	assert.Equal(t, m, *payments[1].Memo)
+	assert.EqualValues(t, 10, payments[1].Transfer.Amount)
+
+	assert.Equal(t, PaymentTypeClosure, payments[2].Type)
+	assert.Equal(t, 1, payments[2].Region)
+	assert.EqualValues(t, keys[3], payments[2].Closure.Account)
+
+	// Invalid kin transaction
+	invalid := solana.NewTransaction(keys[0], token.Transfer(keys[1], keys[2], keys[0], 10))
+	_, err = PaymentsFromConfirmedTransaction(solana.ConfirmedTransaction{Transaction: invalid})
+	assert.Error(t, err)
+}
+
+func TestPaymentsFromBlock(t *testing.T) {
+	keys := generateKeys(t, 5)
+
+	valid := solana.NewTransaction(keys[0], token.Transfer(keys[1], keys[2], keys[3], 10))
+	invalid := solana.NewTransaction(keys[0], token.Transfer(keys[1], keys[2], keys[0], 10))
+
+	assert.Nil(t, PaymentsFromBlock(nil))
+
+	block := &solana.Block{
+		Slot: 20,
+		Transactions: []solana.BlockTransaction{
+			{Transaction: valid},
+			{Transaction: invalid},
+			{Transaction: valid},
+		},
+	}
+
+	payments := PaymentsFromBlock(block)
+	require.Len(t, payments, 2)
+	for _, p := range payments {
+		assert.Equal(t, PaymentTypeTransfer, p.Type)
+		assert.EqualValues(t, 20, p.Slot)
+		assert.Nil(t, p.BlockTime)
+		assert.Nil(t, p.Err)
+		assert.EqualValues(t, keys[2], p.Transfer.Destination)
+	}
+}
@@ -0,0 +1,142 @@
+package kin
+
+import (
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Decimals is the number of decimal places used by the Kin token.
+const Decimals = 5
+
+// ErrOverflow indicates that the result of a quark operation cannot
+// be represented as an int64.
+var ErrOverflow = errors.New("quark arithmetic overflow")
+
+// ToBigQuarks converts a string representation of kin to the
+// quark value, without any bounds on the magnitude of the value.
+func ToBigQuarks(val string) (*big.Int, error) {
+	return ToQuarksWithDecimals(val, Decimals)
+}
+
+// FromBigQuarks converts an arbitrarily sized amount of quarks
+// to the string representation of kin.
+func FromBigQuarks(amount *big.Int) string {
+	return FromQuarksWithDecimals(amount, Decimals)
+}
+
+// ToQuarksWithDecimals converts a string representation of a token amount
+// to the amount in the token's smallest unit, given the number of decimals
+// the token (mint) has been configured with.
+//
+// Negative values are supported. An error is returned if the value string is
+// invalid, or it contains more precision than decimals allows.
+func ToQuarksWithDecimals(val string, decimals uint8) (*big.Int, error) {
+	negative := strings.HasPrefix(val, "-")
+	if negative {
+		val = val[1:]
+	}
+
+	parts := strings.Split(val, ".")
+	if len(parts) > 2 {
+		return nil, errors.New("invalid value")
+	}
+	if parts[0] == "" || !isDigits(parts[0]) {
+		return nil, errors.New("invalid integer component")
+	}
+
+	var fraction string
+	if len(parts) == 2 {
+		fraction = parts[1]
+		if !isDigits(fraction) {
+			return nil, errors.New("invalid decimal component")
+		}
+		if len(fraction) > int(decimals) {
+			return nil, errors.New("value cannot be represented")
+		}
+	}
+
+	digits := parts[0] + fraction + strings.Repeat("0", int(decimals)-len(fraction))
+	result, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, errors.New("invalid value")
+	}
+
+	if negative {
+		result.Neg(result)
+	}
+
+	return result, nil
+}
+
+// FromQuarksWithDecimals converts an amount in a token's smallest unit to
+// the string representation of the token amount, given the number of decimals
+// the token (mint) has been configured with.
+func FromQuarksWithDecimals(amount *big.Int, decimals uint8) string {
+	var sign string
+	if amount.Sign() < 0 {
+		sign = "-"
+	}
+
+	digits := new(big.Int).Abs(amount).String()
+	if decimals == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= int(decimals) {
+		digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+	}
+
+	split := len(digits) - int(decimals)
+	return sign + digits[:split] + "." + digits[split:]
+}
+
+// AddQuarks returns a + b, or ErrOverflow if the result cannot
+// be represented as an int64.
+func AddQuarks(a, b int64) (int64, error) {
+	if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+		return 0, ErrOverflow
+	}
+
+	return a + b, nil
+}
+
+// SubQuarks returns a - b, or ErrOverflow if the result cannot
+// be represented as an int64.
+func SubQuarks(a, b int64) (int64, error) {
+	if (b < 0 && a > math.MaxInt64+b) || (b > 0 && a < math.MinInt64+b) {
+		return 0, ErrOverflow
+	}
+
+	return a - b, nil
+}
+
+// SumQuarks returns the sum of the provided amounts, or ErrOverflow if
+// the result cannot be represented as an int64.
+//
+// Intermediate results are allowed to exceed the bounds of an int64, so
+// long as the final sum does not.
+func SumQuarks(amounts ...int64) (int64, error) {
+	sum := new(big.Int)
+	for _, a := range amounts {
+		sum.Add(sum, big.NewInt(a))
+	}
+
+	if !sum.IsInt64() {
+		return 0, ErrOverflow
+	}
+
+	return sum.Int64(), nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,111 @@
+package kin
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuarks_Negative(t *testing.T) {
+	cases := map[string]int64{
+		"-0.00001":    -1,
+		"-1.00000":    -1e5,
+		"-1.50000":    -(1e5 + 1e5/2),
+		"-9974.99900": -997499900,
+	}
+	for in, expected := range cases {
+		actual, err := ToQuarks(in)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+		assert.Equal(t, in, FromQuarks(expected))
+	}
+
+	_, err := ToQuarks("-")
+	assert.Error(t, err)
+	_, err = ToQuarks("--1")
+	assert.Error(t, err)
+}
+
+func TestBigQuarks(t *testing.T) {
+	// Far greater than what can be represented as an int64
+	in := "100000000000000000000.12345"
+	quarks, err := ToBigQuarks(in)
+	require.NoError(t, err)
+
+	expected, ok := new(big.Int).SetString("10000000000000000000012345", 10)
+	require.True(t, ok)
+	assert.Equal(t, 0, expected.Cmp(quarks))
+	assert.Equal(t, in, FromBigQuarks(quarks))
+
+	_, err = ToQuarks(in)
+	assert.Error(t, err)
+}
+
+func TestQuarksWithDecimals(t *testing.T) {
+	type testCase struct {
+		in       string
+		decimals uint8
+		expected int64
+		str      string
+	}
+
+	for _, tc := range []testCase{
+		{"1", 0, 1, "1"},
+		{"1", 2, 100, "1.00"},
+		{"0.01", 2, 1, "0.01"},
+		{"-0.01", 2, -1, "-0.01"},
+		{"12.3", 9, 12300000000, "12.300000000"},
+		{"0", 9, 0, "0.000000000"},
+	} {
+		actual, err := ToQuarksWithDecimals(tc.in, tc.decimals)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, actual.Int64())
+		assert.Equal(t, tc.str, FromQuarksWithDecimals(actual, tc.decimals))
+	}
+
+	for _, in := range []string{"", ".", "1.2.3", "a", "1.a", "1.-1", "1.1"} {
+		_, err := ToQuarksWithDecimals(in, 0)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestQuarkArithmetic(t *testing.T) {
+	sum, err := AddQuarks(1, 2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, sum)
+
+	sum, err = AddQuarks(math.MaxInt64, -1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, math.MaxInt64-1, sum)
+
+	_, err = AddQuarks(math.MaxInt64, 1)
+	assert.Equal(t, ErrOverflow, err)
+	_, err = AddQuarks(math.MinInt64, -1)
+	assert.Equal(t, ErrOverflow, err)
+
+	diff, err := SubQuarks(1, 2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, -1, diff)
+
+	_, err = SubQuarks(math.MinInt64, 1)
+	assert.Equal(t, ErrOverflow, err)
+	_, err = SubQuarks(math.MaxInt64, -1)
+	assert.Equal(t, ErrOverflow, err)
+	_, err = SubQuarks(0, math.MinInt64)
+	assert.Equal(t, ErrOverflow, err)
+
+	sum, err = SumQuarks()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, sum)
+
+	// Intermediate overflow is fine
+	sum, err = SumQuarks(math.MaxInt64, 1, -2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, math.MaxInt64-1, sum)
+
+	_, err = SumQuarks(math.MaxInt64, 1)
+	assert.Equal(t, ErrOverflow, err)
+}
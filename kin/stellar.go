@@ -0,0 +1,149 @@
+package kin
+
+import (
+	"bytes"
+	"crypto/sha256"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	commonpb "github.com/kinecosystem/agora-api/genproto/common/v3"
+	"github.com/kinecosystem/go/xdr"
+)
+
+// StellarTx represents a parsed kin stellar (Kin 2 / Kin 3) transaction.
+type StellarTx struct {
+	AppIndex uint16
+	AppID    string
+
+	Source PublicKey
+
+	// TextMemo is set if the transaction contains a text memo, and
+	// Memo is set if the transaction contains a valid kin.Memo. At
+	// most one of the two will be set.
+	TextMemo string
+	Memo     *Memo
+
+	Creations []StellarCreation
+	Payments  []StellarPayment
+}
+
+// StellarCreation is a CreateAccount operation within a stellar transaction.
+type StellarCreation struct {
+	Source          PublicKey
+	Destination     PublicKey
+	StartingBalance int64
+}
+
+// StellarPayment is a Payment operation within a stellar transaction.
+//
+// Amount is in the asset's native units, as encoded in the operation.
+type StellarPayment struct {
+	Source      PublicKey
+	Destination PublicKey
+	Asset       xdr.Asset
+	Amount      int64
+}
+
+// ParseEnvelope parses a (stellar transaction envelope, invoice list) pair.
+//
+// The following invariants are checked while parsing:
+//   1. Each operation is one of the following:
+//     - CreateAccount
+//     - Payment
+//   2. If an invoice is provided, the transaction must contain a kin.Memo whose
+//      foreign key references the invoice list.
+//   3. If an invoice is provided, the number of invoices must match the number
+//      of payments.
+//
+// As with ParseTransaction, the link between AppIndex and AppID is not validated.
+func ParseEnvelope(envelope xdr.TransactionEnvelope, il *commonpb.InvoiceList) (parsed StellarTx, err error) {
+	if len(envelope.Tx.Operations) == 0 {
+		return parsed, errors.New("no operations")
+	}
+
+	parsed.Source, err = PublicKeyFromStellarXDR(envelope.Tx.SourceAccount)
+	if err != nil {
+		return parsed, errors.Wrap(err, "invalid transaction source")
+	}
+
+	for i, op := range envelope.Tx.Operations {
+		source := parsed.Source
+		if op.SourceAccount != nil {
+			if source, err = PublicKeyFromStellarXDR(*op.SourceAccount); err != nil {
+				return parsed, errors.Wrapf(err, "invalid source at %d", i)
+			}
+		}
+
+		switch op.Body.Type {
+		case xdr.OperationTypeCreateAccount:
+			create := op.Body.MustCreateAccountOp()
+			dest, err := PublicKeyFromStellarXDR(create.Destination)
+			if err != nil {
+				return parsed, errors.Wrapf(err, "invalid CreateAccount destination at %d", i)
+			}
+
+			parsed.Creations = append(parsed.Creations, StellarCreation{
+				Source:          source,
+				Destination:     dest,
+				StartingBalance: int64(create.StartingBalance),
+			})
+		case xdr.OperationTypePayment:
+			payment := op.Body.MustPaymentOp()
+			dest, err := PublicKeyFromStellarXDR(payment.Destination)
+			if err != nil {
+				return parsed, errors.Wrapf(err, "invalid Payment destination at %d", i)
+			}
+
+			parsed.Payments = append(parsed.Payments, StellarPayment{
+				Source:      source,
+				Destination: dest,
+				Asset:       payment.Asset,
+				Amount:      int64(payment.Amount),
+			})
+		default:
+			return parsed, errors.Errorf("unsupported operation type at %d", i)
+		}
+	}
+
+	switch envelope.Tx.Memo.Type {
+	case xdr.MemoTypeMemoText:
+		parsed.TextMemo = envelope.Tx.Memo.MustText()
+		if appID, ok := AppIDFromTextMemo(parsed.TextMemo); ok {
+			parsed.AppID = appID
+		}
+	case xdr.MemoTypeMemoHash:
+		if m, ok := MemoFromXDR(envelope.Tx.Memo, false); ok {
+			parsed.Memo = &m
+			parsed.AppIndex = m.AppIndex()
+		}
+	}
+
+	if il == nil {
+		return parsed, nil
+	}
+
+	if parsed.Memo == nil {
+		return parsed, errors.New("invoice list provided without a kin memo")
+	}
+
+	raw, err := proto.Marshal(il)
+	if err != nil {
+		return parsed, errors.Wrap(err, "failed to marshal invoice list")
+	}
+	ilHash := sha256.Sum224(raw)
+
+	fk := parsed.Memo.ForeignKey()
+	if !bytes.Equal(fk[:28], ilHash[:]) || fk[28] != 0 {
+		return parsed, errors.New("memo foreign key does not match invoice list")
+	}
+	if len(il.Invoices) != len(parsed.Payments) {
+		return parsed, errors.Errorf(
+			"invoice count (%d) does not match payment count (%d)",
+			len(il.Invoices),
+			len(parsed.Payments),
+		)
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,163 @@
+package kin
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	commonpb "github.com/kinecosystem/agora-api/genproto/common/v3"
+	"github.com/kinecosystem/go/xdr"
+)
+
+func TestParseEnvelope(t *testing.T) {
+	keys := generateKeys(t, 4)
+	il, m := generateStellarInvoiceMemo(t, 2)
+
+	envelope := generateEnvelope(t, keys, xdr.Memo{Type: xdr.MemoTypeMemoHash, Hash: (*xdr.Hash)(&m)})
+	tx, err := ParseEnvelope(envelope, il)
+	require.NoError(t, err)
+
+	assert.EqualValues(t, keys[0], tx.Source)
+	assert.EqualValues(t, 10, tx.AppIndex)
+	assert.Empty(t, tx.AppID)
+	assert.Empty(t, tx.TextMemo)
+	assert.Equal(t, m, *tx.Memo)
+
+	require.Len(t, tx.Creations, 1)
+	assert.EqualValues(t, keys[0], tx.Creations[0].Source)
+	assert.EqualValues(t, keys[1], tx.Creations[0].Destination)
+	assert.EqualValues(t, 5, tx.Creations[0].StartingBalance)
+
+	require.Len(t, tx.Payments, 2)
+	assert.EqualValues(t, keys[0], tx.Payments[0].Source)
+	assert.EqualValues(t, keys[2], tx.Payments[0].Destination)
+	assert.EqualValues(t, 10, tx.Payments[0].Amount)
+	assert.Equal(t, xdr.AssetTypeAssetTypeNative, tx.Payments[0].Asset.Type)
+	assert.EqualValues(t, keys[1], tx.Payments[1].Source)
+	assert.EqualValues(t, keys[3], tx.Payments[1].Destination)
+	assert.EqualValues(t, 20, tx.Payments[1].Amount)
+
+	// Mismatched invoice count
+	other, _ := generateStellarInvoiceMemo(t, 3)
+	_, err = ParseEnvelope(envelope, other)
+	assert.Error(t, err)
+
+	// Invoice list not referenced by the memo
+	wrongFK, err := NewMemo(1, TransactionTypeSpend, 10, make([]byte, 29))
+	require.NoError(t, err)
+	envelope.Tx.Memo = xdr.Memo{Type: xdr.MemoTypeMemoHash, Hash: (*xdr.Hash)(&wrongFK)}
+	_, err = ParseEnvelope(envelope, il)
+	assert.Error(t, err)
+
+	tx, err = ParseEnvelope(envelope, nil)
+	require.NoError(t, err)
+	assert.Equal(t, wrongFK, *tx.Memo)
+}
+
+func TestParseEnvelope_TextMemo(t *testing.T) {
+	keys := generateKeys(t, 4)
+	il, _ := generateStellarInvoiceMemo(t, 2)
+
+	text := "1-test-blah"
+	envelope := generateEnvelope(t, keys, xdr.Memo{Type: xdr.MemoTypeMemoText, Text: &text})
+
+	tx, err := ParseEnvelope(envelope, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "test", tx.AppID)
+	assert.Equal(t, text, tx.TextMemo)
+	assert.Nil(t, tx.Memo)
+
+	// Invoices require a kin.Memo
+	_, err = ParseEnvelope(envelope, il)
+	assert.Error(t, err)
+}
+
+func TestParseEnvelope_Invalid(t *testing.T) {
+	keys := generateKeys(t, 4)
+
+	envelope := generateEnvelope(t, keys, xdr.Memo{Type: xdr.MemoTypeMemoNone})
+	envelope.Tx.Operations = nil
+	_, err := ParseEnvelope(envelope, nil)
+	assert.Error(t, err)
+
+	envelope = generateEnvelope(t, keys, xdr.Memo{Type: xdr.MemoTypeMemoNone})
+	envelope.Tx.Operations = append(envelope.Tx.Operations, xdr.Operation{
+		Body: xdr.OperationBody{
+			Type: xdr.OperationTypeInflation,
+		},
+	})
+	_, err = ParseEnvelope(envelope, nil)
+	assert.Error(t, err)
+}
+
+func generateStellarInvoiceMemo(t *testing.T, n int) (*commonpb.InvoiceList, Memo) {
+	il := &commonpb.InvoiceList{}
+	for i := 0; i < n; i++ {
+		il.Invoices = append(il.Invoices, &commonpb.Invoice{
+			Items: []*commonpb.Invoice_LineItem{
+				{
+					Title: "Item1",
+				},
+			},
+		})
+	}
+
+	raw, err := proto.Marshal(il)
+	require.NoError(t, err)
+
+	h := sha256.Sum224(raw)
+	fk := make([]byte, 29)
+	copy(fk, h[:])
+
+	m, err := NewMemo(1, TransactionTypeSpend, 10, fk)
+	require.NoError(t, err)
+
+	return il, m
+}
+
+func generateEnvelope(t *testing.T, keys []ed25519.PublicKey, memo xdr.Memo) xdr.TransactionEnvelope {
+	opSource := AccountIDFromPublicKey(PublicKey(keys[1]))
+
+	return xdr.TransactionEnvelope{
+		Tx: xdr.Transaction{
+			SourceAccount: AccountIDFromPublicKey(PublicKey(keys[0])),
+			Memo:          memo,
+			Operations: []xdr.Operation{
+				{
+					Body: xdr.OperationBody{
+						Type: xdr.OperationTypeCreateAccount,
+						CreateAccountOp: &xdr.CreateAccountOp{
+							Destination:     AccountIDFromPublicKey(PublicKey(keys[1])),
+							StartingBalance: 5,
+						},
+					},
+				},
+				{
+					Body: xdr.OperationBody{
+						Type: xdr.OperationTypePayment,
+						PaymentOp: &xdr.PaymentOp{
+							Destination: AccountIDFromPublicKey(PublicKey(keys[2])),
+							Asset:       xdr.Asset{Type: xdr.AssetTypeAssetTypeNative},
+							Amount:      10,
+						},
+					},
+				},
+				{
+					SourceAccount: &opSource,
+					Body: xdr.OperationBody{
+						Type: xdr.OperationTypePayment,
+						PaymentOp: &xdr.PaymentOp{
+							Destination: AccountIDFromPublicKey(PublicKey(keys[3])),
+							Asset:       xdr.Asset{Type: xdr.AssetTypeAssetTypeNative},
+							Amount:      20,
+						},
+					},
+				},
+			},
+		},
+	}
+}
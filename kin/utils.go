@@ -1,14 +1,17 @@
 package kin
 
 import (
-	"fmt"
-	"strconv"
+	"math/big"
 	"strings"
 	"unicode"
 
 	"github.com/pkg/errors"
 )
 
+// MaxTextMemoLength is the maximum length of a text memo, as
+// limited by the stellar memo text size.
+const MaxTextMemoLength = 28
+
 // ToQuarks converts a string representation of kin
 // the quark value.
 //
@@ -17,7 +20,7 @@ import (
 // a value smaller than quarks, or a value _far_ greater than
 // the supply.
 func ToQuarks(val string) (int64, error) {
-	parts := strings.Split(val, ".")
+	parts := strings.Split(strings.TrimPrefix(val, "-"), ".")
 	if len(parts) > 2 {
 		return 0, errors.New("invalid kin value")
 	}
@@ -26,25 +29,16 @@ func ToQuarks(val string) (int64, error) {
 		return 0, errors.New("value cannot be represented")
 	}
 
-	kin, err := strconv.ParseInt(parts[0], 10, 64)
+	quarks, err := ToBigQuarks(val)
 	if err != nil {
 		return 0, err
 	}
 
-	var quarks uint64
-	if len(parts) == 2 {
-		if len(parts[1]) > 5 {
-			return 0, errors.New("value cannot be represented")
-		}
-
-		padded := fmt.Sprintf("%s%s", parts[1], strings.Repeat("0", 5-len(parts[1])))
-		quarks, err = strconv.ParseUint(padded, 10, 64)
-		if err != nil {
-			return 0, errors.Wrap(err, "invalid decimal component")
-		}
+	if !quarks.IsInt64() {
+		return 0, errors.New("value cannot be represented")
 	}
 
-	return kin*1e5 + int64(quarks), nil
+	return quarks.Int64(), nil
 }
 
 // MustToQuarks calls ToQuarks, panicking if there's an error.
@@ -62,11 +56,7 @@ func MustToQuarks(val string) int64 {
 // FromQuarks converts an int64 amount of quarks to the
 // string representation of kin.
 func FromQuarks(amount int64) string {
-	if amount < 1e5 {
-		return fmt.Sprintf("0.%05d", amount)
-	}
-
-	return fmt.Sprintf("%d.%05d", amount/1e5, amount%1e5)
+	return FromBigQuarks(big.NewInt(amount))
 }
 
 // AppIDFromTextMemo returns the canonical string AppID given a memo string.
@@ -91,6 +81,45 @@ func AppIDFromTextMemo(memo string) (appID string, ok bool) {
 	return parts[1], true
 }
 
+// BuildTextMemo returns an SDK compatible text memo for the provided
+// app ID and (optional) suffix, in the form "1-<appID>-<suffix>".
+//
+// An error is returned if the app ID is invalid, or if the resulting
+// memo would exceed MaxTextMemoLength.
+func BuildTextMemo(appID, suffix string) (string, error) {
+	if !IsValidAppID(appID) {
+		return "", errors.New("invalid app id")
+	}
+
+	memo := "1-" + appID
+	if suffix != "" {
+		memo += "-" + suffix
+	}
+
+	if len(memo) > MaxTextMemoLength {
+		return "", errors.Errorf("text memo exceeds max length (%d > %d)", len(memo), MaxTextMemoLength)
+	}
+
+	return memo, nil
+}
+
+// ParseTextMemo returns the AppID and suffix of the provided text memo.
+//
+// If the provided memo is in the incorrect format, ok will be false.
+func ParseTextMemo(memo string) (appID, suffix string, ok bool) {
+	appID, ok = AppIDFromTextMemo(memo)
+	if !ok {
+		return "", "", false
+	}
+
+	parts := strings.SplitN(memo, "-", 3)
+	if len(parts) == 3 {
+		suffix = parts[2]
+	}
+
+	return appID, suffix, true
+}
+
 // IsValidAppID returns whether or not the provided string is a valid app ID.
 func IsValidAppID(appID string) bool {
 	if len(appID) < 3 || len(appID) > 4 {
@@ -87,3 +87,37 @@ func TestIsValidAppID(t *testing.T) {
 	// invalid characters
 	assert.False(t, IsValidAppID("tes!"))
 }
+
+func TestBuildTextMemo(t *testing.T) {
+	memo, err := BuildTextMemo("test", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "1-test", memo)
+
+	memo, err = BuildTextMemo("abc", "some-suffix")
+	assert.NoError(t, err)
+	assert.Equal(t, "1-abc-some-suffix", memo)
+
+	appID, suffix, ok := ParseTextMemo(memo)
+	assert.True(t, ok)
+	assert.Equal(t, "abc", appID)
+	assert.Equal(t, "some-suffix", suffix)
+
+	appID, suffix, ok = ParseTextMemo("1-test")
+	assert.True(t, ok)
+	assert.Equal(t, "test", appID)
+	assert.Empty(t, suffix)
+
+	_, err = BuildTextMemo("te", "")
+	assert.Error(t, err)
+
+	// 1-test- + 21 characters = 28
+	_, err = BuildTextMemo("test", strings.Repeat("a", 21))
+	assert.NoError(t, err)
+	_, err = BuildTextMemo("test", strings.Repeat("a", 22))
+	assert.Error(t, err)
+
+	for _, invalid := range []string{"", "test", "2-test-abc", "1-te-abc"} {
+		_, _, ok = ParseTextMemo(invalid)
+		assert.False(t, ok)
+	}
+}
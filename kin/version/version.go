@@ -6,6 +6,8 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/kinecosystem/agora-common/config"
+	"github.com/kinecosystem/agora-common/config/wrapper"
 	"github.com/kinecosystem/agora-common/headers"
 )
 
@@ -31,15 +33,85 @@ const (
 	defaultVersion          = KinVersion4
 )
 
+// Config provides runtime overrides of the default kin version, as well as
+// the range of allowed kin versions.
+//
+// A range outside of the versions supported by this package falls back to the
+// compile-time range. A default outside of the allowed range is clamped to it,
+// so that requests without a version header are never served a version that
+// would be rejected if it were requested explicitly.
+type Config struct {
+	defaultVersion config.Uint64
+	minVersion     config.Uint64
+	maxVersion     config.Uint64
+}
+
+// NewConfig returns a Config backed by the provided configs. A nil config
+// uses the corresponding compile-time default.
+func NewConfig(defaultOverride, minOverride, maxOverride config.Config) *Config {
+	orNoop := func(c config.Config) config.Config {
+		if c == nil {
+			return config.NoopConfig
+		}
+		return c
+	}
+
+	return &Config{
+		defaultVersion: wrapper.NewUint64Config(orNoop(defaultOverride), uint64(defaultVersion)),
+		minVersion:     wrapper.NewUint64Config(orNoop(minOverride), uint64(minVersion)),
+		maxVersion:     wrapper.NewUint64Config(orNoop(maxOverride), uint64(maxVersion)),
+	}
+}
+
+// Shutdown signals the underlying configs to stop all resources.
+func (c *Config) Shutdown() {
+	c.defaultVersion.Shutdown()
+	c.minVersion.Shutdown()
+	c.maxVersion.Shutdown()
+}
+
+// bounds returns the currently configured (default, min, max) versions.
+func (c *Config) bounds(ctx context.Context) (def, min, max KinVersion) {
+	if c == nil {
+		return defaultVersion, minVersion, maxVersion
+	}
+
+	def = KinVersion(c.defaultVersion.Get(ctx))
+	min = KinVersion(c.minVersion.Get(ctx))
+	max = KinVersion(c.maxVersion.Get(ctx))
+
+	if min < minVersion || max > maxVersion || min > max {
+		min, max = minVersion, maxVersion
+	}
+	if def < min {
+		def = min
+	}
+	if def > max {
+		def = max
+	}
+
+	return def, min, max
+}
+
 // GetCtxKinVersion determines which version of Kin to use based on the headers in the provided context.
 func GetCtxKinVersion(ctx context.Context) (v KinVersion, err error) {
+	return GetCtxKinVersionWithConfig(ctx, nil)
+}
+
+// GetCtxKinVersionWithConfig determines which version of Kin to use based on the headers in the provided
+// context, using the default version and allowed range from the provided Config.
+//
+// If conf is nil, the compile-time defaults are used.
+func GetCtxKinVersionWithConfig(ctx context.Context, conf *Config) (v KinVersion, err error) {
+	def, min, max := conf.bounds(ctx)
+
 	val, err := headers.GetASCIIHeaderByName(ctx, KinVersionHeader)
 	if err != nil {
 		return 0, errors.Wrap(err, "failed to get kin version header")
 	}
 
 	if len(val) == 0 {
-		return defaultVersion, nil
+		return def, nil
 	}
 
 	i, err := strconv.Atoi(val)
@@ -47,8 +119,8 @@ func GetCtxKinVersion(ctx context.Context) (v KinVersion, err error) {
 		return 0, errors.Wrap(err, "could not parse integer version from string")
 	}
 
-	if i < int(minVersion) || i > int(maxVersion) {
-		return 0, errors.Wrap(err, "invalid kin version")
+	if i < int(min) || i > int(max) {
+		return 0, errors.Errorf("invalid kin version: %d", i)
 	}
 
 	return KinVersion(i), nil
@@ -56,6 +128,16 @@ func GetCtxKinVersion(ctx context.Context) (v KinVersion, err error) {
 
 // GetCtxDesiredVersion determines which version of Kin the requestor whiches to have enforced.
 func GetCtxDesiredVersion(ctx context.Context) (v KinVersion, err error) {
+	return GetCtxDesiredVersionWithConfig(ctx, nil)
+}
+
+// GetCtxDesiredVersionWithConfig determines which version of Kin the requestor wishes to have enforced,
+// using the allowed range from the provided Config.
+//
+// If conf is nil, the compile-time defaults are used.
+func GetCtxDesiredVersionWithConfig(ctx context.Context, conf *Config) (v KinVersion, err error) {
+	_, min, max := conf.bounds(ctx)
+
 	val, err := headers.GetASCIIHeaderByName(ctx, DesiredKinVersionHeader)
 	if err != nil {
 		return 0, errors.Wrap(err, "failed to get desired kin version header")
@@ -70,8 +152,8 @@ func GetCtxDesiredVersion(ctx context.Context) (v KinVersion, err error) {
 		return 0, errors.Wrap(err, "could not parse integer version from string")
 	}
 
-	if i < int(minVersion) || i > int(maxVersion) {
-		return 0, errors.Wrap(err, "invalid desired kin version")
+	if i < int(min) || i > int(max) {
+		return 0, errors.Errorf("invalid desired kin version: %d", i)
 	}
 
 	return KinVersion(i), nil
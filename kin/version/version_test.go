@@ -0,0 +1,100 @@
+package version
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/kinecosystem/agora-common/config/memory"
+	"github.com/kinecosystem/agora-common/headers"
+)
+
+func TestGetCtxKinVersion(t *testing.T) {
+	v, err := GetCtxKinVersion(headerContext(t, nil))
+	require.NoError(t, err)
+	assert.Equal(t, defaultVersion, v)
+
+	v, err = GetCtxKinVersion(headerContext(t, map[string]string{KinVersionHeader: "3"}))
+	require.NoError(t, err)
+	assert.Equal(t, KinVersion3, v)
+
+	for _, invalid := range []string{"1", "5", "abc"} {
+		_, err = GetCtxKinVersion(headerContext(t, map[string]string{KinVersionHeader: invalid}))
+		assert.Error(t, err)
+	}
+}
+
+func TestGetCtxKinVersionWithConfig(t *testing.T) {
+	defaultConfig := memory.NewConfig(uint64(3))
+	minConfig := memory.NewConfig(uint64(3))
+	maxConfig := memory.NewConfig(nil)
+	conf := NewConfig(defaultConfig, minConfig, maxConfig)
+
+	v, err := GetCtxKinVersionWithConfig(headerContext(t, nil), conf)
+	require.NoError(t, err)
+	assert.Equal(t, KinVersion3, v)
+
+	_, err = GetCtxKinVersionWithConfig(headerContext(t, map[string]string{KinVersionHeader: "2"}), conf)
+	assert.Error(t, err)
+	_, err = GetCtxDesiredVersionWithConfig(headerContext(t, map[string]string{DesiredKinVersionHeader: "2"}), conf)
+	assert.Error(t, err)
+
+	v, err = GetCtxDesiredVersionWithConfig(headerContext(t, map[string]string{DesiredKinVersionHeader: "4"}), conf)
+	require.NoError(t, err)
+	assert.Equal(t, KinVersion4, v)
+
+	// Migrate traffic at runtime
+	defaultConfig.SetValue(uint64(4))
+	v, err = GetCtxKinVersionWithConfig(headerContext(t, nil), conf)
+	require.NoError(t, err)
+	assert.Equal(t, KinVersion4, v)
+
+	// Default outside of the range is clamped to it
+	maxConfig.SetValue(uint64(3))
+	defaultConfig.SetValue(uint64(2))
+	v, err = GetCtxKinVersionWithConfig(headerContext(t, nil), conf)
+	require.NoError(t, err)
+	assert.Equal(t, KinVersion3, v)
+
+	// Unsupported range falls back to the compile-time range
+	maxConfig.SetValue(uint64(10))
+	v, err = GetCtxKinVersionWithConfig(headerContext(t, map[string]string{KinVersionHeader: "2"}), conf)
+	require.NoError(t, err)
+	assert.Equal(t, KinVersion2, v)
+	_, err = GetCtxKinVersionWithConfig(headerContext(t, map[string]string{KinVersionHeader: "5"}), conf)
+	assert.Error(t, err)
+}
+
+func TestGetCtxKinVersionWithConfig_NarrowedRange(t *testing.T) {
+	// Rolling back to Kin 3 by narrowing the range, without overriding the
+	// default, should not serve Kin 4 to requests without a version header.
+	conf := NewConfig(nil, memory.NewConfig(uint64(2)), memory.NewConfig(uint64(3)))
+
+	v, err := GetCtxKinVersionWithConfig(headerContext(t, nil), conf)
+	require.NoError(t, err)
+	assert.Equal(t, KinVersion3, v)
+
+	_, err = GetCtxKinVersionWithConfig(headerContext(t, map[string]string{KinVersionHeader: "4"}), conf)
+	assert.Error(t, err)
+
+	v, err = GetCtxKinVersionWithConfig(headerContext(t, map[string]string{KinVersionHeader: "2"}), conf)
+	require.NoError(t, err)
+	assert.Equal(t, KinVersion2, v)
+}
+
+func headerContext(t *testing.T, md map[string]string) context.Context {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.New(md))
+
+	var result context.Context
+	_, err := headers.UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		result = ctx
+		return nil, nil
+	})
+	require.NoError(t, err)
+
+	return result
+}
@@ -0,0 +1,87 @@
+// Package logging provides a small logger interface, allowing applications to
+// control the formatting, level, and output of the components they embed.
+package logging
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// Fields is a set of structured fields attached to log entries.
+type Fields map[string]interface{}
+
+// Logger is a structured, leveled logger.
+//
+// Implementations must be safe for concurrent use.
+type Logger interface {
+	// WithField returns a Logger that includes the field in its entries.
+	WithField(key string, value interface{}) Logger
+
+	// WithFields returns a Logger that includes the fields in its entries.
+	WithFields(fields Fields) Logger
+
+	// WithError returns a Logger that includes the error in its entries.
+	WithError(err error) Logger
+
+	Trace(args ...interface{})
+	Debug(args ...interface{})
+	Info(args ...interface{})
+	Warn(args ...interface{})
+	Error(args ...interface{})
+
+	Tracef(format string, args ...interface{})
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// LevelSetter is implemented by Loggers whose level can be changed at runtime,
+// such as by the /debug/loglevel endpoint of app.Run. Loggers derived via
+// WithField, WithFields and WithError share the level of their parent.
+type LevelSetter interface {
+	SetLevel(level logrus.Level)
+}
+
+// Default returns a Logger that logs to the standard logrus logger, which
+// components use if no Logger is configured.
+func Default() Logger {
+	return NewLogrus(logrus.NewEntry(logrus.StandardLogger()))
+}
+
+// OrDefault returns the logger, or Default if it is nil.
+func OrDefault(log Logger) Logger {
+	if log == nil {
+		return Default()
+	}
+	return log
+}
+
+type logrusLogger struct {
+	*logrus.Entry
+}
+
+// NewLogrus returns a Logger that logs to the logrus entry.
+func NewLogrus(entry *logrus.Entry) Logger {
+	return &logrusLogger{Entry: entry}
+}
+
+// WithField implements Logger.WithField.
+func (l *logrusLogger) WithField(key string, value interface{}) Logger {
+	return &logrusLogger{Entry: l.Entry.WithField(key, value)}
+}
+
+// WithFields implements Logger.WithFields.
+func (l *logrusLogger) WithFields(fields Fields) Logger {
+	return &logrusLogger{Entry: l.Entry.WithFields(logrus.Fields(fields))}
+}
+
+// WithError implements Logger.WithError.
+func (l *logrusLogger) WithError(err error) Logger {
+	return &logrusLogger{Entry: l.Entry.WithError(err)}
+}
+
+// SetLevel implements LevelSetter.SetLevel, setting the level of the
+// underlying logrus logger.
+func (l *logrusLogger) SetLevel(level logrus.Level) {
+	l.Entry.Logger.SetLevel(level)
+}
@@ -0,0 +1,40 @@
+package logging
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogrus(t *testing.T) {
+	logger, hook := logtest.NewNullLogger()
+	logger.SetLevel(logrus.TraceLevel)
+
+	log := NewLogrus(logrus.NewEntry(logger)).WithField("type", "test")
+	log.WithFields(Fields{"a": 1}).WithError(errors.New("failed")).Warnf("warning %d", 1)
+
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, logrus.WarnLevel, entry.Level)
+	assert.Equal(t, "warning 1", entry.Message)
+	assert.Equal(t, "test", entry.Data["type"])
+	assert.Equal(t, 1, entry.Data["a"])
+	assert.EqualError(t, entry.Data[logrus.ErrorKey].(error), "failed")
+
+	// Fields are not shared between derived loggers.
+	log.Trace("trace")
+	entry = hook.LastEntry()
+	assert.Equal(t, logrus.TraceLevel, entry.Level)
+	assert.Equal(t, logrus.Fields{"type": "test"}, entry.Data)
+}
+
+func TestOrDefault(t *testing.T) {
+	assert.NotNil(t, OrDefault(nil))
+
+	log := NewLogrus(logrus.NewEntry(logrus.New()))
+	assert.Equal(t, log, OrDefault(log))
+}
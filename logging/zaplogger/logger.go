@@ -0,0 +1,106 @@
+// Package zaplogger provides a logging.Logger backed by zap.
+package zaplogger
+
+import (
+	"github.com/sirupsen/logrus"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/kinecosystem/agora-common/logging"
+)
+
+type logger struct {
+	s *zap.SugaredLogger
+
+	// level is the level of the zap logger, if it was provided via
+	// NewWithLevel. It is shared by derived loggers.
+	level *zap.AtomicLevel
+}
+
+// New returns a logging.Logger that logs to the zap logger.
+//
+// zap has no trace level, so trace entries are logged at the debug level.
+func New(l *zap.Logger) logging.Logger {
+	// Skip the adapter's frame, so that callers are reported correctly.
+	return &logger{s: l.WithOptions(zap.AddCallerSkip(1)).Sugar()}
+}
+
+// NewWithLevel is similar to New, but the returned logger implements
+// logging.LevelSetter by setting the provided level, which should be the
+// level that the zap logger was built with (e.g. zap.Config.Level). This
+// allows the level to be changed at runtime, such as by app.Run.
+func NewWithLevel(l *zap.Logger, level zap.AtomicLevel) logging.Logger {
+	return &logger{s: l.WithOptions(zap.AddCallerSkip(1)).Sugar(), level: &level}
+}
+
+// SetLevel implements logging.LevelSetter.SetLevel. It has no effect if the
+// logger was not created with NewWithLevel.
+func (l *logger) SetLevel(level logrus.Level) {
+	if l.level == nil {
+		return
+	}
+
+	switch level {
+	case logrus.TraceLevel, logrus.DebugLevel:
+		l.level.SetLevel(zapcore.DebugLevel)
+	case logrus.InfoLevel:
+		l.level.SetLevel(zapcore.InfoLevel)
+	case logrus.WarnLevel:
+		l.level.SetLevel(zapcore.WarnLevel)
+	case logrus.ErrorLevel:
+		l.level.SetLevel(zapcore.ErrorLevel)
+	case logrus.FatalLevel:
+		l.level.SetLevel(zapcore.FatalLevel)
+	case logrus.PanicLevel:
+		l.level.SetLevel(zapcore.PanicLevel)
+	}
+}
+
+// WithField implements logging.Logger.WithField.
+func (l *logger) WithField(key string, value interface{}) logging.Logger {
+	return &logger{s: l.s.With(key, value), level: l.level}
+}
+
+// WithFields implements logging.Logger.WithFields.
+func (l *logger) WithFields(fields logging.Fields) logging.Logger {
+	args := make([]interface{}, 0, 2*len(fields))
+	for k, v := range fields {
+		args = append(args, k, v)
+	}
+	return &logger{s: l.s.With(args...), level: l.level}
+}
+
+// WithError implements logging.Logger.WithError.
+func (l *logger) WithError(err error) logging.Logger {
+	return &logger{s: l.s.With(zap.Error(err)), level: l.level}
+}
+
+// Trace implements logging.Logger.Trace.
+func (l *logger) Trace(args ...interface{}) { l.s.Debug(args...) }
+
+// Debug implements logging.Logger.Debug.
+func (l *logger) Debug(args ...interface{}) { l.s.Debug(args...) }
+
+// Info implements logging.Logger.Info.
+func (l *logger) Info(args ...interface{}) { l.s.Info(args...) }
+
+// Warn implements logging.Logger.Warn.
+func (l *logger) Warn(args ...interface{}) { l.s.Warn(args...) }
+
+// Error implements logging.Logger.Error.
+func (l *logger) Error(args ...interface{}) { l.s.Error(args...) }
+
+// Tracef implements logging.Logger.Tracef.
+func (l *logger) Tracef(format string, args ...interface{}) { l.s.Debugf(format, args...) }
+
+// Debugf implements logging.Logger.Debugf.
+func (l *logger) Debugf(format string, args ...interface{}) { l.s.Debugf(format, args...) }
+
+// Infof implements logging.Logger.Infof.
+func (l *logger) Infof(format string, args ...interface{}) { l.s.Infof(format, args...) }
+
+// Warnf implements logging.Logger.Warnf.
+func (l *logger) Warnf(format string, args ...interface{}) { l.s.Warnf(format, args...) }
+
+// Errorf implements logging.Logger.Errorf.
+func (l *logger) Errorf(format string, args ...interface{}) { l.s.Errorf(format, args...) }
@@ -0,0 +1,64 @@
+package zaplogger
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/kinecosystem/agora-common/logging"
+)
+
+func TestLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+
+	log := New(zap.New(core)).WithField("type", "test")
+	log.WithFields(logging.Fields{"a": 1}).WithError(errors.New("failed")).Warnf("warning %d", 1)
+	log.Trace("trace")
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, zapcore.WarnLevel, entries[0].Level)
+	assert.Equal(t, "warning 1", entries[0].Message)
+	assert.Equal(t, map[string]interface{}{
+		"type":  "test",
+		"a":     int64(1),
+		"error": "failed",
+	}, entries[0].ContextMap())
+
+	// zap has no trace level, so trace entries are logged at the debug level.
+	assert.Equal(t, zapcore.DebugLevel, entries[1].Level)
+	assert.Equal(t, map[string]interface{}{"type": "test"}, entries[1].ContextMap())
+}
+
+func TestLogger_SetLevel(t *testing.T) {
+	level := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	core, logs := observer.New(level)
+
+	log := NewWithLevel(zap.New(core), level).WithField("type", "test")
+	log.Debug("dropped")
+
+	// Derived loggers share the level.
+	setter, ok := log.(logging.LevelSetter)
+	require.True(t, ok)
+	setter.SetLevel(logrus.DebugLevel)
+	assert.Equal(t, zapcore.DebugLevel, level.Level())
+
+	log.Debug("debug")
+	require.Len(t, logs.AllUntimed(), 1)
+	assert.Equal(t, "debug", logs.AllUntimed()[0].Message)
+
+	setter.SetLevel(logrus.ErrorLevel)
+	log.Warn("dropped")
+	assert.Len(t, logs.AllUntimed(), 1)
+
+	// Loggers created with New do not have a level to set.
+	New(zap.New(core)).(logging.LevelSetter).SetLevel(logrus.DebugLevel)
+	assert.Equal(t, zapcore.ErrorLevel, level.Level())
+}
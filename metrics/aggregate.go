@@ -0,0 +1,305 @@
+package metrics
+
+import (
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kinecosystem/agora-common/logging"
+)
+
+// ObservationCountSuffix is appended to the names of sampled timing and
+// histogram metrics by the client returned by NewAggregatingClient, to name
+// the count of all of the values that were observed, including those that
+// were not submitted.
+const ObservationCountSuffix = ".observations"
+
+type aggregateOptions struct {
+	flushInterval time.Duration
+	maxSamples    int
+	log           logging.Logger
+}
+
+// AggregateOption configures a client returned by NewAggregatingClient.
+type AggregateOption func(o *aggregateOptions)
+
+// WithFlushInterval configures the interval at which aggregated metrics are
+// submitted to the underlying client. Non-positive intervals are ignored.
+func WithFlushInterval(interval time.Duration) AggregateOption {
+	return func(o *aggregateOptions) {
+		o.flushInterval = interval
+	}
+}
+
+// WithMaxSamples configures the maximum number of timing and histogram values
+// submitted per metric (and set of tags) for each flush interval. Non-positive
+// values are ignored.
+func WithMaxSamples(max int) AggregateOption {
+	return func(o *aggregateOptions) {
+		o.maxSamples = max
+	}
+}
+
+// WithAggregateLogger configures the logger used to report submission
+// failures, instead of the standard logrus logger.
+func WithAggregateLogger(log logging.Logger) AggregateOption {
+	return func(o *aggregateOptions) {
+		o.log = log
+	}
+}
+
+var defaultAggregateOptions = aggregateOptions{
+	flushInterval: 10 * time.Second,
+	maxSamples:    100,
+}
+
+type aggregateKey struct {
+	name string
+	tags string
+}
+
+type countAggregate struct {
+	tags  []string
+	value int64
+}
+
+type gaugeAggregate struct {
+	tags  []string
+	value float64
+}
+
+type sampleAggregate struct {
+	tags    []string
+	samples []float64
+	seen    int
+}
+
+type aggregatingClient struct {
+	log    logging.Logger
+	client Client
+	opts   aggregateOptions
+
+	mu         sync.Mutex
+	counts     map[aggregateKey]*countAggregate
+	gauges     map[aggregateKey]*gaugeAggregate
+	timings    map[aggregateKey]*sampleAggregate
+	histograms map[aggregateKey]*sampleAggregate
+
+	closeOnce sync.Once
+	closeErr  error
+	closeCh   chan struct{}
+	doneCh    chan struct{}
+}
+
+// NewAggregatingClient returns a Client that aggregates metrics in memory, and
+// submits them to the provided client on an interval. This substantially
+// reduces the number of metrics submitted by hot code paths.
+//
+// Counts with the same name and tags are summed, and only the latest value of
+// a gauge is submitted. Timing and histogram values are sampled, with at most
+// a configured number of values submitted per interval, which preserves their
+// distribution. Since the number of submitted values no longer reflects the
+// number of observed values, the latter is submitted as a count, named with
+// the ObservationCountSuffix (e.g. "latency.observations").
+//
+// Events and service checks are not aggregated, and are submitted to the
+// underlying client immediately, if it implements EventClient.
+//
+// Errors returned by the underlying client are logged, rather than returned.
+// Close flushes any aggregated metrics before closing the underlying client.
+func NewAggregatingClient(client Client, opts ...AggregateOption) Client {
+	c := &aggregatingClient{
+		client:  client,
+		opts:    defaultAggregateOptions,
+		closeCh: make(chan struct{}),
+		doneCh:  make(chan struct{}),
+	}
+	for _, o := range opts {
+		o(&c.opts)
+	}
+	if c.opts.flushInterval <= 0 {
+		c.opts.flushInterval = defaultAggregateOptions.flushInterval
+	}
+	if c.opts.maxSamples <= 0 {
+		c.opts.maxSamples = defaultAggregateOptions.maxSamples
+	}
+	c.log = logging.OrDefault(c.opts.log).WithField("type", "metrics/aggregate")
+	c.reset()
+
+	go c.flushLoop()
+
+	return c
+}
+
+// Count implements Client.Count
+func (c *aggregatingClient) Count(name string, value int64, tags []string) error {
+	key := newAggregateKey(name, tags)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	agg, ok := c.counts[key]
+	if !ok {
+		agg = &countAggregate{tags: copyTags(tags)}
+		c.counts[key] = agg
+	}
+	agg.value += value
+	return nil
+}
+
+// Gauge implements Client.Gauge
+func (c *aggregatingClient) Gauge(name string, value float64, tags []string) error {
+	key := newAggregateKey(name, tags)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	agg, ok := c.gauges[key]
+	if !ok {
+		agg = &gaugeAggregate{tags: copyTags(tags)}
+		c.gauges[key] = agg
+	}
+	agg.value = value
+	return nil
+}
+
+// Timing implements Client.Timing
+func (c *aggregatingClient) Timing(name string, value time.Duration, tags []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sample(c.timings, name, float64(value), tags)
+	return nil
+}
+
+// Histogram implements Client.Histogram
+func (c *aggregatingClient) Histogram(name string, value float64, tags []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sample(c.histograms, name, value, tags)
+	return nil
+}
+
+// Event implements EventClient.Event. If the underlying client does not
+// implement EventClient, ErrEventsUnsupported is returned.
+func (c *aggregatingClient) Event(e *Event) error {
+	return SendEvent(c.client, e)
+}
+
+// ServiceCheck implements EventClient.ServiceCheck. If the underlying client
+// does not implement EventClient, ErrEventsUnsupported is returned.
+func (c *aggregatingClient) ServiceCheck(sc *ServiceCheck) error {
+	return SendServiceCheck(c.client, sc)
+}
+
+// Close implements Client.Close
+func (c *aggregatingClient) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closeCh)
+		<-c.doneCh
+
+		c.closeErr = c.client.Close()
+	})
+
+	return c.closeErr
+}
+
+// sample adds the value to the aggregate using reservoir sampling, so that
+// each value has an equal chance of being submitted.
+//
+// c.mu must be held when calling sample.
+func (c *aggregatingClient) sample(aggs map[aggregateKey]*sampleAggregate, name string, value float64, tags []string) {
+	key := newAggregateKey(name, tags)
+
+	agg, ok := aggs[key]
+	if !ok {
+		agg = &sampleAggregate{tags: copyTags(tags)}
+		aggs[key] = agg
+	}
+
+	agg.seen++
+	if len(agg.samples) < c.opts.maxSamples {
+		agg.samples = append(agg.samples, value)
+	} else if i := rand.Intn(agg.seen); i < len(agg.samples) {
+		agg.samples[i] = value
+	}
+}
+
+func (c *aggregatingClient) flushLoop() {
+	defer close(c.doneCh)
+
+	ticker := time.NewTicker(c.opts.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.flush()
+		case <-c.closeCh:
+			c.flush()
+			return
+		}
+	}
+}
+
+func (c *aggregatingClient) flush() {
+	c.mu.Lock()
+	counts, gauges, timings, histograms := c.counts, c.gauges, c.timings, c.histograms
+	c.reset()
+	c.mu.Unlock()
+
+	var errs int
+	for key, agg := range counts {
+		if err := c.client.Count(key.name, agg.value, agg.tags); err != nil {
+			errs++
+		}
+	}
+	for key, agg := range gauges {
+		if err := c.client.Gauge(key.name, agg.value, agg.tags); err != nil {
+			errs++
+		}
+	}
+	for key, agg := range timings {
+		if err := c.client.Count(key.name+ObservationCountSuffix, int64(agg.seen), agg.tags); err != nil {
+			errs++
+		}
+		for _, v := range agg.samples {
+			if err := c.client.Timing(key.name, time.Duration(v), agg.tags); err != nil {
+				errs++
+			}
+		}
+	}
+	for key, agg := range histograms {
+		if err := c.client.Count(key.name+ObservationCountSuffix, int64(agg.seen), agg.tags); err != nil {
+			errs++
+		}
+		for _, v := range agg.samples {
+			if err := c.client.Histogram(key.name, v, agg.tags); err != nil {
+				errs++
+			}
+		}
+	}
+
+	if errs > 0 {
+		c.log.WithField("errors", errs).Warn("failed to submit aggregated metrics")
+	}
+}
+
+// reset clears the aggregated metrics.
+//
+// c.mu must be held when calling reset.
+func (c *aggregatingClient) reset() {
+	c.counts = make(map[aggregateKey]*countAggregate)
+	c.gauges = make(map[aggregateKey]*gaugeAggregate)
+	c.timings = make(map[aggregateKey]*sampleAggregate)
+	c.histograms = make(map[aggregateKey]*sampleAggregate)
+}
+
+func newAggregateKey(name string, tags []string) aggregateKey {
+	return aggregateKey{
+		name: name,
+		tags: strings.Join(tags, ","),
+	}
+}
@@ -0,0 +1,174 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kinecosystem/agora-common/testutil"
+)
+
+type aggregateRecord struct {
+	kind  string
+	name  string
+	value float64
+	tags  []string
+}
+
+type aggregateRecordingClient struct {
+	mu      sync.Mutex
+	records []aggregateRecord
+	closed  int
+}
+
+func (c *aggregateRecordingClient) record(kind, name string, value float64, tags []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.records = append(c.records, aggregateRecord{kind: kind, name: name, value: value, tags: tags})
+	return nil
+}
+
+func (c *aggregateRecordingClient) Count(name string, value int64, tags []string) error {
+	return c.record("count", name, float64(value), tags)
+}
+
+func (c *aggregateRecordingClient) Gauge(name string, value float64, tags []string) error {
+	return c.record("gauge", name, value, tags)
+}
+
+func (c *aggregateRecordingClient) Timing(name string, value time.Duration, tags []string) error {
+	return c.record("timing", name, float64(value), tags)
+}
+
+func (c *aggregateRecordingClient) Histogram(name string, value float64, tags []string) error {
+	return c.record("histogram", name, value, tags)
+}
+
+func (c *aggregateRecordingClient) Event(e *Event) error {
+	return c.record("event", e.Title, 0, e.Tags)
+}
+
+func (c *aggregateRecordingClient) ServiceCheck(sc *ServiceCheck) error {
+	return c.record("service_check", sc.Name, float64(sc.Status), sc.Tags)
+}
+
+func (c *aggregateRecordingClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed++
+	return nil
+}
+
+func (c *aggregateRecordingClient) get() []aggregateRecord {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	records := append([]aggregateRecord(nil), c.records...)
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].kind != records[j].kind {
+			return records[i].kind < records[j].kind
+		}
+		return records[i].name < records[j].name
+	})
+	return records
+}
+
+func TestAggregatingClient(t *testing.T) {
+	underlying := &aggregateRecordingClient{}
+	client := NewAggregatingClient(underlying, WithFlushInterval(time.Hour), WithMaxSamples(5))
+
+	tags := []string{"method:a"}
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, client.Count("a", 1, tags))
+		require.NoError(t, client.Gauge("g", float64(i), nil))
+		require.NoError(t, client.Timing("t", time.Duration(i), nil))
+		require.NoError(t, client.Histogram("h", float64(i), nil))
+	}
+	require.NoError(t, client.Count("a", 2, []string{"method:b"}))
+
+	// Nothing should be submitted until the client is flushed.
+	assert.Empty(t, underlying.get())
+
+	require.NoError(t, client.Close())
+	require.NoError(t, client.Close())
+	assert.Equal(t, 1, underlying.closed)
+
+	records := underlying.get()
+	require.Len(t, records, 15)
+
+	counts := records[:2]
+	sort.Slice(counts, func(i, j int) bool { return counts[i].value > counts[j].value })
+	assert.Equal(t, aggregateRecord{kind: "count", name: "a", value: 1000, tags: []string{"method:a"}}, counts[0])
+	assert.Equal(t, aggregateRecord{kind: "count", name: "a", value: 2, tags: []string{"method:b"}}, counts[1])
+
+	// The number of observed values is submitted alongside the samples.
+	assert.Equal(t, aggregateRecord{kind: "count", name: "h.observations", value: 1000}, records[2])
+	assert.Equal(t, aggregateRecord{kind: "count", name: "t.observations", value: 1000}, records[3])
+	assert.Equal(t, aggregateRecord{kind: "gauge", name: "g", value: 999}, records[4])
+
+	for _, r := range records[5:10] {
+		assert.Equal(t, "histogram", r.kind)
+		assert.True(t, r.value >= 0 && r.value < 1000)
+	}
+	for _, r := range records[10:] {
+		assert.Equal(t, "timing", r.kind)
+		assert.True(t, r.value >= 0 && r.value < 1000)
+	}
+}
+
+func TestAggregatingClient_Events(t *testing.T) {
+	underlying := &aggregateRecordingClient{}
+	client := NewAggregatingClient(underlying, WithFlushInterval(time.Hour))
+	defer client.Close()
+
+	// Events and service checks are submitted immediately.
+	require.NoError(t, SendEvent(client, &Event{Title: "deploy", Text: "text", Tags: []string{"env:prod"}}))
+	require.NoError(t, SendServiceCheck(client, &ServiceCheck{Name: "check", Status: ServiceCheckCritical}))
+	assert.Equal(t, []aggregateRecord{
+		{kind: "event", name: "deploy", tags: []string{"env:prod"}},
+		{kind: "service_check", name: "check", value: float64(ServiceCheckCritical)},
+	}, underlying.get())
+
+	unsupported := NewAggregatingClient(testClient{})
+	defer unsupported.Close()
+	assert.Equal(t, ErrEventsUnsupported, SendEvent(unsupported, &Event{Title: "title", Text: "text"}))
+	assert.Equal(t, ErrEventsUnsupported, SendServiceCheck(unsupported, &ServiceCheck{Name: "check"}))
+}
+
+func TestAggregatingClient_InvalidOptions(t *testing.T) {
+	underlying := &aggregateRecordingClient{}
+	client := NewAggregatingClient(underlying, WithFlushInterval(0), WithMaxSamples(-1))
+
+	// Invalid options fall back to the defaults, rather than panicking or
+	// dropping all samples.
+	require.NoError(t, client.Timing("latency", time.Second, nil))
+	require.NoError(t, client.Close())
+	assert.Equal(t, []aggregateRecord{
+		{kind: "count", name: "latency" + ObservationCountSuffix, value: 1},
+		{kind: "timing", name: "latency", value: float64(time.Second)},
+	}, underlying.get())
+}
+
+func TestAggregatingClient_Interval(t *testing.T) {
+	underlying := &aggregateRecordingClient{}
+	client := NewAggregatingClient(underlying, WithFlushInterval(10*time.Millisecond))
+	defer client.Close()
+
+	require.NoError(t, client.Count("a", 1, nil))
+	require.NoError(t, client.Count("a", 1, nil))
+	require.NoError(t, testutil.WaitFor(time.Second, 10*time.Millisecond, func() bool {
+		return len(underlying.get()) == 1
+	}))
+	assert.Equal(t, aggregateRecord{kind: "count", name: "a", value: 2}, underlying.get()[0])
+
+	// Subsequent intervals only submit newly aggregated metrics.
+	require.NoError(t, client.Count("a", 3, nil))
+	require.NoError(t, testutil.WaitFor(time.Second, 10*time.Millisecond, func() bool {
+		return len(underlying.get()) == 2
+	}))
+	assert.EqualValues(t, 3, underlying.get()[1].value)
+}
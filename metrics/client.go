@@ -13,6 +13,10 @@ type Client interface {
 	// Timing measures the time of a metric.
 	Timing(name string, value time.Duration, tags []string) error
 
+	// Histogram measures the statistical distribution of a metric, such as
+	// payload sizes or batch counts.
+	Histogram(name string, value float64, tags []string) error
+
 	// Close closes the client and any underlying resources
 	Close() error
 }
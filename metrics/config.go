@@ -5,6 +5,17 @@ type ClientConfig struct {
 	Namespace string
 	// GlobalTags are tags that will be added to every metric
 	GlobalTags []string
+	// HistogramBuckets are the upper bounds of the buckets used for histograms,
+	// for clients that bucket histogram values in process, such as memory.
+	// Clients that aggregate histograms externally, such as statsd, ignore the
+	// buckets.
+	HistogramBuckets []float64
+	// Address is the address of the metrics backend, for clients that submit
+	// metrics to an external agent. If empty, the client's default is used.
+	Address string
+	// SampleRate is the rate at which metrics are sampled, for clients that
+	// support sampling. If zero, the client's default is used.
+	SampleRate float64
 }
 
 type ClientOption func(o *ClientConfig)
@@ -24,3 +35,25 @@ func WithGlobalTags(tagOptions ...TagOption) ClientOption {
 		o.GlobalTags = append(o.GlobalTags, tags...)
 	}
 }
+
+// WithHistogramBuckets configures the client to use the provided histogram
+// bucket upper bounds.
+func WithHistogramBuckets(buckets ...float64) ClientOption {
+	return func(o *ClientConfig) {
+		o.HistogramBuckets = buckets
+	}
+}
+
+// WithAddress configures the client to submit metrics to the provided address.
+func WithAddress(address string) ClientOption {
+	return func(o *ClientConfig) {
+		o.Address = address
+	}
+}
+
+// WithSampleRate configures the client to use the provided sample rate.
+func WithSampleRate(sampleRate float64) ClientOption {
+	return func(o *ClientConfig) {
+		o.SampleRate = sampleRate
+	}
+}
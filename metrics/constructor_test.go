@@ -45,6 +45,10 @@ func (t testClient) Timing(name string, value time.Duration, tags []string) erro
 	return nil
 }
 
+func (t testClient) Histogram(name string, value float64, tags []string) error {
+	return nil
+}
+
 func (t testClient) Close() error {
 	return nil
 }
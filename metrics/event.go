@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrEventsUnsupported is returned by SendEvent and SendServiceCheck when the
+// client does not implement EventClient.
+var ErrEventsUnsupported = errors.New("client does not support events")
+
+// AlertType is the alert type of an Event.
+type AlertType string
+
+const (
+	AlertTypeInfo    AlertType = "info"
+	AlertTypeSuccess AlertType = "success"
+	AlertTypeWarning AlertType = "warning"
+	AlertTypeError   AlertType = "error"
+)
+
+// Event is a record of a notable occurrence, such as a deployment.
+type Event struct {
+	// Title is the title of the event. It is required.
+	Title string
+	// Text is the description of the event. It is required.
+	Text string
+	// AlertType is the alert type of the event. If empty, it is treated as
+	// AlertTypeInfo.
+	AlertType AlertType
+	// AggregationKey groups the event with others of the same key.
+	AggregationKey string
+	// Timestamp is the time of the event. If not set, the time the event is
+	// received is used.
+	Timestamp time.Time
+	Tags      []string
+}
+
+// ServiceCheckStatus is the status of a ServiceCheck.
+type ServiceCheckStatus byte
+
+const (
+	ServiceCheckOK ServiceCheckStatus = iota
+	ServiceCheckWarning
+	ServiceCheckCritical
+	ServiceCheckUnknown
+)
+
+// ServiceCheck is a report of the health of a service or dependency.
+type ServiceCheck struct {
+	// Name is the name of the service check. It is required.
+	Name   string
+	Status ServiceCheckStatus
+	// Message describes the current status.
+	Message string
+	// Timestamp is the time of the check. If not set, the time the check is
+	// received is used.
+	Timestamp time.Time
+	Tags      []string
+}
+
+// EventClient is implemented by clients that support events and service
+// checks, in addition to metrics.
+type EventClient interface {
+	Client
+
+	// Event submits an event.
+	Event(e *Event) error
+
+	// ServiceCheck submits a service check.
+	ServiceCheck(sc *ServiceCheck) error
+}
+
+// SendEvent submits the event if the client implements EventClient, otherwise
+// ErrEventsUnsupported is returned.
+func SendEvent(client Client, e *Event) error {
+	ec, ok := client.(EventClient)
+	if !ok {
+		return ErrEventsUnsupported
+	}
+	return ec.Event(e)
+}
+
+// SendServiceCheck submits the service check if the client implements
+// EventClient, otherwise ErrEventsUnsupported is returned.
+func SendServiceCheck(client Client, sc *ServiceCheck) error {
+	ec, ok := client.(EventClient)
+	if !ok {
+		return ErrEventsUnsupported
+	}
+	return ec.ServiceCheck(sc)
+}
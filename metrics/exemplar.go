@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceIDExemplarLabel is the exemplar label used to record trace IDs.
+const TraceIDExemplarLabel = "trace_id"
+
+// ObserveWithTraceExemplar observes the value, attaching the ID of the sampled
+// trace in the context (if any) as an exemplar. This allows an example trace to
+// be found for a given histogram bucket.
+//
+// Exemplars are only exposed when metrics are served in the OpenMetrics format.
+func ObserveWithTraceExemplar(ctx context.Context, observer prometheus.Observer, value float64) {
+	sc := trace.SpanContextFromContext(ctx)
+	if eo, ok := observer.(prometheus.ExemplarObserver); ok && sc.IsValid() && sc.IsSampled() {
+		eo.ObserveWithExemplar(value, prometheus.Labels{
+			TraceIDExemplarLabel: sc.TraceID().String(),
+		})
+		return
+	}
+
+	observer.Observe(value)
+}
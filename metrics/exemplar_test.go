@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestObserveWithTraceExemplar(t *testing.T) {
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "test_exemplar_seconds",
+		Buckets: []float64{1, 10},
+	})
+
+	traceID := trace.TraceID{1, 2, 3}
+	sampled := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+	}))
+	unsampled := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{4},
+		SpanID:  trace.SpanID{1},
+	}))
+
+	ObserveWithTraceExemplar(context.Background(), histogram, 0.5)
+	ObserveWithTraceExemplar(unsampled, histogram, 0.5)
+	ObserveWithTraceExemplar(sampled, histogram, 5)
+
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(histogram))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+
+	h := families[0].GetMetric()[0].GetHistogram()
+	assert.EqualValues(t, 3, h.GetSampleCount())
+	require.Len(t, h.GetBucket(), 2)
+	assert.Nil(t, h.GetBucket()[0].GetExemplar())
+
+	exemplar := h.GetBucket()[1].GetExemplar()
+	require.NotNil(t, exemplar)
+	assert.Equal(t, 5.0, exemplar.GetValue())
+	require.Len(t, exemplar.GetLabel(), 1)
+	assert.Equal(t, TraceIDExemplarLabel, exemplar.GetLabel()[0].GetName())
+	assert.Equal(t, traceID.String(), exemplar.GetLabel()[0].GetValue())
+}
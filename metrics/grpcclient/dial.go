@@ -0,0 +1,27 @@
+package grpcclient
+
+import (
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+)
+
+// Dial creates a client connection to the target, with the client metrics and
+// tracing interceptors installed.
+//
+// The default interceptors are executed before any interceptors provided via
+// grpc.WithChainUnaryInterceptor or grpc.WithChainStreamInterceptor in opts.
+//
+// Spans are recorded with the global OpenTelemetry TracerProvider, which is
+// configured by app.Run if tracing is enabled.
+func Dial(target string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	return grpc.Dial(target, append(DialOptions(), opts...)...)
+}
+
+// DialOptions returns the grpc.DialOptions that install the client metrics and
+// tracing interceptors, for use with grpc.DialContext or other dial helpers.
+func DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(UnaryClientInterceptor(), otelgrpc.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(StreamClientInterceptor(), otelgrpc.StreamClientInterceptor()),
+	}
+}
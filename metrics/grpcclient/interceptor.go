@@ -0,0 +1,119 @@
+package grpcclient
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+
+	"github.com/kinecosystem/agora-common/metrics"
+)
+
+const (
+	unary        = "unary"
+	clientStream = "client_stream"
+	serverStream = "server_stream"
+	bidiStream   = "bidi_stream"
+)
+
+var (
+	inFlightGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grpc",
+		Name:      "client_in_flight_requests",
+		Help:      "Number of RPCs currently in flight on the client.",
+	}, []string{"grpc_type", "grpc_service", "grpc_method"})
+
+	enableHistogramOnce sync.Once
+)
+
+func init() {
+	inFlightGauge = metrics.Register(inFlightGauge).(*prometheus.GaugeVec)
+}
+
+// enableHandlingTimeHistogram enables the latency histogram of the default
+// go-grpc-prometheus client metrics, which record the started and handled (by
+// response code) counts of RPCs.
+func enableHandlingTimeHistogram() {
+	enableHistogramOnce.Do(func() {
+		grpc_prometheus.EnableClientHandlingTimeHistogram(grpc_prometheus.WithHistogramBuckets(metrics.MinuteDistributionBuckets))
+	})
+}
+
+// UnaryClientInterceptor returns a grpc.UnaryClientInterceptor that records the
+// latency, response code, and in flight count of unary RPCs.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	enableHandlingTimeHistogram()
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		service, name := splitMethodName(method)
+		inFlight := inFlightGauge.WithLabelValues(unary, service, name)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		return grpc_prometheus.UnaryClientInterceptor(ctx, method, req, reply, cc, invoker, opts...)
+	}
+}
+
+// StreamClientInterceptor returns a grpc.StreamClientInterceptor that records
+// the latency, response code, and in flight count of streaming RPCs.
+//
+// A stream is considered in flight until it has been fully received, or has
+// failed.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	enableHandlingTimeHistogram()
+
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		service, name := splitMethodName(method)
+		inFlight := inFlightGauge.WithLabelValues(streamType(desc), service, name)
+		inFlight.Inc()
+
+		stream, err := grpc_prometheus.StreamClientInterceptor(ctx, desc, cc, method, streamer, opts...)
+		if err != nil {
+			inFlight.Dec()
+			return nil, err
+		}
+
+		return &monitoredStream{ClientStream: stream, inFlight: inFlight}, nil
+	}
+}
+
+type monitoredStream struct {
+	grpc.ClientStream
+
+	inFlight prometheus.Gauge
+	doneOnce sync.Once
+}
+
+// RecvMsg implements grpc.ClientStream.RecvMsg.
+func (s *monitoredStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		// Any error, including io.EOF, indicates the stream is done.
+		s.doneOnce.Do(s.inFlight.Dec)
+	}
+	return err
+}
+
+func streamType(desc *grpc.StreamDesc) string {
+	switch {
+	case desc.ClientStreams && !desc.ServerStreams:
+		return clientStream
+	case !desc.ClientStreams && desc.ServerStreams:
+		return serverStream
+	default:
+		return bidiStream
+	}
+}
+
+// splitMethodName splits a full method name (/package.Service/Method) into its
+// service and method names.
+func splitMethodName(fullMethodName string) (string, string) {
+	fullMethodName = strings.TrimPrefix(fullMethodName, "/")
+	if i := strings.Index(fullMethodName, "/"); i >= 0 {
+		return fullMethodName[:i], fullMethodName[i+1:]
+	}
+	return "unknown", "unknown"
+}
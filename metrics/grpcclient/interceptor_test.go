@@ -0,0 +1,100 @@
+package grpcclient
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestDial(t *testing.T) {
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	serv := grpc.NewServer()
+	healthServ := health.NewServer()
+	healthpb.RegisterHealthServer(serv, healthServ)
+	go func() {
+		_ = serv.Serve(lis)
+	}()
+	defer serv.Stop()
+
+	cc, err := Dial(lis.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer cc.Close()
+
+	client := healthpb.NewHealthClient(cc)
+	okBefore := handledCount(t, "Check", codes.OK)
+	notFoundBefore := handledCount(t, "Check", codes.NotFound)
+
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "unknown"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	assert.Equal(t, okBefore+1, handledCount(t, "Check", codes.OK))
+	assert.Equal(t, notFoundBefore+1, handledCount(t, "Check", codes.NotFound))
+	assert.Equal(t, 0.0, testutil.ToFloat64(inFlightGauge.WithLabelValues(unary, "grpc.health.v1.Health", "Check")))
+
+	// Streams remain in flight until they are done.
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	watchInFlight := inFlightGauge.WithLabelValues(serverStream, "grpc.health.v1.Health", "Watch")
+	assert.Equal(t, 1.0, testutil.ToFloat64(watchInFlight))
+
+	cancel()
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Canceled, status.Code(err))
+	assert.Equal(t, 0.0, testutil.ToFloat64(watchInFlight))
+}
+
+func TestSplitMethodName(t *testing.T) {
+	service, method := splitMethodName("/grpc.health.v1.Health/Check")
+	assert.Equal(t, "grpc.health.v1.Health", service)
+	assert.Equal(t, "Check", method)
+
+	service, method = splitMethodName("invalid")
+	assert.Equal(t, "unknown", service)
+	assert.Equal(t, "unknown", method)
+}
+
+func handledCount(t *testing.T, method string, code codes.Code) float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+
+	for _, f := range families {
+		if f.GetName() != "grpc_client_handled_total" {
+			continue
+		}
+
+	metricLoop:
+		for _, m := range f.GetMetric() {
+			expected := map[string]string{
+				"grpc_service": "grpc.health.v1.Health",
+				"grpc_method":  method,
+				"grpc_code":    code.String(),
+			}
+			for _, l := range m.GetLabel() {
+				if v, ok := expected[l.GetName()]; ok && v != l.GetValue() {
+					continue metricLoop
+				}
+			}
+			return m.GetCounter().GetValue()
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,211 @@
+package metrics
+
+import (
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// OtherTagValue is the value that tag values are collapsed to once the number
+// of unique values for a tag exceeds the configured limit.
+const OtherTagValue = "other"
+
+type guardOptions struct {
+	allowedKeys  map[string]struct{}
+	maxTagValues int
+}
+
+// GuardOption configures a client returned by NewGuardedClient.
+type GuardOption func(o *guardOptions)
+
+// WithAllowedTagKeys configures the client to only submit tags with the
+// provided keys. Tags with other keys are dropped. By default, all tag keys are
+// allowed.
+func WithAllowedTagKeys(keys ...string) GuardOption {
+	return func(o *guardOptions) {
+		if o.allowedKeys == nil {
+			o.allowedKeys = make(map[string]struct{})
+		}
+		for _, key := range keys {
+			o.allowedKeys[normalizeTagPart(key)] = struct{}{}
+		}
+	}
+}
+
+// WithMaxTagValues configures the maximum number of unique values submitted
+// for each tag key. Once the limit is reached, new values are submitted as
+// OtherTagValue. A limit of 0 disables the cap.
+func WithMaxTagValues(max int) GuardOption {
+	return func(o *guardOptions) {
+		o.maxTagValues = max
+	}
+}
+
+var defaultGuardOptions = guardOptions{
+	maxTagValues: 100,
+}
+
+type guardedClient struct {
+	client Client
+	opts   guardOptions
+
+	mu     sync.Mutex
+	values map[string]map[string]struct{}
+}
+
+// NewGuardedClient returns a Client that protects the provided client from tag
+// cardinality explosions.
+//
+// Tags of the form "key:value" are normalized to lower case, with characters
+// other than letters, digits, '_', '-', '.' and '/' replaced by '_'. Tags whose
+// key is not allowed are dropped, and values beyond the configured number of
+// unique values per key are collapsed to OtherTagValue.
+func NewGuardedClient(client Client, opts ...GuardOption) Client {
+	c := &guardedClient{
+		client: client,
+		opts:   defaultGuardOptions,
+		values: make(map[string]map[string]struct{}),
+	}
+	for _, o := range opts {
+		o(&c.opts)
+	}
+
+	return c
+}
+
+// Count implements Client.Count
+func (c *guardedClient) Count(name string, value int64, tags []string) error {
+	return c.client.Count(name, value, c.guardTags(tags))
+}
+
+// Gauge implements Client.Gauge
+func (c *guardedClient) Gauge(name string, value float64, tags []string) error {
+	return c.client.Gauge(name, value, c.guardTags(tags))
+}
+
+// Timing implements Client.Timing
+func (c *guardedClient) Timing(name string, value time.Duration, tags []string) error {
+	return c.client.Timing(name, value, c.guardTags(tags))
+}
+
+// Histogram implements Client.Histogram
+func (c *guardedClient) Histogram(name string, value float64, tags []string) error {
+	return c.client.Histogram(name, value, c.guardTags(tags))
+}
+
+// Event implements EventClient.Event, guarding the tags of the event. If the
+// underlying client does not implement EventClient, ErrEventsUnsupported is
+// returned.
+func (c *guardedClient) Event(e *Event) error {
+	ec, ok := c.client.(EventClient)
+	if !ok {
+		return ErrEventsUnsupported
+	}
+
+	guarded := *e
+	guarded.Tags = c.guardTags(e.Tags)
+	return ec.Event(&guarded)
+}
+
+// ServiceCheck implements EventClient.ServiceCheck, guarding the tags of the
+// service check. If the underlying client does not implement EventClient,
+// ErrEventsUnsupported is returned.
+func (c *guardedClient) ServiceCheck(sc *ServiceCheck) error {
+	ec, ok := c.client.(EventClient)
+	if !ok {
+		return ErrEventsUnsupported
+	}
+
+	guarded := *sc
+	guarded.Tags = c.guardTags(sc.Tags)
+	return ec.ServiceCheck(&guarded)
+}
+
+// Close implements Client.Close
+func (c *guardedClient) Close() error {
+	return c.client.Close()
+}
+
+func (c *guardedClient) guardTags(tags []string) []string {
+	if len(tags) == 0 {
+		return tags
+	}
+
+	guarded := make([]string, 0, len(tags))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, tag := range tags {
+		key, value, hasValue := splitTag(tag)
+		key = normalizeTagPart(key)
+		if key == "" {
+			continue
+		}
+		if c.opts.allowedKeys != nil {
+			if _, ok := c.opts.allowedKeys[key]; !ok {
+				continue
+			}
+		}
+
+		if !hasValue {
+			guarded = append(guarded, key)
+			continue
+		}
+
+		value = c.limitValue(key, normalizeTagPart(value))
+		guarded = append(guarded, key+":"+value)
+	}
+
+	return guarded
+}
+
+// limitValue returns the value if it has been previously seen for the key, or
+// if the key has not reached its unique value limit. Otherwise, OtherTagValue
+// is returned.
+//
+// c.mu must be held when calling limitValue.
+func (c *guardedClient) limitValue(key, value string) string {
+	if c.opts.maxTagValues <= 0 {
+		return value
+	}
+
+	seen, ok := c.values[key]
+	if !ok {
+		seen = make(map[string]struct{})
+		c.values[key] = seen
+	}
+
+	if _, ok := seen[value]; ok {
+		return value
+	}
+	if len(seen) >= c.opts.maxTagValues {
+		return OtherTagValue
+	}
+
+	seen[value] = struct{}{}
+	return value
+}
+
+func splitTag(tag string) (key, value string, hasValue bool) {
+	idx := strings.IndexByte(tag, ':')
+	if idx < 0 {
+		return tag, "", false
+	}
+	return tag[:idx], tag[idx+1:], true
+}
+
+func normalizeTagPart(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r):
+			return r
+		case r == '_', r == '-', r == '.', r == '/':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
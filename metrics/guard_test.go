@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tagRecordingClient struct {
+	testClient
+
+	mu   sync.Mutex
+	tags [][]string
+}
+
+func (c *tagRecordingClient) Count(name string, value int64, tags []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tags = append(c.tags, tags)
+	return nil
+}
+
+func (c *tagRecordingClient) Timing(name string, value time.Duration, tags []string) error {
+	return c.Count(name, 0, tags)
+}
+
+func (c *tagRecordingClient) Event(e *Event) error {
+	return c.Count(e.Title, 0, e.Tags)
+}
+
+func (c *tagRecordingClient) ServiceCheck(sc *ServiceCheck) error {
+	return c.Count(sc.Name, 0, sc.Tags)
+}
+
+func (c *tagRecordingClient) last() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tags[len(c.tags)-1]
+}
+
+func TestGuardedClient_Normalize(t *testing.T) {
+	underlying := &tagRecordingClient{}
+	client := NewGuardedClient(underlying)
+
+	require.NoError(t, client.Count("metric", 1, []string{" Method:GetAccount Info ", "flag", "path:/v1/a?b", ":empty"}))
+	assert.Equal(t, []string{"method:getaccount_info", "flag", "path:/v1/a_b"}, underlying.last())
+
+	require.NoError(t, client.Count("metric", 1, nil))
+	assert.Nil(t, underlying.last())
+}
+
+func TestGuardedClient_AllowedKeys(t *testing.T) {
+	underlying := &tagRecordingClient{}
+	client := NewGuardedClient(underlying, WithAllowedTagKeys("Method", "code"))
+
+	require.NoError(t, client.Timing("metric", time.Second, []string{"method:a", "user:1234", "code:ok", "other"}))
+	assert.Equal(t, []string{"method:a", "code:ok"}, underlying.last())
+}
+
+func TestGuardedClient_Events(t *testing.T) {
+	underlying := &tagRecordingClient{}
+	client := NewGuardedClient(underlying, WithAllowedTagKeys("env"))
+
+	e := &Event{Title: "deploy", Text: "text", Tags: []string{"Env:Prod", "user:1234"}}
+	require.NoError(t, SendEvent(client, e))
+	assert.Equal(t, []string{"env:prod"}, underlying.last())
+	assert.Equal(t, []string{"Env:Prod", "user:1234"}, e.Tags)
+
+	require.NoError(t, SendServiceCheck(client, &ServiceCheck{Name: "check", Tags: []string{"env:prod", "host:a"}}))
+	assert.Equal(t, []string{"env:prod"}, underlying.last())
+
+	unsupported := NewGuardedClient(testClient{})
+	assert.Equal(t, ErrEventsUnsupported, SendEvent(unsupported, &Event{Title: "title", Text: "text"}))
+	assert.Equal(t, ErrEventsUnsupported, SendServiceCheck(unsupported, &ServiceCheck{Name: "check"}))
+}
+
+func TestGuardedClient_MaxTagValues(t *testing.T) {
+	underlying := &tagRecordingClient{}
+	client := NewGuardedClient(underlying, WithMaxTagValues(2))
+
+	for i := 0; i < 4; i++ {
+		require.NoError(t, client.Count("metric", 1, []string{fmt.Sprintf("user:%d", i), "code:ok"}))
+	}
+	assert.Equal(t, [][]string{
+		{"user:0", "code:ok"},
+		{"user:1", "code:ok"},
+		{"user:other", "code:ok"},
+		{"user:other", "code:ok"},
+	}, underlying.tags)
+
+	// Previously seen values continue to be submitted.
+	require.NoError(t, client.Count("metric", 1, []string{"user:1"}))
+	assert.Equal(t, []string{"user:1"}, underlying.last())
+
+	unlimited := NewGuardedClient(underlying, WithMaxTagValues(0))
+	for i := 0; i < 200; i++ {
+		require.NoError(t, unlimited.Count("metric", 1, []string{fmt.Sprintf("user:%d", i)}))
+	}
+	assert.Equal(t, []string{"user:199"}, underlying.last())
+}
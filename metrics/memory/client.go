@@ -2,6 +2,8 @@ package memory
 
 import (
 	"fmt"
+	"math"
+	"sort"
 	"sync"
 	"time"
 
@@ -39,22 +41,49 @@ type TimingRecord struct {
 	Tags  []string
 }
 
+// HistogramRecord is a record of a call to Histogram
+type HistogramRecord struct {
+	Name  string
+	Value float64
+	Tags  []string
+
+	// Bucket is the upper bound of the smallest configured histogram bucket
+	// (see metrics.WithHistogramBuckets) that contains the value, or +Inf if
+	// no configured bucket contains it.
+	Bucket float64
+}
+
+// Client is an in-memory metrics client, which records all submitted metrics.
+// It is intended for asserting metrics in tests.
 type Client struct {
 	sync.Mutex
-	countRecords  []CountRecord
-	gaugeRecords  []GaugeRecord
-	timingRecords []TimingRecord
-	config        *metrics.ClientConfig
+	countRecords     []CountRecord
+	gaugeRecords     []GaugeRecord
+	timingRecords    []TimingRecord
+	histogramRecords []HistogramRecord
+	events           []metrics.Event
+	serviceChecks    []metrics.ServiceCheck
+	config           *metrics.ClientConfig
+	buckets          []float64
 }
 
-// newClient returns an in-memory metrics client
-func newClient(config *metrics.ClientConfig) (metrics.Client, error) {
+// NewClient returns an in-memory metrics client.
+func NewClient(config *metrics.ClientConfig) *Client {
+	buckets := append([]float64(nil), config.HistogramBuckets...)
+	sort.Float64s(buckets)
+
 	return &Client{
-		countRecords:  make([]CountRecord, 0),
-		gaugeRecords:  make([]GaugeRecord, 0),
-		timingRecords: make([]TimingRecord, 0),
-		config:        config,
-	}, nil
+		countRecords:     make([]CountRecord, 0),
+		gaugeRecords:     make([]GaugeRecord, 0),
+		timingRecords:    make([]TimingRecord, 0),
+		histogramRecords: make([]HistogramRecord, 0),
+		config:           config,
+		buckets:          buckets,
+	}
+}
+
+func newClient(config *metrics.ClientConfig) (metrics.Client, error) {
+	return NewClient(config), nil
 }
 
 // Count implements metrics.Client.Count
@@ -99,40 +128,186 @@ func (c *Client) Timing(name string, value time.Duration, tags []string) error {
 	return nil
 }
 
-// getCountRecords returns the count records that have been tracked so far.
-func (c *Client) getCountRecords() []CountRecord {
+// Histogram implements metrics.Client.Histogram
+func (c *Client) Histogram(name string, value float64, tags []string) error {
+	c.Lock()
+	defer c.Unlock()
+
+	tags = append(tags, c.config.GlobalTags...)
+	c.histogramRecords = append(c.histogramRecords, HistogramRecord{
+		Name:   fmt.Sprintf(metricFormat, c.config.Namespace, name),
+		Value:  value,
+		Tags:   tags,
+		Bucket: c.bucket(value),
+	})
+	return nil
+}
+
+// bucket returns the upper bound of the smallest bucket containing the value.
+func (c *Client) bucket(value float64) float64 {
+	if i := sort.SearchFloat64s(c.buckets, value); i < len(c.buckets) {
+		return c.buckets[i]
+	}
+	return math.Inf(1)
+}
+
+// Event implements metrics.EventClient.Event
+func (c *Client) Event(e *metrics.Event) error {
+	c.Lock()
+	defer c.Unlock()
+
+	recorded := *e
+	recorded.Tags = append(append([]string(nil), e.Tags...), c.config.GlobalTags...)
+	c.events = append(c.events, recorded)
+	return nil
+}
+
+// ServiceCheck implements metrics.EventClient.ServiceCheck
+func (c *Client) ServiceCheck(sc *metrics.ServiceCheck) error {
+	c.Lock()
+	defer c.Unlock()
+
+	recorded := *sc
+	recorded.Tags = append(append([]string(nil), sc.Tags...), c.config.GlobalTags...)
+	c.serviceChecks = append(c.serviceChecks, recorded)
+	return nil
+}
+
+// RecordFilter filters the records returned by a Client.
+type RecordFilter func(name string, tags []string) bool
+
+// WithName filters records to those with the provided name. Note that record
+// names include the client's namespace.
+func WithName(name string) RecordFilter {
+	return func(recordName string, _ []string) bool {
+		return recordName == name
+	}
+}
+
+// WithTags filters records to those that include all of the provided tags.
+func WithTags(tags ...string) RecordFilter {
+	return func(_ string, recordTags []string) bool {
+		for _, tag := range tags {
+			if !containsTag(recordTags, tag) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// CountRecords returns the count records that have been tracked so far, and
+// that match all of the provided filters.
+func (c *Client) CountRecords(filters ...RecordFilter) []CountRecord {
+	c.Lock()
+	defer c.Unlock()
+
+	records := make([]CountRecord, 0, len(c.countRecords))
+	for _, r := range c.countRecords {
+		if matches(r.Name, r.Tags, filters) {
+			records = append(records, r)
+		}
+	}
+
+	return records
+}
+
+// GaugeRecords returns the gauge records that have been tracked so far, and
+// that match all of the provided filters.
+func (c *Client) GaugeRecords(filters ...RecordFilter) []GaugeRecord {
 	c.Lock()
 	defer c.Unlock()
 
-	records := make([]CountRecord, len(c.countRecords))
-	copy(records, c.countRecords)
+	records := make([]GaugeRecord, 0, len(c.gaugeRecords))
+	for _, r := range c.gaugeRecords {
+		if matches(r.Name, r.Tags, filters) {
+			records = append(records, r)
+		}
+	}
 
 	return records
 }
 
-// getGaugeRecords returns the count records that have been tracked so far.
-func (c *Client) getGaugeRecords() []GaugeRecord {
+// TimingRecords returns the timing records that have been tracked so far, and
+// that match all of the provided filters.
+func (c *Client) TimingRecords(filters ...RecordFilter) []TimingRecord {
 	c.Lock()
 	defer c.Unlock()
 
-	records := make([]GaugeRecord, len(c.gaugeRecords))
-	copy(records, c.gaugeRecords)
+	records := make([]TimingRecord, 0, len(c.timingRecords))
+	for _, r := range c.timingRecords {
+		if matches(r.Name, r.Tags, filters) {
+			records = append(records, r)
+		}
+	}
 
 	return records
 }
 
-// getTimingRecords returns the count records that have been tracked so far.
-func (c *Client) getTimingRecords() []TimingRecord {
+// HistogramRecords returns the histogram records that have been tracked so far, and
+// that match all of the provided filters.
+func (c *Client) HistogramRecords(filters ...RecordFilter) []HistogramRecord {
 	c.Lock()
 	defer c.Unlock()
 
-	records := make([]TimingRecord, len(c.timingRecords))
-	copy(records, c.timingRecords)
+	records := make([]HistogramRecord, 0, len(c.histogramRecords))
+	for _, r := range c.histogramRecords {
+		if matches(r.Name, r.Tags, filters) {
+			records = append(records, r)
+		}
+	}
 
 	return records
 }
 
+// Events returns the events that have been submitted so far.
+func (c *Client) Events() []metrics.Event {
+	c.Lock()
+	defer c.Unlock()
+
+	return append([]metrics.Event(nil), c.events...)
+}
+
+// ServiceChecks returns the service checks that have been submitted so far.
+func (c *Client) ServiceChecks() []metrics.ServiceCheck {
+	c.Lock()
+	defer c.Unlock()
+
+	return append([]metrics.ServiceCheck(nil), c.serviceChecks...)
+}
+
+// Reset clears all of the records that have been tracked so far.
+func (c *Client) Reset() {
+	c.Lock()
+	defer c.Unlock()
+
+	c.countRecords = make([]CountRecord, 0)
+	c.gaugeRecords = make([]GaugeRecord, 0)
+	c.timingRecords = make([]TimingRecord, 0)
+	c.histogramRecords = make([]HistogramRecord, 0)
+	c.events = nil
+	c.serviceChecks = nil
+}
+
 // Close implements metrics.Client.Close
 func (c *Client) Close() error {
 	return nil
 }
+
+func matches(name string, tags []string, filters []RecordFilter) bool {
+	for _, f := range filters {
+		if !f(name, tags) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package memory
 
 import (
 	"fmt"
+	"math"
 	"testing"
 	"time"
 
@@ -42,7 +43,7 @@ func TestCount(t *testing.T) {
 		require.NoError(t, client.Count(record.Name, record.Value, record.Tags))
 	}
 
-	actualRecords := client.(*Client).getCountRecords()
+	actualRecords := client.(*Client).CountRecords()
 	assert.Equal(t, 3, len(actualRecords))
 
 	for idx, actual := range actualRecords {
@@ -83,7 +84,7 @@ func TestGauge(t *testing.T) {
 		require.NoError(t, client.Gauge(record.Name, record.Value, record.Tags))
 	}
 
-	actualRecords := client.(*Client).getGaugeRecords()
+	actualRecords := client.(*Client).GaugeRecords()
 	assert.Equal(t, 3, len(actualRecords))
 
 	for idx, actual := range actualRecords {
@@ -124,7 +125,7 @@ func TestTiming(t *testing.T) {
 		require.NoError(t, client.Timing(record.Name, record.Value, record.Tags))
 	}
 
-	actualRecords := client.(*Client).getTimingRecords()
+	actualRecords := client.(*Client).TimingRecords()
 	assert.Equal(t, 3, len(actualRecords))
 
 	for idx, actual := range actualRecords {
@@ -133,3 +134,126 @@ func TestTiming(t *testing.T) {
 		assert.Equal(t, append(records[idx].Tags, config.GlobalTags...), actual.Tags)
 	}
 }
+
+func TestHistogram(t *testing.T) {
+	config := &metrics.ClientConfig{
+		Namespace:  "test",
+		GlobalTags: []string{"testtag"},
+	}
+
+	client, err := newClient(config)
+	require.NoError(t, err)
+
+	records := []HistogramRecord{
+		{
+			Name:  "metric1",
+			Value: 1024,
+			Tags:  []string{"tag1"},
+		},
+		{
+			Name:  "metric2",
+			Value: 0.5,
+			Tags:  []string{"tag2"},
+		},
+		{
+			Name:  "metric3",
+			Value: -1,
+			Tags:  []string{"tag3"},
+		},
+	}
+
+	for _, record := range records {
+		require.NoError(t, client.Histogram(record.Name, record.Value, record.Tags))
+	}
+
+	actualRecords := client.(*Client).HistogramRecords()
+	assert.Equal(t, 3, len(actualRecords))
+
+	for idx, actual := range actualRecords {
+		assert.Equal(t, fmt.Sprintf(metricFormat, config.Namespace, records[idx].Name), actual.Name)
+		assert.Equal(t, records[idx].Value, actual.Value)
+		assert.Equal(t, append(records[idx].Tags, config.GlobalTags...), actual.Tags)
+		assert.True(t, math.IsInf(actual.Bucket, 1))
+	}
+}
+
+func TestHistogram_Buckets(t *testing.T) {
+	client := NewClient(&metrics.ClientConfig{HistogramBuckets: []float64{10, 1, 100}})
+
+	for _, value := range []float64{0.5, 1, 50, 1000} {
+		require.NoError(t, client.Histogram("metric", value, nil))
+	}
+
+	var buckets []float64
+	for _, r := range client.HistogramRecords() {
+		buckets = append(buckets, r.Bucket)
+	}
+	assert.Equal(t, []float64{1, 1, 100, math.Inf(1)}, buckets)
+}
+
+func TestFilterAndReset(t *testing.T) {
+	client := NewClient(&metrics.ClientConfig{
+		Namespace:  "test",
+		GlobalTags: []string{"global"},
+	})
+
+	require.NoError(t, client.Count("a", 1, []string{"method:x"}))
+	require.NoError(t, client.Count("a", 2, []string{"method:y"}))
+	require.NoError(t, client.Count("b", 3, []string{"method:x"}))
+	require.NoError(t, client.Gauge("a", 4, []string{"method:x"}))
+
+	assert.Len(t, client.CountRecords(), 3)
+	assert.Len(t, client.CountRecords(WithName("test_a")), 2)
+	assert.Len(t, client.CountRecords(WithTags("method:x", "global")), 2)
+
+	filtered := client.CountRecords(WithName("test_a"), WithTags("method:y"))
+	require.Len(t, filtered, 1)
+	assert.EqualValues(t, 2, filtered[0].Value)
+
+	assert.Empty(t, client.CountRecords(WithName("a")))
+	assert.Len(t, client.GaugeRecords(WithTags("method:x")), 1)
+
+	client.Reset()
+	assert.Empty(t, client.CountRecords())
+	assert.Empty(t, client.GaugeRecords())
+
+	require.NoError(t, client.Count("a", 1, nil))
+	assert.Len(t, client.CountRecords(), 1)
+}
+
+func TestEvents(t *testing.T) {
+	client := NewClient(&metrics.ClientConfig{
+		GlobalTags: []string{"global"},
+	})
+
+	require.NoError(t, metrics.SendEvent(client, &metrics.Event{
+		Title:     "deploy",
+		Text:      "deployed v1",
+		AlertType: metrics.AlertTypeSuccess,
+		Tags:      []string{"version:v1"},
+	}))
+	require.NoError(t, metrics.SendServiceCheck(client, &metrics.ServiceCheck{
+		Name:   "dependency",
+		Status: metrics.ServiceCheckCritical,
+	}))
+
+	assert.Equal(t, []metrics.Event{
+		{
+			Title:     "deploy",
+			Text:      "deployed v1",
+			AlertType: metrics.AlertTypeSuccess,
+			Tags:      []string{"version:v1", "global"},
+		},
+	}, client.Events())
+	assert.Equal(t, []metrics.ServiceCheck{
+		{
+			Name:   "dependency",
+			Status: metrics.ServiceCheckCritical,
+			Tags:   []string{"global"},
+		},
+	}, client.ServiceChecks())
+
+	client.Reset()
+	assert.Empty(t, client.Events())
+	assert.Empty(t, client.ServiceChecks())
+}
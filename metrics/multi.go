@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"strings"
+	"time"
+)
+
+// MultiError is returned by a client created by NewMultiClient when one or more
+// of the underlying clients fail.
+type MultiError []error
+
+// Error implements error.Error.
+func (e MultiError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+type multiClient struct {
+	clients []Client
+}
+
+// NewMultiClient returns a Client that forwards all metrics to each of the
+// provided clients.
+//
+// Each client is called regardless of whether or not the other clients fail,
+// and the errors of any failed clients are returned as a MultiError.
+func NewMultiClient(clients ...Client) Client {
+	return &multiClient{
+		clients: clients,
+	}
+}
+
+// Count implements Client.Count
+func (c *multiClient) Count(name string, value int64, tags []string) error {
+	return c.forEach(func(client Client) error {
+		return client.Count(name, value, copyTags(tags))
+	})
+}
+
+// Gauge implements Client.Gauge
+func (c *multiClient) Gauge(name string, value float64, tags []string) error {
+	return c.forEach(func(client Client) error {
+		return client.Gauge(name, value, copyTags(tags))
+	})
+}
+
+// Timing implements Client.Timing
+func (c *multiClient) Timing(name string, value time.Duration, tags []string) error {
+	return c.forEach(func(client Client) error {
+		return client.Timing(name, value, copyTags(tags))
+	})
+}
+
+// Histogram implements Client.Histogram
+func (c *multiClient) Histogram(name string, value float64, tags []string) error {
+	return c.forEach(func(client Client) error {
+		return client.Histogram(name, value, copyTags(tags))
+	})
+}
+
+// Event implements EventClient.Event, submitting the event to each of the
+// clients that implement EventClient.
+func (c *multiClient) Event(e *Event) error {
+	return c.forEach(func(client Client) error {
+		if ec, ok := client.(EventClient); ok {
+			return ec.Event(e)
+		}
+		return nil
+	})
+}
+
+// ServiceCheck implements EventClient.ServiceCheck, submitting the service
+// check to each of the clients that implement EventClient.
+func (c *multiClient) ServiceCheck(sc *ServiceCheck) error {
+	return c.forEach(func(client Client) error {
+		if ec, ok := client.(EventClient); ok {
+			return ec.ServiceCheck(sc)
+		}
+		return nil
+	})
+}
+
+// Close implements Client.Close
+func (c *multiClient) Close() error {
+	return c.forEach(func(client Client) error {
+		return client.Close()
+	})
+}
+
+func (c *multiClient) forEach(f func(client Client) error) error {
+	var errs MultiError
+	for _, client := range c.clients {
+		if err := f(client); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// copyTags copies the tags for each client, as clients may append to them.
+func copyTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+	return append(make([]string, 0, len(tags)), tags...)
+}
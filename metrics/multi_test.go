@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingClient struct {
+	testClient
+	err error
+}
+
+func (c failingClient) Count(name string, value int64, tags []string) error {
+	return c.err
+}
+
+func TestMultiClient(t *testing.T) {
+	a := &tagRecordingClient{}
+	b := &tagRecordingClient{}
+	client := NewMultiClient(a, b)
+
+	tags := make([]string, 1, 4)
+	tags[0] = "method:x"
+	require.NoError(t, client.Count("metric", 1, tags))
+	assert.Equal(t, []string{"method:x"}, a.last())
+	assert.Equal(t, []string{"method:x"}, b.last())
+
+	// The tags passed to each client should not be shared.
+	a.last()[0] = "modified"
+	assert.Equal(t, []string{"method:x"}, b.last())
+	assert.Equal(t, "method:x", tags[0])
+
+	assert.NoError(t, client.Gauge("metric", 1, nil))
+	assert.NoError(t, client.Close())
+}
+
+func TestMultiClient_ErrorIsolation(t *testing.T) {
+	errA := errors.New("a")
+	errB := errors.New("b")
+	recording := &tagRecordingClient{}
+	client := NewMultiClient(failingClient{err: errA}, recording, failingClient{err: errB})
+
+	err := client.Count("metric", 1, []string{"tag"})
+	require.Error(t, err)
+	assert.Equal(t, MultiError{errA, errB}, err)
+	assert.Equal(t, "a; b", err.Error())
+
+	// The healthy client should still have received the metric.
+	assert.Equal(t, []string{"tag"}, recording.last())
+}
+
+func TestSendEvent_Unsupported(t *testing.T) {
+	assert.Equal(t, ErrEventsUnsupported, SendEvent(testClient{}, &Event{Title: "title", Text: "text"}))
+	assert.Equal(t, ErrEventsUnsupported, SendServiceCheck(testClient{}, &ServiceCheck{Name: "check"}))
+
+	// Clients that do not support events are skipped by the multi client.
+	assert.NoError(t, SendEvent(NewMultiClient(testClient{}), &Event{Title: "title", Text: "text"}))
+}
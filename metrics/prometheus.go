@@ -23,7 +23,25 @@ var MinuteDistributionBuckets = append(
 // Register regsiters the provided prometheus collector, or returns
 // the previously registered metric if it exists.
 func Register(m prometheus.Collector) prometheus.Collector {
-	if err := prometheus.Register(m); err != nil {
+	return RegisterWith(prometheus.DefaultRegisterer, "", nil, m)
+}
+
+// RegisterWith registers the provided prometheus collector with the provided
+// registerer, or returns the previously registered metric if it exists.
+//
+// If set, the prefix is prepended to the names of the collector's metrics, and
+// the constant labels are added to them. This allows libraries that are used
+// more than once in a process to register their metrics without colliding,
+// and tests to use isolated registries.
+func RegisterWith(registerer prometheus.Registerer, prefix string, constLabels prometheus.Labels, m prometheus.Collector) prometheus.Collector {
+	if prefix != "" {
+		registerer = prometheus.WrapRegistererWithPrefix(prefix, registerer)
+	}
+	if len(constLabels) > 0 {
+		registerer = prometheus.WrapRegistererWith(constLabels, registerer)
+	}
+
+	if err := registerer.Register(m); err != nil {
 		if e, ok := err.(prometheus.AlreadyRegisteredError); ok {
 			return e.ExistingCollector
 		}
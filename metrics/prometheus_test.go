@@ -5,6 +5,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRegistration(t *testing.T) {
@@ -18,3 +19,38 @@ func TestRegistration(t *testing.T) {
 	x = Register(c)
 	assert.Equal(t, c, x)
 }
+
+func TestRegisterWith(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	a := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "my_metric",
+	})
+	b := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "my_metric",
+	})
+
+	// The same metric can be registered more than once, with different
+	// prefixes or labels.
+	assert.Equal(t, a, RegisterWith(registry, "a_", nil, a))
+	assert.Equal(t, b, RegisterWith(registry, "b_", prometheus.Labels{"instance": "b"}, b))
+
+	// Previously registered metrics are returned.
+	c := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "my_metric",
+	})
+	assert.Equal(t, a, RegisterWith(registry, "a_", nil, c))
+
+	a.Inc()
+	b.Add(2)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 2)
+	assert.Equal(t, "a_my_metric", families[0].GetName())
+	assert.EqualValues(t, 1, families[0].GetMetric()[0].GetCounter().GetValue())
+	assert.Equal(t, "b_my_metric", families[1].GetName())
+	assert.EqualValues(t, 2, families[1].GetMetric()[0].GetCounter().GetValue())
+	assert.Equal(t, "instance", families[1].GetMetric()[0].GetLabel()[0].GetName())
+	assert.Equal(t, "b", families[1].GetMetric()[0].GetLabel()[0].GetValue())
+}
@@ -3,6 +3,8 @@ package statsd
 import (
 	"os"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/DataDog/datadog-go/statsd"
@@ -18,6 +20,11 @@ const (
 	connAddrEnvVar   = "METRICS_CONN_ADDR"
 	bufferEnvVar     = "METRICS_BUFFER"
 	sampleRateEnvVar = "METRICS_SAMPLE_RATE"
+	telemetryEnvVar  = "METRICS_TELEMETRY"
+
+	// udsWriteTimeoutEnvVar configures the timeout after which packets written
+	// to a Unix domain socket are dropped.
+	udsWriteTimeoutEnvVar = "METRICS_UDS_WRITE_TIMEOUT"
 
 	defaultConnStr    = "localhost:8125"
 	defaultBuffer     = 128
@@ -32,9 +39,18 @@ type Client struct {
 	client     *statsd.Client
 	config     *metrics.ClientConfig
 	sampleRate float64
+
+	errors uint64
 }
 
-// newClient returns a metrics.Client backed by a StatsD-based Datadog client
+// newClient returns a metrics.Client backed by a StatsD-based Datadog client.
+//
+// The connection address may either be a UDP address (host:port), or a Unix
+// domain socket address (unix:///var/run/datadog/dsd.socket).
+//
+// Unless disabled via METRICS_TELEMETRY, the client submits its own telemetry
+// (such as the number of dropped packets) to Datadog, under the
+// datadog.dogstatsd.client namespace.
 func newClient(config *metrics.ClientConfig) (metrics.Client, error) {
 	log := logrus.StandardLogger().WithField("type", "metrics/statsd")
 
@@ -42,7 +58,11 @@ func newClient(config *metrics.ClientConfig) (metrics.Client, error) {
 	var buffer int
 	var sampleRate float64
 
-	connAddr = os.Getenv(connAddrEnvVar)
+	// Explicitly configured values take precedence over the environment.
+	connAddr = config.Address
+	if len(connAddr) == 0 {
+		connAddr = os.Getenv(connAddrEnvVar)
+	}
 	if len(connAddr) == 0 {
 		log.Infof("connection address not configured, using default (%s)", defaultConnStr)
 		connAddr = defaultConnStr
@@ -61,7 +81,9 @@ func newClient(config *metrics.ClientConfig) (metrics.Client, error) {
 	}
 
 	sampleRateStr := os.Getenv(sampleRateEnvVar)
-	if len(sampleRateStr) == 0 {
+	if config.SampleRate > 0 {
+		sampleRate = config.SampleRate
+	} else if len(sampleRateStr) == 0 {
 		log.Infof("sample rate not configured, using default (%.2f)", defaultSampleRate)
 		sampleRate = defaultSampleRate
 	} else {
@@ -72,7 +94,35 @@ func newClient(config *metrics.ClientConfig) (metrics.Client, error) {
 		sampleRate = parsed
 	}
 
-	client, err := statsd.NewBuffered(connAddr, buffer)
+	statsdOpts := []statsd.Option{
+		statsd.WithMaxMessagesPerPayload(buffer),
+	}
+
+	if telemetryStr := os.Getenv(telemetryEnvVar); len(telemetryStr) > 0 {
+		telemetry, err := strconv.ParseBool(telemetryStr)
+		if err != nil {
+			return nil, errors.Errorf("configured telemetry invalid (%s)", telemetryStr)
+		}
+		if !telemetry {
+			statsdOpts = append(statsdOpts, statsd.WithoutTelemetry())
+		}
+	}
+
+	if strings.HasPrefix(connAddr, statsd.UnixAddressPrefix) {
+		if len(connAddr) == len(statsd.UnixAddressPrefix) {
+			return nil, errors.Errorf("configured connection address invalid (%s)", connAddr)
+		}
+
+		if timeoutStr := os.Getenv(udsWriteTimeoutEnvVar); len(timeoutStr) > 0 {
+			timeout, err := time.ParseDuration(timeoutStr)
+			if err != nil {
+				return nil, errors.Errorf("configured uds write timeout invalid (%s)", timeoutStr)
+			}
+			statsdOpts = append(statsdOpts, statsd.WithWriteTimeoutUDS(timeout))
+		}
+	}
+
+	client, err := statsd.New(connAddr, statsdOpts...)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create statsd client")
 	}
@@ -89,20 +139,63 @@ func newClient(config *metrics.ClientConfig) (metrics.Client, error) {
 
 // Count implements metrics.Client.Count
 func (c *Client) Count(name string, value int64, tags []string) error {
-	return c.client.Count(name, value, tags, c.sampleRate)
+	return c.track(c.client.Count(name, value, tags, c.sampleRate))
 }
 
 // Gauge implements metrics.Client.Gauge
 func (c *Client) Gauge(name string, value float64, tags []string) error {
-	return c.client.Gauge(name, value, tags, c.sampleRate)
+	return c.track(c.client.Gauge(name, value, tags, c.sampleRate))
 }
 
 // Timing implements metrics.Client.Timing
 func (c *Client) Timing(name string, value time.Duration, tags []string) error {
 	// By default .XXth_percentile is added as a suffix to the name for us
-	return c.client.Timing(name, value, tags, c.sampleRate)
+	return c.track(c.client.Timing(name, value, tags, c.sampleRate))
+}
+
+// Histogram implements metrics.Client.Histogram
+func (c *Client) Histogram(name string, value float64, tags []string) error {
+	return c.track(c.client.Histogram(name, value, tags, c.sampleRate))
+}
+
+// Event implements metrics.EventClient.Event
+func (c *Client) Event(e *metrics.Event) error {
+	return c.track(c.client.Event(&statsd.Event{
+		Title:          e.Title,
+		Text:           e.Text,
+		AlertType:      statsd.EventAlertType(e.AlertType),
+		AggregationKey: e.AggregationKey,
+		Timestamp:      e.Timestamp,
+		Tags:           e.Tags,
+	}))
+}
+
+// ServiceCheck implements metrics.EventClient.ServiceCheck
+func (c *Client) ServiceCheck(sc *metrics.ServiceCheck) error {
+	return c.track(c.client.ServiceCheck(&statsd.ServiceCheck{
+		Name:      sc.Name,
+		Status:    statsd.ServiceCheckStatus(sc.Status),
+		Message:   sc.Message,
+		Timestamp: sc.Timestamp,
+		Tags:      sc.Tags,
+	}))
 }
 
 func (c *Client) Close() error {
 	return c.client.Close()
 }
+
+// Errors returns the number of metrics that the client failed to submit.
+//
+// Note that packets dropped by the underlying transport are not included, and
+// are instead reported by the client telemetry.
+func (c *Client) Errors() uint64 {
+	return atomic.LoadUint64(&c.errors)
+}
+
+func (c *Client) track(err error) error {
+	if err != nil {
+		atomic.AddUint64(&c.errors, 1)
+	}
+	return err
+}
@@ -0,0 +1,67 @@
+package statsd
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kinecosystem/agora-common/metrics"
+)
+
+func TestClient_UDS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "statsd")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	socketPath := filepath.Join(dir, "dsd.socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client, err := newClient(&metrics.ClientConfig{
+		Namespace:  "test.",
+		GlobalTags: []string{"service:test"},
+		Address:    "unix://" + socketPath,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, client.Count("metric", 2, []string{"tag:a"}))
+	require.NoError(t, metrics.SendEvent(client, &metrics.Event{
+		Title:     "deploy",
+		Text:      "v1",
+		AlertType: metrics.AlertTypeSuccess,
+	}))
+	require.NoError(t, metrics.SendServiceCheck(client, &metrics.ServiceCheck{
+		Name:   "dependency",
+		Status: metrics.ServiceCheckCritical,
+	}))
+	require.NoError(t, client.Close())
+	assert.EqualValues(t, 0, client.(*Client).Errors())
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(buf[:n])), "\n")
+	assert.Contains(t, lines, "test.metric:2|c|#service:test,tag:a")
+	assert.Contains(t, lines, "_e{6,2}:deploy|v1|t:success|#service:test")
+	assert.Contains(t, lines, "_sc|dependency|2|#service:test")
+}
+
+func TestClient_InvalidConfig(t *testing.T) {
+	_, err := newClient(&metrics.ClientConfig{Address: "unix://"})
+	assert.Error(t, err)
+
+	require.NoError(t, os.Setenv(telemetryEnvVar, "invalid"))
+	defer os.Unsetenv(telemetryEnvVar)
+	_, err = newClient(&metrics.ClientConfig{})
+	assert.Error(t, err)
+}
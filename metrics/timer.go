@@ -64,3 +64,47 @@ func (tc *TimerContext) Stop() {
 	}
 	tc.stateMu.Unlock()
 }
+
+// Stopwatch times a single operation, submitting the elapsed time as a timing
+// metric when stopped.
+type Stopwatch struct {
+	client Client
+	name   string
+	tags   []string
+	start  time.Time
+	once   sync.Once
+}
+
+// StartTimer starts a Stopwatch for the named timing metric. It is typically
+// used as:
+//
+//	sw := metrics.StartTimer(client, "name", tags)
+//	defer sw.Stop()
+func StartTimer(client Client, name string, tags []string) *Stopwatch {
+	return &Stopwatch{
+		client: client,
+		name:   name,
+		tags:   tags,
+		start:  time.Now(),
+	}
+}
+
+// Stop submits the time elapsed since the Stopwatch was started, and returns
+// it. Only the first call to Stop submits a timing.
+func (sw *Stopwatch) Stop(additionalTags ...string) time.Duration {
+	elapsed := time.Since(sw.start)
+	sw.once.Do(func() {
+		tags := append(append(make([]string, 0, len(sw.tags)+len(additionalTags)), sw.tags...), additionalTags...)
+		_ = sw.client.Timing(sw.name, elapsed, tags)
+	})
+	return elapsed
+}
+
+// TimeFunc calls f, submitting the time it took as the named timing metric,
+// and returns the error returned by f.
+func TimeFunc(client Client, name string, tags []string, f func() error) error {
+	sw := StartTimer(client, name, tags)
+	defer sw.Stop()
+
+	return f()
+}
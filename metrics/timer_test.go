@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type timingRecordingClient struct {
+	testClient
+
+	mu      sync.Mutex
+	names   []string
+	timings []time.Duration
+	tags    [][]string
+}
+
+func (c *timingRecordingClient) Timing(name string, value time.Duration, tags []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.names = append(c.names, name)
+	c.timings = append(c.timings, value)
+	c.tags = append(c.tags, tags)
+	return nil
+}
+
+func TestStopwatch(t *testing.T) {
+	client := &timingRecordingClient{}
+
+	tags := []string{"method:a"}
+	sw := StartTimer(client, "latency", tags)
+	time.Sleep(10 * time.Millisecond)
+	elapsed := sw.Stop("code:ok")
+	assert.True(t, elapsed >= 10*time.Millisecond)
+
+	// Subsequent stops do not submit timings.
+	sw.Stop()
+
+	require.Len(t, client.timings, 1)
+	assert.Equal(t, "latency", client.names[0])
+	assert.Equal(t, elapsed, client.timings[0])
+	assert.Equal(t, []string{"method:a", "code:ok"}, client.tags[0])
+	assert.Equal(t, []string{"method:a"}, tags)
+}
+
+func TestTimeFunc(t *testing.T) {
+	client := &timingRecordingClient{}
+
+	expected := errors.New("failed")
+	err := TimeFunc(client, "latency", nil, func() error {
+		time.Sleep(10 * time.Millisecond)
+		return expected
+	})
+	assert.Equal(t, expected, err)
+
+	require.Len(t, client.timings, 1)
+	assert.True(t, client.timings[0] >= 10*time.Millisecond)
+}
@@ -0,0 +1,138 @@
+// Package ratelimit provides gRPC server interceptors that rate limit requests.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kinecosystem/agora-common/config"
+)
+
+type options struct {
+	global  *limiter
+	methods map[string]*limiter
+}
+
+// Option configures the interceptors.
+type Option func(o *options)
+
+// WithGlobalLimit configures the maximum number of requests per second across
+// all methods, allowing bursts of up to burst requests.
+//
+// The limits are read from the configs on each request, so they may be adjusted
+// at runtime. A rate that is not positive disables the limit, and a burst that
+// is not positive allows bursts of the rate (rounded up).
+func WithGlobalLimit(perSecond config.Float64, burst config.Int64) Option {
+	return func(o *options) {
+		o.global = newLimiter(perSecond, burst)
+	}
+}
+
+// WithMethodLimit configures the maximum number of requests per second to the
+// method (e.g. /package.Service/Method), in addition to the global limit.
+//
+// The limits are read from the configs in the same way as WithGlobalLimit.
+func WithMethodLimit(method string, perSecond config.Float64, burst config.Int64) Option {
+	return func(o *options) {
+		o.methods[method] = newLimiter(perSecond, burst)
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		methods: make(map[string]*limiter),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// allow returns whether or not the request to the method is allowed by the
+// method and global limits.
+func (o *options) allow(ctx context.Context, method string) bool {
+	if l, ok := o.methods[method]; ok && !l.allow(ctx) {
+		return false
+	}
+	return o.global == nil || o.global.allow(ctx)
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that rejects
+// requests exceeding the configured limits with codes.ResourceExhausted.
+func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
+	o := newOptions(opts)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !o.allow(ctx, info.FullMethod) {
+			return nil, status.Errorf(codes.ResourceExhausted, "rate limit exceeded for %s", info.FullMethod)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that rejects
+// streams exceeding the configured limits with codes.ResourceExhausted. Each
+// stream counts as a single request.
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	o := newOptions(opts)
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !o.allow(ss.Context(), info.FullMethod) {
+			return status.Errorf(codes.ResourceExhausted, "rate limit exceeded for %s", info.FullMethod)
+		}
+		return handler(srv, ss)
+	}
+}
+
+// limiter is a token bucket whose rate and burst are read from configs.
+type limiter struct {
+	perSecond config.Float64
+	burst     config.Int64
+
+	mu      sync.Mutex
+	limiter *rate.Limiter
+}
+
+func newLimiter(perSecond config.Float64, burst config.Int64) *limiter {
+	return &limiter{
+		perSecond: perSecond,
+		burst:     burst,
+	}
+}
+
+func (l *limiter) allow(ctx context.Context) bool {
+	perSecond := l.perSecond.Get(ctx)
+	if perSecond <= 0 {
+		return true
+	}
+
+	var burst int
+	if l.burst != nil {
+		burst = int(l.burst.Get(ctx))
+	}
+	if burst <= 0 {
+		burst = int(math.Ceil(perSecond))
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.limiter == nil {
+		l.limiter = rate.NewLimiter(rate.Limit(perSecond), burst)
+	} else {
+		if l.limiter.Limit() != rate.Limit(perSecond) {
+			l.limiter.SetLimit(rate.Limit(perSecond))
+		}
+		if l.limiter.Burst() != burst {
+			l.limiter.SetBurst(burst)
+		}
+	}
+
+	return l.limiter.Allow()
+}
@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kinecosystem/agora-common/config/memory"
+	"github.com/kinecosystem/agora-common/config/wrapper"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	globalRate := memory.NewConfig(float64(3))
+	methodRate := memory.NewConfig(float64(1))
+
+	interceptor := UnaryServerInterceptor(
+		WithGlobalLimit(wrapper.NewFloat64Config(globalRate, 0), nil),
+		WithMethodLimit(
+			"/test.Service/Limited",
+			wrapper.NewFloat64Config(methodRate, 0),
+			wrapper.NewInt64Config(memory.NewConfig(nil), 0),
+		),
+	)
+
+	call := func(method string) error {
+		_, err := interceptor(context.Background(), "req", &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "resp", nil
+		})
+		return err
+	}
+
+	// The method limit is exceeded first, without consuming global tokens.
+	assert.NoError(t, call("/test.Service/Limited"))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(call("/test.Service/Limited")))
+
+	// The global limit applies across all methods.
+	assert.NoError(t, call("/test.Service/Other"))
+	assert.NoError(t, call("/test.Service/Other"))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(call("/test.Service/Other")))
+
+	// Limits are adjustable at runtime, and disabled if not positive.
+	globalRate.SetValue(float64(0))
+	methodRate.SetValue(float64(0))
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, call("/test.Service/Limited"))
+	}
+
+	globalRate.SetValue(float64(1))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(call("/test.Service/Other")))
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+}
+
+func (s *testServerStream) Context() context.Context {
+	return context.Background()
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	interceptor := StreamServerInterceptor(
+		WithGlobalLimit(
+			wrapper.NewFloat64Config(memory.NewConfig(float64(1)), 0),
+			wrapper.NewInt64Config(memory.NewConfig(int64(2)), 0),
+		),
+	)
+
+	call := func() error {
+		return interceptor(nil, &testServerStream{}, &grpc.StreamServerInfo{FullMethod: "/test.Service/Stream"}, func(srv interface{}, stream grpc.ServerStream) error {
+			return nil
+		})
+	}
+
+	assert.NoError(t, call())
+	assert.NoError(t, call())
+	assert.Equal(t, codes.ResourceExhausted, status.Code(call()))
+}
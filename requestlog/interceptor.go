@@ -0,0 +1,134 @@
+// Package requestlog provides gRPC server interceptors that log requests.
+package requestlog
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/kinecosystem/agora-common/logging"
+)
+
+type options struct {
+	log           logging.Logger
+	sampleRate    float64
+	slowThreshold time.Duration
+	headers       []string
+}
+
+// Option configures the interceptors.
+type Option func(o *options)
+
+// WithLogger configures the logger that requests are logged to. By default,
+// logging.Default is used.
+//
+// A logrus entry may be used via logging.NewLogrus.
+func WithLogger(log logging.Logger) Option {
+	return func(o *options) {
+		o.log = log
+	}
+}
+
+// WithSampleRate configures the rate, between 0 and 1, at which successful
+// requests are logged. Slow and failed requests are always logged.
+func WithSampleRate(rate float64) Option {
+	return func(o *options) {
+		o.sampleRate = rate
+	}
+}
+
+// WithSlowThreshold configures the duration after which requests are
+// considered slow, and are always logged. A threshold of 0 disables slow
+// request logging.
+func WithSlowThreshold(threshold time.Duration) Option {
+	return func(o *options) {
+		o.slowThreshold = threshold
+	}
+}
+
+// WithHeaders configures the request headers (metadata keys) that are included
+// in the log entries.
+func WithHeaders(headers ...string) Option {
+	return func(o *options) {
+		for _, h := range headers {
+			o.headers = append(o.headers, strings.ToLower(h))
+		}
+	}
+}
+
+var defaultOptions = options{
+	sampleRate: 1.0,
+}
+
+func newOptions(opts []Option) *options {
+	o := defaultOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	o.log = logging.OrDefault(o.log).WithField("type", "requestlog")
+	return &o
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that logs the
+// method, response code, duration, and configured headers of requests.
+func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
+	o := newOptions(opts)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		o.logRequest(ctx, info.FullMethod, err, time.Since(start))
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that logs the
+// method, response code, duration, and configured headers of streams.
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	o := newOptions(opts)
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		o.logRequest(ss.Context(), info.FullMethod, err, time.Since(start))
+		return err
+	}
+}
+
+func (o *options) logRequest(ctx context.Context, method string, err error, duration time.Duration) {
+	code := status.Code(err)
+	slow := o.slowThreshold > 0 && duration >= o.slowThreshold
+
+	if code == codes.OK && !slow && rand.Float64() >= o.sampleRate {
+		return
+	}
+
+	log := o.log.WithFields(logging.Fields{
+		"method":      method,
+		"code":        code.String(),
+		"duration_ms": float64(duration) / float64(time.Millisecond),
+	})
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, h := range o.headers {
+			if values := md.Get(h); len(values) > 0 {
+				log = log.WithField(h, strings.Join(values, ","))
+			}
+		}
+	}
+
+	switch {
+	case code != codes.OK:
+		log.WithError(err).Warn("request failed")
+	case slow:
+		log.Warn("slow request")
+	default:
+		log.Info("request handled")
+	}
+}
@@ -0,0 +1,107 @@
+package requestlog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/kinecosystem/agora-common/logging"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	logger, hook := logtest.NewNullLogger()
+	interceptor := UnaryServerInterceptor(
+		WithLogger(logging.NewLogrus(logrus.NewEntry(logger))),
+		WithHeaders("User-Agent"),
+	)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("user-agent", "test", "other", "value"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+
+	resp, err := interceptor(ctx, "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "resp", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "resp", resp)
+
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, logrus.InfoLevel, entry.Level)
+	assert.Equal(t, "/test.Service/Method", entry.Data["method"])
+	assert.Equal(t, "OK", entry.Data["code"])
+	assert.Equal(t, "test", entry.Data["user-agent"])
+	assert.NotContains(t, entry.Data, "other")
+	assert.Contains(t, entry.Data, "duration_ms")
+}
+
+func TestUnaryServerInterceptor_Sampling(t *testing.T) {
+	logger, hook := logtest.NewNullLogger()
+	interceptor := UnaryServerInterceptor(
+		WithLogger(logging.NewLogrus(logrus.NewEntry(logger))),
+		WithSampleRate(0),
+		WithSlowThreshold(10*time.Millisecond),
+	)
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+
+	// Successful requests are not logged with a sample rate of 0.
+	_, err := interceptor(context.Background(), "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "resp", nil
+	})
+	require.NoError(t, err)
+	assert.Empty(t, hook.AllEntries())
+
+	// Failed requests are always logged.
+	_, err = interceptor(context.Background(), "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.Internal, "failed")
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	require.Len(t, hook.AllEntries(), 1)
+	assert.Equal(t, logrus.WarnLevel, hook.LastEntry().Level)
+	assert.Equal(t, "Internal", hook.LastEntry().Data["code"])
+
+	// Slow requests are always logged.
+	_, err = interceptor(context.Background(), "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		time.Sleep(20 * time.Millisecond)
+		return "resp", nil
+	})
+	require.NoError(t, err)
+	require.Len(t, hook.AllEntries(), 2)
+	assert.Equal(t, logrus.WarnLevel, hook.LastEntry().Level)
+	assert.Equal(t, "slow request", hook.LastEntry().Message)
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	logger, hook := logtest.NewNullLogger()
+	interceptor := StreamServerInterceptor(WithLogger(logging.NewLogrus(logrus.NewEntry(logger))))
+
+	ss := &testServerStream{ctx: context.Background()}
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Stream"}
+
+	err := interceptor(nil, ss, info, func(srv interface{}, stream grpc.ServerStream) error {
+		return status.Error(codes.Canceled, "canceled")
+	})
+	assert.Equal(t, codes.Canceled, status.Code(err))
+
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, "/test.Service/Stream", entry.Data["method"])
+	assert.Equal(t, "Canceled", entry.Data["code"])
+}
@@ -0,0 +1,126 @@
+package retry
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// budgetBuckets is the number of buckets a Budget's window is divided into.
+// Deposits and withdrawals expire one bucket at a time.
+const budgetBuckets = 10
+
+// Budget limits the number of retries relative to the number of attempts made
+// over a sliding window, so that synchronized retries during an outage do not
+// amplify the load on the downstream service. A Budget is safe for concurrent
+// use, and is intended to be shared by all of the call sites that call the same
+// service.
+//
+// Each attempt made by an action wrapped with Wrap (or WrapWithContext)
+// deposits ratio tokens into the budget, and each retry allowed by Strategy
+// withdraws one token. In addition, minPerSecond retries per second are always
+// allowed, so that low traffic call sites can still retry.
+type Budget struct {
+	ratio   float64
+	reserve float64
+	bucket  time.Duration
+	now     func() time.Time
+
+	mu      sync.Mutex
+	buckets [budgetBuckets]budgetBucket
+}
+
+type budgetBucket struct {
+	// index is the index of the time interval the bucket contains, since the
+	// zero time.
+	index       int64
+	deposits    int64
+	withdrawals int64
+}
+
+// NewBudget returns a Budget that allows retries for up to ratio (e.g. 0.1 for
+// 10%) of the attempts made over the window, plus minPerSecond retries per
+// second.
+func NewBudget(ratio, minPerSecond float64, window time.Duration) *Budget {
+	bucket := window / budgetBuckets
+	if bucket <= 0 {
+		bucket = 1
+	}
+
+	return &Budget{
+		ratio:   ratio,
+		reserve: minPerSecond * window.Seconds(),
+		bucket:  bucket,
+		now:     time.Now,
+	}
+}
+
+// Wrap returns an action that deposits into the budget each time it is
+// invoked.
+func (b *Budget) Wrap(action Action) Action {
+	return func() error {
+		b.deposit()
+		return action()
+	}
+}
+
+// WrapWithContext is similar to Wrap, but for actions that accept a context.
+func (b *Budget) WrapWithContext(action ContextAction) ContextAction {
+	return func(ctx context.Context) error {
+		b.deposit()
+		return action(ctx)
+	}
+}
+
+// Strategy returns a strategy that withdraws from the budget for each retry,
+// and prevents the retry if the budget is exhausted.
+//
+// Since the withdrawal is made when the strategy is evaluated, it should be
+// specified after strategies that filter out non-retriable errors or limit the
+// number of attempts, and before any strategies that induce delays.
+func (b *Budget) Strategy() Strategy {
+	return func(attempts uint, err error) bool {
+		return b.withdraw()
+	}
+}
+
+func (b *Budget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.current().deposits++
+}
+
+func (b *Budget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	current := b.current()
+	minIndex := current.index - budgetBuckets + 1
+
+	var deposits, withdrawals int64
+	for _, bucket := range b.buckets {
+		if bucket.index >= minIndex {
+			deposits += bucket.deposits
+			withdrawals += bucket.withdrawals
+		}
+	}
+
+	if b.reserve+b.ratio*float64(deposits)-float64(withdrawals) < 1 {
+		return false
+	}
+
+	current.withdrawals++
+	return true
+}
+
+// current returns the bucket for the current time, resetting it if it contains
+// an expired interval. b.mu must be held.
+func (b *Budget) current() *budgetBucket {
+	index := b.now().UnixNano() / int64(b.bucket)
+	bucket := &b.buckets[index%budgetBuckets]
+	if bucket.index != index {
+		*bucket = budgetBucket{index: index}
+	}
+	return bucket
+}
@@ -0,0 +1,69 @@
+package retry
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBudget(t *testing.T) {
+	now := time.Now()
+	b := NewBudget(0.1, 0, 10*time.Second)
+	b.now = func() time.Time { return now }
+
+	// Without any deposits, no retries are allowed.
+	assert.False(t, b.Strategy()(1, errors.New("err")))
+
+	// 10 attempts allow for a single retry.
+	action := b.Wrap(func() error { return nil })
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, action())
+	}
+	assert.True(t, b.Strategy()(1, errors.New("err")))
+	assert.False(t, b.Strategy()(1, errors.New("err")))
+
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, action())
+	}
+	assert.True(t, b.Strategy()(1, errors.New("err")))
+	assert.True(t, b.Strategy()(1, errors.New("err")))
+	assert.False(t, b.Strategy()(1, errors.New("err")))
+
+	// Once the window has passed, the deposits expire.
+	now = now.Add(10 * time.Second)
+	assert.False(t, b.Strategy()(1, errors.New("err")))
+}
+
+func TestBudget_MinPerSecond(t *testing.T) {
+	now := time.Now()
+	b := NewBudget(0.1, 1, 2*time.Second)
+	b.now = func() time.Time { return now }
+
+	assert.True(t, b.Strategy()(1, errors.New("err")))
+	assert.True(t, b.Strategy()(1, errors.New("err")))
+	assert.False(t, b.Strategy()(1, errors.New("err")))
+
+	// Withdrawals expire with the window.
+	now = now.Add(2 * time.Second)
+	assert.True(t, b.Strategy()(1, errors.New("err")))
+}
+
+func TestBudget_Retry(t *testing.T) {
+	b := NewBudget(0.5, 0, time.Minute)
+
+	// The budget is shared across call sites, so the attempts made by one call
+	// site allow for retries by another.
+	for i := 0; i < 4; i++ {
+		_, err := Retry(b.Wrap(func() error { return nil }), b.Strategy())
+		assert.NoError(t, err)
+	}
+
+	attempts, err := Retry(b.Wrap(func() error { return errors.New("err") }), Limit(10), b.Strategy())
+	assert.Error(t, err)
+
+	// After the nth attempt, (4 + n) / 2 - (n - 1) tokens remain, and a retry
+	// requires at least one, which holds for n <= 4.
+	assert.EqualValues(t, 5, attempts)
+}
@@ -0,0 +1,341 @@
+// Package circuit provides a circuit breaker, which stops calls to a failing
+// dependency (such as a Solana RPC node or a webhook endpoint) until it has had
+// time to recover.
+package circuit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/kinecosystem/agora-common/metrics"
+	"github.com/kinecosystem/agora-common/retry"
+)
+
+// ErrOpen is returned when a call is rejected because the breaker is open, or
+// because the maximum number of half-open probes are already in flight.
+var ErrOpen = errors.New("circuit: breaker is open")
+
+// State is the state of a Breaker.
+type State int
+
+const (
+	// Closed is the state in which calls are allowed, and their outcomes are
+	// tracked.
+	Closed State = iota
+
+	// HalfOpen is the state in which a limited number of probe calls are
+	// allowed, to determine whether or not the dependency has recovered.
+	HalfOpen
+
+	// Open is the state in which all calls are rejected.
+	Open
+)
+
+// String implements fmt.Stringer.
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case HalfOpen:
+		return "half_open"
+	case Open:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+var (
+	stateGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "circuit_breaker",
+		Name:      "state",
+		Help:      "State of the circuit breaker (0 = closed, 1 = half open, 2 = open)",
+	}, []string{"name"})
+	transitionCounterVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "circuit_breaker",
+		Name:      "transitions_total",
+		Help:      "Number of circuit breaker state transitions, by the new state",
+	}, []string{"name", "state"})
+	rejectedCounterVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "circuit_breaker",
+		Name:      "rejected_total",
+		Help:      "Number of calls rejected by the circuit breaker",
+	}, []string{"name"})
+)
+
+func init() {
+	stateGauge = metrics.Register(stateGauge).(*prometheus.GaugeVec)
+	transitionCounterVec = metrics.Register(transitionCounterVec).(*prometheus.CounterVec)
+	rejectedCounterVec = metrics.Register(rejectedCounterVec).(*prometheus.CounterVec)
+}
+
+// Breaker is a circuit breaker. It opens once either the consecutive failure
+// threshold or the error rate threshold is reached, rejecting all calls until
+// the open duration has elapsed. It then becomes half open, allowing a limited
+// number of probe calls. If enough probes succeed, the breaker closes, and if
+// any probe fails, the breaker opens again.
+//
+// A Breaker is safe for concurrent use.
+type Breaker struct {
+	name string
+	opts options
+	now  func() time.Time
+
+	mu                   sync.Mutex
+	state                State
+	generation           uint64
+	openedAt             time.Time
+	consecutiveFailures  uint
+	outcomes             []bool
+	nextOutcome          int
+	outcomeCount         int
+	failureCount         int
+	probesInFlight       uint
+	consecutiveSuccesses uint
+}
+
+// New returns a closed Breaker. The name identifies the breaker in its metrics.
+func New(name string, opts ...Option) *Breaker {
+	o := defaultOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	b := &Breaker{
+		name: name,
+		opts: o,
+		now:  time.Now,
+	}
+	if o.errorRateWindow > 0 {
+		b.outcomes = make([]bool, o.errorRateWindow)
+	}
+	stateGauge.WithLabelValues(name).Set(float64(Closed))
+
+	return b
+}
+
+// State returns the current state of the breaker.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.checkOpenDuration()
+	return b.state
+}
+
+// Ticket identifies a call that was allowed by Allow, and the state of the
+// breaker at the time.
+type Ticket struct {
+	generation uint64
+	probe      bool
+}
+
+// Allow returns ErrOpen if a call should not be made. Otherwise, the outcome
+// of the call must be reported with Record, using the returned Ticket.
+//
+// Most callers should use Do or Wrap instead.
+func (b *Breaker) Allow() (Ticket, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.checkOpenDuration()
+
+	ticket := Ticket{generation: b.generation}
+	switch b.state {
+	case Open:
+		rejectedCounterVec.WithLabelValues(b.name).Inc()
+		return Ticket{}, ErrOpen
+	case HalfOpen:
+		if b.probesInFlight >= b.opts.halfOpenProbes {
+			rejectedCounterVec.WithLabelValues(b.name).Inc()
+			return Ticket{}, ErrOpen
+		}
+		b.probesInFlight++
+		ticket.probe = true
+	}
+
+	return ticket, nil
+}
+
+// Record records the outcome of a call that was allowed by Allow.
+//
+// Outcomes of calls that were allowed before the breaker last changed state
+// are ignored, as they do not reflect the current state of the dependency.
+func (b *Breaker) Record(ticket Ticket, err error) {
+	failed := err != nil && b.opts.isFailure(err)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ticket.generation != b.generation {
+		return
+	}
+
+	switch b.state {
+	case Closed:
+		b.recordClosed(failed)
+	case HalfOpen:
+		if !ticket.probe {
+			return
+		}
+		b.probesInFlight--
+
+		if failed {
+			b.setState(Open)
+			return
+		}
+
+		b.consecutiveSuccesses++
+		if b.consecutiveSuccesses >= b.opts.halfOpenProbes {
+			b.setState(Closed)
+		}
+	}
+}
+
+// Do invokes the action if the breaker allows it, and records its outcome.
+// If the breaker does not allow the call, ErrOpen is returned.
+func (b *Breaker) Do(action retry.Action) error {
+	ticket, err := b.Allow()
+	if err != nil {
+		return err
+	}
+
+	err = action()
+	b.Record(ticket, err)
+	return err
+}
+
+// DoWithContext is similar to Do, but for actions that accept a context.
+//
+// Calls that fail because the context is done are not counted as failures,
+// since they do not indicate a problem with the dependency.
+func (b *Breaker) DoWithContext(ctx context.Context, action retry.ContextAction) error {
+	ticket, err := b.Allow()
+	if err != nil {
+		return err
+	}
+
+	err = action(ctx)
+	if err != nil && ctx.Err() != nil {
+		b.release(ticket)
+	} else {
+		b.Record(ticket, err)
+	}
+	return err
+}
+
+// Wrap returns an action that invokes the provided action via Do, for use with
+// retry.Retry.
+func (b *Breaker) Wrap(action retry.Action) retry.Action {
+	return func() error {
+		return b.Do(action)
+	}
+}
+
+// WrapWithContext returns an action that invokes the provided action via
+// DoWithContext, for use with retry.RetryWithContext.
+func (b *Breaker) WrapWithContext(action retry.ContextAction) retry.ContextAction {
+	return func(ctx context.Context) error {
+		return b.DoWithContext(ctx, action)
+	}
+}
+
+// Strategy returns a retry.Strategy that stops retrying once the breaker has
+// opened, rather than waiting out the remaining attempts. It should be used
+// with an action wrapped by Wrap (or WrapWithContext), so that the outcome of
+// each attempt is recorded. For example:
+//
+//	retry.Retry(
+//		breaker.Wrap(action),
+//		breaker.Strategy(),
+//		retry.Limit(3),
+//		retry.Backoff(backoff.BinaryExponential(time.Second), 10*time.Second),
+//	)
+func (b *Breaker) Strategy() retry.Strategy {
+	return func(attempts uint, err error) bool {
+		return !errors.Is(err, ErrOpen) && b.State() != Open
+	}
+}
+
+// release releases a call that was allowed by Allow, without recording its
+// outcome.
+func (b *Breaker) release(ticket Ticket) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ticket.probe && ticket.generation == b.generation {
+		b.probesInFlight--
+	}
+}
+
+// recordClosed records the outcome of a call while the breaker is closed,
+// opening the breaker if a threshold is reached. b.mu must be held.
+func (b *Breaker) recordClosed(failed bool) {
+	if failed {
+		b.consecutiveFailures++
+	} else {
+		b.consecutiveFailures = 0
+	}
+
+	if len(b.outcomes) > 0 {
+		if b.outcomeCount == len(b.outcomes) {
+			if b.outcomes[b.nextOutcome] {
+				b.failureCount--
+			}
+		} else {
+			b.outcomeCount++
+		}
+
+		b.outcomes[b.nextOutcome] = failed
+		if failed {
+			b.failureCount++
+		}
+		b.nextOutcome = (b.nextOutcome + 1) % len(b.outcomes)
+	}
+
+	if b.opts.consecutiveFailures > 0 && b.consecutiveFailures >= b.opts.consecutiveFailures {
+		b.setState(Open)
+		return
+	}
+
+	// The error rate is only evaluated once the window is full, so that a
+	// few failures after startup (or after closing) do not open the breaker.
+	if b.outcomeCount == len(b.outcomes) && len(b.outcomes) > 0 {
+		if float64(b.failureCount)/float64(b.outcomeCount) >= b.opts.errorRate {
+			b.setState(Open)
+		}
+	}
+}
+
+// checkOpenDuration transitions the breaker to half open if it has been open
+// for the configured duration. b.mu must be held.
+func (b *Breaker) checkOpenDuration() {
+	if b.state == Open && b.now().Sub(b.openedAt) >= b.opts.openDuration {
+		b.setState(HalfOpen)
+	}
+}
+
+// setState transitions the breaker to the provided state, resetting the
+// tracked outcomes, and invalidating the tickets of calls in flight. b.mu
+// must be held.
+func (b *Breaker) setState(state State) {
+	b.state = state
+	b.generation++
+	b.consecutiveFailures = 0
+	b.consecutiveSuccesses = 0
+	b.probesInFlight = 0
+	b.nextOutcome = 0
+	b.outcomeCount = 0
+	b.failureCount = 0
+
+	if state == Open {
+		b.openedAt = b.now()
+	}
+
+	stateGauge.WithLabelValues(b.name).Set(float64(state))
+	transitionCounterVec.WithLabelValues(b.name, state.String()).Inc()
+}
@@ -0,0 +1,217 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kinecosystem/agora-common/retry"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time { return c.now }
+
+func newTestBreaker(name string, opts ...Option) (*Breaker, *testClock) {
+	clock := &testClock{now: time.Now()}
+	b := New(name, opts...)
+	b.now = clock.Now
+	return b, clock
+}
+
+func TestBreaker_ConsecutiveFailures(t *testing.T) {
+	b, clock := newTestBreaker("consecutive", WithConsecutiveFailures(3), WithOpenDuration(time.Minute))
+	failure := errors.New("failure")
+
+	// The metrics are global, so only their changes are checked.
+	counterValue := func(c prometheus.Collector) func() float64 {
+		initial := promtestutil.ToFloat64(c)
+		return func() float64 { return promtestutil.ToFloat64(c) - initial }
+	}
+	rejected := counterValue(rejectedCounterVec.WithLabelValues("consecutive"))
+	opened := counterValue(transitionCounterVec.WithLabelValues("consecutive", "open"))
+	halfOpened := counterValue(transitionCounterVec.WithLabelValues("consecutive", "half_open"))
+	closed := counterValue(transitionCounterVec.WithLabelValues("consecutive", "closed"))
+
+	// Successes reset the consecutive failures.
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, failure, b.Do(func() error { return failure }))
+	}
+	assert.NoError(t, b.Do(func() error { return nil }))
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, failure, b.Do(func() error { return failure }))
+	}
+	assert.Equal(t, Closed, b.State())
+
+	assert.Equal(t, failure, b.Do(func() error { return failure }))
+	assert.Equal(t, Open, b.State())
+	assert.EqualValues(t, Open, promtestutil.ToFloat64(stateGauge.WithLabelValues("consecutive")))
+
+	// Calls are rejected while open.
+	assert.Equal(t, ErrOpen, b.Do(func() error {
+		t.Fatal("unexpected call")
+		return nil
+	}))
+	assert.EqualValues(t, 1, rejected())
+
+	// A failed probe reopens the breaker.
+	clock.now = clock.now.Add(time.Minute)
+	assert.Equal(t, HalfOpen, b.State())
+	assert.Equal(t, failure, b.Do(func() error { return failure }))
+	assert.Equal(t, Open, b.State())
+
+	// A successful probe closes the breaker.
+	clock.now = clock.now.Add(time.Minute)
+	assert.NoError(t, b.Do(func() error { return nil }))
+	assert.Equal(t, Closed, b.State())
+
+	assert.EqualValues(t, 2, opened())
+	assert.EqualValues(t, 2, halfOpened())
+	assert.EqualValues(t, 1, closed())
+	assert.EqualValues(t, Closed, promtestutil.ToFloat64(stateGauge.WithLabelValues("consecutive")))
+}
+
+func TestBreaker_ErrorRate(t *testing.T) {
+	b, _ := newTestBreaker("error_rate", WithConsecutiveFailures(0), WithErrorRate(0.5, 4))
+	failure := errors.New("failure")
+
+	// The rate isn't evaluated until the window is full.
+	assert.Equal(t, failure, b.Do(func() error { return failure }))
+	assert.Equal(t, failure, b.Do(func() error { return failure }))
+	assert.Equal(t, failure, b.Do(func() error { return failure }))
+	assert.Equal(t, Closed, b.State())
+
+	// 3/4 failures opens the breaker.
+	assert.NoError(t, b.Do(func() error { return nil }))
+	assert.Equal(t, Open, b.State())
+
+	b, _ = newTestBreaker("error_rate", WithConsecutiveFailures(0), WithErrorRate(0.5, 4))
+	for i := 0; i < 10; i++ {
+		err := b.Do(func() error {
+			if i%4 == 0 {
+				return failure
+			}
+			return nil
+		})
+		require.NotEqual(t, ErrOpen, err)
+	}
+	assert.Equal(t, Closed, b.State())
+}
+
+func TestBreaker_HalfOpenProbes(t *testing.T) {
+	b, clock := newTestBreaker("probes", WithConsecutiveFailures(1), WithHalfOpenProbes(2), WithOpenDuration(time.Second))
+
+	assert.Error(t, b.Do(func() error { return errors.New("failure") }))
+	clock.now = clock.now.Add(time.Second)
+
+	first, err := b.Allow()
+	require.NoError(t, err)
+	second, err := b.Allow()
+	require.NoError(t, err)
+	_, err = b.Allow()
+	assert.Equal(t, ErrOpen, err)
+
+	b.Record(first, nil)
+	assert.Equal(t, HalfOpen, b.State())
+	b.Record(second, nil)
+	assert.Equal(t, Closed, b.State())
+}
+
+func TestBreaker_StaleOutcomes(t *testing.T) {
+	b, clock := newTestBreaker("stale", WithConsecutiveFailures(1), WithOpenDuration(time.Second))
+
+	// A call is allowed while closed, but does not complete until after the
+	// breaker has opened and become half open.
+	stale, err := b.Allow()
+	require.NoError(t, err)
+
+	assert.Error(t, b.Do(func() error { return errors.New("failure") }))
+	clock.now = clock.now.Add(time.Second)
+
+	probe, err := b.Allow()
+	require.NoError(t, err)
+
+	// The stale outcome neither closes the breaker, nor frees up the probe.
+	b.Record(stale, nil)
+	assert.Equal(t, HalfOpen, b.State())
+	_, err = b.Allow()
+	assert.Equal(t, ErrOpen, err)
+
+	b.release(stale)
+	_, err = b.Allow()
+	assert.Equal(t, ErrOpen, err)
+
+	// The outcome of the probe itself does.
+	b.Record(probe, nil)
+	assert.Equal(t, Closed, b.State())
+
+	// Stale failures do not count towards the closed breaker either.
+	b.Record(probe, errors.New("failure"))
+	assert.Equal(t, Closed, b.State())
+}
+
+func TestBreaker_FailurePredicate(t *testing.T) {
+	clientErr := errors.New("client error")
+	b, _ := newTestBreaker("predicate", WithConsecutiveFailures(1), WithFailurePredicate(func(err error) bool {
+		return err != clientErr
+	}))
+
+	assert.Equal(t, clientErr, b.Do(func() error { return clientErr }))
+	assert.Equal(t, Closed, b.State())
+	assert.Error(t, b.Do(func() error { return errors.New("server error") }))
+	assert.Equal(t, Open, b.State())
+}
+
+func TestBreaker_DoWithContext(t *testing.T) {
+	b, _ := newTestBreaker("context", WithConsecutiveFailures(1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Failures due to the context are not recorded.
+	err := b.DoWithContext(ctx, func(ctx context.Context) error { return ctx.Err() })
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, Closed, b.State())
+
+	err = b.DoWithContext(context.Background(), func(ctx context.Context) error { return errors.New("failure") })
+	assert.Error(t, err)
+	assert.Equal(t, Open, b.State())
+}
+
+func TestBreaker_Strategy(t *testing.T) {
+	b, _ := newTestBreaker("strategy", WithConsecutiveFailures(2))
+
+	var calls int
+	attempts, err := retry.Retry(
+		b.Wrap(func() error {
+			calls++
+			return errors.New("failure")
+		}),
+		b.Strategy(),
+		retry.Limit(5),
+	)
+	assert.Error(t, err)
+	assert.EqualValues(t, 2, attempts)
+	assert.Equal(t, 2, calls)
+
+	// Once open, no further attempts are made.
+	attempts, err = retry.Retry(
+		b.Wrap(func() error {
+			calls++
+			return nil
+		}),
+		b.Strategy(),
+		retry.Limit(5),
+	)
+	assert.Equal(t, ErrOpen, err)
+	assert.EqualValues(t, 1, attempts)
+	assert.Equal(t, 2, calls)
+}
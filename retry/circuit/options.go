@@ -0,0 +1,79 @@
+package circuit
+
+import (
+	"time"
+)
+
+type options struct {
+	consecutiveFailures uint
+	errorRate           float64
+	errorRateWindow     int
+	openDuration        time.Duration
+	halfOpenProbes      uint
+	isFailure           func(err error) bool
+}
+
+// Option configures a Breaker.
+type Option func(o *options)
+
+// WithConsecutiveFailures configures the breaker to open after the specified
+// number of consecutive failures. A threshold of 0 disables the check.
+//
+// The default threshold is 5.
+func WithConsecutiveFailures(threshold uint) Option {
+	return func(o *options) {
+		o.consecutiveFailures = threshold
+	}
+}
+
+// WithErrorRate configures the breaker to open once the rate of failures over
+// the last window calls reaches the threshold (between 0 and 1). The rate is
+// only evaluated once window calls have been made. A window of 0 disables the
+// check, which is the default.
+func WithErrorRate(threshold float64, window int) Option {
+	return func(o *options) {
+		o.errorRate = threshold
+		o.errorRateWindow = window
+	}
+}
+
+// WithOpenDuration configures how long the breaker stays open before allowing
+// probe calls.
+//
+// The default duration is 30 seconds.
+func WithOpenDuration(d time.Duration) Option {
+	return func(o *options) {
+		o.openDuration = d
+	}
+}
+
+// WithHalfOpenProbes configures the number of probe calls that are allowed
+// concurrently while the breaker is half open, which is also the number of
+// probes that must succeed for the breaker to close.
+//
+// The default is 1.
+func WithHalfOpenProbes(probes uint) Option {
+	return func(o *options) {
+		if probes > 0 {
+			o.halfOpenProbes = probes
+		}
+	}
+}
+
+// WithFailurePredicate configures which errors count as failures. Errors that
+// do not count as failures are recorded as successes, such as client errors
+// that do not indicate a problem with the dependency.
+//
+// By default, all errors count as failures.
+func WithFailurePredicate(isFailure func(err error) bool) Option {
+	return func(o *options) {
+		o.isFailure = isFailure
+	}
+}
+
+var defaultOptions = options{
+	consecutiveFailures: 5,
+	openDuration:        30 * time.Second,
+	halfOpenProbes:      1,
+	isFailure:           func(err error) bool { return true },
+}
@@ -0,0 +1,97 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Hedge invokes the first action, and if it has not succeeded within delay,
+// invokes the next action alongside it, and so on, until one of them succeeds.
+// The index of the successful action is returned, and the contexts of the
+// other actions are cancelled. For example, to hedge a read across multiple
+// Solana endpoints:
+//
+//	results := make([]uint64, len(clients))
+//	actions := make([]retry.ContextAction, len(clients))
+//	for i, c := range clients {
+//		i, c := i, c
+//		actions[i] = func(ctx context.Context) (err error) {
+//			results[i], err = solana.WithContext(ctx, c).GetSlot(solana.CommitmentMax)
+//			return err
+//		}
+//	}
+//	i, err := retry.Hedge(ctx, 100*time.Millisecond, actions...)
+//
+// If an action fails, the next action is invoked immediately. If all of the
+// actions fail, the error of the last one to fail is returned. If the context
+// is done first, its error is returned.
+//
+// Hedge does not wait for the cancelled actions to return, so each action
+// should write its results to a separate location, as above.
+func Hedge(ctx context.Context, delay time.Duration, actions ...ContextAction) (int, error) {
+	if len(actions) == 0 {
+		return -1, errors.New("retry: no actions to hedge")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		index int
+		err   error
+	}
+
+	// The channel is buffered so that the actions that are still in flight
+	// when Hedge returns do not block.
+	resultCh := make(chan result, len(actions))
+	start := func(i int) {
+		go func() {
+			resultCh <- result{index: i, err: actions[i](ctx)}
+		}()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	start(0)
+	started, pending := 1, 1
+
+	var lastErr error
+	for {
+		select {
+		case <-ctx.Done():
+			return -1, ctx.Err()
+		case <-timer.C:
+			if started < len(actions) {
+				start(started)
+				started++
+				pending++
+				timer.Reset(delay)
+			}
+		case r := <-resultCh:
+			if r.err == nil {
+				return r.index, nil
+			}
+
+			lastErr = r.err
+			pending--
+
+			if started < len(actions) {
+				start(started)
+				started++
+				pending++
+
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				timer.Reset(delay)
+			} else if pending == 0 {
+				return -1, lastErr
+			}
+		}
+	}
+}
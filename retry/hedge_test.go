@@ -0,0 +1,91 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHedge(t *testing.T) {
+	cancelledCh := make(chan struct{})
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		close(cancelledCh)
+		return ctx.Err()
+	}
+	fast := func(ctx context.Context) error {
+		return nil
+	}
+
+	// The second action is started after the delay, and the first is
+	// cancelled once it succeeds.
+	start := time.Now()
+	i, err := Hedge(context.Background(), 50*time.Millisecond, slow, fast)
+	require.NoError(t, err)
+	assert.Equal(t, 1, i)
+	assert.True(t, time.Since(start) >= 50*time.Millisecond)
+
+	select {
+	case <-cancelledCh:
+	case <-time.After(time.Second):
+		t.Fatal("slow action was not cancelled")
+	}
+
+	// Actions that complete within the delay are not hedged.
+	i, err = Hedge(context.Background(), time.Minute, fast, func(ctx context.Context) error {
+		t.Fatal("unexpected hedge")
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, i)
+}
+
+func TestHedge_Failures(t *testing.T) {
+	failure := errors.New("failure")
+	lastFailure := errors.New("last failure")
+
+	// Failed actions are hedged immediately.
+	start := time.Now()
+	i, err := Hedge(
+		context.Background(),
+		time.Minute,
+		func(ctx context.Context) error { return failure },
+		func(ctx context.Context) error { return nil },
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 1, i)
+	assert.True(t, time.Since(start) < time.Minute)
+
+	i, err = Hedge(
+		context.Background(),
+		time.Millisecond,
+		func(ctx context.Context) error { return failure },
+		func(ctx context.Context) error {
+			time.Sleep(10 * time.Millisecond)
+			return lastFailure
+		},
+	)
+	assert.Equal(t, lastFailure, err)
+	assert.Equal(t, -1, i)
+
+	_, err = Hedge(context.Background(), time.Millisecond)
+	assert.Error(t, err)
+}
+
+func TestHedge_Context(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	hang := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	i, err := Hedge(ctx, 10*time.Millisecond, hang, hang, hang)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, -1, i)
+}
@@ -1,13 +1,28 @@
 package retry
 
+import (
+	"context"
+)
+
 // Action is a function to be performed in a retriable manner.
 type Action func() error
 
+// ContextAction is a function to be performed in a retriable manner, which
+// should stop once the provided context is done.
+type ContextAction func(ctx context.Context) error
+
 // Retrier retries the provided action.
 type Retrier interface {
 	Retry(action Action) (uint, error)
 }
 
+// ContextRetrier is a Retrier that can also retry context-aware actions, as
+// with RetryWithContext.
+type ContextRetrier interface {
+	Retrier
+	RetryWithContext(ctx context.Context, action ContextAction) (uint, error)
+}
+
 type retrier struct {
 	strategies []Strategy
 }
@@ -21,10 +36,21 @@ func NewRetrier(strategies ...Strategy) Retrier {
 	}
 }
 
+// NewContextRetrier is similar to NewRetrier, but returns a ContextRetrier.
+func NewContextRetrier(strategies ...Strategy) ContextRetrier {
+	return &retrier{
+		strategies: strategies,
+	}
+}
+
 func (r *retrier) Retry(action Action) (uint, error) {
 	return Retry(action, r.strategies...)
 }
 
+func (r *retrier) RetryWithContext(ctx context.Context, action ContextAction) (uint, error) {
+	return RetryWithContext(ctx, action, r.strategies...)
+}
+
 // Retry executes the provided action, potentially multiple times based off of
 // the provided strategies. Retry will block until the action is successful, or
 // one of the provided strategies indicate no further retries should be performed.
@@ -38,10 +64,35 @@ func Retry(action Action, strategies ...Strategy) (uint, error) {
 			return i, nil
 		}
 
-		for _, s := range strategies {
-			if shouldRetry := s(i, err); !shouldRetry {
-				return i, err
+		if !shouldRetry(i, err, strategies) {
+			return i, err
+		}
+	}
+}
+
+// RetryWithContext is similar to Retry, but stops retrying once the context is
+// done, in which case the context's error is returned. The context is checked
+// before each attempt, and after the strategies are evaluated.
+//
+// Strategies are evaluated to completion, so strategies that delay the next
+// attempt should stop once the context is done (e.g. BackoffWithContext),
+// rather than delaying the return.
+func RetryWithContext(ctx context.Context, action ContextAction, strategies ...Strategy) (uint, error) {
+	for i := uint(1); ; i++ {
+		if err := ctx.Err(); err != nil {
+			return i - 1, err
+		}
+
+		err := action(ctx)
+		if err == nil {
+			return i, nil
+		}
+
+		if !shouldRetry(i, err, strategies) {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return i, ctxErr
 			}
+			return i, err
 		}
 	}
 }
@@ -59,10 +110,43 @@ func Loop(action Action, strategies ...Strategy) error {
 			continue
 		}
 
-		for _, s := range strategies {
-			if shouldRetry := s(i, err); !shouldRetry {
-				return err
+		if !shouldRetry(i, err, strategies) {
+			return err
+		}
+	}
+}
+
+// LoopWithContext is similar to Loop, but stops once the context is done, in
+// which case the context's error is returned. See RetryWithContext for details
+// on how the context is checked.
+func LoopWithContext(ctx context.Context, action ContextAction, strategies ...Strategy) error {
+	for i := uint(1); ; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err := action(ctx)
+		if err == nil {
+			i = 0
+			continue
+		}
+
+		if !shouldRetry(i, err, strategies) {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
 			}
+			return err
+		}
+	}
+}
+
+// shouldRetry evaluates the strategies in order, returning false as soon as one
+// of them indicates the action should not be retried.
+func shouldRetry(attempts uint, err error, strategies []Strategy) bool {
+	for _, s := range strategies {
+		if !s(attempts, err) {
+			return false
 		}
 	}
+	return true
 }
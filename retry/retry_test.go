@@ -1,6 +1,7 @@
 package retry
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -44,6 +45,21 @@ func TestRetrier(t *testing.T) {
 	assert.Equal(t, uint(5), attempts)
 }
 
+func TestContextRetrier(t *testing.T) {
+	retriableErr := errors.New("retriable")
+	r := NewContextRetrier(Limit(5), RetriableErrors(retriableErr))
+
+	attempts, err := r.RetryWithContext(context.Background(), func(ctx context.Context) error { return retriableErr })
+	assert.Equal(t, retriableErr, err)
+	assert.Equal(t, uint(5), attempts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	attempts, err = r.RetryWithContext(ctx, func(ctx context.Context) error { return nil })
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, uint(0), attempts)
+}
+
 func TestLoop(t *testing.T) {
 	ts := &testSleeper{}
 	sleeperImpl = ts
@@ -71,3 +87,103 @@ func TestLoop(t *testing.T) {
 	assert.Error(t, err, errNonRetriable)
 	assert.Equal(t, []time.Duration{1, 2, 3, 1, 2, 3, 1, 2}, ts.sleepTimes)
 }
+
+func TestRetryWithContext(t *testing.T) {
+	sleeperImpl = &testSleeper{}
+
+	retriableErr := errors.New("retriable")
+
+	var calls int
+	attempts, err := RetryWithContext(
+		context.Background(),
+		func(ctx context.Context) error {
+			calls++
+			if calls < 3 {
+				return retriableErr
+			}
+			return nil
+		},
+		Limit(5),
+		RetriableErrors(retriableErr),
+	)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, attempts)
+
+	attempts, err = RetryWithContext(
+		context.Background(),
+		func(ctx context.Context) error { return retriableErr },
+		Limit(5),
+		RetriableErrors(retriableErr),
+	)
+	assert.Equal(t, retriableErr, err)
+	assert.EqualValues(t, 5, attempts)
+
+	// No attempts are made with a context that is already done.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	attempts, err = RetryWithContext(ctx, func(ctx context.Context) error {
+		t.Fatal("unexpected attempt")
+		return nil
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.EqualValues(t, 0, attempts)
+}
+
+func TestRetryWithContext_CancelDuringBackoff(t *testing.T) {
+	sleeperImpl = &realSleeper{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// The delay is interrupted by the context, rather than delaying the return.
+	start := time.Now()
+	attempts, err := RetryWithContext(
+		ctx,
+		func(ctx context.Context) error { return errors.New("err") },
+		BackoffWithContext(ctx, backoff.Constant(time.Minute), time.Minute),
+	)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.EqualValues(t, 1, attempts)
+	assert.True(t, time.Since(start) < time.Minute)
+
+	attempts, err = RetryWithContext(
+		context.Background(),
+		func(ctx context.Context) error { return errors.New("err") },
+		Limit(3),
+		BackoffWithJitterWithContext(context.Background(), backoff.Constant(time.Millisecond), time.Millisecond, 0.1),
+	)
+	assert.EqualError(t, err, "err")
+	assert.EqualValues(t, 3, attempts)
+}
+
+func TestLoopWithContext(t *testing.T) {
+	sleeperImpl = &testSleeper{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var i int
+	err := LoopWithContext(
+		ctx,
+		func(ctx context.Context) error {
+			i++
+			if i == 10 {
+				cancel()
+			}
+			if i%2 == 0 {
+				return nil
+			}
+			return errors.New("err")
+		},
+	)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 10, i)
+
+	errNonRetriable := errors.New("non retriable")
+	err = LoopWithContext(
+		context.Background(),
+		func(ctx context.Context) error { return errNonRetriable },
+		NonRetriableErrors(errNonRetriable),
+	)
+	assert.Equal(t, errNonRetriable, err)
+}
@@ -1,6 +1,7 @@
 package retry
 
 import (
+	"context"
 	"errors"
 	"math"
 	"math/rand"
@@ -55,13 +56,21 @@ func NonRetriableErrors(nonRetriableErrors ...error) Strategy {
 // (the retrier) to sleep.
 func Backoff(strategy backoff.Strategy, maxBackoff time.Duration) Strategy {
 	return func(attempts uint, err error) bool {
-		delay := strategy(attempts)
-		cappedDelay := time.Duration(math.Min(float64(maxBackoff), float64(delay)))
-		sleeperImpl.Sleep(cappedDelay)
+		sleeperImpl.Sleep(cappedDelay(strategy, attempts, maxBackoff))
 		return true
 	}
 }
 
+// BackoffWithContext returns a strategy similar to Backoff, but the delay is
+// interrupted once the context is done, in which case no further retries are
+// performed. It should be used with RetryWithContext (or LoopWithContext),
+// using the same context.
+func BackoffWithContext(ctx context.Context, strategy backoff.Strategy, maxBackoff time.Duration) Strategy {
+	return func(attempts uint, err error) bool {
+		return sleeperImpl.SleepWithContext(ctx, cappedDelay(strategy, attempts, maxBackoff)) == nil
+	}
+}
+
 // BackoffWithJitter returns a strategy similar to Backoff, but induces a jitter
 // on the total delay. The maxBackoff is calculated before the jitter.
 //
@@ -70,18 +79,31 @@ func Backoff(strategy backoff.Strategy, maxBackoff time.Duration) Strategy {
 // of 0.1 will result in a delay of 100ms +/- 10ms.
 func BackoffWithJitter(strategy backoff.Strategy, maxBackoff time.Duration, jitter float64) Strategy {
 	return func(attempts uint, err error) bool {
-		delay := strategy(attempts)
-		cappedDelay := time.Duration(math.Min(float64(maxBackoff), float64(delay)))
-
-		// Center the jitter around the capped delay:
-		//     <------cappedDelay------>
-		//      jitter           jitter
-		cappedDelayWithJitter := time.Duration(float64(cappedDelay) * (1 + (rand.Float64()*jitter*2 - jitter)))
-		sleeperImpl.Sleep(cappedDelayWithJitter)
+		sleeperImpl.Sleep(withJitter(cappedDelay(strategy, attempts, maxBackoff), jitter))
 		return true
 	}
 }
 
+// BackoffWithJitterWithContext returns a strategy similar to BackoffWithJitter,
+// but the delay is interrupted once the context is done, as with
+// BackoffWithContext.
+func BackoffWithJitterWithContext(ctx context.Context, strategy backoff.Strategy, maxBackoff time.Duration, jitter float64) Strategy {
+	return func(attempts uint, err error) bool {
+		return sleeperImpl.SleepWithContext(ctx, withJitter(cappedDelay(strategy, attempts, maxBackoff), jitter)) == nil
+	}
+}
+
+func cappedDelay(strategy backoff.Strategy, attempts uint, maxBackoff time.Duration) time.Duration {
+	return time.Duration(math.Min(float64(maxBackoff), float64(strategy(attempts))))
+}
+
+func withJitter(delay time.Duration, jitter float64) time.Duration {
+	// Center the jitter around the capped delay:
+	//     <------cappedDelay------>
+	//      jitter           jitter
+	return time.Duration(float64(delay) * (1 + (rand.Float64()*jitter*2 - jitter)))
+}
+
 // RetriableGRPCCodes returns a strategy that specifies which GRPC status codes can be retried.
 func RetriableGRPCCodes(retriableCodes ...codes.Code) Strategy {
 	return func(attempts uint, err error) bool {
@@ -112,6 +134,10 @@ func NonRetriableGRPCCodes(nonRetriableCodes ...codes.Code) Strategy {
 
 type sleeper interface {
 	Sleep(time.Duration)
+
+	// SleepWithContext sleeps until either the duration has elapsed, or the
+	// context is done, in which case the context's error is returned.
+	SleepWithContext(context.Context, time.Duration) error
 }
 
 // realSleeper uses the time package to perform actual sleeps
@@ -119,4 +145,16 @@ type realSleeper struct{}
 
 func (r *realSleeper) Sleep(d time.Duration) { time.Sleep(d) }
 
+func (r *realSleeper) SleepWithContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
 var sleeperImpl sleeper = &realSleeper{}
@@ -1,6 +1,7 @@
 package retry
 
 import (
+	"context"
 	"math"
 	"testing"
 	"time"
@@ -148,6 +149,11 @@ func (t *testSleeper) Sleep(d time.Duration) {
 	t.sleepTimes = append(t.sleepTimes, d)
 }
 
+func (t *testSleeper) SleepWithContext(ctx context.Context, d time.Duration) error {
+	t.sleepTimes = append(t.sleepTimes, d)
+	return ctx.Err()
+}
+
 func (t *testSleeper) Total() (total time.Duration) {
 	for _, d := range t.sleepTimes {
 		total += d
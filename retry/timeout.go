@@ -0,0 +1,39 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrAttemptTimeout is returned by an action wrapped with AttemptTimeout when
+// an attempt exceeds its timeout, but the parent context is not done.
+var ErrAttemptTimeout = errors.New("retry: attempt timed out")
+
+// AttemptTimeout returns an action that bounds each attempt of the provided
+// action with its own timeout, so that a single hung attempt does not consume
+// the entire deadline of the parent context. For example:
+//
+//	retry.RetryWithContext(
+//		ctx,
+//		retry.AttemptTimeout(time.Second, action),
+//		retry.RetriableErrors(retry.ErrAttemptTimeout),
+//		retry.Limit(3),
+//	)
+//
+// Attempts that time out result in ErrAttemptTimeout, so that they can be
+// distinguished from the parent context being done. The action must respect
+// the context it is provided for the timeout to have any effect.
+func AttemptTimeout(timeout time.Duration, action ContextAction) ContextAction {
+	return func(ctx context.Context) error {
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		err := action(attemptCtx)
+		if err != nil && attemptCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+			return ErrAttemptTimeout
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,48 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttemptTimeout(t *testing.T) {
+	var calls int
+	attempts, err := RetryWithContext(
+		context.Background(),
+		AttemptTimeout(10*time.Millisecond, func(ctx context.Context) error {
+			calls++
+
+			// Hang on the first attempt, until the attempt times out.
+			if calls == 1 {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+
+			_, hasDeadline := ctx.Deadline()
+			assert.True(t, hasDeadline)
+			return nil
+		}),
+		RetriableErrors(ErrAttemptTimeout),
+		Limit(3),
+	)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, attempts)
+
+	// Errors other than timeouts are returned as is.
+	actionErr := errors.New("err")
+	err = AttemptTimeout(time.Second, func(ctx context.Context) error { return actionErr })(context.Background())
+	assert.Equal(t, actionErr, err)
+
+	// If the parent context is done, the attempt did not time out.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = AttemptTimeout(time.Second, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
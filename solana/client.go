@@ -2,6 +2,7 @@ package solana
 
 import (
 	"bytes"
+	"context"
 	"crypto/ed25519"
 	"encoding/base64"
 	"encoding/json"
@@ -12,10 +13,9 @@ import (
 
 	"github.com/mr-tron/base58"
 	"github.com/pkg/errors"
-	"github.com/prometheus/client_golang/prometheus"
-	"github.com/sirupsen/logrus"
 	"github.com/ybbus/jsonrpc"
 
+	"github.com/kinecosystem/agora-common/logging"
 	"github.com/kinecosystem/agora-common/metrics"
 	"github.com/kinecosystem/agora-common/retry"
 	"github.com/kinecosystem/agora-common/retry/backoff"
@@ -41,42 +41,6 @@ const (
 	rpcNodeUnhealthyCode = -32005
 )
 
-var (
-	rpcCounterVec = prometheus.NewCounterVec(prometheus.CounterOpts{
-		Namespace: "solana",
-		Name:      "requests_total",
-		Help:      "Number of Solana RPCs made",
-	}, []string{"method", "response_code"})
-	rpcTimings = prometheus.NewHistogramVec(prometheus.HistogramOpts{
-		Namespace: "solana",
-		Name:      "request_duration_seconds",
-	}, []string{"method"})
-	retryCount = prometheus.NewHistogramVec(prometheus.HistogramOpts{
-		Namespace: "solana",
-		Name:      "retry_count",
-		Buckets:   prometheus.LinearBuckets(1.0, 1.0, 3),
-	}, []string{"method"})
-	getSigStatusTimings = prometheus.NewHistogramVec(prometheus.HistogramOpts{
-		Namespace: "solana",
-		Name:      "get_signature_status_duration_seconds",
-		Help:      "Timing information for the GetSignatureStatus library call, which polls the GetSignatureStatus RPC",
-		Buckets:   metrics.MinuteDistributionBuckets,
-	}, []string{"commitment"})
-	getSigStatusRetryCount = prometheus.NewHistogramVec(prometheus.HistogramOpts{
-		Namespace: "solana",
-		Name:      "get_signature_status_retry_count",
-		Buckets:   prometheus.LinearBuckets(1.0, 1.0, sigStatusPollLimit),
-	}, []string{"commitment"})
-)
-
-func init() {
-	rpcCounterVec = metrics.Register(rpcCounterVec).(*prometheus.CounterVec)
-	rpcTimings = metrics.Register(rpcTimings).(*prometheus.HistogramVec)
-	retryCount = metrics.Register(retryCount).(*prometheus.HistogramVec)
-	getSigStatusTimings = metrics.Register(getSigStatusTimings).(*prometheus.HistogramVec)
-	getSigStatusRetryCount = metrics.Register(getSigStatusRetryCount).(*prometheus.HistogramVec)
-}
-
 type Commitment struct {
 	Commitment string `json:"commitment"`
 }
@@ -139,6 +103,11 @@ type Block struct {
 	ParentSlot uint64
 	Slot       uint64
 
+	// BlockTime is the estimated production time of the block.
+	//
+	// It will be nil if the block time is not available.
+	BlockTime *time.Time
+
 	Transactions []BlockTransaction
 }
 
@@ -149,6 +118,7 @@ type BlockTransaction struct {
 
 type ConfirmedTransaction struct {
 	Slot        uint64
+	BlockTime   *time.Time
 	Transaction Transaction
 	Err         *TransactionError
 }
@@ -188,11 +158,18 @@ type rpcResponse struct {
 }
 
 type client struct {
-	log     *logrus.Entry
+	ctx     context.Context
+	log     logging.Logger
 	client  jsonrpc.RPCClient
-	retrier retry.Retrier
+	metrics *clientMetrics
+
+	// The cache is shared by the copies of the client returned by
+	// WithContext.
+	cache *blockhashCache
+}
 
-	blockMu   sync.RWMutex
+type blockhashCache struct {
+	sync.RWMutex
 	blockhash Blockhash
 	lastWrite time.Time
 }
@@ -204,32 +181,57 @@ func New(endpoint string) Client {
 
 // NewWithRPCOptions returns a client configured with the specified RPC options.
 func NewWithRPCOptions(endpoint string, opts *jsonrpc.RPCClientOpts) Client {
+	return NewWithOptions(endpoint, WithRPCOptions(opts))
+}
+
+// NewWithOptions returns a client using the specified endpoint, configured with
+// the provided options.
+func NewWithOptions(endpoint string, opts ...Option) Client {
+	o := defaultOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	return &client{
-		log:    logrus.StandardLogger().WithField("type", "solana/client"),
-		client: jsonrpc.NewClientWithOpts(endpoint, opts),
-		retrier: retry.NewRetrier(
-			retry.RetriableErrors(errRateLimited, errServiceError),
-			retry.Limit(3),
-			retry.BackoffWithJitter(backoff.BinaryExponential(time.Second), 10*time.Second, 0.1),
-		),
+		ctx:     context.Background(),
+		log:     logging.OrDefault(o.log).WithField("type", "solana/client"),
+		client:  jsonrpc.NewClientWithOpts(endpoint, o.rpcOpts),
+		metrics: o.metrics,
+		cache:   &blockhashCache{},
+	}
+}
+
+// WithContext returns a copy of the provided Client whose retries (and polling,
+// such as in GetSignatureStatus) stop once the context is done, so that calls
+// made on behalf of a request are abandoned along with the request.
+//
+// Clients that were not created by this package are returned as is.
+func WithContext(ctx context.Context, c Client) Client {
+	impl, ok := c.(*client)
+	if !ok {
+		return c
 	}
+
+	withCtx := *impl
+	withCtx.ctx = ctx
+	return &withCtx
 }
 
 func (c *client) call(out interface{}, method string, params ...interface{}) error {
 	start := time.Now()
-	i, err := c.retrier.Retry(func() error {
+	i, err := retry.RetryWithContext(c.ctx, func(_ context.Context) error {
 		err := c.client.CallFor(out, method, params...)
 		if err == nil {
-			rpcCounterVec.WithLabelValues(method, "200").Inc()
+			c.metrics.rpcCounterVec.WithLabelValues(method, "200").Inc()
 			return nil
 		}
 
 		rpcErr, ok := err.(*jsonrpc.RPCError)
 		if !ok {
-			rpcCounterVec.WithLabelValues(method, "").Inc()
+			c.metrics.rpcCounterVec.WithLabelValues(method, "").Inc()
 			return err
 		}
-		rpcCounterVec.WithLabelValues(method, strconv.Itoa(rpcErr.Code)).Inc()
+		c.metrics.rpcCounterVec.WithLabelValues(method, strconv.Itoa(rpcErr.Code)).Inc()
 		if rpcErr.Code == 429 {
 			return errRateLimited
 		}
@@ -238,9 +240,13 @@ func (c *client) call(out interface{}, method string, params ...interface{}) err
 		}
 
 		return err
-	})
-	rpcTimings.WithLabelValues(method).Observe(time.Since(start).Seconds())
-	retryCount.WithLabelValues(method).Observe(float64(i))
+	},
+		retry.RetriableErrors(errRateLimited, errServiceError),
+		retry.Limit(3),
+		retry.BackoffWithJitterWithContext(c.ctx, backoff.BinaryExponential(time.Second), 10*time.Second, 0.1),
+	)
+	metrics.ObserveWithTraceExemplar(c.ctx, c.metrics.rpcTimings.WithLabelValues(method), time.Since(start).Seconds())
+	c.metrics.retryCount.WithLabelValues(method).Observe(float64(i))
 
 	return err
 }
@@ -270,11 +276,11 @@ func (c *client) GetRecentBlockhash() (hash Blockhash, err error) {
 	// concern when running a batch migrator with a _ton_ of goroutines.
 	window := time.Duration(float64(2*time.Second) * (0.8 + rand.Float64()))
 
-	c.blockMu.RLock()
-	if time.Since(c.lastWrite) < window {
-		hash = c.blockhash
+	c.cache.RLock()
+	if time.Since(c.cache.lastWrite) < window {
+		hash = c.cache.blockhash
 	}
-	c.blockMu.RUnlock()
+	c.cache.RUnlock()
 
 	if hash != (Blockhash{}) {
 		return hash, nil
@@ -298,10 +304,10 @@ func (c *client) GetRecentBlockhash() (hash Blockhash, err error) {
 
 	copy(hash[:], hashBytes)
 
-	c.blockMu.Lock()
-	c.blockhash = hash
-	c.lastWrite = time.Now()
-	c.blockMu.Unlock()
+	c.cache.Lock()
+	c.cache.blockhash = hash
+	c.cache.lastWrite = time.Now()
+	c.cache.Unlock()
 
 	return hash, nil
 }
@@ -327,6 +333,7 @@ func (c *client) GetConfirmedBlock(slot uint64) (block *Block, err error) {
 		Hash       string `json:"blockhash"` // Since this value is in base58, we can't []byte
 		PrevHash   string `json:"previousBlockhash"`
 		ParentSlot uint64 `json:"parentSlot"`
+		BlockTime  *int64 `json:"blockTime"`
 
 		RawTransactions []struct {
 			Transaction []string `json:"transaction"` // [string,encoding]
@@ -350,6 +357,10 @@ func (c *client) GetConfirmedBlock(slot uint64) (block *Block, err error) {
 		ParentSlot: rb.ParentSlot,
 		Slot:       slot,
 	}
+	if rb.BlockTime != nil {
+		t := time.Unix(*rb.BlockTime, 0)
+		block.BlockTime = &t
+	}
 
 	if block.Hash, err = base58.Decode(rb.Hash); err != nil {
 		return nil, errors.Wrap(err, "invalid base58 encoding for hash")
@@ -393,6 +404,7 @@ func (c *client) GetConfirmedBlocksWithLimit(start, limit uint64) (slots []uint6
 func (c *client) GetConfirmedTransaction(sig Signature) (ConfirmedTransaction, error) {
 	type rpcResponse struct {
 		Slot        uint64   `json:"slot"`
+		BlockTime   *int64   `json:"blockTime"`
 		Transaction []string `json:"transaction"` // [val, encoding]
 		Meta        *struct {
 			Err interface{} `json:"err"`
@@ -411,6 +423,10 @@ func (c *client) GetConfirmedTransaction(sig Signature) (ConfirmedTransaction, e
 	txn := ConfirmedTransaction{
 		Slot: resp.Slot,
 	}
+	if resp.BlockTime != nil {
+		t := time.Unix(*resp.BlockTime, 0)
+		txn.BlockTime = &t
+	}
 
 	var err error
 	rawTxn, err := base64.StdEncoding.DecodeString(resp.Transaction[0])
@@ -592,8 +608,9 @@ func (c *client) GetSignatureStatus(sig Signature, commitment Commitment) (*Sign
 	var s *SignatureStatus
 	errConfirmationsNotReached := errors.New("confirmations not reached")
 	start := time.Now()
-	i, err := retry.Retry(
-		func() error {
+	i, err := retry.RetryWithContext(
+		c.ctx,
+		func(_ context.Context) error {
 			statuses, err := c.GetSignatureStatuses([]Signature{sig})
 			if err != nil {
 				return err
@@ -625,10 +642,10 @@ func (c *client) GetSignatureStatus(sig Signature, commitment Commitment) (*Sign
 		},
 		retry.RetriableErrors(ErrSignatureNotFound, errConfirmationsNotReached),
 		retry.Limit(sigStatusPollLimit),
-		retry.Backoff(backoff.Constant(PollRate), PollRate),
+		retry.BackoffWithContext(c.ctx, backoff.Constant(PollRate), PollRate),
 	)
-	getSigStatusTimings.WithLabelValues(commitment.Commitment).Observe(time.Since(start).Seconds())
-	getSigStatusRetryCount.WithLabelValues(commitment.Commitment).Observe(float64(i))
+	metrics.ObserveWithTraceExemplar(c.ctx, c.metrics.getSigStatusTimings.WithLabelValues(commitment.Commitment), time.Since(start).Seconds())
+	c.metrics.getSigStatusRetryCount.WithLabelValues(commitment.Commitment).Observe(float64(i))
 
 	return s, err
 }
@@ -1,9 +1,18 @@
 package solana
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSignatureStatus(t *testing.T) {
@@ -73,3 +82,69 @@ func TestSignatureStatus(t *testing.T) {
 		assert.Equal(t, tc.finalized, tc.s.Finalized())
 	}
 }
+
+func TestClient_MetricsRegisterer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":0,"result":10}`))
+	}))
+	defer server.Close()
+
+	registry := prometheus.NewRegistry()
+	a := NewWithOptions(server.URL, WithMetricsRegisterer(registry, "a_", nil))
+	b := NewWithOptions(server.URL, WithMetricsRegisterer(registry, "", prometheus.Labels{"client": "b"}))
+
+	slot, err := a.GetSlot(CommitmentRecent)
+	require.NoError(t, err)
+	assert.EqualValues(t, 10, slot)
+	_, err = b.GetSlot(CommitmentRecent)
+	require.NoError(t, err)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	counts := make(map[string]float64)
+	for _, f := range families {
+		if !strings.HasSuffix(f.GetName(), "solana_requests_total") {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			labels := make([]string, 0, len(m.GetLabel()))
+			for _, l := range m.GetLabel() {
+				labels = append(labels, l.GetName()+"="+l.GetValue())
+			}
+			counts[f.GetName()+"{"+strings.Join(labels, ",")+"}"] += m.GetCounter().GetValue()
+		}
+	}
+	assert.Equal(t, map[string]float64{
+		"a_solana_requests_total{method=getSlot,response_code=200}":        1,
+		"solana_requests_total{client=b,method=getSlot,response_code=200}": 1,
+	}, counts)
+}
+
+func TestClient_WithContext(t *testing.T) {
+	var calls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":0,"error":{"code":429,"message":"rate limited"}}`))
+	}))
+	defer server.Close()
+
+	c := NewWithOptions(server.URL, WithMetricsRegisterer(prometheus.NewRegistry(), "", nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// The client backs off for at least a second after being rate limited, so
+	// the call is abandoned once the context times out.
+	start := time.Now()
+	_, err := WithContext(ctx, c).GetSlot(CommitmentRecent)
+	assert.Equal(t, context.DeadlineExceeded, errors.Cause(err))
+	assert.True(t, time.Since(start) < time.Second)
+	assert.EqualValues(t, 1, atomic.LoadInt64(&calls))
+
+	// Clients that were not created by the package are returned as is.
+	mock := NewMockClient()
+	assert.Equal(t, mock, WithContext(ctx, mock))
+}
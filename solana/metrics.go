@@ -0,0 +1,57 @@
+package solana
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/kinecosystem/agora-common/metrics"
+)
+
+// defaultMetrics are the client metrics registered with the default
+// prometheus registerer.
+var defaultMetrics = newClientMetrics(prometheus.DefaultRegisterer, "", nil)
+
+type clientMetrics struct {
+	rpcCounterVec          *prometheus.CounterVec
+	rpcTimings             *prometheus.HistogramVec
+	retryCount             *prometheus.HistogramVec
+	getSigStatusTimings    *prometheus.HistogramVec
+	getSigStatusRetryCount *prometheus.HistogramVec
+}
+
+func newClientMetrics(registerer prometheus.Registerer, prefix string, constLabels prometheus.Labels) *clientMetrics {
+	m := &clientMetrics{
+		rpcCounterVec: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "solana",
+			Name:      "requests_total",
+			Help:      "Number of Solana RPCs made",
+		}, []string{"method", "response_code"}),
+		rpcTimings: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "solana",
+			Name:      "request_duration_seconds",
+		}, []string{"method"}),
+		retryCount: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "solana",
+			Name:      "retry_count",
+			Buckets:   prometheus.LinearBuckets(1.0, 1.0, 3),
+		}, []string{"method"}),
+		getSigStatusTimings: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "solana",
+			Name:      "get_signature_status_duration_seconds",
+			Help:      "Timing information for the GetSignatureStatus library call, which polls the GetSignatureStatus RPC",
+			Buckets:   metrics.MinuteDistributionBuckets,
+		}, []string{"commitment"}),
+		getSigStatusRetryCount: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "solana",
+			Name:      "get_signature_status_retry_count",
+			Buckets:   prometheus.LinearBuckets(1.0, 1.0, sigStatusPollLimit),
+		}, []string{"commitment"}),
+	}
+
+	m.rpcCounterVec = metrics.RegisterWith(registerer, prefix, constLabels, m.rpcCounterVec).(*prometheus.CounterVec)
+	m.rpcTimings = metrics.RegisterWith(registerer, prefix, constLabels, m.rpcTimings).(*prometheus.HistogramVec)
+	m.retryCount = metrics.RegisterWith(registerer, prefix, constLabels, m.retryCount).(*prometheus.HistogramVec)
+	m.getSigStatusTimings = metrics.RegisterWith(registerer, prefix, constLabels, m.getSigStatusTimings).(*prometheus.HistogramVec)
+	m.getSigStatusRetryCount = metrics.RegisterWith(registerer, prefix, constLabels, m.getSigStatusRetryCount).(*prometheus.HistogramVec)
+
+	return m
+}
@@ -0,0 +1,48 @@
+package solana
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/ybbus/jsonrpc"
+
+	"github.com/kinecosystem/agora-common/logging"
+)
+
+type options struct {
+	rpcOpts *jsonrpc.RPCClientOpts
+	metrics *clientMetrics
+	log     logging.Logger
+}
+
+// Option configures a client.
+type Option func(o *options)
+
+// WithRPCOptions configures the client to use the specified RPC options.
+func WithRPCOptions(rpcOpts *jsonrpc.RPCClientOpts) Option {
+	return func(o *options) {
+		o.rpcOpts = rpcOpts
+	}
+}
+
+// WithMetricsRegisterer configures the client to register its metrics with the
+// provided registerer, instead of the default prometheus registerer.
+//
+// If set, the prefix is prepended to the metric names, and the constant labels
+// are added to the metrics, allowing multiple clients in a process to report
+// separate metrics.
+func WithMetricsRegisterer(registerer prometheus.Registerer, prefix string, constLabels prometheus.Labels) Option {
+	return func(o *options) {
+		o.metrics = newClientMetrics(registerer, prefix, constLabels)
+	}
+}
+
+// WithLogger configures the logger used by the client, instead of the standard
+// logrus logger.
+func WithLogger(log logging.Logger) Option {
+	return func(o *options) {
+		o.log = log
+	}
+}
+
+var defaultOptions = options{
+	metrics: defaultMetrics,
+}
@@ -0,0 +1,68 @@
+package taskqueue
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// BatchFailure describes a message in a batch that was not submitted.
+type BatchFailure struct {
+	// Index is the index of the message in the submitted batch.
+	Index int
+	Err   error
+}
+
+// BatchError is returned by Submitter.SubmitBatch implementations that may
+// partially submit a batch. Messages that are not listed in Failures were
+// submitted successfully.
+type BatchError struct {
+	// Failures are the messages that were not submitted, ordered by index.
+	Failures []BatchFailure
+
+	// Total is the number of messages in the batch.
+	Total int
+}
+
+// NewBatchError returns a BatchError for the provided failures, or nil if there
+// are none.
+func NewBatchError(total int, failures []BatchFailure) error {
+	if len(failures) == 0 {
+		return nil
+	}
+
+	sorted := make([]BatchFailure, len(failures))
+	copy(sorted, failures)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Index < sorted[j].Index
+	})
+
+	return &BatchError{
+		Failures: sorted,
+		Total:    total,
+	}
+}
+
+// Error implements error.Error.
+func (e *BatchError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "failed to submit %d/%d messages", len(e.Failures), e.Total)
+	for i, f := range e.Failures {
+		if i == 0 {
+			sb.WriteString(": ")
+		} else {
+			sb.WriteString("; ")
+		}
+		fmt.Fprintf(&sb, "[%d] %v", f.Index, f.Err)
+	}
+	return sb.String()
+}
+
+// FailedIndexes returns the indexes of the messages that were not submitted.
+func (e *BatchError) FailedIndexes() []int {
+	indexes := make([]int, len(e.Failures))
+	for i, f := range e.Failures {
+		indexes[i] = f.Index
+	}
+	return indexes
+}
@@ -0,0 +1,25 @@
+package taskqueue
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchError(t *testing.T) {
+	assert.NoError(t, NewBatchError(10, nil))
+
+	err := NewBatchError(10, []BatchFailure{
+		{Index: 7, Err: errors.New("b")},
+		{Index: 2, Err: errors.New("a")},
+	})
+	require.Error(t, err)
+
+	batchErr, ok := err.(*BatchError)
+	require.True(t, ok)
+	assert.Equal(t, 10, batchErr.Total)
+	assert.Equal(t, []int{2, 7}, batchErr.FailedIndexes())
+	assert.Equal(t, "failed to submit 2/10 messages: [2] a; [7] b", err.Error())
+}
@@ -0,0 +1,64 @@
+package taskqueue
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ErrorClass is the class of a handler error, which determines whether the
+// task should be retried.
+type ErrorClass int
+
+const (
+	// ErrorClassTransient errors may succeed if the task is retried.
+	ErrorClassTransient ErrorClass = iota
+
+	// ErrorClassPermanent errors will not succeed if the task is retried, so
+	// the task should be discarded (or dead lettered) instead.
+	ErrorClassPermanent
+)
+
+// ErrorClassifier classifies a (non-nil) handler error.
+type ErrorClassifier func(err error) ErrorClass
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Cause() error {
+	return e.err
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks the error as a permanent failure, such that the task is not
+// retried, regardless of the configured ErrorClassifier. It returns nil if err
+// is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err: err}
+}
+
+// ClassifyError returns the class of the handler error. Errors marked with
+// Permanent are always permanent, while other errors are classified by the
+// classifier, if any, or are otherwise transient.
+func ClassifyError(err error, classifier ErrorClassifier) ErrorClass {
+	var permanent *permanentError
+	if errors.As(err, &permanent) {
+		return ErrorClassPermanent
+	}
+
+	if classifier == nil {
+		return ErrorClassTransient
+	}
+
+	return classifier(err)
+}
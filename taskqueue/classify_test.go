@@ -0,0 +1,40 @@
+package taskqueue
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyError(t *testing.T) {
+	errInvalid := errors.New("invalid")
+	classifier := func(err error) ErrorClass {
+		if errors.Is(err, errInvalid) {
+			return ErrorClassPermanent
+		}
+		return ErrorClassTransient
+	}
+
+	assert.Nil(t, Permanent(nil))
+
+	permanent := Permanent(errors.New("permanent"))
+	assert.EqualError(t, permanent, "permanent")
+
+	for _, tc := range []struct {
+		err        error
+		classifier ErrorClassifier
+		expected   ErrorClass
+	}{
+		{errors.New("transient"), nil, ErrorClassTransient},
+		{errors.New("transient"), classifier, ErrorClassTransient},
+		{errInvalid, nil, ErrorClassTransient},
+		{errInvalid, classifier, ErrorClassPermanent},
+		{errors.Wrap(errInvalid, "wrapped"), classifier, ErrorClassPermanent},
+		{permanent, nil, ErrorClassPermanent},
+		{errors.Wrap(permanent, "wrapped"), nil, ErrorClassPermanent},
+		{permanent, func(error) ErrorClass { return ErrorClassTransient }, ErrorClassPermanent},
+	} {
+		assert.Equal(t, tc.expected, ClassifyError(tc.err, tc.classifier), tc.err.Error())
+	}
+}
@@ -0,0 +1,101 @@
+package taskqueue
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/kinecosystem/agora-common/logging"
+	"github.com/kinecosystem/agora-common/taskqueue/model/task"
+)
+
+// IdempotencyKeyMetadataKey is the task metadata key used to set the
+// idempotency key of a message.
+const IdempotencyKeyMetadataKey = "idempotency_key"
+
+// Deduper tracks the idempotency keys of handled tasks, in order to suppress
+// duplicate deliveries of a task.
+type Deduper interface {
+	// Claim claims the key for handling on behalf of the owner, returning
+	// false if the key has already been handled, or is currently claimed.
+	Claim(ctx context.Context, key, owner string) (bool, error)
+
+	// Complete marks the claimed key as handled.
+	Complete(ctx context.Context, key string) error
+
+	// Release releases the owner's claim on the key, allowing it to be handled
+	// again. Claims held by other owners are not released.
+	Release(ctx context.Context, key, owner string) error
+}
+
+// IdempotencyKeyFunc derives the idempotency key of a message being handled.
+// If the returned key is empty, the message is not deduplicated.
+type IdempotencyKeyFunc func(ctx context.Context, msg *task.Message) string
+
+// DefaultIdempotencyKey returns the IdempotencyKeyMetadataKey metadata value of
+// the message if set, or otherwise the submission ID of the task (see
+// SubmissionID), so that redeliveries of a submission are suppressed, while
+// separate submissions of identical messages are not.
+func DefaultIdempotencyKey(ctx context.Context, msg *task.Message) string {
+	if key, ok := msg.Metadata[IdempotencyKeyMetadataKey]; ok {
+		return key
+	}
+
+	id, _ := SubmissionID(ctx)
+	return id
+}
+
+// DedupeInterceptor returns an Interceptor that only invokes the handler for
+// messages whose idempotency key can be claimed from the Deduper.
+//
+// Duplicate messages are acknowledged without being handled. If the handler
+// fails, the claim is released so the message can be retried. If keyFunc is
+// nil, DefaultIdempotencyKey is used. If log is nil, logging.Default is used.
+func DedupeInterceptor(deduper Deduper, keyFunc IdempotencyKeyFunc, log logging.Logger) Interceptor {
+	log = logging.OrDefault(log).WithField("type", "taskqueue/interceptor")
+	if keyFunc == nil {
+		keyFunc = DefaultIdempotencyKey
+	}
+
+	return func(ctx context.Context, taskMsg *task.Message, handler Handler) error {
+		key := keyFunc(ctx, taskMsg)
+		log := log.WithFields(logging.Fields{
+			"type_name":       taskMsg.TypeName,
+			"idempotency_key": key,
+		})
+
+		if key == "" {
+			log.Debug("task has no idempotency key, not deduplicating")
+			return handler(ctx, taskMsg)
+		}
+
+		// Each claim has a unique owner, so that a claim that expired and was
+		// claimed by another handler is not released by this one.
+		owner := uuid.New().String()
+		claimed, err := deduper.Claim(ctx, key, owner)
+		if err != nil {
+			return errors.Wrap(err, "failed to claim idempotency key")
+		}
+		if !claimed {
+			log.Debug("duplicate task, skipping")
+			return nil
+		}
+
+		if err := handler(ctx, taskMsg); err != nil {
+			// Use a fresh context, as the handler context may be cancelled.
+			if releaseErr := deduper.Release(context.Background(), key, owner); releaseErr != nil {
+				log.WithError(releaseErr).Warn("failed to release idempotency key")
+			}
+			return err
+		}
+
+		if err := deduper.Complete(context.Background(), key); err != nil {
+			// The task has been handled, so we do not fail it. At worst, a
+			// duplicate delivery is handled once the claim expires.
+			log.WithError(err).Warn("failed to complete idempotency key")
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,38 @@
+package dedupe
+
+import "time"
+
+type config struct {
+	// ClaimTTL is the duration a claim is held for. If a claimed key is not
+	// completed or released within the TTL (for example, because the
+	// processor crashed), it may be claimed again.
+	//
+	// It should exceed the maximum duration of the handler.
+	ClaimTTL time.Duration
+
+	// CompletedTTL is the duration a completed key is retained, during which
+	// duplicate deliveries are suppressed.
+	CompletedTTL time.Duration
+}
+
+// Option configures a Deduper.
+type Option func(c *config)
+
+// WithClaimTTL configures the claim TTL.
+func WithClaimTTL(ttl time.Duration) Option {
+	return func(c *config) {
+		c.ClaimTTL = ttl
+	}
+}
+
+// WithCompletedTTL configures the completed key TTL.
+func WithCompletedTTL(ttl time.Duration) Option {
+	return func(c *config) {
+		c.CompletedTTL = ttl
+	}
+}
+
+var defaultConfig = config{
+	ClaimTTL:     5 * time.Minute,
+	CompletedTTL: 24 * time.Hour,
+}
@@ -0,0 +1,136 @@
+package dedupe
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/dynamodbiface"
+	"github.com/google/uuid"
+	"github.com/ory/dockertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dynamotest "github.com/kinecosystem/agora-common/aws/dynamodb/test"
+	"github.com/kinecosystem/agora-common/taskqueue"
+)
+
+var (
+	dynamoClient dynamodbiface.ClientAPI
+)
+
+func TestMain(m *testing.M) {
+	testPool, err := dockertest.NewPool("")
+	if err != nil {
+		panic("Error creating docker pool:" + err.Error())
+	}
+
+	client, cleanUpDynamo, err := dynamotest.StartDynamoDB(testPool)
+	if err != nil {
+		panic("Error starting DynamoDB image:" + err.Error())
+	}
+	dynamoClient = client
+
+	code := m.Run()
+	cleanUpDynamo()
+	os.Exit(code)
+}
+
+func TestMemory(t *testing.T) {
+	testDeduper(t, func(opts ...Option) taskqueue.Deduper {
+		return NewMemory(opts...)
+	})
+}
+
+func TestDynamoDB(t *testing.T) {
+	tableName := setupTable(t)
+	testDeduper(t, func(opts ...Option) taskqueue.Deduper {
+		return NewDynamoDB(tableName, dynamoClient, opts...)
+	})
+}
+
+func testDeduper(t *testing.T, newDeduper func(opts ...Option) taskqueue.Deduper) {
+	ctx := context.Background()
+	d := newDeduper(WithClaimTTL(2*time.Second), WithCompletedTTL(time.Hour))
+
+	// A claimed key cannot be claimed again until it is released.
+	claimed, err := d.Claim(ctx, "a", "owner-1")
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = d.Claim(ctx, "a", "owner-2")
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	// Only the owner can release a claim.
+	require.NoError(t, d.Release(ctx, "a", "owner-2"))
+	claimed, err = d.Claim(ctx, "a", "owner-2")
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	require.NoError(t, d.Release(ctx, "a", "owner-1"))
+	claimed, err = d.Claim(ctx, "a", "owner-2")
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	// A completed key cannot be claimed or released.
+	require.NoError(t, d.Complete(ctx, "a"))
+	require.NoError(t, d.Release(ctx, "a", "owner-2"))
+	claimed, err = d.Claim(ctx, "a", "owner-3")
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	// Releasing an unknown key is a no-op.
+	require.NoError(t, d.Release(ctx, "unknown", "owner-1"))
+
+	// An expired claim can be claimed again, after which the previous owner
+	// can no longer release it.
+	claimed, err = d.Claim(ctx, "b", "owner-1")
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	time.Sleep(3 * time.Second)
+
+	claimed, err = d.Claim(ctx, "b", "owner-2")
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	require.NoError(t, d.Release(ctx, "b", "owner-1"))
+	claimed, err = d.Claim(ctx, "b", "owner-3")
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	claimed, err = d.Claim(ctx, "a", "owner-3")
+	require.NoError(t, err)
+	assert.False(t, claimed)
+}
+
+func setupTable(t *testing.T) string {
+	tableName := "dedupe-" + uuid.New().String()
+
+	_, err := dynamoClient.CreateTableRequest(&dynamodb.CreateTableInput{
+		TableName: aws.String(tableName),
+		KeySchema: []dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String(keyAttr),
+				KeyType:       dynamodb.KeyTypeHash,
+			},
+		},
+		AttributeDefinitions: []dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String(keyAttr),
+				AttributeType: dynamodb.ScalarAttributeTypeS,
+			},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(10),
+			WriteCapacityUnits: aws.Int64(10),
+		},
+	}).Send(context.Background())
+	require.NoError(t, err)
+
+	return tableName
+}
@@ -0,0 +1,126 @@
+package dedupe
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/dynamodbiface"
+	"github.com/pkg/errors"
+
+	dynamoutil "github.com/kinecosystem/agora-common/aws/dynamodb/util"
+	"github.com/kinecosystem/agora-common/taskqueue"
+)
+
+const (
+	keyAttr       = "key"
+	statusAttr    = "status"
+	ownerAttr     = "owner"
+	expiresAtAttr = "expires_at"
+
+	statusClaimed   = "claimed"
+	statusCompleted = "completed"
+)
+
+type db struct {
+	conf      config
+	tableName string
+	db        dynamodbiface.ClientAPI
+}
+
+// NewDynamoDB returns a taskqueue.Deduper backed by the provided DynamoDB table.
+//
+// The table must have a string hash key named 'key'. Items contain an
+// 'expires_at' number attribute (in unix seconds), which may be configured as
+// the table's TTL attribute to clean up expired keys.
+func NewDynamoDB(tableName string, client dynamodbiface.ClientAPI, opts ...Option) taskqueue.Deduper {
+	d := &db{
+		conf:      defaultConfig,
+		tableName: tableName,
+		db:        client,
+	}
+
+	for _, o := range opts {
+		o(&d.conf)
+	}
+
+	return d
+}
+
+// Claim implements taskqueue.Deduper.Claim.
+func (d *db) Claim(ctx context.Context, key, owner string) (bool, error) {
+	now := time.Now()
+
+	_, err := d.db.PutItemRequest(&dynamodb.PutItemInput{
+		TableName: aws.String(d.tableName),
+		Item: map[string]dynamodb.AttributeValue{
+			keyAttr:       {S: aws.String(key)},
+			statusAttr:    {S: aws.String(statusClaimed)},
+			ownerAttr:     {S: aws.String(owner)},
+			expiresAtAttr: {N: aws.String(formatExpiry(now.Add(d.conf.ClaimTTL)))},
+		},
+		ConditionExpression: aws.String("attribute_not_exists(#key) OR #expires_at < :now"),
+		ExpressionAttributeNames: map[string]string{
+			"#key":        keyAttr,
+			"#expires_at": expiresAtAttr,
+		},
+		ExpressionAttributeValues: map[string]dynamodb.AttributeValue{
+			":now": {N: aws.String(formatExpiry(now))},
+		},
+	}).Send(ctx)
+	if dynamoutil.IsConditionalCheckFailed(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrap(err, "failed to claim key")
+	}
+
+	return true, nil
+}
+
+// Complete implements taskqueue.Deduper.Complete.
+func (d *db) Complete(ctx context.Context, key string) error {
+	_, err := d.db.PutItemRequest(&dynamodb.PutItemInput{
+		TableName: aws.String(d.tableName),
+		Item: map[string]dynamodb.AttributeValue{
+			keyAttr:       {S: aws.String(key)},
+			statusAttr:    {S: aws.String(statusCompleted)},
+			expiresAtAttr: {N: aws.String(formatExpiry(time.Now().Add(d.conf.CompletedTTL)))},
+		},
+	}).Send(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to complete key")
+	}
+
+	return nil
+}
+
+// Release implements taskqueue.Deduper.Release.
+func (d *db) Release(ctx context.Context, key, owner string) error {
+	_, err := d.db.DeleteItemRequest(&dynamodb.DeleteItemInput{
+		TableName: aws.String(d.tableName),
+		Key: map[string]dynamodb.AttributeValue{
+			keyAttr: {S: aws.String(key)},
+		},
+		ConditionExpression: aws.String("#status = :claimed AND #owner = :owner"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": statusAttr,
+			"#owner":  ownerAttr,
+		},
+		ExpressionAttributeValues: map[string]dynamodb.AttributeValue{
+			":claimed": {S: aws.String(statusClaimed)},
+			":owner":   {S: aws.String(owner)},
+		},
+	}).Send(ctx)
+	if err != nil && !dynamoutil.IsConditionalCheckFailed(err) {
+		return errors.Wrap(err, "failed to release key")
+	}
+
+	return nil
+}
+
+// formatExpiry formats t in unix seconds, as expected by DynamoDB TTLs.
+func formatExpiry(t time.Time) string {
+	return strconv.FormatInt(t.Unix(), 10)
+}
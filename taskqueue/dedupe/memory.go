@@ -0,0 +1,74 @@
+package dedupe
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kinecosystem/agora-common/taskqueue"
+)
+
+type entry struct {
+	owner     string
+	completed bool
+	expiresAt time.Time
+}
+
+type memory struct {
+	conf config
+
+	sync.Mutex
+	entries map[string]entry
+}
+
+// NewMemory returns an in memory taskqueue.Deduper, which only suppresses
+// duplicates within a single process. It is primarily intended for testing.
+func NewMemory(opts ...Option) taskqueue.Deduper {
+	m := &memory{
+		conf:    defaultConfig,
+		entries: make(map[string]entry),
+	}
+
+	for _, o := range opts {
+		o(&m.conf)
+	}
+
+	return m
+}
+
+// Claim implements taskqueue.Deduper.Claim.
+func (m *memory) Claim(_ context.Context, key, owner string) (bool, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	now := time.Now()
+	if e, ok := m.entries[key]; ok && now.Before(e.expiresAt) {
+		return false, nil
+	}
+
+	m.entries[key] = entry{owner: owner, expiresAt: now.Add(m.conf.ClaimTTL)}
+	return true, nil
+}
+
+// Complete implements taskqueue.Deduper.Complete.
+func (m *memory) Complete(_ context.Context, key string) error {
+	m.Lock()
+	defer m.Unlock()
+
+	m.entries[key] = entry{
+		completed: true,
+		expiresAt: time.Now().Add(m.conf.CompletedTTL),
+	}
+	return nil
+}
+
+// Release implements taskqueue.Deduper.Release.
+func (m *memory) Release(_ context.Context, key, owner string) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if e, ok := m.entries[key]; ok && !e.completed && e.owner == owner {
+		delete(m.entries, key)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package taskqueue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kinecosystem/agora-common/taskqueue/model/task"
+)
+
+type testDeduper struct {
+	claimErr  error
+	claimed   map[string]string
+	completed map[string]bool
+}
+
+func newTestDeduper() *testDeduper {
+	return &testDeduper{
+		claimed:   make(map[string]string),
+		completed: make(map[string]bool),
+	}
+}
+
+func (d *testDeduper) Claim(_ context.Context, key, owner string) (bool, error) {
+	if d.claimErr != nil {
+		return false, d.claimErr
+	}
+	if _, ok := d.claimed[key]; ok || d.completed[key] {
+		return false, nil
+	}
+	d.claimed[key] = owner
+	return true, nil
+}
+
+func (d *testDeduper) Complete(_ context.Context, key string) error {
+	delete(d.claimed, key)
+	d.completed[key] = true
+	return nil
+}
+
+func (d *testDeduper) Release(_ context.Context, key, owner string) error {
+	if d.claimed[key] == owner {
+		delete(d.claimed, key)
+	}
+	return nil
+}
+
+// submissionContext returns the handler context of a delivery of the wrapper.
+func submissionContext(t *testing.T, wrapper *task.Wrapper) context.Context {
+	ctx := WrapperContext(context.Background(), wrapper)
+	_, ok := SubmissionID(ctx)
+	require.True(t, ok)
+	return ctx
+}
+
+func TestDefaultIdempotencyKey(t *testing.T) {
+	msg := &task.Message{TypeName: "a", RawValue: []byte("value")}
+
+	// Deliveries of the same submission share a key, while separate
+	// submissions of the same message do not.
+	first := NewWrapper(context.Background(), msg)
+	key := DefaultIdempotencyKey(submissionContext(t, first), msg)
+	assert.NotEmpty(t, key)
+	assert.Equal(t, key, DefaultIdempotencyKey(submissionContext(t, first), msg))
+	assert.NotEqual(t, key, DefaultIdempotencyKey(submissionContext(t, NewWrapper(context.Background(), msg)), msg))
+
+	// Submissions made by a handler are separate submissions, unless they are
+	// forwarded.
+	handlerCtx := submissionContext(t, first)
+	assert.NotEqual(t, key, DefaultIdempotencyKey(submissionContext(t, NewWrapper(handlerCtx, msg)), msg))
+	forwardingCtx := ForwardingContext(context.Background(), first)
+	assert.Equal(t, key, DefaultIdempotencyKey(submissionContext(t, NewWrapper(forwardingCtx, msg)), msg))
+
+	// Wrappers without a submission ID are not deduplicated.
+	assert.Empty(t, DefaultIdempotencyKey(WrapperContext(context.Background(), &task.Wrapper{Message: msg}), msg))
+
+	assert.Equal(t, "key", DefaultIdempotencyKey(context.Background(), &task.Message{
+		TypeName: "a",
+		Metadata: map[string]string{IdempotencyKeyMetadataKey: "key"},
+	}))
+}
+
+func TestDedupeInterceptor(t *testing.T) {
+	deduper := newTestDeduper()
+
+	var calls int
+	handlerErr := errors.New("handler error")
+	handler := ChainInterceptors(func(ctx context.Context, taskMsg *task.Message) error {
+		calls++
+		if taskMsg.TypeName == "fail" {
+			return handlerErr
+		}
+		return nil
+	}, DedupeInterceptor(deduper, nil, nil))
+
+	msg := &task.Message{TypeName: "a", RawValue: []byte("value")}
+	ctx := submissionContext(t, NewWrapper(context.Background(), msg))
+	require.NoError(t, handler(ctx, msg))
+	require.NoError(t, handler(ctx, msg))
+	assert.Equal(t, 1, calls)
+	assert.True(t, deduper.completed[DefaultIdempotencyKey(ctx, msg)])
+
+	// Identical messages that are submitted separately are handled.
+	require.NoError(t, handler(submissionContext(t, NewWrapper(context.Background(), msg)), msg))
+	assert.Equal(t, 2, calls)
+
+	// Failed tasks should be released, so they can be retried.
+	failing := &task.Message{TypeName: "fail"}
+	ctx = submissionContext(t, NewWrapper(context.Background(), failing))
+	assert.Equal(t, handlerErr, handler(ctx, failing))
+	assert.Equal(t, handlerErr, handler(ctx, failing))
+	assert.Equal(t, 4, calls)
+	assert.Empty(t, deduper.claimed)
+	assert.False(t, deduper.completed[DefaultIdempotencyKey(ctx, failing)])
+
+	// Tasks without an idempotency key are always handled.
+	require.NoError(t, handler(context.Background(), msg))
+	require.NoError(t, handler(context.Background(), msg))
+	assert.Equal(t, 6, calls)
+
+	// Tasks should not be handled if the key can't be claimed.
+	deduper.claimErr = errors.New("claim error")
+	b := &task.Message{TypeName: "b"}
+	assert.Error(t, handler(submissionContext(t, NewWrapper(context.Background(), b)), b))
+	assert.Equal(t, 6, calls)
+}
+
+func TestDedupeInterceptor_KeyFunc(t *testing.T) {
+	deduper := newTestDeduper()
+
+	var calls int
+	handler := ChainInterceptors(func(ctx context.Context, taskMsg *task.Message) error {
+		calls++
+		return nil
+	}, DedupeInterceptor(deduper, func(_ context.Context, msg *task.Message) string {
+		return msg.TypeName
+	}, nil))
+
+	require.NoError(t, handler(context.Background(), &task.Message{TypeName: "a", RawValue: []byte("1")}))
+	require.NoError(t, handler(context.Background(), &task.Message{TypeName: "a", RawValue: []byte("2")}))
+	require.NoError(t, handler(context.Background(), &task.Message{TypeName: "b", RawValue: []byte("1")}))
+	assert.Equal(t, 2, calls)
+}
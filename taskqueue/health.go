@@ -0,0 +1,59 @@
+package taskqueue
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// PollHealth describes the health of a Processor's polling for tasks.
+type PollHealth struct {
+	// LastSuccessfulPoll is the time at which a poll for tasks last succeeded,
+	// regardless of whether any tasks were received. Prior to the first poll,
+	// it is the time the processor was created.
+	LastSuccessfulPoll time.Time
+
+	// ConsecutiveErrors is the number of polls that have failed since the
+	// last successful poll.
+	ConsecutiveErrors int
+
+	// Paused indicates whether the processor is paused (or shut down), in
+	// which case it is not expected to poll.
+	Paused bool
+}
+
+// HealthReporter is implemented by processors that report the health of their
+// polling for tasks.
+type HealthReporter interface {
+	PollHealth() PollHealth
+}
+
+// HealthCheck returns a health check function for the processor, which fails
+// if the processor has not successfully polled within maxPollAge, or has failed
+// to poll maxConsecutiveErrors times in a row. Paused processors are healthy.
+//
+// The maxPollAge should exceed the time taken by a single poll (such as the
+// long polling interval), as well as the longest expected task duration.
+// If maxPollAge or maxConsecutiveErrors are not positive, the respective
+// condition is not checked.
+func HealthCheck(reporter HealthReporter, maxPollAge time.Duration, maxConsecutiveErrors int) func(ctx context.Context) error {
+	return func(_ context.Context) error {
+		h := reporter.PollHealth()
+		if h.Paused {
+			return nil
+		}
+
+		if maxPollAge > 0 {
+			if age := time.Since(h.LastSuccessfulPoll); age > maxPollAge {
+				return errors.Errorf("last successful poll was %s ago", age)
+			}
+		}
+
+		if maxConsecutiveErrors > 0 && h.ConsecutiveErrors >= maxConsecutiveErrors {
+			return errors.Errorf("%d consecutive poll errors", h.ConsecutiveErrors)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,43 @@
+package taskqueue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testHealthReporter struct {
+	health PollHealth
+}
+
+func (r *testHealthReporter) PollHealth() PollHealth {
+	return r.health
+}
+
+func TestHealthCheck(t *testing.T) {
+	reporter := &testHealthReporter{
+		health: PollHealth{LastSuccessfulPoll: time.Now()},
+	}
+	check := HealthCheck(reporter, time.Minute, 3)
+	assert.NoError(t, check(context.Background()))
+
+	reporter.health.ConsecutiveErrors = 2
+	assert.NoError(t, check(context.Background()))
+
+	reporter.health.ConsecutiveErrors = 3
+	assert.Error(t, check(context.Background()))
+
+	reporter.health.ConsecutiveErrors = 0
+	reporter.health.LastSuccessfulPoll = time.Now().Add(-2 * time.Minute)
+	assert.Error(t, check(context.Background()))
+
+	// Paused processors are not expected to poll.
+	reporter.health.Paused = true
+	assert.NoError(t, check(context.Background()))
+
+	// Conditions can be disabled.
+	reporter.health = PollHealth{ConsecutiveErrors: 10}
+	assert.NoError(t, HealthCheck(reporter, 0, 0)(context.Background()))
+}
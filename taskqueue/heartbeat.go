@@ -0,0 +1,36 @@
+package taskqueue
+
+import (
+	"context"
+)
+
+type heartbeatKey struct{}
+
+// HeartbeatFunc signals that the task being handled is still making progress.
+type HeartbeatFunc func(progress string) error
+
+// ContextWithHeartbeat returns a context that invokes the provided HeartbeatFunc
+// when Heartbeat is called. It is intended for use by Processor implementations.
+func ContextWithHeartbeat(ctx context.Context, fn HeartbeatFunc) context.Context {
+	return context.WithValue(ctx, heartbeatKey{}, fn)
+}
+
+// Heartbeat signals that the task being handled with the provided context is
+// still making progress, optionally describing the progress made.
+//
+// Processors that support heartbeats extend the task's lease (for example, the
+// SQS visibility timeout) on each heartbeat, allowing long running tasks to be
+// kept alive on demand. If the processor does not support heartbeats, Heartbeat
+// is a no-op.
+func Heartbeat(ctx context.Context, progress string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	fn, ok := ctx.Value(heartbeatKey{}).(HeartbeatFunc)
+	if !ok {
+		return nil
+	}
+
+	return fn(progress)
+}
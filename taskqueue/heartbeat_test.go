@@ -0,0 +1,32 @@
+package taskqueue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeartbeat(t *testing.T) {
+	// Heartbeats are a no-op if unsupported.
+	assert.NoError(t, Heartbeat(context.Background(), "progress"))
+
+	var progress []string
+	ctx, cancel := context.WithCancel(ContextWithHeartbeat(context.Background(), func(p string) error {
+		progress = append(progress, p)
+		if p == "fail" {
+			return errors.New("heartbeat failed")
+		}
+		return nil
+	}))
+
+	assert.NoError(t, Heartbeat(ctx, "a"))
+	assert.NoError(t, Heartbeat(ctx, "b"))
+	assert.EqualError(t, Heartbeat(ctx, "fail"), "heartbeat failed")
+	assert.Equal(t, []string{"a", "b", "fail"}, progress)
+
+	cancel()
+	assert.Equal(t, context.Canceled, Heartbeat(ctx, "c"))
+	assert.Len(t, progress, 3)
+}
@@ -0,0 +1,113 @@
+package taskqueue
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/kinecosystem/agora-common/logging"
+	"github.com/kinecosystem/agora-common/metrics"
+	"github.com/kinecosystem/agora-common/taskqueue/model/task"
+)
+
+var (
+	taskCounterVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "taskqueue",
+		Name:      "tasks_handled_total",
+		Help:      "Number of tasks handled",
+	}, []string{"type_name", "result"})
+	taskTimings = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "taskqueue",
+		Name:      "task_duration_seconds",
+		Buckets:   metrics.MinuteDistributionBuckets,
+	}, []string{"type_name"})
+)
+
+func init() {
+	taskCounterVec = metrics.Register(taskCounterVec).(*prometheus.CounterVec)
+	taskTimings = metrics.Register(taskTimings).(*prometheus.HistogramVec)
+}
+
+// Interceptor intercepts the handling of a task message, analogous to a
+// grpc.UnaryServerInterceptor. It is the responsibility of the interceptor
+// to invoke the handler.
+type Interceptor func(ctx context.Context, taskMsg *task.Message, handler Handler) error
+
+// ChainInterceptors returns a Handler that invokes the interceptors in order,
+// with the provided handler as the innermost call.
+func ChainInterceptors(handler Handler, interceptors ...Interceptor) Handler {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handler
+		handler = func(ctx context.Context, taskMsg *task.Message) error {
+			return interceptor(ctx, taskMsg, next)
+		}
+	}
+
+	return handler
+}
+
+// RecoveryInterceptor returns an Interceptor that converts handler panics
+// into errors, so a single bad task does not crash the processor. If log is
+// nil, logging.Default is used.
+func RecoveryInterceptor(log logging.Logger) Interceptor {
+	log = logging.OrDefault(log).WithField("type", "taskqueue/interceptor")
+
+	return func(ctx context.Context, taskMsg *task.Message, handler Handler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.WithFields(logging.Fields{
+					"type_name": taskMsg.TypeName,
+					"panic":     r,
+					"stack":     string(debug.Stack()),
+				}).Error("task handler panicked")
+
+				err = errors.Errorf("task handler panicked: %v", r)
+			}
+		}()
+
+		return handler(ctx, taskMsg)
+	}
+}
+
+// LoggingInterceptor returns an Interceptor that logs the outcome of each task.
+//
+// A logrus entry may be used via logging.NewLogrus.
+func LoggingInterceptor(log logging.Logger) Interceptor {
+	return func(ctx context.Context, taskMsg *task.Message, handler Handler) error {
+		start := time.Now()
+		err := handler(ctx, taskMsg)
+
+		entry := log.WithFields(logging.Fields{
+			"type_name": taskMsg.TypeName,
+			"duration":  time.Since(start),
+		})
+		if err != nil {
+			entry.WithError(err).Warn("task failed")
+		} else {
+			entry.Debug("task completed")
+		}
+
+		return err
+	}
+}
+
+// MetricsInterceptor returns an Interceptor that records the outcome and
+// duration of each task.
+func MetricsInterceptor() Interceptor {
+	return func(ctx context.Context, taskMsg *task.Message, handler Handler) error {
+		start := time.Now()
+		err := handler(ctx, taskMsg)
+		taskTimings.WithLabelValues(taskMsg.TypeName).Observe(time.Since(start).Seconds())
+
+		result := "success"
+		if err != nil {
+			result = "failure"
+		}
+		taskCounterVec.WithLabelValues(taskMsg.TypeName, result).Inc()
+
+		return err
+	}
+}
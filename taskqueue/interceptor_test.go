@@ -0,0 +1,66 @@
+package taskqueue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kinecosystem/agora-common/logging"
+	"github.com/kinecosystem/agora-common/taskqueue/model/task"
+)
+
+func TestChainInterceptors(t *testing.T) {
+	var calls []string
+	interceptor := func(name string) Interceptor {
+		return func(ctx context.Context, taskMsg *task.Message, handler Handler) error {
+			calls = append(calls, name+"-before")
+			err := handler(ctx, taskMsg)
+			calls = append(calls, name+"-after")
+			return err
+		}
+	}
+
+	handler := ChainInterceptors(func(ctx context.Context, taskMsg *task.Message) error {
+		calls = append(calls, "handler")
+		return errors.New("handler error")
+	}, interceptor("a"), interceptor("b"))
+
+	assert.EqualError(t, handler(context.Background(), &task.Message{}), "handler error")
+	assert.Equal(t, []string{"a-before", "b-before", "handler", "b-after", "a-after"}, calls)
+
+	// No interceptors should result in the original handler.
+	calls = nil
+	handler = ChainInterceptors(func(ctx context.Context, taskMsg *task.Message) error {
+		calls = append(calls, "handler")
+		return nil
+	})
+	assert.NoError(t, handler(context.Background(), &task.Message{}))
+	assert.Equal(t, []string{"handler"}, calls)
+}
+
+func TestRecoveryInterceptor(t *testing.T) {
+	handler := ChainInterceptors(func(ctx context.Context, taskMsg *task.Message) error {
+		panic("oh no")
+	}, RecoveryInterceptor(nil))
+	assert.EqualError(t, handler(context.Background(), &task.Message{TypeName: "type"}), "task handler panicked: oh no")
+
+	handler = ChainInterceptors(func(ctx context.Context, taskMsg *task.Message) error {
+		return nil
+	}, RecoveryInterceptor(nil))
+	assert.NoError(t, handler(context.Background(), &task.Message{TypeName: "type"}))
+}
+
+func TestLoggingAndMetricsInterceptors(t *testing.T) {
+	handlerErr := errors.New("handler error")
+	handler := ChainInterceptors(func(ctx context.Context, taskMsg *task.Message) error {
+		if taskMsg.TypeName == "fail" {
+			return handlerErr
+		}
+		return nil
+	}, LoggingInterceptor(logging.Default()), MetricsInterceptor())
+
+	assert.NoError(t, handler(context.Background(), &task.Message{TypeName: "ok"}))
+	assert.Equal(t, handlerErr, handler(context.Background(), &task.Message{TypeName: "fail"}))
+}
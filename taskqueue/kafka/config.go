@@ -0,0 +1,106 @@
+package kafka
+
+import (
+	"time"
+
+	"github.com/kinecosystem/agora-common/logging"
+	"github.com/kinecosystem/agora-common/taskqueue"
+)
+
+type config struct {
+	// TaskConcurrency configures the number of consumers in the processor.
+	//
+	// Each consumer is a member of the consumer group, and processes the
+	// messages of its assigned partitions in order. Concurrency beyond the
+	// number of partitions in the topic has no effect.
+	TaskConcurrency int
+
+	// MaxAttempts is the number of times a task is attempted before it is
+	// requeued to the end of the topic.
+	//
+	// Tasks in a partition are processed in order, so requeuing ensures a
+	// failing task does not block the rest of the partition indefinitely.
+	MaxAttempts int
+
+	// RetryBackoff is the delay between attempts of a failed task.
+	RetryBackoff time.Duration
+
+	// ErrorClassifier classifies handler errors. Tasks that fail with a
+	// permanent error are committed without being retried or requeued.
+	//
+	// Errors marked with taskqueue.Permanent are always permanent.
+	ErrorClassifier taskqueue.ErrorClassifier
+
+	// BatchTimeout is the maximum duration the submitter waits to batch
+	// messages together before writing them to the topic.
+	BatchTimeout time.Duration
+
+	// PausedStart indicates that the processor's initial state should be paused.
+	// In this state, the processor won't process tasks until Start() is called.
+	PausedStart bool
+
+	// Logger is the logger used by the queue. If nil, the standard
+	// logrus logger is used.
+	Logger logging.Logger
+}
+
+// Option configures a Processor.
+type Option func(c *config)
+
+// WithTaskConcurrency configures the task concurrency.
+func WithTaskConcurrency(concurrency int) Option {
+	return func(c *config) {
+		c.TaskConcurrency = concurrency
+	}
+}
+
+// WithMaxAttempts configures the maximum attempts of a task before it is requeued.
+func WithMaxAttempts(attempts int) Option {
+	return func(c *config) {
+		c.MaxAttempts = attempts
+	}
+}
+
+// WithRetryBackoff configures the delay between attempts of a failed task.
+func WithRetryBackoff(backoff time.Duration) Option {
+	return func(c *config) {
+		c.RetryBackoff = backoff
+	}
+}
+
+// WithErrorClassifier configures the classifier used to determine whether
+// handler errors are permanent.
+func WithErrorClassifier(classifier taskqueue.ErrorClassifier) Option {
+	return func(c *config) {
+		c.ErrorClassifier = classifier
+	}
+}
+
+// WithBatchTimeout configures the submitter batch timeout.
+func WithBatchTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.BatchTimeout = timeout
+	}
+}
+
+// WithPausedStart configures the processor to be initialized in a paused state.
+func WithPausedStart() Option {
+	return func(c *config) {
+		c.PausedStart = true
+	}
+}
+
+// WithLogger configures the logger used by the queue, instead of the
+// standard logrus logger.
+func WithLogger(log logging.Logger) Option {
+	return func(c *config) {
+		c.Logger = log
+	}
+}
+
+var defaultConfig = config{
+	TaskConcurrency: 4,
+	MaxAttempts:     3,
+	RetryBackoff:    time.Second,
+	BatchTimeout:    10 * time.Millisecond,
+}
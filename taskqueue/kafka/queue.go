@@ -0,0 +1,416 @@
+// Package kafka provides a Kafka backed taskqueue.Processor and taskqueue.Submitter.
+//
+// Processors consume tasks as members of a consumer group, and commit the
+// offset of a task only after it has been handled, providing at-least-once
+// semantics.
+package kafka
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/segmentio/kafka-go"
+
+	"github.com/kinecosystem/agora-common/logging"
+	"github.com/kinecosystem/agora-common/taskqueue"
+	"github.com/kinecosystem/agora-common/taskqueue/model/task"
+)
+
+const (
+	// KeyMetadataKey is the task metadata key used to set the message key.
+	//
+	// Messages with the same key are written to the same partition, and are
+	// therefore processed in order.
+	KeyMetadataKey = "kafka.key"
+
+	// shutdownGracePeriod is the maximum duration Shutdown waits for in
+	// flight tasks to complete.
+	shutdownGracePeriod = 30 * time.Second
+)
+
+// reader is the subset of kafka.Reader used by the processor.
+type reader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// writer is the subset of kafka.Writer used by the processor and submitter.
+type writer interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+type queue struct {
+	log       logging.Logger
+	conf      config
+	topic     string
+	writer    writer
+	newReader func() reader
+	handler   taskqueue.Handler
+
+	wg sync.WaitGroup
+
+	shutdownCh   chan struct{}
+	shutdownOnce sync.Once
+
+	// fetchCtx is cancelled on shutdown to unblock consumers waiting for messages.
+	fetchCtx    context.Context
+	cancelFetch context.CancelFunc
+
+	runLock   sync.RWMutex
+	stateLock sync.Mutex
+	running   bool
+
+	readerLock sync.Mutex
+	readers    []reader
+}
+
+func NewProcessorCtor(brokers []string, topic, groupID string, opts ...Option) taskqueue.ProcessorCtor {
+	return func(handler taskqueue.Handler) (taskqueue.Processor, error) {
+		return NewProcessor(brokers, topic, groupID, handler, opts...)
+	}
+}
+
+func NewProcessor(brokers []string, topic, groupID string, handler taskqueue.Handler, opts ...Option) (taskqueue.Processor, error) {
+	if handler == nil {
+		return nil, errors.Errorf("handler is nil")
+	}
+	if groupID == "" {
+		return nil, errors.Errorf("group id is empty")
+	}
+
+	newReader := func() reader {
+		return kafka.NewReader(kafka.ReaderConfig{
+			Brokers: brokers,
+			Topic:   topic,
+			GroupID: groupID,
+		})
+	}
+
+	return newQueue(topic, newWriter(brokers, topic, opts...), newReader, handler, opts...)
+}
+
+func NewSubmitter(brokers []string, topic string, opts ...Option) (taskqueue.Submitter, error) {
+	return newQueue(topic, newWriter(brokers, topic, opts...), nil, nil, opts...)
+}
+
+func newWriter(brokers []string, topic string, opts ...Option) writer {
+	conf := defaultConfig
+	for _, o := range opts {
+		o(&conf)
+	}
+
+	return &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		BatchTimeout: conf.BatchTimeout,
+		RequiredAcks: kafka.RequireAll,
+	}
+}
+
+func newQueue(topic string, w writer, newReader func() reader, handler taskqueue.Handler, opts ...Option) (*queue, error) {
+	q := &queue{
+		conf:       defaultConfig,
+		topic:      topic,
+		writer:     w,
+		newReader:  newReader,
+		handler:    handler,
+		shutdownCh: make(chan struct{}),
+	}
+	q.fetchCtx, q.cancelFetch = context.WithCancel(context.Background())
+
+	for _, o := range opts {
+		o(&q.conf)
+	}
+	q.log = logging.OrDefault(q.conf.Logger).WithFields(logging.Fields{
+		"type":  "taskqueue/kafka",
+		"topic": topic,
+	})
+
+	if q.conf.MaxAttempts <= 0 {
+		return nil, errors.New("max attempts must be positive")
+	}
+
+	if q.conf.PausedStart {
+		q.runLock.Lock()
+	} else {
+		q.running = true
+	}
+
+	if handler != nil {
+		q.wg.Add(q.conf.TaskConcurrency)
+		for i := 0; i < q.conf.TaskConcurrency; i++ {
+			r := q.newReader()
+			q.readers = append(q.readers, r)
+
+			go func(id int) {
+				q.taskWorker(id, r)
+			}(i)
+		}
+	}
+
+	return q, nil
+}
+
+// Submit implements taskqueue.Submitter.Submit.
+func (q *queue) Submit(ctx context.Context, msg *task.Message) error {
+	return q.SubmitBatch(ctx, []*task.Message{msg})
+}
+
+// SubmitBatch implements taskqueue.Submitter.SubmitBatch.
+func (q *queue) SubmitBatch(ctx context.Context, msgs []*task.Message) error {
+	ctx, span := taskqueue.StartSubmitSpan(ctx, "kafka", q.topic, msgs...)
+	err := q.writeMessages(ctx, msgs)
+	taskqueue.EndSpan(span, err)
+	return err
+}
+
+func (q *queue) writeMessages(ctx context.Context, msgs []*task.Message) error {
+	select {
+	case <-q.shutdownCh:
+		return errors.New("queue shutting down")
+	default:
+	}
+
+	kafkaMsgs := make([]kafka.Message, len(msgs))
+	for i, msg := range msgs {
+		b, err := marshalTask(ctx, msg)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal task")
+		}
+
+		kafkaMsgs[i] = kafka.Message{Value: b}
+		if key, ok := msg.Metadata[KeyMetadataKey]; ok {
+			kafkaMsgs[i].Key = []byte(key)
+		}
+	}
+
+	if err := q.writer.WriteMessages(ctx, kafkaMsgs...); err != nil {
+		return errors.Wrap(err, "failed to submit task")
+	}
+
+	return nil
+}
+
+// SubmitWithDelay implements taskqueue.Submitter.SubmitWithDelay.
+//
+// Kafka does not support delayed delivery, so only a zero delay is accepted.
+func (q *queue) SubmitWithDelay(ctx context.Context, msg *task.Message, delay time.Duration) error {
+	if delay != 0 {
+		return errors.New("delays are not supported by kafka queues")
+	}
+
+	return q.Submit(ctx, msg)
+}
+
+func (q *queue) Start() {
+	q.stateLock.Lock()
+	defer q.stateLock.Unlock()
+
+	if !q.running {
+		q.running = true
+		q.runLock.Unlock()
+	}
+}
+
+func (q *queue) Pause() {
+	q.stateLock.Lock()
+	defer q.stateLock.Unlock()
+
+	if q.running {
+		q.running = false
+		q.runLock.Lock()
+	}
+}
+
+func (q *queue) Resume() {
+	q.Start()
+}
+
+func (q *queue) Shutdown() {
+	q.shutdownOnce.Do(func() {
+		log := q.log.WithField("method", "Shutdown")
+		close(q.shutdownCh)
+		q.cancelFetch()
+
+		// we call start to ensure that any task worker currently
+		// blocked on the runlock becomes unblocked.
+		q.Start()
+
+		if ok := waitForGroup(&q.wg, shutdownGracePeriod); !ok {
+			log.Warnf("workers did not fully shutdown within the grace period %s", shutdownGracePeriod)
+		}
+
+		q.readerLock.Lock()
+		for _, r := range q.readers {
+			if err := r.Close(); err != nil {
+				log.WithError(err).Warn("failed to close reader")
+			}
+		}
+		q.readerLock.Unlock()
+
+		if err := q.writer.Close(); err != nil {
+			log.WithError(err).Warn("failed to close writer")
+		}
+	})
+}
+
+func (q *queue) taskWorker(id int, r reader) {
+	log := q.log.WithField("worker_id", id)
+	log.Debug("worker starting")
+	defer func() {
+		q.wg.Done()
+		log.Info("worker stopped")
+	}()
+
+	for {
+		msg, err := r.FetchMessage(q.fetchCtx)
+		if err != nil {
+			select {
+			case <-q.shutdownCh:
+				return
+			default:
+			}
+
+			log.WithError(err).Warn("failed to fetch task")
+			time.Sleep(time.Second)
+			continue
+		}
+
+		// The fetched message is not committed while paused, so it will be
+		// redelivered should the processor be shut down.
+		q.runLock.RLock()
+		q.runLock.RUnlock()
+
+		select {
+		case <-q.shutdownCh:
+			return
+		default:
+		}
+
+		if err := q.processMessage(r, msg); err != nil {
+			log.WithError(err).Warn("failed to process task")
+		}
+	}
+}
+
+// processMessage handles the message, requeuing it if it exceeds the maximum
+// number of attempts, and commits it.
+func (q *queue) processMessage(r reader, msg kafka.Message) error {
+	log := q.log.WithFields(logging.Fields{
+		"partition": msg.Partition,
+		"offset":    msg.Offset,
+	})
+
+	wrapper, err := unmarshalTask(msg.Value)
+	if err != nil {
+		log.WithError(err).Warn("failed to unmarshal message, skipping")
+		return r.CommitMessages(context.Background(), msg)
+	}
+
+	log.WithField("task", wrapper.String()).Trace("received task message")
+
+	ctx := taskqueue.WrapperContext(context.Background(), wrapper)
+	for attempt := 1; ; attempt++ {
+		// handler is expected to do logging
+		spanCtx, span := taskqueue.StartProcessSpan(ctx, "kafka", q.topic, wrapper.Message)
+		err = q.handler(spanCtx, wrapper.Message)
+		taskqueue.EndSpan(span, err)
+		if err == nil {
+			break
+		}
+
+		if taskqueue.ClassifyError(err, q.conf.ErrorClassifier) == taskqueue.ErrorClassPermanent {
+			log.WithField("task", wrapper.String()).Info("task failed permanently, skipping")
+			break
+		}
+
+		if attempt >= q.conf.MaxAttempts {
+			if err := q.requeue(msg); err != nil {
+				return errors.Wrap(err, "failed to requeue task")
+			}
+			break
+		}
+
+		select {
+		case <-q.shutdownCh:
+			return errors.New("processor shutting down, not retrying task")
+		case <-time.After(q.conf.RetryBackoff):
+		}
+	}
+
+	return r.CommitMessages(context.Background(), msg)
+}
+
+// requeue writes the message to the end of the topic, retrying until it
+// succeeds or the processor is shut down.
+//
+// The message must not be committed unless it has been requeued, as the
+// commit of any later message in the partition would implicitly commit it.
+func (q *queue) requeue(msg kafka.Message) error {
+	for {
+		err := q.writer.WriteMessages(context.Background(), kafka.Message{
+			Key:   msg.Key,
+			Value: msg.Value,
+		})
+		if err == nil {
+			return nil
+		}
+
+		q.log.WithError(err).Warn("failed to requeue task, retrying")
+
+		select {
+		case <-q.shutdownCh:
+			return err
+		case <-time.After(q.conf.RetryBackoff):
+		}
+	}
+}
+
+func waitForGroup(wg *sync.WaitGroup, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	doneCh := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(doneCh)
+	}()
+
+	select {
+	case <-doneCh:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func marshalTask(ctx context.Context, msg *task.Message) ([]byte, error) {
+	if msg == nil {
+		return nil, errors.Errorf("task message is nil")
+	}
+
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return proto.Marshal(taskqueue.NewWrapper(ctx, msg))
+}
+
+func unmarshalTask(b []byte) (*task.Wrapper, error) {
+	wrapper := &task.Wrapper{}
+	if err := proto.Unmarshal(b, wrapper); err != nil {
+		return nil, err
+	}
+
+	if err := wrapper.Validate(); err != nil {
+		return nil, err
+	}
+
+	return wrapper, nil
+}
@@ -0,0 +1,320 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kinecosystem/agora-common/headers"
+	"github.com/kinecosystem/agora-common/taskqueue"
+	"github.com/kinecosystem/agora-common/taskqueue/model/task"
+	"github.com/kinecosystem/agora-common/testutil"
+)
+
+// testTopic is an in memory topic with a single partition.
+type testTopic struct {
+	sync.Mutex
+	msgCh     chan kafka.Message
+	written   []kafka.Message
+	committed []kafka.Message
+	writeErr  error
+}
+
+func newTestTopic() *testTopic {
+	return &testTopic{
+		msgCh: make(chan kafka.Message, 100),
+	}
+}
+
+func (t *testTopic) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	select {
+	case <-ctx.Done():
+		return kafka.Message{}, ctx.Err()
+	case m := <-t.msgCh:
+		return m, nil
+	}
+}
+
+func (t *testTopic) CommitMessages(_ context.Context, msgs ...kafka.Message) error {
+	t.Lock()
+	defer t.Unlock()
+	t.committed = append(t.committed, msgs...)
+	return nil
+}
+
+func (t *testTopic) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	t.Lock()
+	defer t.Unlock()
+	if t.writeErr != nil {
+		return t.writeErr
+	}
+
+	for _, m := range msgs {
+		m.Offset = int64(len(t.written))
+		t.written = append(t.written, m)
+		t.msgCh <- m
+	}
+	return nil
+}
+
+func (t *testTopic) Close() error {
+	return nil
+}
+
+func (t *testTopic) getWritten() []kafka.Message {
+	t.Lock()
+	defer t.Unlock()
+	return append([]kafka.Message(nil), t.written...)
+}
+
+func (t *testTopic) getCommitted() []kafka.Message {
+	t.Lock()
+	defer t.Unlock()
+	return append([]kafka.Message(nil), t.committed...)
+}
+
+func newTestQueue(t *testing.T, topic *testTopic, handler func(context.Context, *task.Message) error, opts ...Option) *queue {
+	newReader := func() reader { return topic }
+	if handler == nil {
+		newReader = nil
+	}
+
+	q, err := newQueue("test", topic, newReader, handler, opts...)
+	require.NoError(t, err)
+	return q
+}
+
+func TestQueue_RoundTrip(t *testing.T) {
+	topic := newTestTopic()
+
+	var mu sync.Mutex
+	var received []*task.Message
+	p := newTestQueue(t, topic, func(_ context.Context, msg *task.Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, msg)
+		return nil
+	}, WithTaskConcurrency(1))
+	defer p.Shutdown()
+
+	msgs := []*task.Message{
+		{TypeName: "a", RawValue: []byte("1")},
+		{TypeName: "b", RawValue: []byte("2"), Metadata: map[string]string{KeyMetadataKey: "key"}},
+	}
+	require.NoError(t, p.Submit(context.Background(), msgs[0]))
+	require.NoError(t, p.SubmitBatch(context.Background(), msgs[1:]))
+
+	written := topic.getWritten()
+	require.Len(t, written, 2)
+	assert.Nil(t, written[0].Key)
+	assert.Equal(t, []byte("key"), written[1].Key)
+
+	require.NoError(t, testutil.WaitFor(time.Second, 10*time.Millisecond, func() bool {
+		return len(topic.getCommitted()) == 2
+	}))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, received, 2)
+	for i := range msgs {
+		assert.True(t, proto.Equal(msgs[i], received[i]))
+	}
+}
+
+func TestQueue_Invalid(t *testing.T) {
+	topic := newTestTopic()
+	s := newTestQueue(t, topic, nil)
+
+	assert.Error(t, s.Submit(context.Background(), nil))
+	assert.Error(t, s.Submit(context.Background(), &task.Message{}))
+	assert.Error(t, s.SubmitWithDelay(context.Background(), &task.Message{TypeName: "a"}, time.Second))
+	assert.NoError(t, s.SubmitWithDelay(context.Background(), &task.Message{TypeName: "a"}, 0))
+
+	s.Shutdown()
+	assert.Error(t, s.Submit(context.Background(), &task.Message{TypeName: "a"}))
+
+	_, err := newQueue("test", topic, nil, nil, WithMaxAttempts(0))
+	assert.Error(t, err)
+}
+
+func TestQueue_Retry(t *testing.T) {
+	topic := newTestTopic()
+
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	p := newTestQueue(t, topic, func(_ context.Context, msg *task.Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts[msg.TypeName]++
+
+		// 'flaky' succeeds on its second attempt, while 'failing' is requeued
+		// once before succeeding.
+		switch msg.TypeName {
+		case "flaky":
+			if attempts[msg.TypeName] < 2 {
+				return errors.New("flaky")
+			}
+		case "failing":
+			if attempts[msg.TypeName] <= 3 {
+				return errors.New("failing")
+			}
+		}
+		return nil
+	}, WithTaskConcurrency(1), WithMaxAttempts(3), WithRetryBackoff(time.Millisecond))
+	defer p.Shutdown()
+
+	require.NoError(t, p.Submit(context.Background(), &task.Message{TypeName: "flaky"}))
+	require.NoError(t, p.Submit(context.Background(), &task.Message{TypeName: "failing"}))
+
+	require.NoError(t, testutil.WaitFor(time.Second, 10*time.Millisecond, func() bool {
+		return len(topic.getCommitted()) == 3
+	}))
+
+	mu.Lock()
+	assert.Equal(t, 2, attempts["flaky"])
+	assert.Equal(t, 4, attempts["failing"])
+	mu.Unlock()
+
+	// The failing task should have been requeued with the original payload.
+	written := topic.getWritten()
+	require.Len(t, written, 3)
+	assert.Equal(t, written[1].Value, written[2].Value)
+}
+
+func TestQueue_PauseResume(t *testing.T) {
+	topic := newTestTopic()
+
+	var mu sync.Mutex
+	var count int
+	p := newTestQueue(t, topic, func(_ context.Context, msg *task.Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		count++
+		return nil
+	}, WithTaskConcurrency(1), WithPausedStart())
+	defer p.Shutdown()
+
+	require.NoError(t, p.Submit(context.Background(), &task.Message{TypeName: "a"}))
+
+	time.Sleep(100 * time.Millisecond)
+	mu.Lock()
+	assert.Equal(t, 0, count)
+	mu.Unlock()
+	assert.Empty(t, topic.getCommitted())
+
+	p.Start()
+	require.NoError(t, testutil.WaitFor(time.Second, 10*time.Millisecond, func() bool {
+		return len(topic.getCommitted()) == 1
+	}))
+
+	p.Pause()
+	require.NoError(t, p.Submit(context.Background(), &task.Message{TypeName: "b"}))
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, topic.getCommitted(), 1)
+
+	p.Resume()
+	require.NoError(t, testutil.WaitFor(time.Second, 10*time.Millisecond, func() bool {
+		return len(topic.getCommitted()) == 2
+	}))
+}
+
+func TestQueue_ShutdownWhilePaused(t *testing.T) {
+	topic := newTestTopic()
+	p := newTestQueue(t, topic, func(_ context.Context, msg *task.Message) error {
+		return nil
+	}, WithPausedStart())
+
+	require.NoError(t, p.Submit(context.Background(), &task.Message{TypeName: "a"}))
+
+	doneCh := make(chan struct{})
+	go func() {
+		p.Shutdown()
+		close(doneCh)
+	}()
+
+	select {
+	case <-doneCh:
+	case <-time.After(time.Second):
+		require.Fail(t, "shutdown did not complete")
+	}
+
+	// The fetched task must not be processed after shutdown.
+	assert.Empty(t, topic.getCommitted())
+}
+
+func TestQueue_Headers(t *testing.T) {
+	topic := newTestTopic()
+
+	propagatingCh := make(chan *task.Message, 1)
+	p := newTestQueue(t, topic, func(ctx context.Context, msg *task.Message) error {
+		propagating := &task.Message{}
+		if err := headers.GetPropagatingHeader(ctx, propagating); err != nil {
+			return err
+		}
+		propagatingCh <- propagating
+		return nil
+	}, WithTaskConcurrency(1))
+	defer p.Shutdown()
+
+	expected := &task.Message{TypeName: "header"}
+	ctx, err := headers.ContextWithHeaders(context.Background(), headers.PropagatingHeaderSetter(expected))
+	require.NoError(t, err)
+	require.NoError(t, p.Submit(ctx, &task.Message{TypeName: "a"}))
+
+	select {
+	case actual := <-propagatingCh:
+		assert.True(t, proto.Equal(expected, actual))
+	case <-time.After(time.Second):
+		require.Fail(t, "task not handled")
+	}
+}
+
+func TestQueue_PermanentError(t *testing.T) {
+	topic := newTestTopic()
+
+	errInvalid := errors.New("invalid")
+
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	p := newTestQueue(t, topic, func(_ context.Context, msg *task.Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts[msg.TypeName]++
+
+		switch msg.TypeName {
+		case "marked":
+			return taskqueue.Permanent(errors.New("marked"))
+		case "classified":
+			return errInvalid
+		}
+		return nil
+	}, WithTaskConcurrency(1), WithMaxAttempts(3), WithRetryBackoff(time.Millisecond), WithErrorClassifier(func(err error) taskqueue.ErrorClass {
+		if err == errInvalid {
+			return taskqueue.ErrorClassPermanent
+		}
+		return taskqueue.ErrorClassTransient
+	}))
+	defer p.Shutdown()
+
+	require.NoError(t, p.Submit(context.Background(), &task.Message{TypeName: "marked"}))
+	require.NoError(t, p.Submit(context.Background(), &task.Message{TypeName: "classified"}))
+
+	require.NoError(t, testutil.WaitFor(time.Second, 10*time.Millisecond, func() bool {
+		return len(topic.getCommitted()) == 2
+	}))
+
+	// Permanently failed tasks should neither be retried nor requeued.
+	mu.Lock()
+	assert.Equal(t, 1, attempts["marked"])
+	assert.Equal(t, 1, attempts["classified"])
+	mu.Unlock()
+	assert.Len(t, topic.getWritten(), 2)
+}
@@ -24,11 +24,16 @@ const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
 // Wrapper is a wrapper for task queue payloads.
 type Wrapper struct {
-	Message              *Message             `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
-	SubmissionTime       *timestamp.Timestamp `protobuf:"bytes,2,opt,name=submission_time,json=submissionTime,proto3" json:"submission_time,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
-	XXX_unrecognized     []byte               `json:"-"`
-	XXX_sizecache        int32                `json:"-"`
+	Message        *Message             `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	SubmissionTime *timestamp.Timestamp `protobuf:"bytes,2,opt,name=submission_time,json=submissionTime,proto3" json:"submission_time,omitempty"`
+	// Metadata contains the headers of the submitting context, which are
+	// restored into the context of the task handler.
+	//
+	// Values are bytes, as binary headers are not valid UTF-8.
+	Metadata             map[string][]byte `protobuf:"bytes,3,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *Wrapper) Reset()         { *m = Wrapper{} }
@@ -70,6 +75,13 @@ func (m *Wrapper) GetSubmissionTime() *timestamp.Timestamp {
 	return nil
 }
 
+func (m *Wrapper) GetMetadata() map[string][]byte {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
 // Message is the task message payload.
 type Message struct {
 	// The canonical protobuf message type name; used for validating the type of the payload.
@@ -79,10 +91,15 @@ type Message struct {
 	// The protobuf message bytes.
 	//
 	// Note: we allow 0 byte length for messages with only default field values.
-	RawValue             []byte   `protobuf:"bytes,2,opt,name=raw_value,json=rawValue,proto3" json:"raw_value,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	RawValue []byte `protobuf:"bytes,2,opt,name=raw_value,json=rawValue,proto3" json:"raw_value,omitempty"`
+	// Metadata contains optional attributes that are interpreted by the
+	// underlying queue implementation, such as FIFO message group IDs.
+	//
+	// Keys are namespaced by the queue implementation (for example, 'sqs.').
+	Metadata             map[string]string `protobuf:"bytes,3,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *Message) Reset()         { *m = Message{} }
@@ -124,30 +141,44 @@ func (m *Message) GetRawValue() []byte {
 	return nil
 }
 
+func (m *Message) GetMetadata() map[string]string {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Wrapper)(nil), "internal.task.v1.Wrapper")
+	proto.RegisterMapType((map[string][]byte)(nil), "internal.task.v1.Wrapper.MetadataEntry")
 	proto.RegisterType((*Message)(nil), "internal.task.v1.Message")
+	proto.RegisterMapType((map[string]string)(nil), "internal.task.v1.Message.MetadataEntry")
 }
 
 func init() { proto.RegisterFile("task.proto", fileDescriptor_ce5d8dd45b4a91ff) }
 
 var fileDescriptor_ce5d8dd45b4a91ff = []byte{
-	// 270 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x5c, 0x8f, 0x3f, 0x4f, 0x84, 0x30,
-	0x18, 0xc6, 0x53, 0x72, 0xc7, 0x9f, 0x6a, 0x94, 0xb0, 0x88, 0x2c, 0x9a, 0x1b, 0xd4, 0xa9, 0x44,
-	0x9d, 0x5d, 0xba, 0x9f, 0x03, 0x31, 0x9a, 0xdc, 0x42, 0x5e, 0x62, 0x25, 0x44, 0x0a, 0x4d, 0x5b,
-	0xb8, 0xe8, 0xc4, 0xec, 0x17, 0xf0, 0x7b, 0xf8, 0xf1, 0x98, 0x4c, 0x5b, 0x89, 0xc9, 0x6d, 0x6d,
-	0x9f, 0xe7, 0x7d, 0x7f, 0xbf, 0x62, 0xac, 0x41, 0xbd, 0x13, 0x21, 0x7b, 0xdd, 0x27, 0x71, 0xd3,
-	0x69, 0x26, 0x3b, 0x68, 0x89, 0x7d, 0x1c, 0x6f, 0xb3, 0xb3, 0x11, 0xda, 0xe6, 0x15, 0x34, 0xcb,
-	0x97, 0x83, 0xab, 0x66, 0x17, 0x75, 0xdf, 0xd7, 0x2d, 0xcb, 0xed, 0xad, 0x1a, 0xde, 0x72, 0xdd,
-	0x70, 0xa6, 0x34, 0x70, 0xe1, 0x0a, 0x9b, 0x6f, 0x84, 0x83, 0x17, 0x09, 0x42, 0x30, 0x99, 0x3c,
-	0xe0, 0x80, 0x33, 0xa5, 0xa0, 0x66, 0x29, 0xba, 0x44, 0x37, 0x47, 0x77, 0xe7, 0xe4, 0x90, 0x44,
-	0xb6, 0xae, 0x40, 0xc3, 0x99, 0xae, 0xbf, 0x90, 0x17, 0xa3, 0x62, 0x99, 0x49, 0xb6, 0xf8, 0x54,
-	0x0d, 0x15, 0x6f, 0x94, 0x6a, 0xfa, 0xae, 0x34, 0xa0, 0xd4, 0xb3, 0x6b, 0x32, 0xe2, 0x2c, 0xc8,
-	0x62, 0x41, 0x9e, 0x16, 0x0b, 0xbb, 0xe7, 0x07, 0x79, 0x21, 0x2a, 0x4e, 0xfe, 0x87, 0x4d, 0xbc,
-	0xd9, 0xe1, 0xe0, 0x0f, 0x96, 0x5c, 0xe3, 0x48, 0x7f, 0x08, 0x56, 0x76, 0xc0, 0x9d, 0x5a, 0x44,
-	0xf1, 0x4c, 0x03, 0xb9, 0x8e, 0x51, 0x3a, 0x79, 0x45, 0x68, 0xc2, 0x47, 0xe0, 0x2c, 0xb9, 0xc2,
-	0x91, 0x84, 0x7d, 0x39, 0x42, 0x3b, 0x38, 0xf8, 0x31, 0x8d, 0x66, 0xea, 0x7f, 0xae, 0xd2, 0x69,
-	0x8a, 0x8b, 0x50, 0xc2, 0xfe, 0xd9, 0x44, 0xd4, 0xdf, 0xad, 0xcc, 0x87, 0x2a, 0xdf, 0x1a, 0xdd,
-	0xff, 0x06, 0x00, 0x00, 0xff, 0xff, 0xdf, 0x5e, 0xbe, 0x24, 0x5e, 0x01, 0x00, 0x00,
+	// 352 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x91, 0xbf, 0x4e, 0xc3, 0x30,
+	0x10, 0xc6, 0xe5, 0xa4, 0x6d, 0x12, 0xb7, 0x40, 0x64, 0x21, 0x11, 0xb2, 0x50, 0x75, 0xa1, 0x2c,
+	0xae, 0x28, 0x0b, 0x82, 0x01, 0x29, 0x88, 0xb1, 0x20, 0x45, 0x08, 0x24, 0x96, 0xea, 0xaa, 0x9a,
+	0x2a, 0x6a, 0xfe, 0xc9, 0x76, 0x5b, 0x95, 0xa9, 0x33, 0xef, 0xc0, 0x4b, 0x30, 0x32, 0xf1, 0x28,
+	0xac, 0xbc, 0x05, 0x72, 0x9c, 0x40, 0x81, 0x85, 0x81, 0xed, 0x2e, 0xf7, 0xdd, 0x7d, 0xbf, 0x2f,
+	0xc6, 0x58, 0x82, 0x98, 0xd2, 0x9c, 0x67, 0x32, 0x23, 0x6e, 0x94, 0x4a, 0xc6, 0x53, 0x88, 0x69,
+	0xf1, 0x71, 0x7e, 0xe8, 0xef, 0xcc, 0x21, 0x8e, 0xc6, 0x20, 0x59, 0xaf, 0x2a, 0xb4, 0xd4, 0xdf,
+	0x9b, 0x64, 0xd9, 0x24, 0x66, 0xbd, 0xa2, 0x1b, 0xcd, 0xee, 0x7b, 0x32, 0x4a, 0x98, 0x90, 0x90,
+	0xe4, 0x5a, 0xd0, 0x79, 0x32, 0xb0, 0x75, 0xcb, 0x21, 0xcf, 0x19, 0x27, 0x67, 0xd8, 0x4a, 0x98,
+	0x10, 0x30, 0x61, 0x1e, 0x6a, 0xa3, 0x6e, 0xb3, 0xbf, 0x4b, 0x7f, 0x3a, 0xd1, 0x81, 0x16, 0x04,
+	0xf8, 0xe5, 0xfd, 0xd5, 0xac, 0x3f, 0x22, 0xc3, 0x45, 0x61, 0xb5, 0x45, 0xae, 0xf0, 0x96, 0x98,
+	0x8d, 0x92, 0x48, 0x88, 0x28, 0x4b, 0x87, 0xca, 0xca, 0x33, 0x8a, 0x43, 0x3e, 0xd5, 0x1c, 0xb4,
+	0xe2, 0xa0, 0xd7, 0x15, 0x47, 0x79, 0xe9, 0x19, 0x19, 0x36, 0x0a, 0x37, 0xbf, 0xd6, 0x95, 0x80,
+	0x9c, 0x63, 0x3b, 0x61, 0x12, 0xc6, 0x20, 0xc1, 0x33, 0xdb, 0x66, 0xb7, 0xd9, 0xdf, 0xff, 0x8d,
+	0x54, 0xe2, 0xd3, 0x41, 0xa9, 0xbc, 0x48, 0x25, 0x5f, 0x86, 0x9f, 0x8b, 0xfe, 0x29, 0xde, 0xf8,
+	0x36, 0x22, 0x2e, 0x36, 0xa7, 0x6c, 0x59, 0x64, 0x74, 0x42, 0x55, 0x92, 0x6d, 0x5c, 0x9f, 0x43,
+	0x3c, 0xd3, 0xb8, 0xad, 0x50, 0x37, 0x27, 0xc6, 0x31, 0xea, 0xbc, 0x21, 0x6c, 0x95, 0x99, 0xc9,
+	0x01, 0x76, 0xe4, 0x32, 0x67, 0xc3, 0x14, 0x12, 0xfd, 0x87, 0x9c, 0xa0, 0xa5, 0xe0, 0x2d, 0x5e,
+	0x77, 0x91, 0xb7, 0x32, 0x42, 0x5b, 0x8d, 0x2f, 0x21, 0x61, 0xa4, 0x8b, 0x1d, 0x0e, 0x8b, 0xe1,
+	0xda, 0xd1, 0xa0, 0xa9, 0xa4, 0x8d, 0x87, 0x9a, 0xb7, 0x5a, 0xb9, 0xa1, 0xcd, 0x61, 0x71, 0xa3,
+	0x86, 0x7f, 0x8b, 0x58, 0x12, 0xfc, 0x5b, 0x44, 0x67, 0x2d, 0x62, 0xd0, 0xb8, 0xab, 0x29, 0x9f,
+	0x51, 0xa3, 0x78, 0x9c, 0xa3, 0x8f, 0x01, 0x00, 0xcc, 0x2a, 0xf4, 0x4f, 0x6b, 0x02, 0x00, 0x00,
 }
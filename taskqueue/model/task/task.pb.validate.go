@@ -67,6 +67,8 @@ func (m *Wrapper) Validate() error {
 		}
 	}
 
+	// no validation rules for Metadata
+
 	return nil
 }
 
@@ -145,6 +147,8 @@ func (m *Message) Validate() error {
 		}
 	}
 
+	// no validation rules for Metadata
+
 	return nil
 }
 
@@ -0,0 +1,162 @@
+package taskqueue
+
+import (
+	"context"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/kinecosystem/agora-common/logging"
+	"github.com/kinecosystem/agora-common/taskqueue/model/task"
+)
+
+// UnknownTypePolicy configures how a Mux handles messages with a type
+// that has no registered handler.
+type UnknownTypePolicy int
+
+const (
+	// UnknownTypeFail returns an error for unknown message types, which
+	// causes the task to be retried.
+	UnknownTypeFail UnknownTypePolicy = iota
+
+	// UnknownTypeDrop logs and acknowledges unknown message types.
+	UnknownTypeDrop
+)
+
+// TypedHandler handles a task message payload that has been unmarshalled
+// into its concrete proto type.
+type TypedHandler func(ctx context.Context, msg proto.Message) error
+
+// MuxOption configures a Mux.
+type MuxOption func(m *Mux)
+
+// WithUnknownTypePolicy configures the policy for unknown message types.
+func WithUnknownTypePolicy(policy UnknownTypePolicy) MuxOption {
+	return func(m *Mux) {
+		m.policy = policy
+	}
+}
+
+// WithLogger configures the logger used by the Mux, instead of the standard
+// logrus logger.
+func WithLogger(log logging.Logger) MuxOption {
+	return func(m *Mux) {
+		m.log = log.WithField("type", "taskqueue/mux")
+	}
+}
+
+// WithFallbackHandler configures a handler for unknown message types,
+// which takes precedence over the UnknownTypePolicy.
+func WithFallbackHandler(handler Handler) MuxOption {
+	return func(m *Mux) {
+		m.fallback = handler
+	}
+}
+
+type muxEntry struct {
+	prototype proto.Message
+	handler   TypedHandler
+}
+
+// Mux is a Handler that dispatches task messages to handlers registered
+// by the proto message type of the payload.
+type Mux struct {
+	log      logging.Logger
+	policy   UnknownTypePolicy
+	fallback Handler
+
+	mu       sync.RWMutex
+	handlers map[string]muxEntry
+}
+
+// NewMux returns a new Mux with no registered handlers.
+func NewMux(opts ...MuxOption) *Mux {
+	m := &Mux{
+		log:      logging.Default().WithField("type", "taskqueue/mux"),
+		handlers: make(map[string]muxEntry),
+	}
+
+	for _, o := range opts {
+		o(m)
+	}
+
+	return m
+}
+
+// Register registers the handler for messages of the same type as the
+// provided prototype. The handler is invoked with a new instance of the type.
+func (m *Mux) Register(prototype proto.Message, handler TypedHandler) error {
+	if prototype == nil {
+		return errors.New("prototype is nil")
+	}
+	if handler == nil {
+		return errors.New("handler is nil")
+	}
+
+	typeName := proto.MessageName(prototype)
+	if typeName == "" {
+		return errors.New("prototype has no message name")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.handlers[typeName]; ok {
+		return errors.Errorf("handler already registered for %s", typeName)
+	}
+
+	m.handlers[typeName] = muxEntry{
+		prototype: prototype,
+		handler:   handler,
+	}
+
+	return nil
+}
+
+// Handle implements Handler, and can be passed directly to a ProcessorCtor.
+func (m *Mux) Handle(ctx context.Context, taskMsg *task.Message) error {
+	m.mu.RLock()
+	entry, ok := m.handlers[taskMsg.TypeName]
+	m.mu.RUnlock()
+
+	if !ok {
+		if m.fallback != nil {
+			return m.fallback(ctx, taskMsg)
+		}
+
+		switch m.policy {
+		case UnknownTypeDrop:
+			m.log.WithField("type_name", taskMsg.TypeName).Warn("dropping task with unknown type")
+			return nil
+		default:
+			return errors.Errorf("no handler registered for %s", taskMsg.TypeName)
+		}
+	}
+
+	msg := proto.Clone(entry.prototype)
+	msg.Reset()
+	if err := proto.Unmarshal(taskMsg.RawValue, msg); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal %s", taskMsg.TypeName)
+	}
+
+	return entry.handler(ctx, msg)
+}
+
+// NewMessage returns a task message containing the provided proto message,
+// which can be routed by a Mux.
+func NewMessage(msg proto.Message) (*task.Message, error) {
+	if msg == nil {
+		return nil, errors.New("message is nil")
+	}
+
+	b, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal message")
+	}
+
+	return &task.Message{
+		TypeName: proto.MessageName(msg),
+		RawValue: b,
+	}, nil
+}
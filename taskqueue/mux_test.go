@@ -0,0 +1,75 @@
+package taskqueue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/kinecosystem/agora-common/taskqueue/model/task"
+)
+
+func TestMux(t *testing.T) {
+	m := NewMux()
+
+	var strings []string
+	require.NoError(t, m.Register(&wrapperspb.StringValue{}, func(ctx context.Context, msg proto.Message) error {
+		strings = append(strings, msg.(*wrapperspb.StringValue).Value)
+		return nil
+	}))
+	require.NoError(t, m.Register(&timestamppb.Timestamp{}, func(ctx context.Context, msg proto.Message) error {
+		return errors.New("handler error")
+	}))
+
+	assert.Error(t, m.Register(&wrapperspb.StringValue{}, func(ctx context.Context, msg proto.Message) error { return nil }))
+	assert.Error(t, m.Register(nil, func(ctx context.Context, msg proto.Message) error { return nil }))
+	assert.Error(t, m.Register(&wrapperspb.Int64Value{}, nil))
+
+	for _, v := range []string{"a", "b"} {
+		msg, err := NewMessage(wrapperspb.String(v))
+		require.NoError(t, err)
+		assert.Equal(t, "google.protobuf.StringValue", msg.TypeName)
+		assert.NoError(t, m.Handle(context.Background(), msg))
+	}
+	assert.Equal(t, []string{"a", "b"}, strings)
+
+	msg, err := NewMessage(timestamppb.Now())
+	require.NoError(t, err)
+	assert.EqualError(t, m.Handle(context.Background(), msg), "handler error")
+
+	// Payloads that do not match the registered type should fail.
+	assert.Error(t, m.Handle(context.Background(), &task.Message{
+		TypeName: "google.protobuf.StringValue",
+		RawValue: []byte{0xff},
+	}))
+
+	// Unknown types should fail by default.
+	msg, err = NewMessage(wrapperspb.Int64(1))
+	require.NoError(t, err)
+	assert.Error(t, m.Handle(context.Background(), msg))
+}
+
+func TestMux_UnknownTypes(t *testing.T) {
+	msg, err := NewMessage(wrapperspb.Int64(1))
+	require.NoError(t, err)
+
+	m := NewMux(WithUnknownTypePolicy(UnknownTypeDrop))
+	assert.NoError(t, m.Handle(context.Background(), msg))
+
+	var fallback []*task.Message
+	m = NewMux(
+		WithUnknownTypePolicy(UnknownTypeDrop),
+		WithFallbackHandler(func(ctx context.Context, taskMsg *task.Message) error {
+			fallback = append(fallback, taskMsg)
+			return errors.New("fallback error")
+		}),
+	)
+	assert.EqualError(t, m.Handle(context.Background(), msg), "fallback error")
+	require.Len(t, fallback, 1)
+	assert.True(t, proto.Equal(msg, fallback[0]))
+}
@@ -0,0 +1,55 @@
+package periodic
+
+import (
+	"time"
+
+	"github.com/kinecosystem/agora-common/logging"
+)
+
+type config struct {
+	// PollingInterval is the interval at which the leader checks for due jobs.
+	//
+	// It is effectively the scheduling granularity of the scheduler.
+	PollingInterval time.Duration
+
+	// LockTTL is the duration of the leader lease. If the leader fails to
+	// renew the lease within this duration, another scheduler may become
+	// the leader.
+	//
+	// The lease is renewed (or contended for) every third of the TTL.
+	LockTTL time.Duration
+
+	// Logger is the logger used by the scheduler. If nil, the standard
+	// logrus logger is used.
+	Logger logging.Logger
+}
+
+// Option configures a Scheduler.
+type Option func(c *config)
+
+// WithPollingInterval configures the polling interval.
+func WithPollingInterval(interval time.Duration) Option {
+	return func(c *config) {
+		c.PollingInterval = interval
+	}
+}
+
+// WithLockTTL configures the leader lease duration.
+func WithLockTTL(ttl time.Duration) Option {
+	return func(c *config) {
+		c.LockTTL = ttl
+	}
+}
+
+// WithLogger configures the logger used by the scheduler, instead of the
+// standard logrus logger.
+func WithLogger(log logging.Logger) Option {
+	return func(c *config) {
+		c.Logger = log
+	}
+}
+
+var defaultConfig = config{
+	PollingInterval: time.Second,
+	LockTTL:         30 * time.Second,
+}
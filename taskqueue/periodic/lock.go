@@ -0,0 +1,70 @@
+package periodic
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/pkg/errors"
+
+	dynamoutil "github.com/kinecosystem/agora-common/aws/dynamodb/util"
+)
+
+const (
+	lockKey = "lock"
+
+	ownerAttr     = "owner"
+	expiresAtAttr = "expires_at"
+)
+
+// acquireLock attempts to acquire (or renew) the leader lease, returning
+// whether or not the lease is held.
+func (s *scheduler) acquireLock(ctx context.Context, now time.Time) (bool, error) {
+	_, err := s.db.PutItemRequest(&dynamodb.PutItemInput{
+		TableName: aws.String(s.tableName),
+		Item: map[string]dynamodb.AttributeValue{
+			keyAttr:       {S: aws.String(lockKey)},
+			ownerAttr:     {S: aws.String(s.owner)},
+			expiresAtAttr: {N: aws.String(strconv.FormatInt(now.Add(s.conf.LockTTL).UnixNano(), 10))},
+		},
+		ConditionExpression: aws.String("attribute_not_exists(#key) OR #owner = :owner OR #expires_at < :now"),
+		ExpressionAttributeNames: map[string]string{
+			"#key":        keyAttr,
+			"#owner":      ownerAttr,
+			"#expires_at": expiresAtAttr,
+		},
+		ExpressionAttributeValues: map[string]dynamodb.AttributeValue{
+			":owner": {S: aws.String(s.owner)},
+			":now":   {N: aws.String(strconv.FormatInt(now.UnixNano(), 10))},
+		},
+	}).Send(ctx)
+	if dynamoutil.IsConditionalCheckFailed(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrap(err, "failed to acquire lock")
+	}
+
+	return true, nil
+}
+
+// releaseLock releases the leader lease, if held.
+func (s *scheduler) releaseLock(ctx context.Context) error {
+	_, err := s.db.DeleteItemRequest(&dynamodb.DeleteItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]dynamodb.AttributeValue{
+			keyAttr: {S: aws.String(lockKey)},
+		},
+		ConditionExpression:      aws.String("#owner = :owner"),
+		ExpressionAttributeNames: map[string]string{"#owner": ownerAttr},
+		ExpressionAttributeValues: map[string]dynamodb.AttributeValue{
+			":owner": {S: aws.String(s.owner)},
+		},
+	}).Send(ctx)
+	if err != nil && !dynamoutil.IsConditionalCheckFailed(err) {
+		return errors.Wrap(err, "failed to release lock")
+	}
+
+	return nil
+}
@@ -0,0 +1,361 @@
+// Package periodic provides a scheduler that submits tasks on a cron or
+// fixed interval schedule.
+//
+// Multiple schedulers may be run against the same table (e.g. one per
+// instance of a service), in which case a leader is elected via a lease
+// stored in DynamoDB, and only the leader submits tasks. The last run of each
+// job is also stored in the table, so schedules survive leadership changes
+// and restarts. Missed runs are coalesced into a single run.
+//
+// The table must have the following key schema:
+//
+//	key (S) - partition key
+package periodic
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/dynamodbiface"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+
+	dynamoutil "github.com/kinecosystem/agora-common/aws/dynamodb/util"
+	"github.com/kinecosystem/agora-common/logging"
+	"github.com/kinecosystem/agora-common/taskqueue"
+	"github.com/kinecosystem/agora-common/taskqueue/model/task"
+)
+
+const (
+	keyAttr     = "key"
+	lastRunAttr = "last_run"
+
+	jobKeyPrefix = "job/"
+)
+
+// Schedule determines when a job runs.
+type Schedule interface {
+	// Next returns the next time the job should run after the provided time.
+	Next(time.Time) time.Time
+}
+
+// Cron returns a Schedule for the provided standard cron spec, which
+// supports the five field format as well as descriptors such as @hourly.
+func Cron(spec string) (Schedule, error) {
+	s, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid cron spec")
+	}
+
+	return s, nil
+}
+
+// Every returns a Schedule that runs at a fixed interval.
+func Every(interval time.Duration) Schedule {
+	return every(interval)
+}
+
+type every time.Duration
+
+// Next implements Schedule.Next.
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+// Job is a task message that is submitted on a schedule.
+type Job struct {
+	// Name uniquely identifies the job within the table.
+	Name     string
+	Schedule Schedule
+	Message  *task.Message
+}
+
+// Scheduler submits jobs to a task queue according to their schedule.
+type Scheduler interface {
+	// IsLeader returns whether or not the scheduler currently holds the
+	// leader lease.
+	IsLeader() bool
+
+	Shutdown()
+}
+
+type scheduler struct {
+	log       logging.Logger
+	conf      config
+	db        dynamodbiface.ClientAPI
+	tableName string
+	submitter taskqueue.Submitter
+	jobs      []Job
+	owner     string
+
+	wg sync.WaitGroup
+
+	shutdownCh   chan struct{}
+	shutdownOnce sync.Once
+
+	leaseMu     sync.RWMutex
+	leaseExpiry time.Time
+
+	// only accessed by the run loop
+	lastAttempt time.Time
+	lastRuns    map[string]time.Time
+}
+
+// NewScheduler returns a Scheduler that submits the provided jobs to the
+// submitter while it holds the leader lease.
+func NewScheduler(tableName string, db dynamodbiface.ClientAPI, submitter taskqueue.Submitter, jobs []Job, opts ...Option) (Scheduler, error) {
+	if submitter == nil {
+		return nil, errors.New("submitter is nil")
+	}
+
+	names := make(map[string]struct{})
+	for _, j := range jobs {
+		if j.Name == "" {
+			return nil, errors.New("job name is empty")
+		}
+		if _, ok := names[j.Name]; ok {
+			return nil, errors.Errorf("duplicate job name: %s", j.Name)
+		}
+		names[j.Name] = struct{}{}
+
+		if j.Schedule == nil {
+			return nil, errors.Errorf("job %s has no schedule", j.Name)
+		}
+		if j.Message == nil {
+			return nil, errors.Errorf("job %s has no message", j.Name)
+		}
+		if err := j.Message.Validate(); err != nil {
+			return nil, errors.Wrapf(err, "job %s has an invalid message", j.Name)
+		}
+	}
+
+	s := &scheduler{
+		conf:       defaultConfig,
+		db:         db,
+		tableName:  tableName,
+		submitter:  submitter,
+		jobs:       jobs,
+		owner:      uuid.New().String(),
+		shutdownCh: make(chan struct{}),
+		lastRuns:   make(map[string]time.Time),
+	}
+
+	for _, o := range opts {
+		o(&s.conf)
+	}
+	s.log = logging.OrDefault(s.conf.Logger).WithFields(logging.Fields{
+		"type":  "taskqueue/periodic",
+		"table": tableName,
+	})
+
+	if s.conf.PollingInterval <= 0 {
+		return nil, errors.New("polling interval must be positive")
+	}
+	if s.conf.LockTTL <= 0 {
+		return nil, errors.New("lock ttl must be positive")
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run()
+	}()
+
+	return s, nil
+}
+
+// IsLeader implements Scheduler.IsLeader.
+func (s *scheduler) IsLeader() bool {
+	s.leaseMu.RLock()
+	defer s.leaseMu.RUnlock()
+
+	return time.Now().Before(s.leaseExpiry)
+}
+
+// Shutdown stops the scheduler, releasing the leader lease if held.
+func (s *scheduler) Shutdown() {
+	s.shutdownOnce.Do(func() {
+		close(s.shutdownCh)
+		s.wg.Wait()
+	})
+}
+
+func (s *scheduler) run() {
+	log := s.log.WithField("method", "run")
+
+	ticker := time.NewTicker(s.conf.PollingInterval)
+	defer ticker.Stop()
+
+	for {
+		s.tick(time.Now())
+
+		select {
+		case <-s.shutdownCh:
+			s.setLeaseExpiry(time.Time{})
+			if err := s.releaseLock(context.Background()); err != nil {
+				log.WithError(err).Warn("failed to release lock")
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *scheduler) tick(now time.Time) {
+	log := s.log.WithField("method", "tick")
+	ctx := context.Background()
+
+	if now.Sub(s.lastAttempt) >= s.conf.LockTTL/3 {
+		s.lastAttempt = now
+
+		wasLeader := s.IsLeader()
+		held, err := s.acquireLock(ctx, now)
+		if err != nil {
+			// We keep our current lease (if any), which will expire if we
+			// continue to fail to renew it.
+			log.WithError(err).Warn("failed to acquire lock")
+		} else if held {
+			s.setLeaseExpiry(now.Add(s.conf.LockTTL))
+			if !wasLeader {
+				log.Info("acquired leadership")
+
+				// Another leader may have run jobs since we last led.
+				s.lastRuns = make(map[string]time.Time)
+			}
+		} else {
+			if wasLeader {
+				log.Info("lost leadership")
+			}
+			s.setLeaseExpiry(time.Time{})
+		}
+	}
+
+	if !s.IsLeader() {
+		return
+	}
+
+	for _, j := range s.jobs {
+		if err := s.runJob(ctx, j, now); err != nil {
+			log.WithError(err).WithField("job", j.Name).Warn("failed to run job")
+		}
+	}
+}
+
+func (s *scheduler) runJob(ctx context.Context, j Job, now time.Time) error {
+	last, ok := s.lastRuns[j.Name]
+	if !ok {
+		var err error
+		if last, err = s.loadLastRun(ctx, j.Name, now); err != nil {
+			return err
+		}
+		s.lastRuns[j.Name] = last
+	}
+
+	next := j.Schedule.Next(last)
+	if now.Before(next) {
+		return nil
+	}
+
+	// If we've missed more than one run, we only run once.
+	runAt := next
+	if !now.Before(j.Schedule.Next(next)) {
+		runAt = now
+	}
+
+	// We record the run before submitting the task, so that the run is
+	// claimed even if another scheduler believes itself to be the leader.
+	claimed, err := s.swapLastRun(ctx, j.Name, last, runAt)
+	if err != nil {
+		return err
+	} else if !claimed {
+		delete(s.lastRuns, j.Name)
+		return nil
+	}
+
+	if err := s.submitter.Submit(ctx, j.Message); err != nil {
+		// Revert the run so that it is retried on the next tick.
+		if _, revertErr := s.swapLastRun(ctx, j.Name, runAt, last); revertErr != nil {
+			s.log.WithError(revertErr).WithField("job", j.Name).Warn("failed to revert job run, run skipped")
+		}
+		delete(s.lastRuns, j.Name)
+
+		return errors.Wrap(err, "failed to submit job task")
+	}
+
+	s.lastRuns[j.Name] = runAt
+	return nil
+}
+
+// loadLastRun returns the last run of the job. If the job has never run,
+// it is initialized to now, such that the first run happens at the next
+// scheduled time.
+func (s *scheduler) loadLastRun(ctx context.Context, name string, now time.Time) (time.Time, error) {
+	resp, err := s.db.GetItemRequest(&dynamodb.GetItemInput{
+		TableName:      aws.String(s.tableName),
+		Key:            map[string]dynamodb.AttributeValue{keyAttr: {S: aws.String(jobKeyPrefix + name)}},
+		ConsistentRead: aws.Bool(true),
+	}).Send(ctx)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "failed to get last run")
+	}
+
+	if v, ok := resp.Item[lastRunAttr]; ok && v.N != nil {
+		nanos, err := strconv.ParseInt(aws.StringValue(v.N), 10, 64)
+		if err != nil {
+			return time.Time{}, errors.Wrap(err, "invalid last run")
+		}
+
+		return time.Unix(0, nanos), nil
+	}
+
+	_, err = s.db.PutItemRequest(&dynamodb.PutItemInput{
+		TableName: aws.String(s.tableName),
+		Item: map[string]dynamodb.AttributeValue{
+			keyAttr:     {S: aws.String(jobKeyPrefix + name)},
+			lastRunAttr: {N: aws.String(strconv.FormatInt(now.UnixNano(), 10))},
+		},
+		ConditionExpression:      aws.String("attribute_not_exists(#key)"),
+		ExpressionAttributeNames: map[string]string{"#key": keyAttr},
+	}).Send(ctx)
+	if dynamoutil.IsConditionalCheckFailed(err) {
+		return time.Time{}, errors.New("job initialized concurrently")
+	} else if err != nil {
+		return time.Time{}, errors.Wrap(err, "failed to initialize last run")
+	}
+
+	return now, nil
+}
+
+// swapLastRun updates the last run of the job if it is still the expected
+// value, returning whether or not the update was applied.
+func (s *scheduler) swapLastRun(ctx context.Context, name string, expected, updated time.Time) (bool, error) {
+	_, err := s.db.UpdateItemRequest(&dynamodb.UpdateItemInput{
+		TableName:                aws.String(s.tableName),
+		Key:                      map[string]dynamodb.AttributeValue{keyAttr: {S: aws.String(jobKeyPrefix + name)}},
+		UpdateExpression:         aws.String("SET #last_run = :updated"),
+		ConditionExpression:      aws.String("#last_run = :expected"),
+		ExpressionAttributeNames: map[string]string{"#last_run": lastRunAttr},
+		ExpressionAttributeValues: map[string]dynamodb.AttributeValue{
+			":expected": {N: aws.String(strconv.FormatInt(expected.UnixNano(), 10))},
+			":updated":  {N: aws.String(strconv.FormatInt(updated.UnixNano(), 10))},
+		},
+	}).Send(ctx)
+	if dynamoutil.IsConditionalCheckFailed(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrap(err, "failed to update last run")
+	}
+
+	return true, nil
+}
+
+func (s *scheduler) setLeaseExpiry(t time.Time) {
+	s.leaseMu.Lock()
+	s.leaseExpiry = t
+	s.leaseMu.Unlock()
+}
@@ -0,0 +1,255 @@
+package periodic
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/dynamodbiface"
+	"github.com/google/uuid"
+	"github.com/ory/dockertest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dynamotest "github.com/kinecosystem/agora-common/aws/dynamodb/test"
+	"github.com/kinecosystem/agora-common/taskqueue/model/task"
+	"github.com/kinecosystem/agora-common/testutil"
+)
+
+var (
+	dynamoClient dynamodbiface.ClientAPI
+)
+
+func TestMain(m *testing.M) {
+	testPool, err := dockertest.NewPool("")
+	if err != nil {
+		panic("Error creating docker pool:" + err.Error())
+	}
+
+	client, cleanUpDynamo, err := dynamotest.StartDynamoDB(testPool)
+	if err != nil {
+		panic("Error starting DynamoDB image:" + err.Error())
+	}
+	dynamoClient = client
+
+	defaultConfig.PollingInterval = 50 * time.Millisecond
+	defaultConfig.LockTTL = 600 * time.Millisecond
+
+	code := m.Run()
+	cleanUpDynamo()
+	os.Exit(code)
+}
+
+func TestSchedule(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 30, 0, time.UTC)
+
+	assert.Equal(t, start.Add(time.Minute), Every(time.Minute).Next(start))
+
+	s, err := Cron("*/5 * * * *")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 1, 1, 0, 5, 0, 0, time.UTC), s.Next(start))
+
+	s, err = Cron("@hourly")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 1, 1, 1, 0, 0, 0, time.UTC), s.Next(start))
+
+	_, err = Cron("not a spec")
+	assert.Error(t, err)
+}
+
+func TestNewScheduler_Invalid(t *testing.T) {
+	msg := &task.Message{TypeName: "job"}
+
+	for _, jobs := range [][]Job{
+		{{Schedule: Every(time.Second), Message: msg}},
+		{{Name: "a", Message: msg}},
+		{{Name: "a", Schedule: Every(time.Second)}},
+		{{Name: "a", Schedule: Every(time.Second), Message: &task.Message{}}},
+		{
+			{Name: "a", Schedule: Every(time.Second), Message: msg},
+			{Name: "a", Schedule: Every(time.Second), Message: msg},
+		},
+	} {
+		_, err := NewScheduler("table", dynamoClient, &testSubmitter{}, jobs)
+		assert.Error(t, err)
+	}
+
+	_, err := NewScheduler("table", dynamoClient, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestScheduler_Every(t *testing.T) {
+	tableName := setupTable(t)
+
+	submitter := &testSubmitter{}
+	s, err := NewScheduler(tableName, dynamoClient, submitter, []Job{
+		{
+			Name:     "fast",
+			Schedule: Every(200 * time.Millisecond),
+			Message:  &task.Message{TypeName: "fast"},
+		},
+		{
+			Name:     "slow",
+			Schedule: Every(time.Hour),
+			Message:  &task.Message{TypeName: "slow"},
+		},
+	})
+	require.NoError(t, err)
+	defer s.Shutdown()
+
+	require.NoError(t, testutil.WaitFor(5*time.Second, 50*time.Millisecond, func() bool {
+		return submitter.Count("fast") >= 3
+	}))
+	assert.True(t, s.IsLeader())
+	assert.Equal(t, 0, submitter.Count("slow"))
+}
+
+func TestScheduler_LeaderElection(t *testing.T) {
+	tableName := setupTable(t)
+
+	submitter := &testSubmitter{}
+	jobs := []Job{
+		{
+			Name:     "job",
+			Schedule: Every(200 * time.Millisecond),
+			Message:  &task.Message{TypeName: "job"},
+		},
+	}
+
+	first, err := NewScheduler(tableName, dynamoClient, submitter, jobs)
+	require.NoError(t, err)
+	require.NoError(t, testutil.WaitFor(2*time.Second, 50*time.Millisecond, first.IsLeader))
+
+	second, err := NewScheduler(tableName, dynamoClient, submitter, jobs)
+	require.NoError(t, err)
+	defer second.Shutdown()
+
+	// Only a single scheduler should run the job.
+	time.Sleep(time.Second)
+	assert.True(t, first.IsLeader())
+	assert.False(t, second.IsLeader())
+
+	count := submitter.Count("job")
+	assert.True(t, count >= 3 && count <= 6, "unexpected count: %d", count)
+
+	// Shutting down the leader should release the lease, allowing the
+	// second scheduler to take over.
+	first.Shutdown()
+	require.NoError(t, testutil.WaitFor(2*time.Second, 50*time.Millisecond, second.IsLeader))
+
+	count = submitter.Count("job")
+	require.NoError(t, testutil.WaitFor(2*time.Second, 50*time.Millisecond, func() bool {
+		return submitter.Count("job") > count
+	}))
+}
+
+func TestScheduler_SubmitFailure(t *testing.T) {
+	tableName := setupTable(t)
+
+	submitter := &testSubmitter{err: errors.New("unavailable")}
+	s, err := NewScheduler(tableName, dynamoClient, submitter, []Job{
+		{
+			Name:     "job",
+			Schedule: Every(100 * time.Millisecond),
+			Message:  &task.Message{TypeName: "job"},
+		},
+	})
+	require.NoError(t, err)
+	defer s.Shutdown()
+
+	require.NoError(t, testutil.WaitFor(2*time.Second, 50*time.Millisecond, func() bool {
+		return submitter.Attempts() >= 2
+	}))
+	assert.Equal(t, 0, submitter.Count("job"))
+
+	submitter.SetError(nil)
+	require.NoError(t, testutil.WaitFor(2*time.Second, 50*time.Millisecond, func() bool {
+		return submitter.Count("job") >= 1
+	}))
+}
+
+type testSubmitter struct {
+	sync.Mutex
+	err      error
+	attempts int
+	counts   map[string]int
+}
+
+func (s *testSubmitter) Submit(_ context.Context, msg *task.Message) error {
+	s.Lock()
+	defer s.Unlock()
+
+	s.attempts++
+	if s.err != nil {
+		return s.err
+	}
+
+	if s.counts == nil {
+		s.counts = make(map[string]int)
+	}
+	s.counts[msg.TypeName]++
+	return nil
+}
+
+func (s *testSubmitter) SubmitBatch(ctx context.Context, msgs []*task.Message) error {
+	for _, m := range msgs {
+		if err := s.Submit(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *testSubmitter) SubmitWithDelay(ctx context.Context, msg *task.Message, _ time.Duration) error {
+	return s.Submit(ctx, msg)
+}
+
+func (s *testSubmitter) SetError(err error) {
+	s.Lock()
+	defer s.Unlock()
+	s.err = err
+}
+
+func (s *testSubmitter) Attempts() int {
+	s.Lock()
+	defer s.Unlock()
+	return s.attempts
+}
+
+func (s *testSubmitter) Count(typeName string) int {
+	s.Lock()
+	defer s.Unlock()
+	return s.counts[typeName]
+}
+
+func setupTable(t *testing.T) string {
+	tableName := "periodic-" + uuid.New().String()
+
+	_, err := dynamoClient.CreateTableRequest(&dynamodb.CreateTableInput{
+		TableName: aws.String(tableName),
+		KeySchema: []dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String(keyAttr),
+				KeyType:       dynamodb.KeyTypeHash,
+			},
+		},
+		AttributeDefinitions: []dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String(keyAttr),
+				AttributeType: dynamodb.ScalarAttributeTypeS,
+			},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(10),
+			WriteCapacityUnits: aws.Int64(10),
+		},
+	}).Send(context.Background())
+	require.NoError(t, err)
+
+	return tableName
+}
@@ -2,6 +2,7 @@ package taskqueue
 
 import (
 	"context"
+	"time"
 
 	"github.com/kinecosystem/agora-common/taskqueue/model/task"
 )
@@ -28,13 +29,49 @@ type ProcessorCtor func(handler Handler) (Processor, error)
 type Processor interface {
 	Submitter
 
+	// Start starts processing tasks, if the processor is not already running.
 	Start()
+
+	// Pause stops the processor from receiving new tasks, until Start or
+	// Resume is called. Tasks that are already being processed are not
+	// interrupted.
 	Pause()
+
+	// Resume resumes processing tasks after a Pause.
+	Resume()
+
 	Shutdown()
 }
 
 // Submitter submits messages to the task queue.
 type Submitter interface {
 	Submit(ctx context.Context, msg *task.Message) error
+
+	// SubmitBatch submits a batch of messages. Implementations that may
+	// partially submit a batch return a *BatchError identifying the
+	// messages that were not submitted.
 	SubmitBatch(ctx context.Context, msgs []*task.Message) error
+
+	// SubmitWithDelay submits a message that should not be processed until
+	// the delay has elapsed. Implementations may limit the maximum delay, in
+	// which case a DelayedSubmitter should be used for longer delays.
+	SubmitWithDelay(ctx context.Context, msg *task.Message, delay time.Duration) error
+}
+
+// DelayedSubmitter submits messages to the task queue that should not be
+// processed until the provided time.
+type DelayedSubmitter interface {
+	SubmitAt(ctx context.Context, msg *task.Message, at time.Time) error
+}
+
+// Drainer is implemented by processors that support draining in flight tasks
+// before shutting down.
+type Drainer interface {
+	// Drain stops the processor from receiving new tasks, and waits for in
+	// flight tasks to complete until the provided context is done. Any tasks
+	// still in flight at that point are abandoned, in which case the number
+	// of abandoned tasks is returned along with the context error.
+	//
+	// The processor cannot be restarted after being drained.
+	Drain(ctx context.Context) (abandoned int, err error)
 }
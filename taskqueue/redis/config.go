@@ -0,0 +1,119 @@
+package redis
+
+import (
+	"time"
+
+	"github.com/kinecosystem/agora-common/logging"
+	"github.com/kinecosystem/agora-common/taskqueue"
+)
+
+type config struct {
+	// TaskConcurrency configure the number of concurrent task workers
+	// in the processor.
+	TaskConcurrency int
+
+	// PollingInterval is the interval at which idle workers poll the queue.
+	//
+	// If tasks are continually available, this parameter has no effect.
+	PollingInterval time.Duration
+
+	// VisibilityTimeout is the duration a received task is hidden from other
+	// workers. Tasks that are not completed within the timeout (including
+	// tasks whose handler failed) become visible on the queue again.
+	VisibilityTimeout time.Duration
+
+	// VisibilityExtensionEnabled configures whether or not the queue should
+	// refresh the VisibilityTimeout of a task being processed.
+	//
+	// This is useful for tasks that take a significant amount of time.
+	// Tasks that utilize this feature should continually check the provided
+	// context to see if the task has been cancelled or not.
+	VisibilityExtensionEnabled bool
+
+	// MaxVisibilityExtensions is the maximum amount of of extensions that can
+	// be made for a single task before becoming visibile on the queue again.
+	MaxVisibilityExtensions int
+
+	// ErrorClassifier classifies handler errors. Tasks that fail with a
+	// permanent error are deleted rather than retried.
+	//
+	// Errors marked with taskqueue.Permanent are always permanent.
+	ErrorClassifier taskqueue.ErrorClassifier
+
+	// PausedStart indicates that the processor's initial state should be paused.
+	// In this state, the processor won't process tasks until Start() is called.
+	PausedStart bool
+
+	// Logger is the logger used by the queue. If nil, the standard
+	// logrus logger is used.
+	Logger logging.Logger
+}
+
+// Option configures a Processor.
+type Option func(c *config)
+
+// WithTaskConcurrency configures the task concurrency.
+func WithTaskConcurrency(concurrency int) Option {
+	return func(c *config) {
+		c.TaskConcurrency = concurrency
+	}
+}
+
+// WithPollingInterval configures the polling interval.
+func WithPollingInterval(interval time.Duration) Option {
+	return func(c *config) {
+		c.PollingInterval = interval
+	}
+}
+
+// WithVisibilityTimeout configures the visibility timeout.
+func WithVisibilityTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.VisibilityTimeout = timeout
+	}
+}
+
+// WithVisibilityExtensionEnabled configures whether or not visibility extensions are enabled.
+func WithVisibilityExtensionEnabled(enabled bool) Option {
+	return func(c *config) {
+		c.VisibilityExtensionEnabled = enabled
+	}
+}
+
+// WithMaxVisibilityExtensions configures the maximum number of visibility extensions per task.
+func WithMaxVisibilityExtensions(max int) Option {
+	return func(c *config) {
+		c.MaxVisibilityExtensions = max
+	}
+}
+
+// WithPausedStart configures the processor to be initialized in a paused state.
+func WithPausedStart() Option {
+	return func(c *config) {
+		c.PausedStart = true
+	}
+}
+
+// WithErrorClassifier configures the classifier used to determine whether
+// handler errors are permanent.
+func WithErrorClassifier(classifier taskqueue.ErrorClassifier) Option {
+	return func(c *config) {
+		c.ErrorClassifier = classifier
+	}
+}
+
+// WithLogger configures the logger used by the queue, instead of the
+// standard logrus logger.
+func WithLogger(log logging.Logger) Option {
+	return func(c *config) {
+		c.Logger = log
+	}
+}
+
+var defaultConfig = config{
+	TaskConcurrency:            4,
+	PollingInterval:            time.Second,
+	VisibilityTimeout:          30 * time.Second,
+	VisibilityExtensionEnabled: false,
+	MaxVisibilityExtensions:    10,
+}
@@ -0,0 +1,538 @@
+// Package redis provides a Redis backed taskqueue.Processor and taskqueue.Submitter,
+// for deployments without access to SQS.
+//
+// Each queue is stored in a set of keys sharing the queue name as a hash tag:
+//
+//	{<name>}:ready    - sorted set of task IDs, scored by the time they become visible
+//	{<name>}:inflight - sorted set of received task IDs, scored by their visibility deadline
+//	{<name>}:tasks    - hash of task ID to the serialized task
+//	{<name>}:leases   - hash of received task ID to the lease token of its current receiver
+//
+// Receiving a task atomically moves it from the ready set to the inflight set,
+// and tasks whose visibility deadline has passed are returned to the ready set.
+// A task is only removed once it has been handled, providing at-least-once
+// semantics.
+//
+// Visibility is based on the clock of the submitter or processor, so hosts are
+// expected to be reasonably in sync.
+package redis
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v7"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/kinecosystem/agora-common/logging"
+	"github.com/kinecosystem/agora-common/taskqueue"
+	"github.com/kinecosystem/agora-common/taskqueue/model/task"
+)
+
+// reclaimLimit is the maximum number of expired tasks returned to the ready
+// set per receive.
+const reclaimLimit = 100
+
+var (
+	// receiveScript returns expired inflight tasks to the ready set, then
+	// moves the first visible task (if any) to the inflight set.
+	//
+	// KEYS: ready, inflight, tasks, leases
+	// ARGV: now, visibility deadline, lease token, reclaim limit
+	//
+	// Returns nil if no task is visible, {id} if the task payload is missing,
+	// or {id, payload}.
+	receiveScript = redis.NewScript(`
+local expired = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ARGV[1], 'LIMIT', 0, tonumber(ARGV[4]))
+for _, id in ipairs(expired) do
+	redis.call('ZREM', KEYS[2], id)
+	redis.call('HDEL', KEYS[4], id)
+	redis.call('ZADD', KEYS[1], ARGV[1], id)
+end
+
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, 1)
+if #ids == 0 then
+	return false
+end
+
+local id = ids[1]
+redis.call('ZREM', KEYS[1], id)
+
+local payload = redis.call('HGET', KEYS[3], id)
+if not payload then
+	return {id}
+end
+
+redis.call('ZADD', KEYS[2], ARGV[2], id)
+redis.call('HSET', KEYS[4], id, ARGV[3])
+return {id, payload}
+`)
+
+	// deleteScript deletes a received task, if the lease is still held.
+	//
+	// KEYS: inflight, tasks, leases
+	// ARGV: id, lease token
+	deleteScript = redis.NewScript(`
+if redis.call('HGET', KEYS[3], ARGV[1]) ~= ARGV[2] then
+	return 0
+end
+
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('HDEL', KEYS[2], ARGV[1])
+redis.call('HDEL', KEYS[3], ARGV[1])
+return 1
+`)
+
+	// extendScript updates the visibility deadline of a received task, if
+	// the lease is still held.
+	//
+	// KEYS: inflight, leases
+	// ARGV: id, lease token, visibility deadline
+	extendScript = redis.NewScript(`
+if redis.call('HGET', KEYS[2], ARGV[1]) ~= ARGV[2] then
+	return 0
+end
+
+redis.call('ZADD', KEYS[1], 'XX', ARGV[3], ARGV[1])
+return 1
+`)
+)
+
+// errLeaseLost is returned when a task's lease is no longer held by the
+// receiver, typically because its visibility timeout expired.
+var errLeaseLost = errors.New("task lease lost")
+
+type queue struct {
+	log     logging.Logger
+	conf    config
+	client  redis.Cmdable
+	handler taskqueue.Handler
+	name    string
+
+	readyKey    string
+	inflightKey string
+	tasksKey    string
+	leasesKey   string
+
+	wg sync.WaitGroup
+
+	shutdownCh   chan struct{}
+	shutdownOnce sync.Once
+
+	runLock   sync.RWMutex
+	stateLock sync.Mutex
+	running   bool
+}
+
+// received is a task received from the queue.
+type received struct {
+	id      string
+	lease   string
+	wrapper *task.Wrapper
+}
+
+func NewProcessorCtor(queueName string, client redis.Cmdable, opts ...Option) taskqueue.ProcessorCtor {
+	return func(handler taskqueue.Handler) (taskqueue.Processor, error) {
+		return NewProcessor(queueName, client, handler, opts...)
+	}
+}
+
+func NewProcessor(queueName string, client redis.Cmdable, handler taskqueue.Handler, opts ...Option) (taskqueue.Processor, error) {
+	if handler == nil {
+		return nil, errors.Errorf("handler is nil")
+	}
+
+	return newQueue(queueName, client, handler, opts...)
+}
+
+func NewSubmitter(queueName string, client redis.Cmdable, opts ...Option) (taskqueue.Submitter, error) {
+	return newQueue(queueName, client, nil, opts...)
+}
+
+func newQueue(queueName string, client redis.Cmdable, handler taskqueue.Handler, opts ...Option) (*queue, error) {
+	if queueName == "" {
+		return nil, errors.New("queue name is empty")
+	}
+
+	q := &queue{
+		conf:        defaultConfig,
+		client:      client,
+		handler:     handler,
+		name:        queueName,
+		readyKey:    fmt.Sprintf("{%s}:ready", queueName),
+		inflightKey: fmt.Sprintf("{%s}:inflight", queueName),
+		tasksKey:    fmt.Sprintf("{%s}:tasks", queueName),
+		leasesKey:   fmt.Sprintf("{%s}:leases", queueName),
+		shutdownCh:  make(chan struct{}),
+	}
+
+	for _, o := range opts {
+		o(&q.conf)
+	}
+	q.log = logging.OrDefault(q.conf.Logger).WithFields(logging.Fields{
+		"type":  "taskqueue/redis",
+		"queue": queueName,
+	})
+
+	if q.conf.VisibilityTimeout <= 0 {
+		return nil, errors.New("visibility timeout must be positive")
+	}
+
+	if q.conf.PausedStart {
+		q.runLock.Lock()
+	} else {
+		q.running = true
+	}
+
+	if handler != nil {
+		q.wg.Add(q.conf.TaskConcurrency)
+		for i := 0; i < q.conf.TaskConcurrency; i++ {
+			go func(id int) {
+				q.taskWorker(id)
+			}(i)
+		}
+	}
+
+	return q, nil
+}
+
+// Submit implements taskqueue.Submitter.Submit.
+func (q *queue) Submit(ctx context.Context, msg *task.Message) error {
+	return q.submit(ctx, []*task.Message{msg}, 0)
+}
+
+// SubmitWithDelay implements taskqueue.Submitter.SubmitWithDelay.
+//
+// Unlike SQS, there is no maximum delay.
+func (q *queue) SubmitWithDelay(ctx context.Context, msg *task.Message, delay time.Duration) error {
+	if delay < 0 {
+		return errors.Errorf("delay must be non-negative: %s", delay)
+	}
+
+	return q.submit(ctx, []*task.Message{msg}, delay)
+}
+
+// SubmitBatch implements taskqueue.Submitter.SubmitBatch.
+//
+// The batch is submitted atomically.
+func (q *queue) SubmitBatch(ctx context.Context, msgs []*task.Message) error {
+	return q.submit(ctx, msgs, 0)
+}
+
+func (q *queue) submit(ctx context.Context, msgs []*task.Message, delay time.Duration) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	ctx, span := taskqueue.StartSubmitSpan(ctx, "redis", q.name, msgs...)
+	err := q.push(ctx, msgs, delay)
+	taskqueue.EndSpan(span, err)
+	return err
+}
+
+func (q *queue) push(ctx context.Context, msgs []*task.Message, delay time.Duration) error {
+	payloads := make([]string, len(msgs))
+	for i, msg := range msgs {
+		b, err := marshalTask(ctx, msg)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal task")
+		}
+		payloads[i] = string(b)
+	}
+
+	visibleAt := toScore(time.Now().Add(delay))
+
+	_, err := q.client.TxPipelined(func(pipe redis.Pipeliner) error {
+		for _, payload := range payloads {
+			id := uuid.New().String()
+			pipe.HSet(q.tasksKey, id, payload)
+			pipe.ZAdd(q.readyKey, &redis.Z{Score: visibleAt, Member: id})
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to submit task")
+	}
+
+	return nil
+}
+
+func (q *queue) Start() {
+	q.stateLock.Lock()
+	defer q.stateLock.Unlock()
+
+	if !q.running {
+		q.running = true
+		q.runLock.Unlock()
+	}
+}
+
+func (q *queue) Pause() {
+	q.stateLock.Lock()
+	defer q.stateLock.Unlock()
+
+	if q.running {
+		q.running = false
+		q.runLock.Lock()
+	}
+}
+
+func (q *queue) Resume() {
+	q.Start()
+}
+
+func (q *queue) Shutdown() {
+	q.shutdownOnce.Do(func() {
+		log := q.log.WithField("method", "Shutdown")
+		close(q.shutdownCh)
+
+		// we call start to ensure that any task worker currently
+		// blocked on the runlock becomes unblocked.
+		q.Start()
+
+		gracePeriod := q.conf.VisibilityTimeout
+		if ok := waitForGroup(&q.wg, gracePeriod); !ok {
+			log.Warnf("workers did not fully shutdown within the grace period %s", gracePeriod)
+		}
+	})
+}
+
+func (q *queue) taskWorker(id int) {
+	log := q.log.WithField("worker_id", id)
+	log.Debug("worker starting")
+	defer func() {
+		q.wg.Done()
+		log.Info("worker stopped")
+	}()
+
+	for {
+		select {
+		case <-q.shutdownCh:
+			return
+		default:
+		}
+
+		q.runLock.RLock()
+		r, err := q.receive()
+		q.runLock.RUnlock()
+
+		if err != nil {
+			log.WithError(err).Warn("failed to poll for tasks")
+			q.sleep(5 * time.Second)
+			continue
+		}
+		if r == nil {
+			q.sleep(q.conf.PollingInterval)
+			continue
+		}
+		if r.wrapper == nil {
+			// receive has logged the reason, and the next task may be
+			// immediately available.
+			continue
+		}
+
+		log.WithField("task", r.wrapper.String()).Trace("received task message")
+		err = q.processTask(r)
+		if taskqueue.ClassifyError(err, nil) == taskqueue.ErrorClassPermanent {
+			// processTask marks handler errors classified as permanent, in
+			// which case the task should not be retried.
+			log.WithField("task", r.wrapper.String()).Info("task failed permanently, deleting")
+			if err := q.deleteTask(r); err != nil {
+				log.WithError(err).Warn("failed to delete permanently failed task from queue")
+			}
+		} else if err != nil {
+			// handler is expected to do logging
+		} else if err := q.deleteTask(r); err != nil {
+			log.WithError(err).Warn("failed to delete completed task from queue")
+		}
+	}
+}
+
+// receive receives the next visible task, if any.
+//
+// Invalid tasks are deleted, in which case the returned task has no wrapper.
+func (q *queue) receive() (*received, error) {
+	now := time.Now()
+	lease := uuid.New().String()
+
+	result, err := receiveScript.Run(
+		q.client,
+		[]string{q.readyKey, q.inflightKey, q.tasksKey, q.leasesKey},
+		toScore(now),
+		toScore(now.Add(q.conf.VisibilityTimeout)),
+		lease,
+		reclaimLimit,
+	).Result()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	values, ok := result.([]interface{})
+	if !ok || len(values) == 0 {
+		return nil, errors.Errorf("unexpected receive result: %v", result)
+	}
+
+	r := &received{lease: lease}
+	if r.id, ok = values[0].(string); !ok {
+		return nil, errors.Errorf("unexpected task id: %v", values[0])
+	}
+
+	log := q.log.WithField("task_id", r.id)
+	if len(values) < 2 {
+		log.Info("got task without payload, dropping from queue")
+		return r, nil
+	}
+
+	payload, ok := values[1].(string)
+	if !ok {
+		return nil, errors.Errorf("unexpected task payload: %v", values[1])
+	}
+
+	r.wrapper, err = unmarshalTask([]byte(payload))
+	if err != nil {
+		log.WithError(err).Warn("failed to unmarshal task")
+		if err := q.deleteTask(r); err != nil {
+			log.WithError(err).Warn("failed to delete invalid task from queue")
+		}
+		r.wrapper = nil
+	}
+
+	return r, nil
+}
+
+func (q *queue) processTask(r *received) (taskErr error) {
+	ctx, span := taskqueue.StartProcessSpan(taskqueue.WrapperContext(context.Background(), r.wrapper), "redis", q.name, r.wrapper.Message)
+	defer func() {
+		taskqueue.EndSpan(span, taskErr)
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	result := make(chan error, 1)
+	go func() {
+		result <- q.handler(ctx, r.wrapper.Message)
+	}()
+
+	// extend the visibility timeout when 80% of the timeout has elapsed to be safe.
+	keepAliveInterval := q.conf.VisibilityTimeout / 5 * 4
+
+	for ext := 0; ext < q.conf.MaxVisibilityExtensions; ext++ {
+		select {
+		case <-q.shutdownCh:
+			return errors.New("processor shutting down, not waiting for task")
+		case err := <-result:
+			if err != nil && taskqueue.ClassifyError(err, q.conf.ErrorClassifier) == taskqueue.ErrorClassPermanent {
+				return taskqueue.Permanent(err)
+			}
+			return err
+
+		case <-time.After(keepAliveInterval):
+			if !q.conf.VisibilityExtensionEnabled {
+				return errors.Errorf("task handler timed out after %v (80 percent of visibility timeout)", keepAliveInterval)
+			}
+
+			if err := q.extendVisibilityTimeout(r); err != nil {
+				// just give up, let the task become visible and be processed later
+				return errors.Wrap(err, "failed to extend visibility timeout for task")
+			}
+		}
+	}
+
+	return errors.Errorf("max visibility extensions (%d) exceeded, not waiting for task", q.conf.MaxVisibilityExtensions)
+}
+
+func (q *queue) extendVisibilityTimeout(r *received) error {
+	ok, err := extendScript.Run(
+		q.client,
+		[]string{q.inflightKey, q.leasesKey},
+		r.id,
+		r.lease,
+		toScore(time.Now().Add(q.conf.VisibilityTimeout)),
+	).Int()
+	if err != nil {
+		return err
+	} else if ok == 0 {
+		return errLeaseLost
+	}
+
+	return nil
+}
+
+func (q *queue) deleteTask(r *received) error {
+	ok, err := deleteScript.Run(
+		q.client,
+		[]string{q.inflightKey, q.tasksKey, q.leasesKey},
+		r.id,
+		r.lease,
+	).Int()
+	if err != nil {
+		return err
+	} else if ok == 0 {
+		return errLeaseLost
+	}
+
+	return nil
+}
+
+// sleep sleeps for the provided duration, or until the queue is shut down.
+func (q *queue) sleep(d time.Duration) {
+	select {
+	case <-q.shutdownCh:
+	case <-time.After(d):
+	}
+}
+
+// toScore returns the sorted set score of t, in milliseconds.
+func toScore(t time.Time) float64 {
+	return float64(t.UnixNano() / int64(time.Millisecond))
+}
+
+func waitForGroup(wg *sync.WaitGroup, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	doneCh := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(doneCh)
+	}()
+
+	select {
+	case <-doneCh:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func marshalTask(ctx context.Context, msg *task.Message) ([]byte, error) {
+	if msg == nil {
+		return nil, errors.Errorf("task message is nil")
+	}
+
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return proto.Marshal(taskqueue.NewWrapper(ctx, msg))
+}
+
+func unmarshalTask(b []byte) (*task.Wrapper, error) {
+	wrapper := &task.Wrapper{}
+	if err := proto.Unmarshal(b, wrapper); err != nil {
+		return nil, err
+	}
+
+	if err := wrapper.Validate(); err != nil {
+		return nil, err
+	}
+
+	return wrapper, nil
+}
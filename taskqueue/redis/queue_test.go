@@ -0,0 +1,285 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v7"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/uuid"
+	"github.com/ory/dockertest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	redistest "github.com/kinecosystem/agora-common/redis/test"
+	"github.com/kinecosystem/agora-common/taskqueue/model/task"
+	"github.com/kinecosystem/agora-common/testutil"
+)
+
+var (
+	client *redis.Client
+)
+
+func TestMain(m *testing.M) {
+	testPool, err := dockertest.NewPool("")
+	if err != nil {
+		panic("Error creating docker pool:" + err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	connString, cleanUpRedis, err := redistest.StartRedis(ctx, testPool)
+	cancel()
+	if err != nil {
+		panic("Error starting redis image:" + err.Error())
+	}
+
+	client = redis.NewClient(&redis.Options{
+		Addr: connString,
+	})
+
+	defaultConfig.PollingInterval = 100 * time.Millisecond
+	defaultConfig.VisibilityTimeout = time.Second
+
+	code := m.Run()
+	client.Close()
+	cleanUpRedis()
+	os.Exit(code)
+}
+
+func TestTaskQueue_Basic(t *testing.T) {
+	queueName := fmt.Sprintf("%s%s", "test-queue-", uuid.New().String())
+
+	msgCh := make(chan task.Message, 100)
+	p, err := NewProcessor(queueName, client, func(ctx context.Context, msg *task.Message) error {
+		select {
+		case msgCh <- *msg:
+		default:
+			require.Fail(t, "task chan full")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	defer p.Shutdown()
+
+	s, err := NewSubmitter(queueName, client)
+	require.NoError(t, err)
+
+	expected := make(map[string]*task.Message)
+	var batch []*task.Message
+	for i := 0; i < 10; i++ {
+		msg := &task.Message{
+			TypeName: "test",
+			RawValue: []byte(fmt.Sprintf("msg-%d", i)),
+		}
+		expected[string(msg.RawValue)] = msg
+
+		if i < 5 {
+			require.NoError(t, s.Submit(context.Background(), msg))
+		} else {
+			batch = append(batch, msg)
+		}
+	}
+	require.NoError(t, s.SubmitBatch(context.Background(), batch))
+
+	require.NoError(t, testutil.WaitFor(5*time.Second, 50*time.Millisecond, func() bool {
+		return len(msgCh) == 10
+	}))
+
+	for i := 0; i < 10; i++ {
+		msg := <-msgCh
+		assert.True(t, proto.Equal(expected[string(msg.RawValue)], &msg))
+		delete(expected, string(msg.RawValue))
+	}
+	assert.Empty(t, expected)
+
+	// Handled tasks should be removed from the queue.
+	require.NoError(t, testutil.WaitFor(time.Second, 50*time.Millisecond, func() bool {
+		n, err := client.HLen(fmt.Sprintf("{%s}:tasks", queueName)).Result()
+		require.NoError(t, err)
+		return n == 0
+	}))
+}
+
+func TestTaskQueue_Invalid(t *testing.T) {
+	queueName := fmt.Sprintf("%s%s", "test-queue-", uuid.New().String())
+
+	s, err := NewSubmitter(queueName, client)
+	require.NoError(t, err)
+
+	assert.Error(t, s.Submit(context.Background(), nil))
+	assert.Error(t, s.Submit(context.Background(), &task.Message{}))
+	assert.Error(t, s.SubmitWithDelay(context.Background(), &task.Message{TypeName: "test"}, -time.Second))
+
+	_, err = NewSubmitter("", client)
+	assert.Error(t, err)
+	_, err = NewProcessor(queueName, client, nil)
+	assert.Error(t, err)
+}
+
+func TestTaskQueue_Delay(t *testing.T) {
+	queueName := fmt.Sprintf("%s%s", "test-queue-", uuid.New().String())
+
+	var mu sync.Mutex
+	received := make(map[string]time.Time)
+	p, err := NewProcessor(queueName, client, func(ctx context.Context, msg *task.Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received[msg.TypeName] = time.Now()
+		return nil
+	})
+	require.NoError(t, err)
+	defer p.Shutdown()
+
+	start := time.Now()
+	require.NoError(t, p.SubmitWithDelay(context.Background(), &task.Message{TypeName: "delayed"}, time.Second))
+	require.NoError(t, p.Submit(context.Background(), &task.Message{TypeName: "immediate"}))
+
+	require.NoError(t, testutil.WaitFor(5*time.Second, 50*time.Millisecond, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == 2
+	}))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.True(t, received["immediate"].Sub(start) < time.Second)
+	assert.True(t, received["delayed"].Sub(start) >= 900*time.Millisecond)
+}
+
+func TestTaskQueue_Retry(t *testing.T) {
+	queueName := fmt.Sprintf("%s%s", "test-queue-", uuid.New().String())
+
+	var mu sync.Mutex
+	var attempts []time.Time
+	p, err := NewProcessor(queueName, client, func(ctx context.Context, msg *task.Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts = append(attempts, time.Now())
+		if len(attempts) < 3 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	defer p.Shutdown()
+
+	require.NoError(t, p.Submit(context.Background(), &task.Message{TypeName: "test"}))
+
+	require.NoError(t, testutil.WaitFor(5*time.Second, 50*time.Millisecond, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(attempts) == 3
+	}))
+
+	// Failed tasks should only become visible after the visibility timeout.
+	mu.Lock()
+	for i := 1; i < len(attempts); i++ {
+		assert.True(t, attempts[i].Sub(attempts[i-1]) >= 900*time.Millisecond)
+	}
+	mu.Unlock()
+
+	time.Sleep(2 * time.Second)
+	mu.Lock()
+	assert.Len(t, attempts, 3)
+	mu.Unlock()
+}
+
+func TestTaskQueue_VisibilityExtension(t *testing.T) {
+	queueName := fmt.Sprintf("%s%s", "test-queue-", uuid.New().String())
+
+	var mu sync.Mutex
+	var count int
+	p, err := NewProcessor(queueName, client, func(ctx context.Context, msg *task.Message) error {
+		mu.Lock()
+		count++
+		mu.Unlock()
+
+		time.Sleep(2500 * time.Millisecond)
+		return nil
+	}, WithVisibilityExtensionEnabled(true))
+	require.NoError(t, err)
+	defer p.Shutdown()
+
+	require.NoError(t, p.Submit(context.Background(), &task.Message{TypeName: "test"}))
+
+	require.NoError(t, testutil.WaitFor(5*time.Second, 50*time.Millisecond, func() bool {
+		n, err := client.HLen(fmt.Sprintf("{%s}:tasks", queueName)).Result()
+		require.NoError(t, err)
+		return n == 0
+	}))
+
+	// The task should not have been received by another worker while it was
+	// being processed.
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, count)
+}
+
+func TestTaskQueue_LeaseLost(t *testing.T) {
+	queueName := fmt.Sprintf("%s%s", "test-queue-", uuid.New().String())
+
+	q, err := newQueue(queueName, client, nil)
+	require.NoError(t, err)
+	require.NoError(t, q.Submit(context.Background(), &task.Message{TypeName: "test"}))
+
+	r, err := q.receive()
+	require.NoError(t, err)
+	require.NotNil(t, r)
+	require.NotNil(t, r.wrapper)
+
+	// The task is invisible until the visibility timeout.
+	other, err := q.receive()
+	require.NoError(t, err)
+	assert.Nil(t, other)
+
+	time.Sleep(1100 * time.Millisecond)
+
+	other, err = q.receive()
+	require.NoError(t, err)
+	require.NotNil(t, other)
+	assert.Equal(t, r.id, other.id)
+
+	// The original receiver no longer holds the lease.
+	assert.Equal(t, errLeaseLost, q.extendVisibilityTimeout(r))
+	assert.Equal(t, errLeaseLost, q.deleteTask(r))
+	assert.NoError(t, q.extendVisibilityTimeout(other))
+	assert.NoError(t, q.deleteTask(other))
+}
+
+func TestTaskQueue_PauseResume(t *testing.T) {
+	queueName := fmt.Sprintf("%s%s", "test-queue-", uuid.New().String())
+
+	msgCh := make(chan task.Message, 100)
+	p, err := NewProcessor(queueName, client, func(ctx context.Context, msg *task.Message) error {
+		msgCh <- *msg
+		return nil
+	}, WithPausedStart())
+	require.NoError(t, err)
+	defer p.Shutdown()
+
+	require.NoError(t, p.Submit(context.Background(), &task.Message{TypeName: "test"}))
+
+	time.Sleep(500 * time.Millisecond)
+	assert.Empty(t, msgCh)
+
+	p.Start()
+	require.NoError(t, testutil.WaitFor(2*time.Second, 50*time.Millisecond, func() bool {
+		return len(msgCh) == 1
+	}))
+
+	p.Pause()
+	require.NoError(t, p.Submit(context.Background(), &task.Message{TypeName: "test"}))
+
+	time.Sleep(500 * time.Millisecond)
+	assert.Len(t, msgCh, 1)
+
+	p.Resume()
+	require.NoError(t, testutil.WaitFor(2*time.Second, 50*time.Millisecond, func() bool {
+		return len(msgCh) == 2
+	}))
+}
@@ -0,0 +1,67 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/kinecosystem/agora-common/logging"
+)
+
+type config struct {
+	// NumShards is the number of partitions scheduled tasks are spread over.
+	//
+	// Increasing the number of shards increases the write throughput of the
+	// table, at the cost of more queries per poll. Changing the number of shards
+	// after tasks have been scheduled will cause tasks in the removed shards to
+	// never be polled.
+	NumShards int
+
+	// PollingInterval is the interval at which the poller checks for due tasks.
+	//
+	// It is effectively the scheduling granularity of the scheduler.
+	PollingInterval time.Duration
+
+	// BatchSize is the maximum number of due tasks retrieved per shard query.
+	BatchSize int
+
+	// Logger is the logger used by the scheduler. If nil, the standard
+	// logrus logger is used.
+	Logger logging.Logger
+}
+
+// Option configures a Scheduler.
+type Option func(c *config)
+
+// WithNumShards configures the number of shards.
+func WithNumShards(shards int) Option {
+	return func(c *config) {
+		c.NumShards = shards
+	}
+}
+
+// WithPollingInterval configures the polling interval.
+func WithPollingInterval(interval time.Duration) Option {
+	return func(c *config) {
+		c.PollingInterval = interval
+	}
+}
+
+// WithBatchSize configures the batch size.
+func WithBatchSize(size int) Option {
+	return func(c *config) {
+		c.BatchSize = size
+	}
+}
+
+// WithLogger configures the logger used by the scheduler, instead of the
+// standard logrus logger.
+func WithLogger(log logging.Logger) Option {
+	return func(c *config) {
+		c.Logger = log
+	}
+}
+
+var defaultConfig = config{
+	NumShards:       16,
+	PollingInterval: 5 * time.Second,
+	BatchSize:       100,
+}
@@ -0,0 +1,321 @@
+// Package scheduler provides a DynamoDB backed taskqueue.DelayedSubmitter.
+//
+// SQS limits message delays to 15 minutes. The scheduler stores tasks in a
+// DynamoDB table until they are due, at which point a poller moves them onto
+// a target taskqueue.Submitter (typically an SQS queue).
+//
+// The table must have the following key schema:
+//
+//	shard      (N) - partition key
+//	execute_at (S) - sort key
+//
+// Tasks are delivered at least once. Due tasks are removed from the table
+// before they are submitted to the target, and are re-inserted should the
+// submission fail.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/dynamodbiface"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	dynamoutil "github.com/kinecosystem/agora-common/aws/dynamodb/util"
+	"github.com/kinecosystem/agora-common/logging"
+	"github.com/kinecosystem/agora-common/taskqueue"
+	"github.com/kinecosystem/agora-common/taskqueue/model/task"
+)
+
+const (
+	shardAttr     = "shard"
+	executeAtAttr = "execute_at"
+	taskAttr      = "task"
+
+	// executeAtFormat is the format of the sort key. Zero padding the timestamp
+	// ensures that the lexicographical order of the keys matches the execution
+	// order, while the uuid suffix ensures uniqueness.
+	executeAtFormat = "%020d-%s"
+
+	// Tasks are eventually submitted to SQS, so we enforce its 256 KiB limit,
+	// which comfortably fits within the 400 KB DynamoDB item limit.
+	//
+	// https://docs.aws.amazon.com/amazondynamodb/latest/developerguide/ServiceQuotas.html
+	taskByteLimit = 262144
+)
+
+// Scheduler is a taskqueue.DelayedSubmitter that moves due tasks onto a
+// target taskqueue.Submitter.
+type Scheduler interface {
+	taskqueue.DelayedSubmitter
+
+	Shutdown()
+}
+
+type scheduler struct {
+	log       logging.Logger
+	conf      config
+	db        dynamodbiface.ClientAPI
+	tableName string
+	target    taskqueue.Submitter
+
+	wg sync.WaitGroup
+
+	shutdownCh   chan struct{}
+	shutdownOnce sync.Once
+}
+
+// NewScheduler returns a Scheduler that submits due tasks to the provided target.
+func NewScheduler(tableName string, db dynamodbiface.ClientAPI, target taskqueue.Submitter, opts ...Option) (Scheduler, error) {
+	if target == nil {
+		return nil, errors.New("target is nil")
+	}
+
+	return newScheduler(tableName, db, target, opts...)
+}
+
+// NewSubmitter returns a taskqueue.DelayedSubmitter that only schedules tasks.
+//
+// A Scheduler must be running against the same table for the tasks to be
+// submitted to the underlying queue.
+func NewSubmitter(tableName string, db dynamodbiface.ClientAPI, opts ...Option) (taskqueue.DelayedSubmitter, error) {
+	return newScheduler(tableName, db, nil, opts...)
+}
+
+func newScheduler(tableName string, db dynamodbiface.ClientAPI, target taskqueue.Submitter, opts ...Option) (*scheduler, error) {
+	s := &scheduler{
+		conf:       defaultConfig,
+		db:         db,
+		tableName:  tableName,
+		target:     target,
+		shutdownCh: make(chan struct{}),
+	}
+
+	for _, o := range opts {
+		o(&s.conf)
+	}
+	s.log = logging.OrDefault(s.conf.Logger).WithFields(logging.Fields{
+		"type":  "taskqueue/scheduler",
+		"table": tableName,
+	})
+
+	if s.conf.NumShards <= 0 {
+		return nil, errors.New("number of shards must be positive")
+	}
+	if s.conf.BatchSize <= 0 {
+		return nil, errors.New("batch size must be positive")
+	}
+	if s.conf.PollingInterval <= 0 {
+		return nil, errors.New("polling interval must be positive")
+	}
+
+	if target != nil {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.poller()
+		}()
+	}
+
+	return s, nil
+}
+
+// SubmitAt implements taskqueue.DelayedSubmitter.SubmitAt.
+func (s *scheduler) SubmitAt(ctx context.Context, msg *task.Message, at time.Time) error {
+	select {
+	case <-s.shutdownCh:
+		return errors.New("scheduler shutting down")
+	default:
+	}
+
+	b, err := marshalTask(ctx, msg)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal task")
+	}
+
+	_, err = s.db.PutItemRequest(&dynamodb.PutItemInput{
+		TableName: aws.String(s.tableName),
+		Item: map[string]dynamodb.AttributeValue{
+			shardAttr:     {N: aws.String(strconv.Itoa(rand.Intn(s.conf.NumShards)))},
+			executeAtAttr: {S: aws.String(fmt.Sprintf(executeAtFormat, unixNano(at), uuid.New().String()))},
+			taskAttr:      {B: b},
+		},
+	}).Send(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to schedule task")
+	}
+
+	return nil
+}
+
+// Shutdown stops the poller, waiting for any in flight tasks to be submitted.
+func (s *scheduler) Shutdown() {
+	s.shutdownOnce.Do(func() {
+		close(s.shutdownCh)
+		s.wg.Wait()
+	})
+}
+
+func (s *scheduler) poller() {
+	log := s.log.WithField("method", "poller")
+
+	ticker := time.NewTicker(s.conf.PollingInterval)
+	defer ticker.Stop()
+
+	for {
+		for shard := 0; shard < s.conf.NumShards; shard++ {
+			select {
+			case <-s.shutdownCh:
+				return
+			default:
+			}
+
+			if err := s.pollShard(shard); err != nil {
+				log.WithError(err).WithField("shard", shard).Warn("failed to poll shard")
+			}
+		}
+
+		select {
+		case <-s.shutdownCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *scheduler) pollShard(shard int) error {
+	// Any key belonging to a task due at or before now sorts before the
+	// (zero padded) timestamp of the following nanosecond.
+	upperBound := fmt.Sprintf("%020d", unixNano(time.Now())+1)
+
+	var startKey map[string]dynamodb.AttributeValue
+	for {
+		resp, err := s.db.QueryRequest(&dynamodb.QueryInput{
+			TableName:              aws.String(s.tableName),
+			KeyConditionExpression: aws.String("#shard = :shard AND #execute_at < :upper_bound"),
+			ExpressionAttributeNames: map[string]string{
+				"#shard":      shardAttr,
+				"#execute_at": executeAtAttr,
+			},
+			ExpressionAttributeValues: map[string]dynamodb.AttributeValue{
+				":shard":       {N: aws.String(strconv.Itoa(shard))},
+				":upper_bound": {S: aws.String(upperBound)},
+			},
+			ExclusiveStartKey: startKey,
+			Limit:             aws.Int64(int64(s.conf.BatchSize)),
+		}).Send(context.Background())
+		if err != nil {
+			return errors.Wrap(err, "failed to query due tasks")
+		}
+
+		for _, item := range resp.Items {
+			if err := s.submitItem(item); err != nil {
+				return err
+			}
+		}
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			return nil
+		}
+		startKey = resp.LastEvaluatedKey
+	}
+}
+
+func (s *scheduler) submitItem(item map[string]dynamodb.AttributeValue) error {
+	log := s.log.WithFields(logging.Fields{
+		"method":     "submitItem",
+		"execute_at": aws.StringValue(item[executeAtAttr].S),
+	})
+
+	// We claim the task by deleting it, which prevents multiple pollers
+	// from submitting the same task.
+	resp, err := s.db.DeleteItemRequest(&dynamodb.DeleteItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]dynamodb.AttributeValue{
+			shardAttr:     item[shardAttr],
+			executeAtAttr: item[executeAtAttr],
+		},
+		ConditionExpression:      aws.String("attribute_exists(#shard)"),
+		ExpressionAttributeNames: map[string]string{"#shard": shardAttr},
+		ReturnValues:             dynamodb.ReturnValueAllOld,
+	}).Send(context.Background())
+	if dynamoutil.IsConditionalCheckFailed(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to claim task")
+	}
+
+	wrapper, err := unmarshalTask(resp.Attributes[taskAttr].B)
+	if err != nil {
+		// There's no point in retrying a task we cannot decode, so we drop it.
+		log.WithError(err).Warn("failed to unmarshal task, dropping")
+		return nil
+	}
+
+	// The headers of the scheduling context are restored, so they propagate
+	// to the target queue. The submission ID is retained, so that duplicate
+	// submissions of the task can be deduplicated by the target.
+	if err := s.target.Submit(taskqueue.ForwardingContext(context.Background(), wrapper), wrapper.Message); err != nil {
+		_, putErr := s.db.PutItemRequest(&dynamodb.PutItemInput{
+			TableName: aws.String(s.tableName),
+			Item:      resp.Attributes,
+		}).Send(context.Background())
+		if putErr != nil {
+			log.WithError(putErr).Warn("failed to restore task after failed submission, task lost")
+		}
+
+		return errors.Wrap(err, "failed to submit task")
+	}
+
+	return nil
+}
+
+func unixNano(t time.Time) int64 {
+	if t.Before(time.Unix(0, 0)) {
+		return 0
+	}
+
+	return t.UnixNano()
+}
+
+func marshalTask(ctx context.Context, msg *task.Message) ([]byte, error) {
+	if msg == nil {
+		return nil, errors.Errorf("task message is nil")
+	}
+
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+
+	b, err := proto.Marshal(taskqueue.NewWrapper(ctx, msg))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) > taskByteLimit {
+		return nil, errors.Errorf("encoded task payload size exceeded limit (%d/%d)", len(b), taskByteLimit)
+	}
+
+	return b, nil
+}
+
+func unmarshalTask(b []byte) (*task.Wrapper, error) {
+	wrapper := &task.Wrapper{}
+	if err := proto.Unmarshal(b, wrapper); err != nil {
+		return nil, err
+	}
+
+	if err := wrapper.Validate(); err != nil {
+		return nil, err
+	}
+
+	return wrapper, nil
+}
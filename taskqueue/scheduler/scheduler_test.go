@@ -0,0 +1,229 @@
+package scheduler
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/dynamodbiface"
+	"github.com/google/uuid"
+	"github.com/ory/dockertest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dynamotest "github.com/kinecosystem/agora-common/aws/dynamodb/test"
+	"github.com/kinecosystem/agora-common/taskqueue/model/task"
+	"github.com/kinecosystem/agora-common/testutil"
+)
+
+var (
+	dynamoClient dynamodbiface.ClientAPI
+)
+
+func TestMain(m *testing.M) {
+	testPool, err := dockertest.NewPool("")
+	if err != nil {
+		panic("Error creating docker pool:" + err.Error())
+	}
+
+	client, cleanUpDynamo, err := dynamotest.StartDynamoDB(testPool)
+	if err != nil {
+		panic("Error starting DynamoDB image:" + err.Error())
+	}
+	dynamoClient = client
+
+	defaultConfig.PollingInterval = 100 * time.Millisecond
+
+	code := m.Run()
+	cleanUpDynamo()
+	os.Exit(code)
+}
+
+func TestScheduler_SubmitAt(t *testing.T) {
+	tableName := setupTable(t)
+
+	target := &testSubmitter{}
+	s, err := NewScheduler(tableName, dynamoClient, target, WithNumShards(4))
+	require.NoError(t, err)
+	defer s.Shutdown()
+
+	due := &task.Message{TypeName: "due"}
+	future := &task.Message{TypeName: "future"}
+	delayed := &task.Message{TypeName: "delayed"}
+
+	require.NoError(t, s.SubmitAt(context.Background(), due, time.Now().Add(-time.Minute)))
+	require.NoError(t, s.SubmitAt(context.Background(), future, time.Now().Add(time.Hour)))
+	require.NoError(t, s.SubmitAt(context.Background(), delayed, time.Now().Add(time.Second)))
+
+	require.NoError(t, testutil.WaitFor(5*time.Second, 100*time.Millisecond, func() bool {
+		return len(target.Messages()) == 2
+	}))
+
+	msgs := target.Messages()
+	assert.Equal(t, "due", msgs[0].TypeName)
+	assert.Equal(t, "delayed", msgs[1].TypeName)
+
+	// The future task should remain in the table.
+	time.Sleep(500 * time.Millisecond)
+	assert.Len(t, target.Messages(), 2)
+	assert.Equal(t, 1, countItems(t, tableName))
+}
+
+func TestScheduler_SubmitFailure(t *testing.T) {
+	tableName := setupTable(t)
+
+	target := &testSubmitter{err: errors.New("unavailable")}
+	s, err := NewScheduler(tableName, dynamoClient, target)
+	require.NoError(t, err)
+	defer s.Shutdown()
+
+	require.NoError(t, s.SubmitAt(context.Background(), &task.Message{TypeName: "due"}, time.Now()))
+
+	// Failed submissions should be restored to the table, and retried.
+	require.NoError(t, testutil.WaitFor(5*time.Second, 100*time.Millisecond, func() bool {
+		return target.Attempts() >= 2
+	}))
+	assert.Empty(t, target.Messages())
+
+	target.SetError(nil)
+	require.NoError(t, testutil.WaitFor(5*time.Second, 100*time.Millisecond, func() bool {
+		return len(target.Messages()) == 1
+	}))
+	assert.Equal(t, 0, countItems(t, tableName))
+}
+
+func TestSubmitter(t *testing.T) {
+	tableName := setupTable(t)
+
+	submitter, err := NewSubmitter(tableName, dynamoClient)
+	require.NoError(t, err)
+
+	require.NoError(t, submitter.SubmitAt(context.Background(), &task.Message{TypeName: "due"}, time.Now()))
+	assert.Error(t, submitter.SubmitAt(context.Background(), nil, time.Now()))
+	assert.Error(t, submitter.SubmitAt(context.Background(), &task.Message{}, time.Now()))
+
+	// Without a scheduler, the task is never moved.
+	time.Sleep(500 * time.Millisecond)
+	assert.Equal(t, 1, countItems(t, tableName))
+
+	target := &testSubmitter{}
+	s, err := NewScheduler(tableName, dynamoClient, target)
+	require.NoError(t, err)
+	defer s.Shutdown()
+
+	require.NoError(t, testutil.WaitFor(5*time.Second, 100*time.Millisecond, func() bool {
+		return len(target.Messages()) == 1
+	}))
+}
+
+func TestScheduler_Shutdown(t *testing.T) {
+	tableName := setupTable(t)
+
+	s, err := NewScheduler(tableName, dynamoClient, &testSubmitter{})
+	require.NoError(t, err)
+
+	s.Shutdown()
+	s.Shutdown()
+
+	assert.Error(t, s.SubmitAt(context.Background(), &task.Message{TypeName: "due"}, time.Now()))
+}
+
+type testSubmitter struct {
+	sync.Mutex
+	err      error
+	attempts int
+	msgs     []*task.Message
+}
+
+func (s *testSubmitter) Submit(_ context.Context, msg *task.Message) error {
+	s.Lock()
+	defer s.Unlock()
+
+	s.attempts++
+	if s.err != nil {
+		return s.err
+	}
+
+	s.msgs = append(s.msgs, msg)
+	return nil
+}
+
+func (s *testSubmitter) SubmitBatch(ctx context.Context, msgs []*task.Message) error {
+	for _, m := range msgs {
+		if err := s.Submit(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *testSubmitter) SubmitWithDelay(ctx context.Context, msg *task.Message, _ time.Duration) error {
+	return s.Submit(ctx, msg)
+}
+
+func (s *testSubmitter) SetError(err error) {
+	s.Lock()
+	defer s.Unlock()
+	s.err = err
+}
+
+func (s *testSubmitter) Attempts() int {
+	s.Lock()
+	defer s.Unlock()
+	return s.attempts
+}
+
+func (s *testSubmitter) Messages() []*task.Message {
+	s.Lock()
+	defer s.Unlock()
+	return append([]*task.Message(nil), s.msgs...)
+}
+
+func setupTable(t *testing.T) string {
+	tableName := "scheduler-" + uuid.New().String()
+
+	_, err := dynamoClient.CreateTableRequest(&dynamodb.CreateTableInput{
+		TableName: aws.String(tableName),
+		KeySchema: []dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String(shardAttr),
+				KeyType:       dynamodb.KeyTypeHash,
+			},
+			{
+				AttributeName: aws.String(executeAtAttr),
+				KeyType:       dynamodb.KeyTypeRange,
+			},
+		},
+		AttributeDefinitions: []dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String(shardAttr),
+				AttributeType: dynamodb.ScalarAttributeTypeN,
+			},
+			{
+				AttributeName: aws.String(executeAtAttr),
+				AttributeType: dynamodb.ScalarAttributeTypeS,
+			},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(10),
+			WriteCapacityUnits: aws.Int64(10),
+		},
+	}).Send(context.Background())
+	require.NoError(t, err)
+
+	return tableName
+}
+
+func countItems(t *testing.T, tableName string) int {
+	resp, err := dynamoClient.ScanRequest(&dynamodb.ScanInput{
+		TableName: aws.String(tableName),
+	}).Send(context.Background())
+	require.NoError(t, err)
+
+	return len(resp.Items)
+}
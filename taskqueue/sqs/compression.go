@@ -0,0 +1,94 @@
+package sqs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+// Compression is a compression algorithm applied to the serialized task
+// wrapper before it is encoded into the message body.
+type Compression string
+
+const (
+	CompressionNone Compression = ""
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+)
+
+// contentEncodingAttr is the message attribute containing the Compression
+// of the message body. Messages without the attribute are uncompressed,
+// which keeps processors compatible with older submitters.
+const contentEncodingAttr = "agora-content-encoding"
+
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+func (c Compression) valid() bool {
+	switch c {
+	case CompressionNone, CompressionGzip, CompressionZstd:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c Compression) compress(b []byte) ([]byte, error) {
+	switch c {
+	case CompressionNone:
+		return b, nil
+	case CompressionGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(b); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case CompressionZstd:
+		return zstdEncoder.EncodeAll(b, nil), nil
+	default:
+		return nil, errors.Errorf("unsupported compression: %s", c)
+	}
+}
+
+func (c Compression) decompress(b []byte) ([]byte, error) {
+	switch c {
+	case CompressionNone:
+		return b, nil
+	case CompressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return ioutil.ReadAll(r)
+	case CompressionZstd:
+		return zstdDecoder.DecodeAll(b, nil)
+	default:
+		return nil, errors.Errorf("unsupported compression: %s", c)
+	}
+}
+
+// attributes returns the message attributes that indicate the compression.
+func (c Compression) attributes() map[string]sqs.MessageAttributeValue {
+	if c == CompressionNone {
+		return nil
+	}
+
+	return map[string]sqs.MessageAttributeValue{
+		contentEncodingAttr: {
+			DataType:    aws.String("String"),
+			StringValue: aws.String(string(c)),
+		},
+	}
+}
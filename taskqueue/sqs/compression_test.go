@@ -0,0 +1,91 @@
+package sqs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kinecosystem/agora-common/taskqueue/model/task"
+	"github.com/kinecosystem/agora-common/testutil"
+)
+
+func TestCompression_RoundTrip(t *testing.T) {
+	msg := &task.Message{
+		TypeName: "something",
+		RawValue: []byte(strings.Repeat("hello", 1000)),
+	}
+
+	uncompressed, err := marshalTask(context.Background(), msg, CompressionNone)
+	require.NoError(t, err)
+
+	for _, c := range []Compression{CompressionNone, CompressionGzip, CompressionZstd} {
+		body, err := marshalTask(context.Background(), msg, c)
+		require.NoError(t, err)
+		if c != CompressionNone {
+			assert.Less(t, len(body), len(uncompressed))
+		}
+
+		wrapper, err := unmarshalTask(body, c)
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(msg, wrapper.Message))
+	}
+
+	body, err := marshalTask(context.Background(), msg, CompressionGzip)
+	require.NoError(t, err)
+	_, err = unmarshalTask(body, CompressionZstd)
+	assert.Error(t, err)
+
+	_, err = marshalTask(context.Background(), msg, Compression("lz4"))
+	assert.Error(t, err)
+	_, err = unmarshalTask(uncompressed, Compression("lz4"))
+	assert.Error(t, err)
+}
+
+func TestTaskQueue_Compression(t *testing.T) {
+	queueName := fmt.Sprintf("%s%s", "test-queue-", uuid.New().String())
+	setupQueue(t, queueName)
+	defer deleteQueue(t, queueName)
+
+	_, err := NewSubmitter(queueName, sqsClient, WithCompression(Compression("lz4")))
+	assert.Error(t, err)
+
+	msg := &task.Message{
+		TypeName: "something",
+		RawValue: []byte(strings.Repeat("hello", 1000)),
+	}
+
+	// Processors should handle tasks regardless of the submitter compression.
+	for _, c := range []Compression{CompressionNone, CompressionGzip, CompressionZstd} {
+		s, err := NewSubmitter(queueName, sqsClient, WithCompression(c))
+		require.NoError(t, err)
+		require.NoError(t, s.Submit(context.Background(), msg))
+	}
+
+	msgCh := make(chan task.Message, 100)
+	defer close(msgCh)
+	p, err := NewProcessor(queueName, sqsClient, func(ctx context.Context, msg *task.Message) error {
+		select {
+		case msgCh <- *msg:
+		default:
+			require.Fail(t, "task chan full")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	defer p.Shutdown()
+
+	require.NoError(t, testutil.WaitFor(5*time.Second, 200*time.Millisecond, func() bool {
+		return len(msgCh) == 3
+	}))
+	for i := 0; i < 3; i++ {
+		received := <-msgCh
+		assert.True(t, proto.Equal(msg, &received))
+	}
+}
@@ -1,6 +1,15 @@
 package sqs
 
-import "time"
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/s3iface"
+	"golang.org/x/time/rate"
+
+	agoraconfig "github.com/kinecosystem/agora-common/config"
+	"github.com/kinecosystem/agora-common/logging"
+	"github.com/kinecosystem/agora-common/taskqueue"
+)
 
 type config struct {
 	// TaskConcurrency configure the number of concurrent task workers
@@ -23,15 +32,126 @@ type config struct {
 	// This is useful for tasks that take a significant amount of time.
 	// Tasks that utilize this feature should continually check the provided
 	// context to see if the task has been cancelled or not.
+	//
+	// Regardless of this setting, handlers may extend the VisibilityTimeout on
+	// demand by calling taskqueue.Heartbeat.
 	VisibilityExtensionEnabled bool
 
 	// MaxVisibilityExtensions is the maximum amount of of extensions that can
 	// be made for a single task before becoming visibile on the queue again.
+	//
+	// Heartbeats do not count towards this limit, and reset the timer used to
+	// automatically extend the VisibilityTimeout.
 	MaxVisibilityExtensions int
 
 	// PausedStart indicates that the processor's initial state should be paused.
 	// In this state, the processor won't process tasks until Start() is called.
 	PausedStart bool
+
+	// MessageGroupID is the default MessageGroupId of messages submitted to
+	// a FIFO queue. It may be overridden per message with the
+	// MessageGroupIDKey metadata key.
+	//
+	// See: https://docs.aws.amazon.com/AWSSimpleQueueService/latest/SQSDeveloperGuide/using-messagegroupid-property.html
+	MessageGroupID string
+
+	// ContentBasedDeduplication configures whether or not the
+	// MessageDeduplicationId of messages submitted to a FIFO queue should
+	// be derived from the task message contents, if not provided with the
+	// MessageDeduplicationIDKey metadata key.
+	//
+	// Note that the queue level content based deduplication setting is not
+	// sufficient, as the message body contains the submission time.
+	ContentBasedDeduplication bool
+
+	// Interceptors are invoked, in order, around the task handler.
+	Interceptors []taskqueue.Interceptor
+
+	// S3OffloadClient, if set, is used to offload task payloads that exceed
+	// the SQS message size limit to S3. The SQS message then only contains a
+	// pointer to the payload, which is deleted after the task is handled.
+	//
+	// Processors must be configured with a client in order to handle offloaded
+	// payloads.
+	S3OffloadClient s3iface.ClientAPI
+
+	// S3OffloadBucket is the bucket offloaded payloads are stored in.
+	S3OffloadBucket string
+
+	// S3OffloadPrefix is the key prefix of offloaded payloads.
+	S3OffloadPrefix string
+
+	// PausedConfig, if set, allows the processor to be paused and resumed
+	// at runtime. The processor is paused (or resumed) whenever the value
+	// changes, so Pause and Resume may still be used in between changes.
+	//
+	// Note that the initial value is only applied if it indicates the
+	// processor should be paused; use PausedStart for an initially paused
+	// processor that is not managed by the config.
+	PausedConfig agoraconfig.Bool
+
+	// TaskConcurrencyConfig, if set, allows the number of task workers to be
+	// scaled at runtime. TaskConcurrency is used as the initial concurrency,
+	// as well as the fallback for invalid (non-positive) values.
+	TaskConcurrencyConfig agoraconfig.Int64
+
+	// ConfigPollingInterval is the interval at which PausedConfig and
+	// TaskConcurrencyConfig are checked.
+	ConfigPollingInterval time.Duration
+
+	// Compression is the compression applied to submitted tasks.
+	//
+	// Processors decompress tasks based on the message attributes, regardless
+	// of this setting.
+	Compression Compression
+
+	// BatchSubmitAttempts is the maximum number of attempts made to submit
+	// each batch of SubmitBatch. Only entries that failed due to errors not
+	// caused by the sender are retried.
+	BatchSubmitAttempts uint
+
+	// RateLimiter, if set, limits the rate at which tasks are handled. The
+	// limiter is shared by all task workers, and may be shared across
+	// processors.
+	//
+	// Received tasks wait for the limiter before being handled, so the
+	// VisibilityTimeout should comfortably exceed TaskConcurrency / rate.
+	RateLimiter *rate.Limiter
+
+	// Deduper, if set, is consulted before invoking the handler in order to
+	// suppress duplicate deliveries of a task. It is invoked after any
+	// configured Interceptors.
+	Deduper taskqueue.Deduper
+
+	// IdempotencyKeyFunc derives the idempotency key used by the Deduper.
+	// If nil, taskqueue.DefaultIdempotencyKey is used.
+	IdempotencyKeyFunc taskqueue.IdempotencyKeyFunc
+
+	// MaxReceiveCount, if positive, is the maximum number of times a message
+	// may be received before it is considered a poison message. Poison
+	// messages are parked instead of being handled again, so a single bad
+	// payload cannot occupy a worker indefinitely.
+	MaxReceiveCount int
+
+	// ParkingQueue is the name of the queue poison messages and permanently
+	// failed tasks are moved to, acting as a dead letter queue. If empty, such
+	// messages are deleted.
+	ParkingQueue string
+
+	// ErrorClassifier classifies handler errors. Tasks that fail with a
+	// permanent error are parked rather than retried, while tasks that fail
+	// with a transient error become visible again after the VisibilityTimeout.
+	//
+	// Errors marked with taskqueue.Permanent are always permanent.
+	ErrorClassifier taskqueue.ErrorClassifier
+
+	// StatsPollingInterval, if positive, is the interval at which the depth
+	// and oldest message age of each queue is published as Prometheus gauges.
+	StatsPollingInterval time.Duration
+
+	// Logger is the logger used by the queue. If nil, the standard
+	// logrus logger is used.
+	Logger logging.Logger
 }
 
 // Option configures a Processor.
@@ -79,10 +199,147 @@ func WithPausedStart() Option {
 	}
 }
 
+// WithMessageGroupID configures the default message group ID for FIFO queues.
+func WithMessageGroupID(id string) Option {
+	return func(c *config) {
+		c.MessageGroupID = id
+	}
+}
+
+// WithContentBasedDeduplication configures FIFO queues to derive message
+// deduplication IDs from the task message contents.
+func WithContentBasedDeduplication() Option {
+	return func(c *config) {
+		c.ContentBasedDeduplication = true
+	}
+}
+
+// WithInterceptors configures interceptors to be invoked around the task handler.
+//
+// Interceptors are appended to any previously configured interceptors.
+func WithInterceptors(interceptors ...taskqueue.Interceptor) Option {
+	return func(c *config) {
+		c.Interceptors = append(c.Interceptors, interceptors...)
+	}
+}
+
+// WithS3Offload configures payloads exceeding the SQS message size limit to be
+// offloaded to the provided S3 bucket, under the provided key prefix.
+func WithS3Offload(client s3iface.ClientAPI, bucket, prefix string) Option {
+	return func(c *config) {
+		c.S3OffloadClient = client
+		c.S3OffloadBucket = bucket
+		c.S3OffloadPrefix = prefix
+	}
+}
+
+// WithPausedConfig configures a config that pauses the processor when true.
+func WithPausedConfig(paused agoraconfig.Bool) Option {
+	return func(c *config) {
+		c.PausedConfig = paused
+	}
+}
+
+// WithTaskConcurrencyConfig configures a config for the task concurrency.
+func WithTaskConcurrencyConfig(concurrency agoraconfig.Int64) Option {
+	return func(c *config) {
+		c.TaskConcurrencyConfig = concurrency
+	}
+}
+
+// WithConfigPollingInterval configures the interval at which dynamic configs are checked.
+func WithConfigPollingInterval(interval time.Duration) Option {
+	return func(c *config) {
+		c.ConfigPollingInterval = interval
+	}
+}
+
+// WithCompression configures the compression applied to submitted tasks.
+func WithCompression(compression Compression) Option {
+	return func(c *config) {
+		c.Compression = compression
+	}
+}
+
+// WithBatchSubmitAttempts configures the maximum number of attempts made to submit each batch.
+func WithBatchSubmitAttempts(attempts uint) Option {
+	return func(c *config) {
+		c.BatchSubmitAttempts = attempts
+	}
+}
+
+// WithRateLimit configures the maximum number of tasks handled per second,
+// allowing bursts of up to burst tasks.
+func WithRateLimit(tasksPerSecond float64, burst int) Option {
+	return func(c *config) {
+		c.RateLimiter = rate.NewLimiter(rate.Limit(tasksPerSecond), burst)
+	}
+}
+
+// WithRateLimiter configures a rate limiter for handled tasks, which may be
+// shared with other processors.
+func WithRateLimiter(limiter *rate.Limiter) Option {
+	return func(c *config) {
+		c.RateLimiter = limiter
+	}
+}
+
+// WithDeduper configures a deduper used to suppress duplicate deliveries of a
+// task, keyed by the provided key func (or taskqueue.DefaultIdempotencyKey if nil).
+func WithDeduper(deduper taskqueue.Deduper, keyFunc taskqueue.IdempotencyKeyFunc) Option {
+	return func(c *config) {
+		c.Deduper = deduper
+		c.IdempotencyKeyFunc = keyFunc
+	}
+}
+
+// WithMaxReceiveCount configures the number of times a message may be received
+// before it is parked as a poison message.
+func WithMaxReceiveCount(count int) Option {
+	return func(c *config) {
+		c.MaxReceiveCount = count
+	}
+}
+
+// WithParkingQueue configures the queue poison messages and permanently failed
+// tasks are moved to.
+func WithParkingQueue(queueName string) Option {
+	return func(c *config) {
+		c.ParkingQueue = queueName
+	}
+}
+
+// WithErrorClassifier configures the classifier used to determine whether
+// handler errors are permanent.
+func WithErrorClassifier(classifier taskqueue.ErrorClassifier) Option {
+	return func(c *config) {
+		c.ErrorClassifier = classifier
+	}
+}
+
+// WithStatsPollingInterval configures the interval at which queue depth and
+// age gauges are published.
+func WithStatsPollingInterval(interval time.Duration) Option {
+	return func(c *config) {
+		c.StatsPollingInterval = interval
+	}
+}
+
+// WithLogger configures the logger used by the queue, instead of the
+// standard logrus logger.
+func WithLogger(log logging.Logger) Option {
+	return func(c *config) {
+		c.Logger = log
+	}
+}
+
 var defaultConfig = config{
 	TaskConcurrency:            4,
 	PollingInterval:            10 * time.Second,
 	VisibilityTimeout:          30 * time.Second,
 	VisibilityExtensionEnabled: false,
 	MaxVisibilityExtensions:    10,
+	MessageGroupID:             "default",
+	ConfigPollingInterval:      10 * time.Second,
+	BatchSubmitAttempts:        3,
 }
@@ -0,0 +1,105 @@
+package sqs
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	// payloadLocationAttr is the message attribute containing the location of
+	// an offloaded payload, in the form s3://<bucket>/<key>.
+	//
+	// The body of such messages contains the location as well, for visibility.
+	payloadLocationAttr = "agora-payload-location"
+
+	s3Scheme = "s3://"
+)
+
+// offloadPayload uploads the encoded task body to S3, returning the pointer body
+// and attributes that should be submitted to SQS instead.
+func (q *queue) offloadPayload(ctx context.Context, body string) (string, map[string]sqs.MessageAttributeValue, error) {
+	key := q.conf.S3OffloadPrefix + uuid.New().String()
+
+	_, err := q.conf.S3OffloadClient.PutObjectRequest(&s3.PutObjectInput{
+		Bucket: aws.String(q.conf.S3OffloadBucket),
+		Key:    aws.String(key),
+		Body:   strings.NewReader(body),
+	}).Send(ctx)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to offload task payload")
+	}
+
+	location := s3Scheme + q.conf.S3OffloadBucket + "/" + key
+	return location, map[string]sqs.MessageAttributeValue{
+		payloadLocationAttr: {
+			DataType:    aws.String("String"),
+			StringValue: aws.String(location),
+		},
+	}, nil
+}
+
+// loadPayload returns the encoded task body stored at the provided location.
+func (q *queue) loadPayload(ctx context.Context, location string) (string, error) {
+	if q.conf.S3OffloadClient == nil {
+		return "", errors.New("received offloaded payload, but no s3 client is configured")
+	}
+
+	bucket, key, err := parseLocation(location)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := q.conf.S3OffloadClient.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}).Send(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to load offloaded task payload")
+	}
+	defer resp.Body.Close()
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(resp.Body); err != nil {
+		return "", errors.Wrap(err, "failed to read offloaded task payload")
+	}
+
+	return buf.String(), nil
+}
+
+// deletePayload deletes the offloaded payload stored at the provided location.
+func (q *queue) deletePayload(ctx context.Context, location string) error {
+	if q.conf.S3OffloadClient == nil {
+		return errors.New("no s3 client is configured")
+	}
+
+	bucket, key, err := parseLocation(location)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.conf.S3OffloadClient.DeleteObjectRequest(&s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}).Send(ctx)
+	return err
+}
+
+func parseLocation(location string) (bucket, key string, err error) {
+	if !strings.HasPrefix(location, s3Scheme) {
+		return "", "", errors.Errorf("unsupported payload location: %s", location)
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(location, s3Scheme), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", errors.Errorf("invalid payload location: %s", location)
+	}
+
+	return parts[0], parts[1], nil
+}
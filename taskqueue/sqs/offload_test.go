@@ -0,0 +1,123 @@
+package sqs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/uuid"
+	"github.com/ory/dockertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	s3test "github.com/kinecosystem/agora-common/aws/s3/test"
+	"github.com/kinecosystem/agora-common/taskqueue/model/task"
+	"github.com/kinecosystem/agora-common/testutil"
+)
+
+func TestTaskQueue_S3Offload(t *testing.T) {
+	pool, err := dockertest.NewPool("")
+	require.NoError(t, err)
+
+	s3Client, cleanupFunc, err := s3test.StartS3(pool)
+	require.NoError(t, err)
+	defer cleanupFunc()
+
+	_, err = s3Client.CreateBucketRequest(&s3.CreateBucketInput{
+		Bucket: aws.String("bucket"),
+	}).Send(context.Background())
+	require.NoError(t, err)
+
+	queueName := fmt.Sprintf("%s%s", "test-queue-", uuid.New().String())
+	setupQueue(t, queueName)
+	defer deleteQueue(t, queueName)
+
+	// The raw value is within the message limit, but the encoded wrapper is not.
+	large := &task.Message{
+		TypeName: "large",
+		RawValue: []byte(strings.Repeat("a", sqsByteLimit-1024)),
+	}
+	small := &task.Message{
+		TypeName: "small",
+		RawValue: []byte("hello"),
+	}
+
+	s, err := NewSubmitter(queueName, sqsClient)
+	require.NoError(t, err)
+	assert.Error(t, s.Submit(context.Background(), large))
+
+	s, err = NewSubmitter(queueName, sqsClient, WithS3Offload(s3Client, "bucket", "tasks/"))
+	require.NoError(t, err)
+	require.NoError(t, s.Submit(context.Background(), large))
+	require.NoError(t, s.SubmitBatch(context.Background(), []*task.Message{small, large}))
+
+	resp, err := s3Client.ListObjectsRequest(&s3.ListObjectsInput{
+		Bucket: aws.String("bucket"),
+		Prefix: aws.String("tasks/"),
+	}).Send(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, resp.Contents, 2)
+
+	msgCh := make(chan task.Message, 100)
+	defer close(msgCh)
+	p, err := NewProcessor(queueName, sqsClient, func(ctx context.Context, msg *task.Message) error {
+		select {
+		case msgCh <- *msg:
+		default:
+			require.Fail(t, "task chan full")
+		}
+		return nil
+	}, WithS3Offload(s3Client, "bucket", "tasks/"))
+	require.NoError(t, err)
+	defer p.Shutdown()
+
+	require.NoError(t, testutil.WaitFor(5*time.Second, 200*time.Millisecond, func() bool {
+		return len(msgCh) == 3
+	}))
+
+	var largeCount int
+	for i := 0; i < 3; i++ {
+		msg := <-msgCh
+		if msg.TypeName == "large" {
+			largeCount++
+			assert.True(t, proto.Equal(large, &msg))
+		} else {
+			assert.True(t, proto.Equal(small, &msg))
+		}
+	}
+	assert.Equal(t, 2, largeCount)
+
+	// Offloaded payloads should be deleted once handled.
+	require.NoError(t, testutil.WaitFor(2*time.Second, 100*time.Millisecond, func() bool {
+		resp, err := s3Client.ListObjectsRequest(&s3.ListObjectsInput{
+			Bucket: aws.String("bucket"),
+			Prefix: aws.String("tasks/"),
+		}).Send(context.Background())
+		require.NoError(t, err)
+		return len(resp.Contents) == 0
+	}))
+}
+
+func TestParseLocation(t *testing.T) {
+	bucket, key, err := parseLocation("s3://bucket/path/to/key")
+	require.NoError(t, err)
+	assert.Equal(t, "bucket", bucket)
+	assert.Equal(t, "path/to/key", key)
+
+	for _, invalid := range []string{
+		"",
+		"bucket/key",
+		"https://bucket/key",
+		"s3://bucket",
+		"s3://bucket/",
+		"s3:///key",
+	} {
+		_, _, err := parseLocation(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
@@ -2,10 +2,13 @@ package sqs
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
-	"math"
+	"encoding/hex"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -13,9 +16,12 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/sqs/sqsiface"
 	"github.com/golang/protobuf/proto"
 	"github.com/pkg/errors"
-	"github.com/sirupsen/logrus"
-	"google.golang.org/protobuf/types/known/timestamppb"
+	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/kinecosystem/agora-common/logging"
+	"github.com/kinecosystem/agora-common/metrics"
+	"github.com/kinecosystem/agora-common/retry"
+	"github.com/kinecosystem/agora-common/retry/backoff"
 	"github.com/kinecosystem/agora-common/taskqueue"
 	"github.com/kinecosystem/agora-common/taskqueue/model/task"
 )
@@ -31,23 +37,98 @@ const (
 	// https://docs.aws.amazon.com/AWSSimpleQueueService/latest/SQSDeveloperGuide/sqs-client-side-buffering-request-batching.html
 	// https://docs.aws.amazon.com/AWSSimpleQueueService/latest/SQSDeveloperGuide/quotas-messages.html
 	sqsBatchLimit = 10
+
+	// FIFO queue names must end with the .fifo suffix.
+	//
+	// https://docs.aws.amazon.com/AWSSimpleQueueService/latest/SQSDeveloperGuide/FIFO-queues.html
+	fifoSuffix = ".fifo"
+
+	// Maximum delay of a message.
+	//
+	// https://docs.aws.amazon.com/AWSSimpleQueueService/latest/SQSDeveloperGuide/sqs-message-timers.html
+	sqsMaxDelay = 15 * time.Minute
+)
+
+const (
+	// MessageGroupIDKey is the task metadata key used to override the
+	// MessageGroupId of a message submitted to a FIFO queue.
+	MessageGroupIDKey = "sqs.message_group_id"
+
+	// MessageDeduplicationIDKey is the task metadata key used to set the
+	// MessageDeduplicationId of a message submitted to a FIFO queue.
+	MessageDeduplicationIDKey = "sqs.message_deduplication_id"
+)
+
+var (
+	visibilityExtensionCounterVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "taskqueue",
+		Subsystem: "sqs",
+		Name:      "visibility_extensions_total",
+		Help:      "Number of visibility timeout extensions made for tasks being handled",
+	}, []string{"queue", "trigger"})
+	parkedMessageCounterVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "taskqueue",
+		Subsystem: "sqs",
+		Name:      "parked_messages_total",
+		Help:      "Number of poison or permanently failed messages parked",
+	}, []string{"queue", "reason"})
 )
 
+func init() {
+	visibilityExtensionCounterVec = metrics.Register(visibilityExtensionCounterVec).(*prometheus.CounterVec)
+	parkedMessageCounterVec = metrics.Register(parkedMessageCounterVec).(*prometheus.CounterVec)
+}
+
 type queue struct {
-	log      *logrus.Entry
+	log      logging.Logger
 	conf     config
 	sqs      sqsiface.ClientAPI
 	queueURL string
+	fifo     bool
 	handler  taskqueue.Handler
 
+	// parkingURL is the url of the queue poison messages are moved to, if any.
+	parkingURL string
+
+	// sources are the queues tasks are received from. The first source is
+	// the queue tasks are submitted to.
+	sources     []source
+	totalWeight int
+
 	wg sync.WaitGroup
 
+	// shutdownCh is closed once the processor stops receiving tasks, and
+	// abandonCh once in flight tasks should no longer be waited for.
 	shutdownCh   chan struct{}
+	abandonCh    chan struct{}
 	shutdownOnce sync.Once
+	stopOnce     sync.Once
+	abandonOnce  sync.Once
+
+	// pollCtx is cancelled when the processor stops, interrupting long polls.
+	pollCtx    context.Context
+	cancelPoll context.CancelFunc
+
+	// inFlight is the number of tasks currently being handled.
+	inFlight int64
+
+	// lastPoll is the time (in unix nanoseconds) of the last successful poll,
+	// and pollErrors the number of failed polls since.
+	lastPoll   int64
+	pollErrors int64
+
+	// oldestAges is the age of the oldest message received from each source
+	// since stats were last published, guarded by statsLock.
+	statsLock  sync.Mutex
+	oldestAges map[string]time.Duration
 
 	runLock   sync.RWMutex
 	stateLock sync.Mutex
 	running   bool
+
+	workerLock   sync.Mutex
+	workers      []chan struct{}
+	nextWorkerID int
 }
 
 func NewProcessorCtor(queueName string, sqsClient sqsiface.ClientAPI, opts ...Option) taskqueue.ProcessorCtor {
@@ -69,20 +150,47 @@ func NewSubmitter(queueName string, sqsClient sqsiface.ClientAPI, opts ...Option
 }
 
 func newQueue(queueName string, sqsClient sqsiface.ClientAPI, handler taskqueue.Handler, opts ...Option) (*queue, error) {
+	return newWeightedQueue([]WeightedQueue{{Name: queueName, Weight: 1}}, sqsClient, handler, opts...)
+}
+
+func newWeightedQueue(queues []WeightedQueue, sqsClient sqsiface.ClientAPI, handler taskqueue.Handler, opts ...Option) (*queue, error) {
+	if len(queues) == 0 {
+		return nil, errors.New("no queues provided")
+	}
+
+	queueName := queues[0].Name
 	q := &queue{
-		log: logrus.StandardLogger().WithFields(logrus.Fields{
-			"type":  "taskqueue/sqs",
-			"queue": queueName,
-		}),
 		conf:       defaultConfig,
 		sqs:        sqsClient,
+		fifo:       strings.HasSuffix(queueName, fifoSuffix),
 		shutdownCh: make(chan struct{}),
+		abandonCh:  make(chan struct{}),
 		handler:    handler,
+		oldestAges: make(map[string]time.Duration),
 	}
 
+	q.pollCtx, q.cancelPoll = context.WithCancel(context.Background())
+	q.lastPoll = time.Now().UnixNano()
+
 	for _, o := range opts {
 		o(&q.conf)
 	}
+	q.log = logging.OrDefault(q.conf.Logger).WithFields(logging.Fields{
+		"type":  "taskqueue/sqs",
+		"queue": queueName,
+	})
+
+	if !q.conf.Compression.valid() {
+		return nil, errors.Errorf("unsupported compression: %s", q.conf.Compression)
+	}
+
+	if handler != nil {
+		interceptors := q.conf.Interceptors
+		if q.conf.Deduper != nil {
+			interceptors = append(interceptors[:len(interceptors):len(interceptors)], taskqueue.DedupeInterceptor(q.conf.Deduper, q.conf.IdempotencyKeyFunc, q.log))
+		}
+		q.handler = taskqueue.ChainInterceptors(handler, interceptors...)
+	}
 
 	if q.conf.PausedStart {
 		q.runLock.Lock()
@@ -90,20 +198,62 @@ func newQueue(queueName string, sqsClient sqsiface.ClientAPI, handler taskqueue.
 		q.running = true
 	}
 
-	resp, err := sqsClient.GetQueueUrlRequest(&sqs.GetQueueUrlInput{
-		QueueName: aws.String(queueName),
-	}).Send(context.Background())
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to get queue url")
+	for _, wq := range queues {
+		if wq.Weight <= 0 {
+			return nil, errors.Errorf("weight of queue %s must be positive", wq.Name)
+		}
+
+		resp, err := sqsClient.GetQueueUrlRequest(&sqs.GetQueueUrlInput{
+			QueueName: aws.String(wq.Name),
+		}).Send(context.Background())
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get queue url of %s", wq.Name)
+		}
+
+		q.sources = append(q.sources, source{
+			name:   wq.Name,
+			url:    aws.StringValue(resp.QueueUrl),
+			weight: wq.Weight,
+		})
+		q.totalWeight += wq.Weight
+	}
+	q.queueURL = q.sources[0].url
+
+	if handler != nil && q.conf.ParkingQueue != "" {
+		resp, err := sqsClient.GetQueueUrlRequest(&sqs.GetQueueUrlInput{
+			QueueName: aws.String(q.conf.ParkingQueue),
+		}).Send(context.Background())
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get queue url of parking queue %s", q.conf.ParkingQueue)
+		}
+		q.parkingURL = aws.StringValue(resp.QueueUrl)
 	}
-	q.queueURL = aws.StringValue(resp.QueueUrl)
 
 	if handler != nil {
-		q.wg.Add(q.conf.TaskConcurrency)
-		for i := 0; i < q.conf.TaskConcurrency; i++ {
-			go func(id int) {
-				q.taskWorker(id)
-			}(i)
+		q.setConcurrency(q.desiredConcurrency())
+
+		if q.conf.TaskConcurrencyConfig != nil {
+			q.wg.Add(1)
+			go func() {
+				defer q.wg.Done()
+				q.watchConcurrencyConfig()
+			}()
+		}
+
+		if q.conf.PausedConfig != nil {
+			q.wg.Add(1)
+			go func() {
+				defer q.wg.Done()
+				q.watchPausedConfig()
+			}()
+		}
+
+		if q.conf.StatsPollingInterval > 0 {
+			q.wg.Add(1)
+			go func() {
+				defer q.wg.Done()
+				q.watchStats()
+			}()
 		}
 	}
 
@@ -112,21 +262,56 @@ func newQueue(queueName string, sqsClient sqsiface.ClientAPI, handler taskqueue.
 
 // Submit implements taskqueue.Submitter.Submit,
 func (q *queue) Submit(ctx context.Context, msg *task.Message) error {
+	return q.submit(ctx, msg, 0)
+}
+
+// SubmitWithDelay implements taskqueue.Submitter.SubmitWithDelay.
+//
+// The delay must not exceed 15 minutes, and is not supported by FIFO queues.
+func (q *queue) SubmitWithDelay(ctx context.Context, msg *task.Message, delay time.Duration) error {
+	if delay < 0 || delay > sqsMaxDelay {
+		return errors.Errorf("delay must be between 0 and %s", sqsMaxDelay)
+	}
+	if q.fifo && delay > 0 {
+		return errors.New("per message delays are not supported by FIFO queues")
+	}
+
+	return q.submit(ctx, msg, delay)
+}
+
+func (q *queue) submit(ctx context.Context, msg *task.Message, delay time.Duration) error {
+	ctx, span := taskqueue.StartSubmitSpan(ctx, "sqs", q.sources[0].name, msg)
+	err := q.sendMessage(ctx, msg, delay)
+	taskqueue.EndSpan(span, err)
+	return err
+}
+
+func (q *queue) sendMessage(ctx context.Context, msg *task.Message, delay time.Duration) error {
 	select {
 	case <-q.shutdownCh:
 		return errors.New("queue shutting down")
 	default:
 	}
 
-	msgBody, err := marshalTask(msg)
+	msgBody, attrs, err := q.encodeTask(ctx, msg)
 	if err != nil {
 		return errors.Wrap(err, "failed to marshal task")
 	}
 
-	_, err = q.sqs.SendMessageRequest(&sqs.SendMessageInput{
-		QueueUrl:    aws.String(q.queueURL),
-		MessageBody: aws.String(msgBody),
-	}).Send(ctx)
+	groupID, dedupID := q.fifoAttributes(msg)
+
+	input := &sqs.SendMessageInput{
+		QueueUrl:               aws.String(q.queueURL),
+		MessageBody:            aws.String(msgBody),
+		MessageAttributes:      attrs,
+		MessageGroupId:         groupID,
+		MessageDeduplicationId: dedupID,
+	}
+	if delay > 0 {
+		input.DelaySeconds = aws.Int64(int64(delay.Seconds()))
+	}
+
+	_, err = q.sqs.SendMessageRequest(input).Send(ctx)
 	if err != nil {
 		return errors.Wrap(err, "failed to submit task")
 	}
@@ -135,39 +320,182 @@ func (q *queue) Submit(ctx context.Context, msg *task.Message) error {
 }
 
 // SubmitBatch implements taskqueue.Submitter.SubmitBatch,
+//
+// Messages are submitted in batches within the SQS entry and size limits, and
+// entries that fail to be submitted are retried. If any message could not be
+// submitted, a *taskqueue.BatchError is returned. The remaining messages are
+// submitted regardless.
 func (q *queue) SubmitBatch(ctx context.Context, msgs []*task.Message) error {
+	ctx, span := taskqueue.StartSubmitSpan(ctx, "sqs", q.sources[0].name, msgs...)
+	err := q.submitBatch(ctx, msgs)
+	taskqueue.EndSpan(span, err)
+	return err
+}
+
+func (q *queue) submitBatch(ctx context.Context, msgs []*task.Message) error {
 	select {
 	case <-q.shutdownCh:
 		return errors.New("queue shutting down")
 	default:
 	}
 
-	entries := make([]sqs.SendMessageBatchRequestEntry, len(msgs))
+	var failures []taskqueue.BatchFailure
+	entries := make([]sqs.SendMessageBatchRequestEntry, 0, len(msgs))
 	for i := 0; i < len(msgs); i++ {
-		msgBody, err := marshalTask(msgs[i])
+		msgBody, attrs, err := q.encodeTask(ctx, msgs[i])
 		if err != nil {
-			return errors.Wrap(err, "failed to marshal task")
+			failures = append(failures, taskqueue.BatchFailure{
+				Index: i,
+				Err:   errors.Wrap(err, "failed to marshal task"),
+			})
+			continue
 		}
 
-		entries[i] = sqs.SendMessageBatchRequestEntry{
-			Id:          aws.String(strconv.Itoa(i)),
-			MessageBody: aws.String(msgBody),
-		}
+		groupID, dedupID := q.fifoAttributes(msgs[i])
+
+		entries = append(entries, sqs.SendMessageBatchRequestEntry{
+			Id:                     aws.String(strconv.Itoa(i)),
+			MessageBody:            aws.String(msgBody),
+			MessageAttributes:      attrs,
+			MessageGroupId:         groupID,
+			MessageDeduplicationId: dedupID,
+		})
 	}
 
-	for batchStart := 0; batchStart < len(entries); batchStart += sqsBatchLimit {
-		batchEnd := int(math.Min(float64(batchStart+sqsBatchLimit), float64(len(entries))))
+	for _, batch := range chunkEntries(entries) {
+		failures = append(failures, q.sendBatch(ctx, batch)...)
+	}
 
-		_, err := q.sqs.SendMessageBatchRequest(&sqs.SendMessageBatchInput{
-			QueueUrl: aws.String(q.queueURL),
-			Entries:  entries[batchStart:batchEnd],
-		}).Send(ctx)
-		if err != nil {
-			return errors.Wrap(err, "failed to submit task")
+	return taskqueue.NewBatchError(len(msgs), failures)
+}
+
+// sendBatch sends a batch of entries, retrying entries that failed due to
+// errors that were not caused by the sender. It returns the entries that
+// could not be sent.
+func (q *queue) sendBatch(ctx context.Context, entries []sqs.SendMessageBatchRequestEntry) []taskqueue.BatchFailure {
+	var failures []taskqueue.BatchFailure
+	pending := entries
+
+	_, err := retry.Retry(
+		func() error {
+			resp, err := q.sqs.SendMessageBatchRequest(&sqs.SendMessageBatchInput{
+				QueueUrl: aws.String(q.queueURL),
+				Entries:  pending,
+			}).Send(ctx)
+			if err != nil {
+				return errors.Wrap(err, "failed to submit task")
+			}
+
+			if len(resp.Failed) == 0 {
+				pending = nil
+				return nil
+			}
+
+			byID := make(map[string]sqs.SendMessageBatchRequestEntry, len(pending))
+			for _, e := range pending {
+				byID[aws.StringValue(e.Id)] = e
+			}
+
+			var retriable []sqs.SendMessageBatchRequestEntry
+			for _, f := range resp.Failed {
+				entry, ok := byID[aws.StringValue(f.Id)]
+				if !ok {
+					continue
+				}
+
+				if aws.BoolValue(f.SenderFault) {
+					failures = append(failures, taskqueue.BatchFailure{
+						Index: entryIndex(entry),
+						Err:   batchEntryError(f),
+					})
+				} else {
+					retriable = append(retriable, entry)
+				}
+			}
+
+			pending = retriable
+			if len(pending) > 0 {
+				return errors.Errorf("failed to submit %d tasks", len(pending))
+			}
+			return nil
+		},
+		retry.Limit(q.conf.BatchSubmitAttempts),
+		retry.BackoffWithJitter(backoff.BinaryExponential(100*time.Millisecond), time.Second, 0.1),
+	)
+	for _, e := range pending {
+		failures = append(failures, taskqueue.BatchFailure{
+			Index: entryIndex(e),
+			Err:   err,
+		})
+	}
+
+	return failures
+}
+
+// chunkEntries splits entries into batches within the SQS entry and total
+// payload size limits.
+func chunkEntries(entries []sqs.SendMessageBatchRequestEntry) [][]sqs.SendMessageBatchRequestEntry {
+	var batches [][]sqs.SendMessageBatchRequestEntry
+	var batch []sqs.SendMessageBatchRequestEntry
+	var batchSize int
+
+	for _, e := range entries {
+		size := entrySize(e)
+		if len(batch) == sqsBatchLimit || (len(batch) > 0 && batchSize+size > sqsByteLimit) {
+			batches = append(batches, batch)
+			batch = nil
+			batchSize = 0
 		}
+
+		batch = append(batch, e)
+		batchSize += size
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
 	}
 
-	return nil
+	return batches
+}
+
+// entrySize returns the size of an entry, as counted against the SQS limits.
+func entrySize(e sqs.SendMessageBatchRequestEntry) int {
+	size := len(aws.StringValue(e.MessageBody))
+	for name, attr := range e.MessageAttributes {
+		size += len(name) + len(aws.StringValue(attr.DataType)) + len(aws.StringValue(attr.StringValue)) + len(attr.BinaryValue)
+	}
+	return size
+}
+
+// entryIndex returns the index of the message the entry was created for.
+func entryIndex(e sqs.SendMessageBatchRequestEntry) int {
+	// The entry IDs are always generated from the index.
+	i, _ := strconv.Atoi(aws.StringValue(e.Id))
+	return i
+}
+
+func batchEntryError(f sqs.BatchResultErrorEntry) error {
+	return errors.Errorf("failed to submit task: %s: %s", aws.StringValue(f.Code), aws.StringValue(f.Message))
+}
+
+// fifoAttributes returns the MessageGroupId and MessageDeduplicationId for
+// the provided message. Both are nil for standard queues.
+func (q *queue) fifoAttributes(msg *task.Message) (groupID, dedupID *string) {
+	if !q.fifo {
+		return nil, nil
+	}
+
+	groupID = aws.String(q.conf.MessageGroupID)
+	if id, ok := msg.Metadata[MessageGroupIDKey]; ok {
+		groupID = aws.String(id)
+	}
+
+	if id, ok := msg.Metadata[MessageDeduplicationIDKey]; ok {
+		dedupID = aws.String(id)
+	} else if q.conf.ContentBasedDeduplication {
+		dedupID = aws.String(contentDeduplicationID(msg))
+	}
+
+	return groupID, dedupID
 }
 
 func (q *queue) Start() {
@@ -177,6 +505,10 @@ func (q *queue) Start() {
 	if !q.running {
 		q.running = true
 		q.runLock.Unlock()
+
+		// The processor did not poll while paused, which should not count
+		// against its health.
+		atomic.StoreInt64(&q.lastPoll, time.Now().UnixNano())
 	}
 }
 
@@ -190,23 +522,184 @@ func (q *queue) Pause() {
 	}
 }
 
+func (q *queue) Resume() {
+	q.Start()
+}
+
+// watchPausedConfig pauses or resumes the processor whenever the paused
+// config value changes.
+func (q *queue) watchPausedConfig() {
+	log := q.log.WithField("method", "watchPausedConfig")
+
+	ticker := time.NewTicker(q.conf.ConfigPollingInterval)
+	defer ticker.Stop()
+
+	var lastPaused bool
+	var initialized bool
+	for {
+		paused := q.conf.PausedConfig.Get(context.Background())
+		if !initialized || paused != lastPaused {
+			if paused {
+				log.Info("pausing processor")
+				q.Pause()
+			} else if initialized {
+				log.Info("resuming processor")
+				q.Resume()
+			}
+
+			lastPaused = paused
+			initialized = true
+		}
+
+		select {
+		case <-q.shutdownCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown stops the processor, abandoning any in flight tasks.
+//
+// Use Drain to wait for in flight tasks to complete.
 func (q *queue) Shutdown() {
 	q.shutdownOnce.Do(func() {
 		log := q.log.WithField("method", "Shutdown")
+		q.stop()
+		q.abandon()
+
+		gracePeriod := q.conf.VisibilityTimeout
+		if ok := waitForGroup(&q.wg, gracePeriod); !ok {
+			log.Warnf("workers did not fully shutdown within the grace period %s", gracePeriod)
+		}
+	})
+}
+
+// Drain implements taskqueue.Drainer.Drain.
+func (q *queue) Drain(ctx context.Context) (abandoned int, err error) {
+	log := q.log.WithField("method", "Drain")
+	q.stop()
+
+	doneCh := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(doneCh)
+	}()
+
+	select {
+	case <-doneCh:
+		return 0, nil
+	case <-ctx.Done():
+		abandoned = int(atomic.LoadInt64(&q.inFlight))
+		q.abandon()
+
+		log.WithField("abandoned", abandoned).Warn("in flight tasks did not complete before the drain deadline")
+		return abandoned, ctx.Err()
+	}
+}
+
+// PollHealth implements taskqueue.HealthReporter.PollHealth.
+func (q *queue) PollHealth() taskqueue.PollHealth {
+	q.stateLock.Lock()
+	running := q.running
+	q.stateLock.Unlock()
+
+	select {
+	case <-q.shutdownCh:
+		running = false
+	default:
+	}
+
+	return taskqueue.PollHealth{
+		LastSuccessfulPoll: time.Unix(0, atomic.LoadInt64(&q.lastPoll)),
+		ConsecutiveErrors:  int(atomic.LoadInt64(&q.pollErrors)),
+		Paused:             !running,
+	}
+}
+
+// stop stops the processor from receiving new tasks.
+func (q *queue) stop() {
+	q.stopOnce.Do(func() {
 		close(q.shutdownCh)
+		q.cancelPoll()
 
 		// we call start to ensure that any task worker currently
 		// blocked on the runlock becomes unblocked.
 		q.Start()
+	})
+}
 
-		gracePeriod := q.conf.VisibilityTimeout
-		if ok := waitForGroup(&q.wg, gracePeriod); !ok {
-			log.Warnf("workers did not fully shutdown within the grace period %s", gracePeriod)
-		}
+// abandon stops waiting for in flight tasks. Their handler contexts are
+// cancelled, and the messages become visible again after the visibility timeout.
+func (q *queue) abandon() {
+	q.abandonOnce.Do(func() {
+		close(q.abandonCh)
 	})
 }
 
-func (q *queue) taskWorker(id int) {
+// setConcurrency starts or stops task workers until there are n workers running.
+//
+// Stopped workers finish processing their current task before exiting.
+func (q *queue) setConcurrency(n int) {
+	q.workerLock.Lock()
+	defer q.workerLock.Unlock()
+
+	if len(q.workers) != n {
+		q.log.WithFields(logging.Fields{
+			"previous": len(q.workers),
+			"current":  n,
+		}).Info("updating task concurrency")
+	}
+
+	for len(q.workers) < n {
+		stopCh := make(chan struct{})
+		q.workers = append(q.workers, stopCh)
+
+		q.wg.Add(1)
+		go func(id int) {
+			q.taskWorker(id, stopCh)
+		}(q.nextWorkerID)
+		q.nextWorkerID++
+	}
+
+	for len(q.workers) > n {
+		last := len(q.workers) - 1
+		close(q.workers[last])
+		q.workers = q.workers[:last]
+	}
+}
+
+// desiredConcurrency returns the number of task workers that should be running.
+func (q *queue) desiredConcurrency() int {
+	if q.conf.TaskConcurrencyConfig == nil {
+		return q.conf.TaskConcurrency
+	}
+
+	desired := int(q.conf.TaskConcurrencyConfig.Get(context.Background()))
+	if desired <= 0 {
+		return q.conf.TaskConcurrency
+	}
+
+	return desired
+}
+
+// watchConcurrencyConfig scales the number of task workers whenever the
+// concurrency config changes.
+func (q *queue) watchConcurrencyConfig() {
+	ticker := time.NewTicker(q.conf.ConfigPollingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.shutdownCh:
+			return
+		case <-ticker.C:
+			q.setConcurrency(q.desiredConcurrency())
+		}
+	}
+}
+
+func (q *queue) taskWorker(id int, stopCh <-chan struct{}) {
 	log := q.log.WithField("worker_id", id)
 	log.Debug("worker starting")
 	defer func() {
@@ -218,40 +711,88 @@ func (q *queue) taskWorker(id int) {
 		select {
 		case <-q.shutdownCh:
 			return
+		case <-stopCh:
+			return
 		default:
 		}
 
 		q.runLock.RLock()
-		resp, err := q.sqs.ReceiveMessageRequest(&sqs.ReceiveMessageInput{
-			QueueUrl:            aws.String(q.queueURL),
-			MaxNumberOfMessages: aws.Int64(1),
-			VisibilityTimeout:   aws.Int64(int64(q.conf.VisibilityTimeout.Seconds())),
-			WaitTimeSeconds:     aws.Int64(int64(q.conf.PollingInterval.Seconds())),
-		}).Send(context.Background())
+		src, msgs, err := q.receive(q.pickSource())
 		q.runLock.RUnlock()
 
+		select {
+		case <-q.shutdownCh:
+			// Messages received while stopping have not been started, so
+			// we release them rather than waiting for them to become visible.
+			for _, msg := range msgs {
+				if err := q.releaseMessage(src.url, aws.StringValue(msg.ReceiptHandle)); err != nil {
+					log.WithError(err).Warn("failed to release message")
+				}
+			}
+			return
+		default:
+		}
+
 		if err != nil {
+			atomic.AddInt64(&q.pollErrors, 1)
 			log.WithError(err).Warn("failed to poll for tasks")
 			time.Sleep(5 * time.Second)
 			continue
 		}
+		atomic.StoreInt64(&q.lastPoll, time.Now().UnixNano())
+		atomic.StoreInt64(&q.pollErrors, 0)
 
-		for _, msg := range resp.Messages {
+		for _, msg := range msgs {
 			receiptHandle := aws.StringValue(msg.ReceiptHandle)
+			q.observeMessageAge(src, msg)
 
 			if msg.Body == nil {
 				log.WithField("message", msg.String()).Info("got empty message, deleting from queue")
-				if err := q.deleteMessage(receiptHandle); err != nil {
+				if err := q.deleteMessage(src.url, receiptHandle); err != nil {
 					log.WithError(err).Warn("failed to delete empty message from queue")
 				}
 				continue
 			}
 
-			wrapper, err := unmarshalTask(aws.StringValue(msg.Body))
+			if q.conf.MaxReceiveCount > 0 && receiveCount(msg) > q.conf.MaxReceiveCount {
+				log.WithFields(logging.Fields{
+					"message_id":    aws.StringValue(msg.MessageId),
+					"receive_count": receiveCount(msg),
+				}).Warn("max receive count exceeded, parking poison message")
+				if err := q.parkMessage(src, msg, "max_receive_count"); err != nil {
+					log.WithError(err).Warn("failed to park poison message")
+				}
+				continue
+			}
+
+			body := aws.StringValue(msg.Body)
+			location := aws.StringValue(msg.MessageAttributes[payloadLocationAttr].StringValue)
+			if location != "" {
+				body, err = q.loadPayload(context.Background(), location)
+				if err != nil {
+					// The payload may be temporarily unavailable, so we let the
+					// message become visible again.
+					log.WithError(err).Warn("failed to load offloaded payload")
+					continue
+				}
+			}
+
+			compression := Compression(aws.StringValue(msg.MessageAttributes[contentEncodingAttr].StringValue))
+			wrapper, err := unmarshalTask(body, compression)
 			if err != nil {
 				log.WithError(err).Warn("failed to unmarshal message")
-				if err := q.deleteMessage(receiptHandle); err != nil {
+				if err := q.deleteMessage(src.url, receiptHandle); err != nil {
 					log.WithError(err).Warn("failed to delete invalid message from queue")
+				} else {
+					q.cleanupPayload(location)
+				}
+				continue
+			}
+
+			if err := q.waitForRateLimit(); err != nil {
+				// The processor is stopping, so the task should be picked up elsewhere.
+				if err := q.releaseMessage(src.url, receiptHandle); err != nil {
+					log.WithError(err).Warn("failed to release message")
 				}
 				continue
 			}
@@ -259,64 +800,190 @@ func (q *queue) taskWorker(id int) {
 			// todo(metrics): add metric for now() - submissionTime
 
 			log.WithField("task", wrapper.String()).Trace("received task message")
-			if err := q.processTask(receiptHandle, q.conf.VisibilityTimeout, wrapper.Message); err != nil {
+			atomic.AddInt64(&q.inFlight, 1)
+			err = q.processTask(src, receiptHandle, q.conf.VisibilityTimeout, wrapper)
+			atomic.AddInt64(&q.inFlight, -1)
+
+			if taskqueue.ClassifyError(err, nil) == taskqueue.ErrorClassPermanent {
+				// processTask marks handler errors classified as permanent,
+				// in which case the task should not be retried.
+				log.WithField("task", wrapper.String()).Info("task failed permanently, parking message")
+				if err := q.parkMessage(src, msg, "permanent_error"); err != nil {
+					log.WithError(err).Warn("failed to park permanently failed message")
+				}
+			} else if err != nil {
 				// handler is expected to do logging
 				// todo(metrics): meter failed processing
-			} else if err := q.deleteMessage(receiptHandle); err != nil {
+			} else if err := q.deleteMessage(src.url, receiptHandle); err != nil {
 				log.WithError(err).Warn("failed to delete completed message from queue")
 				// todo(metrics): add metrics for success + timing (regardless of fail)
+			} else {
+				q.cleanupPayload(location)
 			}
 		}
 	}
 }
 
-func (q *queue) processTask(handle string, visibilityTimeout time.Duration, msg *task.Message) error {
-	ctx, cancel := context.WithCancel(context.Background())
+func (q *queue) processTask(src source, handle string, visibilityTimeout time.Duration, wrapper *task.Wrapper) (taskErr error) {
+	log := q.log.WithFields(logging.Fields{
+		"method": "processTask",
+		"queue":  src.name,
+	})
+
+	// Heartbeats extend the visibility timeout on demand, and signal the loop
+	// below to restart its keep alive timer.
+	heartbeatCh := make(chan struct{}, 1)
+	heartbeat := func(progress string) error {
+		if err := q.extendVisibilityTimeout(src.url, handle, visibilityTimeout); err != nil {
+			return errors.Wrap(err, "failed to extend visibility timeout for task")
+		}
+		visibilityExtensionCounterVec.WithLabelValues(src.name, "heartbeat").Inc()
+		log.WithField("progress", progress).Trace("task heartbeat")
+
+		select {
+		case heartbeatCh <- struct{}{}:
+		default:
+		}
+		return nil
+	}
+
+	ctx, span := taskqueue.StartProcessSpan(taskqueue.WrapperContext(context.Background(), wrapper), "sqs", src.name, wrapper.Message)
+	defer func() {
+		taskqueue.EndSpan(span, taskErr)
+	}()
+
+	ctx, cancel := context.WithCancel(taskqueue.ContextWithHeartbeat(ctx, heartbeat))
 	defer cancel()
 
 	// todo(metrics): add timing?
 	result := make(chan error)
 	go func() {
-		result <- q.handler(ctx, msg)
+		result <- q.handler(ctx, wrapper.Message)
 	}()
 
 	// extend the visibility timeout when 80% of the timeout has elapsed to be safe.
 	keepAliveInterval := visibilityTimeout / 5 * 4
+	timer := time.NewTimer(keepAliveInterval)
+	defer timer.Stop()
 
-	for ext := 0; ext < q.conf.MaxVisibilityExtensions; ext++ {
+	var extensions int
+	for {
 		select {
-		case <-q.shutdownCh:
-			return errors.New("processor shutting down, not waiting for task")
+		case <-q.abandonCh:
+			return errors.New("processor shutting down, abandoning task")
 		case err := <-result:
+			if err != nil && taskqueue.ClassifyError(err, q.conf.ErrorClassifier) == taskqueue.ErrorClassPermanent {
+				return taskqueue.Permanent(err)
+			}
 			return err
 
-		case <-time.After(keepAliveInterval):
+		case <-heartbeatCh:
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(keepAliveInterval)
+
+		case <-timer.C:
 			if !q.conf.VisibilityExtensionEnabled {
 				return errors.Errorf("task handler timed out after %v (80 percent of visibility timeout)", keepAliveInterval)
 			}
+			if extensions >= q.conf.MaxVisibilityExtensions {
+				return errors.Errorf("max visibility extensions (%d) exceeded, not waiting for task", q.conf.MaxVisibilityExtensions)
+			}
 
-			if err := q.extendVisibilityTimeout(handle, visibilityTimeout); err != nil {
+			if err := q.extendVisibilityTimeout(src.url, handle, visibilityTimeout); err != nil {
 				// just give up, let the task become visible and be processed later
 				return errors.Wrap(err, "failed to extend visibility timeout for task")
 			}
+			visibilityExtensionCounterVec.WithLabelValues(src.name, "timer").Inc()
+
+			extensions++
+			timer.Reset(keepAliveInterval)
 		}
 	}
-
-	return errors.Errorf("max visibility extensions (%d) exceeded, not waiting for task", q.conf.MaxVisibilityExtensions)
 }
 
-func (q *queue) extendVisibilityTimeout(handle string, timeout time.Duration) error {
+func (q *queue) extendVisibilityTimeout(queueURL, handle string, timeout time.Duration) error {
 	_, err := q.sqs.ChangeMessageVisibilityRequest(&sqs.ChangeMessageVisibilityInput{
-		QueueUrl:          aws.String(q.queueURL),
+		QueueUrl:          aws.String(queueURL),
 		ReceiptHandle:     aws.String(handle),
 		VisibilityTimeout: aws.Int64(int64(timeout.Seconds())),
 	}).Send(context.Background())
 	return err
 }
 
-func (q *queue) deleteMessage(handle string) error {
+// cleanupPayload deletes the offloaded payload at the provided location, if any.
+func (q *queue) cleanupPayload(location string) {
+	if location == "" {
+		return
+	}
+
+	if err := q.deletePayload(context.Background(), location); err != nil {
+		q.log.WithError(err).WithField("location", location).Warn("failed to delete offloaded payload")
+	}
+}
+
+// waitForRateLimit blocks until the rate limiter (if any) permits a task to be
+// handled, or the processor stops.
+func (q *queue) waitForRateLimit() error {
+	if q.conf.RateLimiter == nil {
+		return nil
+	}
+
+	return q.conf.RateLimiter.Wait(q.pollCtx)
+}
+
+// receiveCount returns the approximate number of times the message has been received.
+func receiveCount(msg sqs.Message) int {
+	count, _ := strconv.Atoi(msg.Attributes[string(sqs.MessageSystemAttributeNameApproximateReceiveCount)])
+	return count
+}
+
+// parkMessage moves a poison or permanently failed message to the parking
+// queue, if configured, and deletes it from the source queue.
+//
+// The message is moved as is, so any offloaded payload is retained and the
+// message may later be resubmitted to the source queue.
+func (q *queue) parkMessage(src source, msg sqs.Message, reason string) error {
+	if q.parkingURL != "" {
+		input := &sqs.SendMessageInput{
+			QueueUrl:          aws.String(q.parkingURL),
+			MessageBody:       msg.Body,
+			MessageAttributes: msg.MessageAttributes,
+		}
+		if strings.HasSuffix(q.conf.ParkingQueue, fifoSuffix) {
+			input.MessageGroupId = aws.String(q.conf.MessageGroupID)
+			input.MessageDeduplicationId = msg.MessageId
+		}
+
+		if _, err := q.sqs.SendMessageRequest(input).Send(context.Background()); err != nil {
+			return errors.Wrap(err, "failed to send message to parking queue")
+		}
+	}
+
+	if err := q.deleteMessage(src.url, aws.StringValue(msg.ReceiptHandle)); err != nil {
+		return errors.Wrap(err, "failed to delete poison message")
+	}
+
+	if q.parkingURL == "" {
+		q.cleanupPayload(aws.StringValue(msg.MessageAttributes[payloadLocationAttr].StringValue))
+	}
+
+	parkedMessageCounterVec.WithLabelValues(src.name, reason).Inc()
+	return nil
+}
+
+// releaseMessage makes a received message immediately visible again.
+func (q *queue) releaseMessage(queueURL, handle string) error {
+	return q.extendVisibilityTimeout(queueURL, handle, 0)
+}
+
+func (q *queue) deleteMessage(queueURL, handle string) error {
 	_, err := q.sqs.DeleteMessageRequest(&sqs.DeleteMessageInput{
-		QueueUrl:      aws.String(q.queueURL),
+		QueueUrl:      aws.String(queueURL),
 		ReceiptHandle: aws.String(handle),
 	}).Send(context.Background())
 	return err
@@ -340,7 +1007,45 @@ func waitForGroup(wg *sync.WaitGroup, timeout time.Duration) bool {
 	}
 }
 
-func marshalTask(msg *task.Message) (string, error) {
+// contentDeduplicationID returns a deduplication ID derived from the type and
+// value of the provided message.
+func contentDeduplicationID(msg *task.Message) string {
+	h := sha256.New()
+	h.Write([]byte(msg.TypeName))
+	h.Write([]byte{0})
+	h.Write(msg.RawValue)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// encodeTask returns the SQS message body and attributes for the provided task,
+// offloading the payload to S3 if it exceeds the SQS limit and offloading is enabled.
+func (q *queue) encodeTask(ctx context.Context, msg *task.Message) (string, map[string]sqs.MessageAttributeValue, error) {
+	taskBody, err := marshalTask(ctx, msg, q.conf.Compression)
+	if err != nil {
+		return "", nil, err
+	}
+
+	attrs := q.conf.Compression.attributes()
+	if len(taskBody) <= sqsByteLimit {
+		return taskBody, attrs, nil
+	}
+
+	if q.conf.S3OffloadClient == nil {
+		return "", nil, errors.Errorf("encoded task payload size exceeded SQS limit (%d/%d)", len(taskBody), sqsByteLimit)
+	}
+
+	pointerBody, pointerAttrs, err := q.offloadPayload(ctx, taskBody)
+	if err != nil {
+		return "", nil, err
+	}
+	for k, v := range attrs {
+		pointerAttrs[k] = v
+	}
+
+	return pointerBody, pointerAttrs, nil
+}
+
+func marshalTask(ctx context.Context, msg *task.Message, compression Compression) (string, error) {
 	if msg == nil {
 		return "", errors.Errorf("task message is nil")
 	}
@@ -349,25 +1054,20 @@ func marshalTask(msg *task.Message) (string, error) {
 		return "", err
 	}
 
-	taskWrapper := &task.Wrapper{
-		Message:        msg,
-		SubmissionTime: timestamppb.Now(),
-	}
-
-	bytes, err := proto.Marshal(taskWrapper)
+	bytes, err := proto.Marshal(taskqueue.NewWrapper(ctx, msg))
 	if err != nil {
 		return "", err
 	}
 
-	taskBody := base64.URLEncoding.EncodeToString(bytes)
-	if len(taskBody) > sqsByteLimit {
-		return "", errors.Errorf("encoded task payload size exceeded SQS limit (%d/%d)", len(taskBody), sqsByteLimit)
+	bytes, err = compression.compress(bytes)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to compress task")
 	}
 
-	return taskBody, nil
+	return base64.URLEncoding.EncodeToString(bytes), nil
 }
 
-func unmarshalTask(body string) (*task.Wrapper, error) {
+func unmarshalTask(body string, compression Compression) (*task.Wrapper, error) {
 	bytes, err := base64.URLEncoding.DecodeString(body)
 	if err != nil {
 		// Attempt to decode without padding, which adds compat with Java since
@@ -378,6 +1078,11 @@ func unmarshalTask(body string) (*task.Wrapper, error) {
 		}
 	}
 
+	bytes, err = compression.decompress(bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decompress task")
+	}
+
 	wrapper := &task.Wrapper{}
 	if err := proto.Unmarshal(bytes, wrapper); err != nil {
 		return nil, err
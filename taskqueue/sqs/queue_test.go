@@ -18,11 +18,16 @@ import (
 	"github.com/google/uuid"
 	"github.com/ory/dockertest"
 	"github.com/pkg/errors"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	sqstest "github.com/kinecosystem/agora-common/aws/sqs/test"
+	"github.com/kinecosystem/agora-common/config/memory"
+	"github.com/kinecosystem/agora-common/config/wrapper"
+	"github.com/kinecosystem/agora-common/taskqueue"
+	"github.com/kinecosystem/agora-common/taskqueue/dedupe"
 	"github.com/kinecosystem/agora-common/taskqueue/model/task"
 	"github.com/kinecosystem/agora-common/testutil"
 )
@@ -302,6 +307,97 @@ func TestTaskQueue_Submitter(t *testing.T) {
 	require.Len(t, msgCh, 0)
 }
 
+func TestTaskQueue_SubmitterBatchPartialFailure(t *testing.T) {
+	queueName := fmt.Sprintf("%s%s", "test-queue-", uuid.New().String())
+	setupQueue(t, queueName)
+	defer deleteQueue(t, queueName)
+
+	s, err := NewSubmitter(queueName, sqsClient)
+	require.NoError(t, err)
+
+	msgs := make([]*task.Message, 15)
+	for i := range msgs {
+		msgs[i] = &task.Message{
+			TypeName: "something",
+			RawValue: []byte(fmt.Sprintf("hello%d", i)),
+		}
+	}
+	msgs[3] = nil
+	msgs[12] = &task.Message{
+		TypeName: "large",
+		RawValue: []byte(strings.Repeat("a", sqsByteLimit)),
+	}
+
+	err = s.SubmitBatch(context.Background(), msgs)
+	require.Error(t, err)
+
+	batchErr, ok := err.(*taskqueue.BatchError)
+	require.True(t, ok)
+	assert.Equal(t, 15, batchErr.Total)
+	assert.Equal(t, []int{3, 12}, batchErr.FailedIndexes())
+
+	msgCh := make(chan task.Message, 100)
+	p, err := NewProcessor(queueName, sqsClient, func(ctx context.Context, msg *task.Message) error {
+		msgCh <- *msg
+		return nil
+	})
+	require.NoError(t, err)
+	defer p.Shutdown()
+
+	require.NoError(t, testutil.WaitFor(2*time.Second, 200*time.Millisecond, func() bool {
+		return len(msgCh) == 13
+	}))
+}
+
+func TestChunkEntries(t *testing.T) {
+	entry := func(size int) sqs.SendMessageBatchRequestEntry {
+		return sqs.SendMessageBatchRequestEntry{
+			Id:          aws.String("0"),
+			MessageBody: aws.String(strings.Repeat("a", size)),
+		}
+	}
+
+	var entries []sqs.SendMessageBatchRequestEntry
+	for i := 0; i < 25; i++ {
+		entries = append(entries, entry(10))
+	}
+
+	batches := chunkEntries(entries)
+	require.Len(t, batches, 3)
+	assert.Len(t, batches[0], 10)
+	assert.Len(t, batches[1], 10)
+	assert.Len(t, batches[2], 5)
+
+	// Batches are split once the total payload size would exceed the limit.
+	entries = []sqs.SendMessageBatchRequestEntry{
+		entry(sqsByteLimit / 2),
+		entry(sqsByteLimit / 2),
+		entry(1),
+		entry(sqsByteLimit),
+	}
+	batches = chunkEntries(entries)
+	require.Len(t, batches, 3)
+	assert.Len(t, batches[0], 2)
+	assert.Len(t, batches[1], 1)
+	assert.Len(t, batches[2], 1)
+
+	// Attributes count towards the size.
+
+	entries[0].MessageAttributes = map[string]sqs.MessageAttributeValue{
+		"attr": {
+			DataType:    aws.String("String"),
+			StringValue: aws.String("value"),
+		},
+	}
+	batches = chunkEntries(entries)
+	require.Len(t, batches, 3)
+	assert.Len(t, batches[0], 1)
+	assert.Len(t, batches[1], 2)
+	assert.Len(t, batches[2], 1)
+
+	assert.Empty(t, chunkEntries(nil))
+}
+
 func TestTaskQueue_SubmitterBatch(t *testing.T) {
 	queueName := fmt.Sprintf("%s%s", "test-queue-", uuid.New().String())
 	setupQueue(t, queueName)
@@ -454,6 +550,226 @@ func TestTaskQueue_VisibilityTimeoutExtension(t *testing.T) {
 	// todo(metrics): 1 success, 0 failures
 }
 
+func TestTaskQueue_SubmitWithDelay(t *testing.T) {
+	queueName := fmt.Sprintf("%s%s", "test-queue-", uuid.New().String())
+	setupQueue(t, queueName)
+	defer deleteQueue(t, queueName)
+
+	msgCh := make(chan task.Message, 100)
+	defer close(msgCh)
+	p, err := NewProcessor(queueName, sqsClient, func(ctx context.Context, msg *task.Message) error {
+		select {
+		case msgCh <- *msg:
+		default:
+			require.Fail(t, "task chan full")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	defer p.Shutdown()
+
+	msg := &task.Message{TypeName: "something", RawValue: []byte("hello")}
+	assert.Error(t, p.SubmitWithDelay(context.Background(), msg, -time.Second))
+	assert.Error(t, p.SubmitWithDelay(context.Background(), msg, sqsMaxDelay+time.Second))
+
+	require.NoError(t, p.SubmitWithDelay(context.Background(), msg, 2*time.Second))
+
+	// The task should not be visible until the delay has elapsed
+	time.Sleep(time.Second)
+	require.Len(t, msgCh, 0)
+
+	require.NoError(t, testutil.WaitFor(5*time.Second, 200*time.Millisecond, func() bool {
+		return len(msgCh) == 1
+	}))
+}
+
+func TestTaskQueue_PauseResume(t *testing.T) {
+	queueName := fmt.Sprintf("%s%s", "test-queue-", uuid.New().String())
+	setupQueue(t, queueName)
+	defer deleteQueue(t, queueName)
+
+	pausedConfig := memory.NewConfig(true)
+
+	var count int32
+	p, err := NewProcessor(queueName, sqsClient, func(ctx context.Context, msg *task.Message) error {
+		atomic.AddInt32(&count, 1)
+		return nil
+	}, WithPausedConfig(wrapper.NewBoolConfig(pausedConfig, false)), WithConfigPollingInterval(100*time.Millisecond))
+	require.NoError(t, err)
+	defer p.Shutdown()
+
+	msg := &task.Message{TypeName: "something", RawValue: []byte("hello")}
+	require.NoError(t, p.Submit(context.Background(), msg))
+
+	// The config starts paused, so nothing should be consumed.
+	time.Sleep(2 * time.Second)
+	assert.EqualValues(t, 0, atomic.LoadInt32(&count))
+
+	pausedConfig.SetValue(false)
+	require.NoError(t, testutil.WaitFor(5*time.Second, 100*time.Millisecond, func() bool {
+		return atomic.LoadInt32(&count) == 1
+	}))
+
+	// Manual pauses should be respected until the next config change.
+	p.Pause()
+	require.NoError(t, p.Submit(context.Background(), msg))
+	time.Sleep(2 * time.Second)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&count))
+
+	p.Resume()
+	require.NoError(t, testutil.WaitFor(5*time.Second, 100*time.Millisecond, func() bool {
+		return atomic.LoadInt32(&count) == 2
+	}))
+
+	pausedConfig.SetValue(true)
+	time.Sleep(time.Second)
+	require.NoError(t, p.Submit(context.Background(), msg))
+	time.Sleep(2 * time.Second)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&count))
+}
+
+func TestTaskQueue_DynamicConcurrency(t *testing.T) {
+	queueName := fmt.Sprintf("%s%s", "test-queue-", uuid.New().String())
+	setupQueue(t, queueName)
+	defer deleteQueue(t, queueName)
+
+	concurrencyConfig := memory.NewConfig(int64(1))
+
+	var inFlight int32
+	releaseCh := make(chan struct{})
+	p, err := NewProcessor(queueName, sqsClient, func(ctx context.Context, msg *task.Message) error {
+		atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+
+		select {
+		case <-releaseCh:
+		case <-ctx.Done():
+		}
+		return nil
+	},
+		WithTaskConcurrency(2),
+		WithVisibilityTimeout(10*time.Second),
+		WithTaskConcurrencyConfig(wrapper.NewInt64Config(concurrencyConfig, 2)),
+		WithConfigPollingInterval(100*time.Millisecond),
+	)
+	require.NoError(t, err)
+	defer p.Shutdown()
+	defer close(releaseCh)
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, p.Submit(context.Background(), &task.Message{TypeName: "something", RawValue: []byte("hello")}))
+	}
+
+	require.NoError(t, testutil.WaitFor(5*time.Second, 100*time.Millisecond, func() bool {
+		return atomic.LoadInt32(&inFlight) == 1
+	}))
+	time.Sleep(time.Second)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&inFlight))
+
+	concurrencyConfig.SetValue(int64(4))
+	require.NoError(t, testutil.WaitFor(5*time.Second, 100*time.Millisecond, func() bool {
+		return atomic.LoadInt32(&inFlight) == 4
+	}))
+
+	// Invalid values should fall back to the configured task concurrency.
+	q := p.(*queue)
+	concurrencyConfig.SetValue(int64(0))
+	require.NoError(t, testutil.WaitFor(5*time.Second, 100*time.Millisecond, func() bool {
+		q.workerLock.Lock()
+		defer q.workerLock.Unlock()
+		return len(q.workers) == 2
+	}))
+}
+
+func TestTaskQueue_FIFO(t *testing.T) {
+	queueName := fmt.Sprintf("%s%s%s", "test-queue-", uuid.New().String(), fifoSuffix)
+	_, err := sqsClient.CreateQueueRequest(&sqs.CreateQueueInput{
+		QueueName: aws.String(queueName),
+		Attributes: map[string]string{
+			string(sqs.QueueAttributeNameFifoQueue): "true",
+		},
+	}).Send(context.Background())
+	require.NoError(t, err)
+	defer deleteQueue(t, queueName)
+
+	s, err := NewSubmitter(queueName, sqsClient, WithContentBasedDeduplication())
+	require.NoError(t, err)
+
+	// FIFO queues only support queue level delays.
+	assert.Error(t, s.SubmitWithDelay(context.Background(), &task.Message{TypeName: "something"}, time.Second))
+
+	// Duplicate content should be deduplicated, regardless of submission time.
+	msg := &task.Message{TypeName: "something", RawValue: []byte("hello")}
+	require.NoError(t, s.Submit(context.Background(), msg))
+	require.NoError(t, s.Submit(context.Background(), msg))
+
+	// An explicit deduplication ID takes precedence over the content.
+	require.NoError(t, s.SubmitBatch(context.Background(), []*task.Message{
+		{
+			TypeName: "something",
+			RawValue: []byte("hello"),
+			Metadata: map[string]string{MessageDeduplicationIDKey: "a"},
+		},
+		{
+			TypeName: "something",
+			RawValue: []byte("hello"),
+			Metadata: map[string]string{
+				MessageDeduplicationIDKey: "b",
+				MessageGroupIDKey:         "other",
+			},
+		},
+	}))
+
+	msgCh := make(chan task.Message, 100)
+	defer close(msgCh)
+	p, err := NewProcessor(queueName, sqsClient, func(ctx context.Context, msg *task.Message) error {
+		select {
+		case msgCh <- *msg:
+		default:
+			require.Fail(t, "task chan full")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	defer p.Shutdown()
+
+	require.NoError(t, testutil.WaitFor(2*time.Second, 200*time.Millisecond, func() bool {
+		return len(msgCh) == 3
+	}))
+
+	time.Sleep(500 * time.Millisecond)
+	require.Len(t, msgCh, 3)
+}
+
+func TestFIFOAttributes(t *testing.T) {
+	msg := &task.Message{TypeName: "something", RawValue: []byte("hello")}
+
+	standard := &queue{conf: defaultConfig}
+	groupID, dedupID := standard.fifoAttributes(msg)
+	assert.Nil(t, groupID)
+	assert.Nil(t, dedupID)
+
+	fifo := &queue{conf: defaultConfig, fifo: true}
+	groupID, dedupID = fifo.fifoAttributes(msg)
+	assert.Equal(t, defaultConfig.MessageGroupID, aws.StringValue(groupID))
+	assert.Nil(t, dedupID)
+
+	fifo.conf.MessageGroupID = "group"
+	fifo.conf.ContentBasedDeduplication = true
+	groupID, dedupID = fifo.fifoAttributes(msg)
+	assert.Equal(t, "group", aws.StringValue(groupID))
+	assert.Equal(t, contentDeduplicationID(msg), aws.StringValue(dedupID))
+	assert.NotEqual(t, contentDeduplicationID(msg), contentDeduplicationID(&task.Message{TypeName: "something", RawValue: []byte("world")}))
+
+	msg.Metadata = map[string]string{
+		MessageGroupIDKey:         "override",
+		MessageDeduplicationIDKey: "id",
+	}
+	groupID, dedupID = fifo.fifoAttributes(msg)
+	assert.Equal(t, "override", aws.StringValue(groupID))
+	assert.Equal(t, "id", aws.StringValue(dedupID))
+}
+
 func setupQueue(t *testing.T, queueName string) string {
 	resp, err := sqsClient.GetQueueUrlRequest(&sqs.GetQueueUrlInput{
 		QueueName: aws.String(queueName),
@@ -480,3 +796,372 @@ func deleteQueue(t *testing.T, queueName string) {
 	}).Send(context.Background())
 	require.NoError(t, err)
 }
+
+func TestTaskQueue_Drain(t *testing.T) {
+	queueName := fmt.Sprintf("%s%s", "test-queue-", uuid.New().String())
+	setupQueue(t, queueName)
+	defer deleteQueue(t, queueName)
+
+	var started, completed int32
+	releaseCh := make(chan struct{})
+	p, err := NewProcessor(queueName, sqsClient, func(ctx context.Context, msg *task.Message) error {
+		atomic.AddInt32(&started, 1)
+		<-releaseCh
+		atomic.AddInt32(&completed, 1)
+		return nil
+	}, WithTaskConcurrency(2), WithVisibilityTimeout(10*time.Second))
+	require.NoError(t, err)
+	defer p.Shutdown()
+
+	msg := &task.Message{TypeName: "something", RawValue: []byte("hello")}
+	require.NoError(t, p.Submit(context.Background(), msg))
+	require.NoError(t, p.Submit(context.Background(), msg))
+
+	require.NoError(t, testutil.WaitFor(5*time.Second, 100*time.Millisecond, func() bool {
+		return atomic.LoadInt32(&started) == 2
+	}))
+
+	go func() {
+		time.Sleep(500 * time.Millisecond)
+		close(releaseCh)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	abandoned, err := p.(*queue).Drain(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, abandoned)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&completed))
+
+	// Drain should not wait for the long poll of idle workers.
+	assert.True(t, time.Since(start) < 2*time.Second)
+
+	// Completed tasks should have been deleted.
+	queueURL := setupQueue(t, queueName)
+	resp, err := sqsClient.GetQueueAttributesRequest(&sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(queueURL),
+		AttributeNames: []sqs.QueueAttributeName{sqs.QueueAttributeNameApproximateNumberOfMessagesNotVisible},
+	}).Send(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "0", resp.Attributes[string(sqs.QueueAttributeNameApproximateNumberOfMessagesNotVisible)])
+}
+
+func TestTaskQueue_DrainAbandoned(t *testing.T) {
+	queueName := fmt.Sprintf("%s%s", "test-queue-", uuid.New().String())
+	setupQueue(t, queueName)
+	defer deleteQueue(t, queueName)
+
+	var started int32
+	cancelledCh := make(chan struct{})
+	p, err := NewProcessor(queueName, sqsClient, func(ctx context.Context, msg *task.Message) error {
+		atomic.AddInt32(&started, 1)
+		<-ctx.Done()
+		close(cancelledCh)
+		return ctx.Err()
+	}, WithVisibilityTimeout(10*time.Second))
+	require.NoError(t, err)
+	defer p.Shutdown()
+
+	msg := &task.Message{TypeName: "something", RawValue: []byte("hello")}
+	require.NoError(t, p.Submit(context.Background(), msg))
+
+	require.NoError(t, testutil.WaitFor(5*time.Second, 100*time.Millisecond, func() bool {
+		return atomic.LoadInt32(&started) == 1
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	abandoned, err := p.(*queue).Drain(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, 1, abandoned)
+
+	// The abandoned handler should be cancelled.
+	select {
+	case <-cancelledCh:
+	case <-time.After(time.Second):
+		require.Fail(t, "handler context not cancelled")
+	}
+
+	// Submissions are rejected after being drained.
+	assert.Error(t, p.Submit(context.Background(), msg))
+}
+
+func TestTaskQueue_RateLimit(t *testing.T) {
+	queueName := fmt.Sprintf("%s%s", "test-queue-", uuid.New().String())
+	setupQueue(t, queueName)
+	defer deleteQueue(t, queueName)
+
+	s, err := NewSubmitter(queueName, sqsClient)
+	require.NoError(t, err)
+
+	msgs := make([]*task.Message, 10)
+	for i := range msgs {
+		msgs[i] = &task.Message{TypeName: "something", RawValue: []byte("hello")}
+	}
+	require.NoError(t, s.SubmitBatch(context.Background(), msgs))
+
+	var count int32
+	start := time.Now()
+	p, err := NewProcessor(queueName, sqsClient, func(ctx context.Context, msg *task.Message) error {
+		atomic.AddInt32(&count, 1)
+		return nil
+	}, WithTaskConcurrency(4), WithRateLimit(5, 1))
+	require.NoError(t, err)
+	defer p.Shutdown()
+
+	require.NoError(t, testutil.WaitFor(5*time.Second, 50*time.Millisecond, func() bool {
+		return atomic.LoadInt32(&count) == 10
+	}))
+
+	// With a burst of 1, the first task is handled immediately, and the
+	// remaining 9 are spaced by 200ms.
+	assert.True(t, time.Since(start) >= 1800*time.Millisecond)
+}
+
+func TestTaskQueue_Deduper(t *testing.T) {
+	queueName := fmt.Sprintf("%s%s", "test-queue-", uuid.New().String())
+	setupQueue(t, queueName)
+	defer deleteQueue(t, queueName)
+
+	s, err := NewSubmitter(queueName, sqsClient)
+	require.NoError(t, err)
+
+	// Submit a message with an explicit idempotency key multiple times, as
+	// well as an identical message without one, which is a separate
+	// submission.
+	for i := 0; i < 3; i++ {
+		require.NoError(t, s.Submit(context.Background(), &task.Message{
+			TypeName: "something",
+			RawValue: []byte("hello"),
+			Metadata: map[string]string{taskqueue.IdempotencyKeyMetadataKey: "key"},
+		}))
+	}
+	require.NoError(t, s.Submit(context.Background(), &task.Message{TypeName: "something", RawValue: []byte("hello")}))
+
+	var count int32
+	p, err := NewProcessor(queueName, sqsClient, func(ctx context.Context, msg *task.Message) error {
+		atomic.AddInt32(&count, 1)
+		return nil
+	}, WithTaskConcurrency(1), WithDeduper(dedupe.NewMemory(), nil))
+	require.NoError(t, err)
+	defer p.Shutdown()
+
+	require.NoError(t, testutil.WaitFor(5*time.Second, 50*time.Millisecond, func() bool {
+		return atomic.LoadInt32(&count) == 2
+	}))
+
+	// Duplicates should be acknowledged without being handled.
+	time.Sleep(500 * time.Millisecond)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&count))
+}
+
+func TestTaskQueue_Heartbeat(t *testing.T) {
+	queueName := fmt.Sprintf("%s%s", "test-queue-", uuid.New().String())
+	setupQueue(t, queueName)
+	defer deleteQueue(t, queueName)
+
+	var count int32
+	p, err := NewProcessor(queueName, sqsClient, func(ctx context.Context, msg *task.Message) error {
+		atomic.AddInt32(&count, 1)
+
+		// Task exceeds multiple visibility timeout blocks, but stays alive
+		// through heartbeats rather than automatic extensions.
+		for i := 0; i < 6; i++ {
+			time.Sleep(500 * time.Millisecond)
+			if err := taskqueue.Heartbeat(ctx, fmt.Sprintf("step %d", i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, WithVisibilityExtensionEnabled(false))
+	require.NoError(t, err)
+	defer p.Shutdown()
+
+	require.NoError(t, p.Submit(context.Background(), &task.Message{TypeName: "something"}))
+
+	require.NoError(t, testutil.WaitFor(5*time.Second, 100*time.Millisecond, func() bool {
+		return atomic.LoadInt32(&count) == 1
+	}))
+
+	// The message should be deleted once handled, without being redelivered.
+	time.Sleep(4 * time.Second)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&count))
+}
+
+func TestTaskQueue_PoisonMessage(t *testing.T) {
+	queueName := fmt.Sprintf("%s%s", "test-queue-", uuid.New().String())
+	parkingName := fmt.Sprintf("%s%s", "test-queue-parking-", uuid.New().String())
+	setupQueue(t, queueName)
+	defer deleteQueue(t, queueName)
+	parkingURL := setupQueue(t, parkingName)
+	defer deleteQueue(t, parkingName)
+
+	var attempts int32
+	p, err := NewProcessor(queueName, sqsClient, func(ctx context.Context, msg *task.Message) error {
+		atomic.AddInt32(&attempts, 1)
+		return errors.New("poison")
+	}, WithTaskConcurrency(1), WithMaxReceiveCount(2), WithParkingQueue(parkingName))
+	require.NoError(t, err)
+	defer p.Shutdown()
+
+	taskMsg := &task.Message{TypeName: "something", RawValue: []byte("poison")}
+	require.NoError(t, p.Submit(context.Background(), taskMsg))
+
+	var parked []sqs.Message
+	require.NoError(t, testutil.WaitFor(10*time.Second, 100*time.Millisecond, func() bool {
+		resp, err := sqsClient.ReceiveMessageRequest(&sqs.ReceiveMessageInput{
+			QueueUrl:              aws.String(parkingURL),
+			MessageAttributeNames: []string{"All"},
+		}).Send(context.Background())
+		require.NoError(t, err)
+
+		parked = resp.Messages
+		return len(parked) == 1
+	}))
+
+	// The message should have only been handled up to the max receive count,
+	// and parked unmodified.
+	assert.EqualValues(t, 2, atomic.LoadInt32(&attempts))
+
+	compression := Compression(aws.StringValue(parked[0].MessageAttributes[contentEncodingAttr].StringValue))
+	wrapper, err := unmarshalTask(aws.StringValue(parked[0].Body), compression)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(taskMsg, wrapper.Message))
+
+	_, err = NewProcessor(queueName, sqsClient, func(ctx context.Context, msg *task.Message) error {
+		return nil
+	}, WithParkingQueue("nonexistent"))
+	assert.Error(t, err)
+}
+
+func TestTaskQueue_Stats(t *testing.T) {
+	queueName := fmt.Sprintf("%s%s", "test-queue-", uuid.New().String())
+	setupQueue(t, queueName)
+	defer deleteQueue(t, queueName)
+
+	releaseCh := make(chan struct{})
+	p, err := NewProcessor(queueName, sqsClient, func(ctx context.Context, msg *task.Message) error {
+		<-releaseCh
+		return nil
+	}, WithTaskConcurrency(1), WithPausedStart(), WithStatsPollingInterval(100*time.Millisecond))
+	require.NoError(t, err)
+	defer p.Shutdown()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, p.Submit(context.Background(), &task.Message{TypeName: "something"}))
+	}
+
+	require.NoError(t, testutil.WaitFor(5*time.Second, 50*time.Millisecond, func() bool {
+		return promtestutil.ToFloat64(queueMessagesGaugeVec.WithLabelValues(queueName, "visible")) == 3
+	}))
+	assert.EqualValues(t, 0, promtestutil.ToFloat64(queueMessagesGaugeVec.WithLabelValues(queueName, "not_visible")))
+
+	time.Sleep(200 * time.Millisecond)
+	p.Start()
+
+	// Once a message is being handled, it should no longer be visible, and its
+	// age should be reported.
+	require.NoError(t, testutil.WaitFor(5*time.Second, 50*time.Millisecond, func() bool {
+		return promtestutil.ToFloat64(queueMessagesGaugeVec.WithLabelValues(queueName, "not_visible")) == 1 &&
+			promtestutil.ToFloat64(oldestMessageAgeGaugeVec.WithLabelValues(queueName)) >= 0.2
+	}))
+
+	close(releaseCh)
+	require.NoError(t, testutil.WaitFor(5*time.Second, 50*time.Millisecond, func() bool {
+		return promtestutil.ToFloat64(queueMessagesGaugeVec.WithLabelValues(queueName, "visible")) == 0 &&
+			promtestutil.ToFloat64(queueMessagesGaugeVec.WithLabelValues(queueName, "not_visible")) == 0 &&
+			promtestutil.ToFloat64(oldestMessageAgeGaugeVec.WithLabelValues(queueName)) == 0
+	}))
+}
+
+func TestTaskQueue_PollHealth(t *testing.T) {
+	queueName := fmt.Sprintf("%s%s", "test-queue-", uuid.New().String())
+	setupQueue(t, queueName)
+	defer deleteQueue(t, queueName)
+
+	start := time.Now()
+	p, err := NewProcessor(queueName, sqsClient, func(ctx context.Context, msg *task.Message) error {
+		return nil
+	}, WithPollingInterval(100*time.Millisecond))
+	require.NoError(t, err)
+	defer p.Shutdown()
+
+	reporter, ok := p.(taskqueue.HealthReporter)
+	require.True(t, ok)
+
+	check := taskqueue.HealthCheck(reporter, time.Second, 3)
+	require.NoError(t, testutil.WaitFor(time.Second, 50*time.Millisecond, func() bool {
+		return reporter.PollHealth().LastSuccessfulPoll.After(start.Add(100 * time.Millisecond))
+	}))
+	assert.NoError(t, check(context.Background()))
+	assert.Zero(t, reporter.PollHealth().ConsecutiveErrors)
+	assert.False(t, reporter.PollHealth().Paused)
+
+	p.Pause()
+	assert.True(t, reporter.PollHealth().Paused)
+	assert.NoError(t, check(context.Background()))
+
+	p.Resume()
+	assert.False(t, reporter.PollHealth().Paused)
+	assert.NoError(t, check(context.Background()))
+
+	p.Shutdown()
+	assert.True(t, reporter.PollHealth().Paused)
+}
+
+func TestTaskQueue_PermanentError(t *testing.T) {
+	queueName := fmt.Sprintf("%s%s", "test-queue-", uuid.New().String())
+	parkingName := fmt.Sprintf("%s%s", "test-queue-parking-", uuid.New().String())
+	setupQueue(t, queueName)
+	defer deleteQueue(t, queueName)
+	parkingURL := setupQueue(t, parkingName)
+	defer deleteQueue(t, parkingName)
+
+	errInvalid := errors.New("invalid")
+
+	var attempts int32
+	p, err := NewProcessor(queueName, sqsClient, func(ctx context.Context, msg *task.Message) error {
+		atomic.AddInt32(&attempts, 1)
+
+		switch msg.TypeName {
+		case "marked":
+			return taskqueue.Permanent(errors.New("marked"))
+		default:
+			return errInvalid
+		}
+	}, WithTaskConcurrency(1), WithParkingQueue(parkingName), WithErrorClassifier(func(err error) taskqueue.ErrorClass {
+		if errors.Is(err, errInvalid) {
+			return taskqueue.ErrorClassPermanent
+		}
+		return taskqueue.ErrorClassTransient
+	}))
+	require.NoError(t, err)
+	defer p.Shutdown()
+
+	require.NoError(t, p.Submit(context.Background(), &task.Message{TypeName: "marked"}))
+	require.NoError(t, p.Submit(context.Background(), &task.Message{TypeName: "classified"}))
+
+	var parked []string
+	require.NoError(t, testutil.WaitFor(5*time.Second, 100*time.Millisecond, func() bool {
+		resp, err := sqsClient.ReceiveMessageRequest(&sqs.ReceiveMessageInput{
+			QueueUrl:              aws.String(parkingURL),
+			MessageAttributeNames: []string{"All"},
+		}).Send(context.Background())
+		require.NoError(t, err)
+
+		for _, msg := range resp.Messages {
+			wrapper, err := unmarshalTask(aws.StringValue(msg.Body), Compression(aws.StringValue(msg.MessageAttributes[contentEncodingAttr].StringValue)))
+			require.NoError(t, err)
+			parked = append(parked, wrapper.Message.TypeName)
+		}
+		return len(parked) == 2
+	}))
+	assert.ElementsMatch(t, []string{"marked", "classified"}, parked)
+
+	// Permanently failed tasks should not be retried.
+	time.Sleep(2 * time.Second)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&attempts))
+}
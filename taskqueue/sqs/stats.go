@@ -0,0 +1,113 @@
+package sqs
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/kinecosystem/agora-common/metrics"
+)
+
+var (
+	queueMessagesGaugeVec = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "taskqueue",
+		Subsystem: "sqs",
+		Name:      "queue_messages",
+		Help:      "Approximate number of messages in the queue, by state",
+	}, []string{"queue", "state"})
+	oldestMessageAgeGaugeVec = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "taskqueue",
+		Subsystem: "sqs",
+		Name:      "oldest_message_age_seconds",
+		Help:      "Approximate age of the oldest message received from the queue since the last poll",
+	}, []string{"queue"})
+)
+
+func init() {
+	queueMessagesGaugeVec = metrics.Register(queueMessagesGaugeVec).(*prometheus.GaugeVec)
+	oldestMessageAgeGaugeVec = metrics.Register(oldestMessageAgeGaugeVec).(*prometheus.GaugeVec)
+}
+
+// observeMessageAge records the age of a received message, based on the time
+// it was sent.
+//
+// SQS does not expose the age of the oldest message outside of CloudWatch, so
+// the oldest message received between polls is used as an approximation.
+func (q *queue) observeMessageAge(src source, msg sqs.Message) {
+	if q.conf.StatsPollingInterval <= 0 {
+		return
+	}
+
+	sentMillis, err := strconv.ParseInt(msg.Attributes[string(sqs.MessageSystemAttributeNameSentTimestamp)], 10, 64)
+	if err != nil {
+		return
+	}
+	age := time.Since(time.Unix(0, sentMillis*int64(time.Millisecond)))
+
+	q.statsLock.Lock()
+	defer q.statsLock.Unlock()
+	if age > q.oldestAges[src.name] {
+		q.oldestAges[src.name] = age
+	}
+}
+
+// watchStats periodically publishes the depth and message age of each source.
+func (q *queue) watchStats() {
+	ticker := time.NewTicker(q.conf.StatsPollingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.shutdownCh:
+			return
+		case <-ticker.C:
+		}
+
+		for _, src := range q.sources {
+			if err := q.publishStats(src); err != nil {
+				q.log.WithError(err).WithField("source", src.name).Warn("failed to publish queue stats")
+			}
+		}
+	}
+}
+
+func (q *queue) publishStats(src source) error {
+	resp, err := q.sqs.GetQueueAttributesRequest(&sqs.GetQueueAttributesInput{
+		QueueUrl: aws.String(src.url),
+		AttributeNames: []sqs.QueueAttributeName{
+			sqs.QueueAttributeNameApproximateNumberOfMessages,
+			sqs.QueueAttributeNameApproximateNumberOfMessagesNotVisible,
+		},
+	}).Send(q.pollCtx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get queue attributes")
+	}
+
+	visible, err := strconv.ParseInt(resp.Attributes[string(sqs.QueueAttributeNameApproximateNumberOfMessages)], 10, 64)
+	if err != nil {
+		return errors.Wrap(err, "invalid number of messages")
+	}
+	notVisible, err := strconv.ParseInt(resp.Attributes[string(sqs.QueueAttributeNameApproximateNumberOfMessagesNotVisible)], 10, 64)
+	if err != nil {
+		return errors.Wrap(err, "invalid number of messages not visible")
+	}
+
+	q.statsLock.Lock()
+	age := q.oldestAges[src.name]
+	delete(q.oldestAges, src.name)
+	q.statsLock.Unlock()
+
+	// If the queue has been drained, there is no oldest message.
+	if visible == 0 && notVisible == 0 {
+		age = 0
+	}
+
+	queueMessagesGaugeVec.WithLabelValues(src.name, "visible").Set(float64(visible))
+	queueMessagesGaugeVec.WithLabelValues(src.name, "not_visible").Set(float64(notVisible))
+	oldestMessageAgeGaugeVec.WithLabelValues(src.name).Set(age.Seconds())
+	return nil
+}
@@ -0,0 +1,142 @@
+package sqs
+
+import (
+	"math/rand"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/sqsiface"
+	"github.com/pkg/errors"
+
+	"github.com/kinecosystem/agora-common/taskqueue"
+)
+
+// WeightedQueue is a queue processed by a weighted processor.
+type WeightedQueue struct {
+	Name string
+
+	// Weight is the relative share of polls made against the queue.
+	Weight int
+}
+
+// source is a queue that tasks are received from.
+type source struct {
+	name   string
+	url    string
+	weight int
+}
+
+func NewWeightedProcessorCtor(queues []WeightedQueue, sqsClient sqsiface.ClientAPI, opts ...Option) taskqueue.ProcessorCtor {
+	return func(handler taskqueue.Handler) (taskqueue.Processor, error) {
+		return NewWeightedProcessor(queues, sqsClient, handler, opts...)
+	}
+}
+
+// NewWeightedProcessor returns a processor that processes tasks from multiple
+// queues with a shared pool of task workers.
+//
+// Each poll selects a queue at random, proportionally to its weight. If the
+// selected queue is empty, the remaining queues are checked in order of weight,
+// so idle workers are never held up by an empty queue while others have tasks.
+// A queue with a higher weight (such as a high priority queue) therefore
+// receives a larger share of the workers, without starving the others.
+//
+// Tasks submitted through the processor are submitted to the first queue.
+func NewWeightedProcessor(queues []WeightedQueue, sqsClient sqsiface.ClientAPI, handler taskqueue.Handler, opts ...Option) (taskqueue.Processor, error) {
+	if handler == nil {
+		return nil, errors.Errorf("handler is nil")
+	}
+
+	return newWeightedQueue(queues, sqsClient, handler, opts...)
+}
+
+// pickSource returns the index of a source selected proportionally to its weight.
+func (q *queue) pickSource() int {
+	if len(q.sources) == 1 {
+		return 0
+	}
+
+	n := rand.Intn(q.totalWeight)
+	for i, src := range q.sources {
+		if n < src.weight {
+			return i
+		}
+		n -= src.weight
+	}
+
+	return 0
+}
+
+// receive receives messages from the preferred source. If there are multiple
+// sources, the remaining sources are checked (in order of weight) whenever
+// the preferred source is empty, before long polling the preferred source.
+func (q *queue) receive(preferred int) (source, []sqs.Message, error) {
+	if len(q.sources) == 1 {
+		msgs, err := q.receiveFrom(q.sources[0], q.conf.PollingInterval.Seconds())
+		return q.sources[0], msgs, err
+	}
+
+	for _, i := range q.sourceOrder(preferred) {
+		msgs, err := q.receiveFrom(q.sources[i], 0)
+		if err != nil {
+			return q.sources[i], nil, err
+		}
+		if len(msgs) > 0 {
+			return q.sources[i], msgs, nil
+		}
+	}
+
+	msgs, err := q.receiveFrom(q.sources[preferred], q.conf.PollingInterval.Seconds())
+	return q.sources[preferred], msgs, err
+}
+
+// sourceOrder returns the preferred source index, followed by the remaining
+// source indexes in order of descending weight.
+func (q *queue) sourceOrder(preferred int) []int {
+	order := []int{preferred}
+	for len(order) < len(q.sources) {
+		next := -1
+		for i, src := range q.sources {
+			if containsIndex(order, i) {
+				continue
+			}
+			if next < 0 || src.weight > q.sources[next].weight {
+				next = i
+			}
+		}
+		order = append(order, next)
+	}
+
+	return order
+}
+
+func (q *queue) receiveFrom(src source, waitSeconds float64) ([]sqs.Message, error) {
+	resp, err := q.sqs.ReceiveMessageRequest(&sqs.ReceiveMessageInput{
+		QueueUrl:            aws.String(src.url),
+		MaxNumberOfMessages: aws.Int64(1),
+		VisibilityTimeout:   aws.Int64(int64(q.conf.VisibilityTimeout.Seconds())),
+		WaitTimeSeconds:     aws.Int64(int64(waitSeconds)),
+		AttributeNames: []sqs.QueueAttributeName{
+			sqs.QueueAttributeName(sqs.MessageSystemAttributeNameApproximateReceiveCount),
+			sqs.QueueAttributeName(sqs.MessageSystemAttributeNameSentTimestamp),
+		},
+		MessageAttributeNames: []string{
+			payloadLocationAttr,
+			contentEncodingAttr,
+		},
+	}).Send(q.pollCtx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to receive from %s", src.name)
+	}
+
+	return resp.Messages, nil
+}
+
+func containsIndex(indexes []int, i int) bool {
+	for _, idx := range indexes {
+		if idx == i {
+			return true
+		}
+	}
+	return false
+}
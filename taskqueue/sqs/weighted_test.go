@@ -0,0 +1,117 @@
+package sqs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kinecosystem/agora-common/taskqueue/model/task"
+	"github.com/kinecosystem/agora-common/testutil"
+)
+
+func TestTaskQueue_Weighted(t *testing.T) {
+	highName := fmt.Sprintf("%s%s", "test-queue-high-", uuid.New().String())
+	lowName := fmt.Sprintf("%s%s", "test-queue-low-", uuid.New().String())
+	setupQueue(t, highName)
+	defer deleteQueue(t, highName)
+	setupQueue(t, lowName)
+	defer deleteQueue(t, lowName)
+
+	high, err := NewSubmitter(highName, sqsClient)
+	require.NoError(t, err)
+	low, err := NewSubmitter(lowName, sqsClient)
+	require.NoError(t, err)
+
+	for i := 0; i < 20; i++ {
+		require.NoError(t, high.Submit(context.Background(), &task.Message{TypeName: "high"}))
+		require.NoError(t, low.Submit(context.Background(), &task.Message{TypeName: "low"}))
+	}
+
+	var mu sync.Mutex
+	var handled []string
+	p, err := NewWeightedProcessor(
+		[]WeightedQueue{
+			{Name: highName, Weight: 9},
+			{Name: lowName, Weight: 1},
+		},
+		sqsClient,
+		func(ctx context.Context, msg *task.Message) error {
+			mu.Lock()
+			defer mu.Unlock()
+			handled = append(handled, msg.TypeName)
+			return nil
+		},
+		WithTaskConcurrency(1),
+	)
+	require.NoError(t, err)
+	defer p.Shutdown()
+
+	// The low priority queue should be processed once the high priority
+	// queue is empty, without waiting for a long poll.
+	require.NoError(t, testutil.WaitFor(10*time.Second, 100*time.Millisecond, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(handled) == 40
+	}))
+
+	mu.Lock()
+	var highCount int
+	for _, typeName := range handled[:20] {
+		if typeName == "high" {
+			highCount++
+		}
+	}
+	mu.Unlock()
+	assert.True(t, highCount > 10, "high priority tasks should be preferred (%d/20)", highCount)
+
+	// Submissions go to the first queue.
+	require.NoError(t, p.Submit(context.Background(), &task.Message{TypeName: "high"}))
+	require.NoError(t, testutil.WaitFor(5*time.Second, 100*time.Millisecond, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(handled) == 41 && handled[40] == "high"
+	}))
+}
+
+func TestTaskQueue_WeightedInvalid(t *testing.T) {
+	handler := func(ctx context.Context, msg *task.Message) error { return nil }
+
+	_, err := NewWeightedProcessor(nil, sqsClient, handler)
+	assert.Error(t, err)
+
+	queueName := fmt.Sprintf("%s%s", "test-queue-", uuid.New().String())
+	setupQueue(t, queueName)
+	defer deleteQueue(t, queueName)
+
+	_, err = NewWeightedProcessor([]WeightedQueue{{Name: queueName}}, sqsClient, handler)
+	assert.Error(t, err)
+}
+
+func TestPickSource(t *testing.T) {
+	q := &queue{
+		sources: []source{
+			{name: "a", weight: 3},
+			{name: "b", weight: 1},
+			{name: "c", weight: 6},
+		},
+		totalWeight: 10,
+	}
+
+	counts := make([]int, len(q.sources))
+	for i := 0; i < 10000; i++ {
+		counts[q.pickSource()]++
+	}
+	assert.InDelta(t, 3000, counts[0], 300)
+	assert.InDelta(t, 1000, counts[1], 300)
+	assert.InDelta(t, 6000, counts[2], 300)
+
+	assert.Equal(t, []int{0, 2, 1}, q.sourceOrder(0))
+	assert.Equal(t, []int{1, 2, 0}, q.sourceOrder(1))
+	assert.Equal(t, []int{2, 0, 1}, q.sourceOrder(2))
+}
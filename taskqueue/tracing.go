@@ -0,0 +1,122 @@
+package taskqueue
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kinecosystem/agora-common/taskqueue/model/task"
+)
+
+const tracerName = "github.com/kinecosystem/agora-common/taskqueue"
+
+var (
+	typeNameKey  = attribute.Key("taskqueue.type_name")
+	batchSizeKey = attribute.Key("taskqueue.batch_size")
+)
+
+// traceMetadataPrefix prefixes the wrapper metadata keys that carry the trace
+// context, so that it is not restored as headers by WrapperContext.
+const traceMetadataPrefix = reservedMetadataPrefix + "trace."
+
+// metadataCarrier adapts wrapper metadata to a propagation.TextMapCarrier,
+// storing the propagated fields under traceMetadataPrefix.
+type metadataCarrier map[string][]byte
+
+// Get implements propagation.TextMapCarrier.Get.
+func (c metadataCarrier) Get(key string) string {
+	return string(c[traceMetadataPrefix+key])
+}
+
+// Set implements propagation.TextMapCarrier.Set.
+func (c metadataCarrier) Set(key, value string) {
+	c[traceMetadataPrefix+key] = []byte(value)
+}
+
+// Keys implements propagation.TextMapCarrier.Keys.
+func (c metadataCarrier) Keys() []string {
+	var keys []string
+	for k := range c {
+		if strings.HasPrefix(k, traceMetadataPrefix) {
+			keys = append(keys, strings.TrimPrefix(k, traceMetadataPrefix))
+		}
+	}
+	return keys
+}
+
+// StartSubmitSpan starts a producer span for submitting messages to a queue of
+// the provided messaging system (such as "sqs"). The span context is carried by
+// wrappers created from the returned context, allowing consumer spans to be
+// linked to it.
+//
+// The span is ended with EndSpan.
+func StartSubmitSpan(ctx context.Context, system, queueName string, msgs ...*task.Message) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemKey.String(system),
+		semconv.MessagingDestinationKey.String(queueName),
+	}
+	if len(msgs) == 1 {
+		attrs = append(attrs, typeNameKey.String(msgs[0].GetTypeName()))
+	} else {
+		attrs = append(attrs, batchSizeKey.Int(len(msgs)))
+	}
+
+	return otel.Tracer(tracerName).Start(
+		ctx,
+		queueName+" send",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// EndSpan ends the span, recording the error (if any) as its outcome.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// StartProcessSpan starts a consumer span for handling a message received from
+// a queue of the provided messaging system. The context should be derived from
+// WrapperContext.
+//
+// If the message was submitted within a producer span, the consumer span
+// continues its trace and is linked to it, so asynchronous flows appear in a
+// single trace. The span is ended with EndSpan.
+func StartProcessSpan(ctx context.Context, system, queueName string, msg *task.Message) (context.Context, trace.Span) {
+	opts := []trace.SpanOption{
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String(system),
+			semconv.MessagingDestinationKey.String(queueName),
+			semconv.MessagingOperationProcess,
+			typeNameKey.String(msg.GetTypeName()),
+		),
+	}
+	if producer := trace.SpanContextFromContext(ctx); producer.IsValid() && producer.IsRemote() {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: producer}))
+	}
+
+	return otel.Tracer(tracerName).Start(ctx, queueName+" process", opts...)
+}
+
+// StartSpan starts an internal span for tracing a step of a task handler. The
+// span is a child of the span in the context, which for handlers is the process
+// span of the task being handled.
+//
+// The span is ended with EndSpan.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(
+		ctx,
+		name,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attrs...),
+	)
+}
@@ -0,0 +1,112 @@
+package taskqueue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kinecosystem/agora-common/headers"
+	"github.com/kinecosystem/agora-common/taskqueue/model/task"
+)
+
+func setupTracing() (recorder *oteltest.SpanRecorder, cleanup func()) {
+	recorder = &oteltest.SpanRecorder{}
+
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return recorder, func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	}
+}
+
+func TestTracing(t *testing.T) {
+	recorder, cleanup := setupTracing()
+	defer cleanup()
+
+	msg := &task.Message{TypeName: "type"}
+
+	ctx, producer := StartSubmitSpan(context.Background(), "test", "queue", msg)
+	wrapper := NewWrapper(ctx, msg)
+	EndSpan(producer, nil)
+	assert.Contains(t, wrapper.Metadata, traceMetadataPrefix+"traceparent")
+	assert.NotContains(t, wrapper.Metadata, "traceparent")
+
+	// The trace context is not restored as a header, which would otherwise be
+	// forwarded on outbound calls made by the handler.
+	assert.Empty(t, headers.ExtractHeaders(WrapperContext(context.Background(), wrapper)))
+
+	ctx, consumer := StartProcessSpan(WrapperContext(context.Background(), wrapper), "test", "queue", msg)
+	assert.Equal(t, consumer.SpanContext(), trace.SpanContextFromContext(ctx))
+	EndSpan(consumer, errors.New("failed"))
+
+	spans := recorder.Completed()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "queue send", spans[0].Name())
+	assert.Equal(t, trace.SpanKindProducer, spans[0].SpanKind())
+	assert.Equal(t, "type", spans[0].Attributes()[typeNameKey].AsString())
+	assert.Equal(t, codes.Unset, spans[0].StatusCode())
+
+	// The consumer span should continue the producer's trace, and link to it.
+	assert.Equal(t, "queue process", spans[1].Name())
+	assert.Equal(t, trace.SpanKindConsumer, spans[1].SpanKind())
+	assert.Equal(t, spans[0].SpanContext().TraceID(), spans[1].SpanContext().TraceID())
+	assert.Equal(t, spans[0].SpanContext().SpanID(), spans[1].ParentSpanID())
+	require.Len(t, spans[1].Links(), 1)
+	assert.Equal(t, spans[0].SpanContext().SpanID(), spans[1].Links()[0].SpanContext.SpanID())
+	assert.Equal(t, codes.Error, spans[1].StatusCode())
+}
+
+func TestTracing_NoProducer(t *testing.T) {
+	recorder, cleanup := setupTracing()
+	defer cleanup()
+
+	msg := &task.Message{TypeName: "type"}
+	wrapper := NewWrapper(context.Background(), msg)
+	assert.Len(t, wrapper.Metadata, 1)
+	assert.Contains(t, wrapper.Metadata, submissionIDMetadataKey)
+
+	_, span := StartProcessSpan(WrapperContext(context.Background(), wrapper), "test", "queue", msg)
+	EndSpan(span, nil)
+
+	spans := recorder.Completed()
+	require.Len(t, spans, 1)
+	assert.False(t, spans[0].ParentSpanID().IsValid())
+	assert.Empty(t, spans[0].Links())
+
+	_, span = StartSubmitSpan(context.Background(), "test", "queue", msg, msg)
+	EndSpan(span, nil)
+	spans = recorder.Completed()
+	require.Len(t, spans, 2)
+	assert.EqualValues(t, 2, spans[1].Attributes()[batchSizeKey].AsInt64())
+}
+
+func TestStartSpan(t *testing.T) {
+	recorder, cleanup := setupTracing()
+	defer cleanup()
+
+	msg := &task.Message{TypeName: "type"}
+	ctx, process := StartProcessSpan(context.Background(), "test", "queue", msg)
+
+	_, span := StartSpan(ctx, "step", typeNameKey.String("type"))
+	EndSpan(span, nil)
+	EndSpan(process, nil)
+
+	spans := recorder.Completed()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "step", spans[0].Name())
+	assert.Equal(t, trace.SpanKindInternal, spans[0].SpanKind())
+	assert.Equal(t, process.SpanContext().SpanID(), spans[0].ParentSpanID())
+	assert.Equal(t, process.SpanContext().TraceID(), spans[0].SpanContext().TraceID())
+}
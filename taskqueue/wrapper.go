@@ -0,0 +1,99 @@
+package taskqueue
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/kinecosystem/agora-common/headers"
+	"github.com/kinecosystem/agora-common/taskqueue/model/task"
+)
+
+const (
+	// reservedMetadataPrefix prefixes the wrapper metadata keys that are used
+	// by the task queue itself, rather than carrying headers. They are not
+	// restored as headers by WrapperContext.
+	reservedMetadataPrefix = "taskqueue."
+
+	// submissionIDMetadataKey is the wrapper metadata key of the submission ID.
+	submissionIDMetadataKey = reservedMetadataPrefix + "submission_id"
+)
+
+type submissionIDKey struct{}
+
+type forwardedSubmissionKey struct{}
+
+// NewWrapper returns a wrapper for the provided message, submitted now.
+//
+// Each wrapper is stamped with a unique submission ID, which identifies
+// redeliveries of the same submission (see SubmissionID). If the context was
+// returned by ForwardingContext, the submission ID of the forwarded wrapper is
+// used instead.
+//
+// The headers of the submitting context that would be sent on an outbound
+// gRPC call are carried in the wrapper metadata, so they can be restored into
+// the handler context with WrapperContext. The trace context of the submitting
+// context is carried as well, using the global OpenTelemetry propagator.
+func NewWrapper(ctx context.Context, msg *task.Message) *task.Wrapper {
+	wrapper := &task.Wrapper{
+		Message:        msg,
+		SubmissionTime: timestamppb.Now(),
+	}
+
+	submissionID := uuid.New().String()
+	if forwarded, ok := ctx.Value(forwardedSubmissionKey{}).(string); ok {
+		submissionID = forwarded
+	}
+
+	metadata := metadataCarrier(headers.ExtractHeaders(ctx))
+	otel.GetTextMapPropagator().Inject(ctx, metadata)
+	metadata[submissionIDMetadataKey] = []byte(submissionID)
+	wrapper.Metadata = metadata
+
+	return wrapper
+}
+
+// WrapperContext returns a context containing the headers carried by the
+// wrapper, as if they were received by a gRPC service. Root and propagating
+// headers remain as such, while other headers become inbound headers.
+//
+// The carried trace context (if any) is restored as the remote span context,
+// and the submission ID (if any) is available via SubmissionID.
+func WrapperContext(ctx context.Context, wrapper *task.Wrapper) context.Context {
+	carried := make(map[string][]byte, len(wrapper.Metadata))
+	for k, v := range wrapper.Metadata {
+		if !strings.HasPrefix(k, reservedMetadataPrefix) {
+			carried[k] = v
+		}
+	}
+
+	ctx = headers.ContextWithExtractedHeaders(ctx, carried)
+	if id, ok := wrapper.Metadata[submissionIDMetadataKey]; ok {
+		ctx = context.WithValue(ctx, submissionIDKey{}, string(id))
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(wrapper.Metadata))
+}
+
+// ForwardingContext is similar to WrapperContext, but is used to submit the
+// wrapped message to another queue on behalf of its original submitter (for
+// example, by a scheduler). Wrappers created from the returned context retain
+// the submission ID of the forwarded wrapper, so that duplicate forwards are
+// recognized as the same submission.
+func ForwardingContext(ctx context.Context, wrapper *task.Wrapper) context.Context {
+	ctx = WrapperContext(ctx, wrapper)
+	if id, ok := SubmissionID(ctx); ok {
+		ctx = context.WithValue(ctx, forwardedSubmissionKey{}, id)
+	}
+	return ctx
+}
+
+// SubmissionID returns the submission ID of the task being handled, which is
+// shared by all deliveries of the same submission. Wrappers submitted by older
+// versions of this package do not have a submission ID.
+func SubmissionID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(submissionIDKey{}).(string)
+	return id, ok && id != ""
+}
@@ -0,0 +1,73 @@
+package taskqueue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kinecosystem/agora-common/headers"
+	"github.com/kinecosystem/agora-common/taskqueue/model/task"
+)
+
+func TestWrapper_Headers(t *testing.T) {
+	msg := &task.Message{TypeName: "test"}
+
+	// Contexts without headers should only produce a submission ID.
+	wrapper := NewWrapper(context.Background(), msg)
+	assert.True(t, proto.Equal(msg, wrapper.Message))
+	assert.NotNil(t, wrapper.SubmissionTime)
+	assert.Len(t, wrapper.Metadata, 1)
+	assert.NotEmpty(t, wrapper.Metadata[submissionIDMetadataKey])
+
+	propagating := &task.Message{TypeName: "propagating"}
+	outbound := &task.Message{TypeName: "outbound"}
+	ctx, err := headers.ContextWithHeaders(
+		context.Background(),
+		headers.PropagatingHeaderSetter(propagating),
+		headers.DefaultHeaderSetter(outbound),
+	)
+	require.NoError(t, err)
+	require.NoError(t, headers.SetASCIIHeader(ctx, "request-id", "abc"))
+
+	wrapper = NewWrapper(ctx, msg)
+	assert.Len(t, wrapper.Metadata, 4)
+
+	// Round trip through serialization, as a queue would.
+	b, err := proto.Marshal(wrapper)
+	require.NoError(t, err)
+	decoded := &task.Wrapper{}
+	require.NoError(t, proto.Unmarshal(b, decoded))
+
+	handlerCtx := WrapperContext(context.Background(), decoded)
+
+	actual := &task.Message{}
+	require.NoError(t, headers.GetPropagatingHeader(handlerCtx, actual))
+	assert.True(t, proto.Equal(propagating, actual))
+
+	// Outbound headers are received as inbound headers.
+	actual = &task.Message{}
+	require.NoError(t, headers.GetHeader(handlerCtx, actual))
+	assert.True(t, proto.Equal(outbound, actual))
+
+	requestID, err := headers.GetASCIIHeaderByName(handlerCtx, "request-id")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", requestID)
+
+	// Propagating headers continue to be propagated by the handler, while the
+	// submission ID is not restored as a header.
+	assert.Len(t, headers.ExtractHeaders(handlerCtx), 2)
+	submissionID, ok := SubmissionID(handlerCtx)
+	assert.True(t, ok)
+	assert.Equal(t, string(wrapper.Metadata[submissionIDMetadataKey]), submissionID)
+	header, err := headers.GetASCIIHeaderByName(handlerCtx, submissionIDMetadataKey)
+	require.NoError(t, err)
+	assert.Empty(t, header)
+
+	// The handler context is usable without any metadata.
+	handlerCtx = WrapperContext(context.Background(), &task.Wrapper{Message: msg})
+	assert.Empty(t, headers.ExtractHeaders(handlerCtx))
+	require.NoError(t, headers.SetPropagatingHeader(handlerCtx, propagating))
+}
@@ -0,0 +1,56 @@
+// Package apptest runs an app.App in-process for integration tests, with the
+// same interceptors and services that app.Run configures.
+package apptest
+
+import (
+	"net"
+	"sync"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	"github.com/kinecosystem/agora-common/app"
+)
+
+// Run initializes the app and serves it on an ephemeral localhost port,
+// returning a connection to the server, and a function that stops the server
+// and the app.
+//
+// The config is typically app.DefaultConfig() with the fields under test
+// modified. Only the gRPC related fields of the config apply, since listeners,
+// signal handling, and the debug and HTTP gateway endpoints are not started.
+func Run(a app.App, config app.BaseConfig, options ...app.Option) (conn *grpc.ClientConn, stopFunc func(), err error) {
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to start listener")
+	}
+
+	serv, err := app.NewServer(a, config, options...)
+	if err != nil {
+		lis.Close()
+		return nil, nil, err
+	}
+
+	// note: this is safe since we don't specify grpc.WithBlock()
+	conn, err = grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		lis.Close()
+		a.Stop()
+		return nil, nil, errors.Wrap(err, "failed to create grpc.ClientConn")
+	}
+
+	go func() {
+		_ = serv.Serve(lis)
+	}()
+
+	var stopOnce sync.Once
+	stopFunc = func() {
+		stopOnce.Do(func() {
+			conn.Close()
+			serv.Stop()
+			a.Stop()
+		})
+	}
+
+	return conn, stopFunc, nil
+}
@@ -0,0 +1,77 @@
+package apptest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/kinecosystem/agora-common/app"
+)
+
+type testApp struct {
+	initErr    error
+	config     app.Config
+	registered bool
+	stopped    bool
+}
+
+func (a *testApp) Init(config app.Config) error {
+	a.config = config
+	return a.initErr
+}
+
+func (a *testApp) RegisterWithGRPC(_ *grpc.Server) {
+	a.registered = true
+}
+
+func (a *testApp) ShutdownChan() <-chan struct{} {
+	return nil
+}
+
+func (a *testApp) Stop() {
+	a.stopped = true
+}
+
+func TestRun(t *testing.T) {
+	var methods []string
+	interceptor := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		methods = append(methods, info.FullMethod)
+		return handler(ctx, req)
+	}
+
+	config := app.DefaultConfig()
+	config.AppConfig = app.Config{"key": "value"}
+
+	a := &testApp{}
+	conn, stop, err := Run(
+		a,
+		config,
+		app.WithUnaryServerInterceptor(interceptor),
+		app.WithMetricsRegistry(prometheus.NewRegistry()),
+	)
+	require.NoError(t, err)
+	defer stop()
+
+	assert.Equal(t, "value", a.config.GetString("key", ""))
+	assert.True(t, a.registered)
+
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+	assert.Equal(t, []string{"/grpc.health.v1.Health/Check"}, methods)
+
+	stop()
+	assert.True(t, a.stopped)
+}
+
+func TestRun_InitError(t *testing.T) {
+	initErr := errors.New("init failed")
+	_, _, err := Run(&testApp{initErr: initErr}, app.DefaultConfig())
+	assert.Equal(t, initErr, errors.Cause(err))
+}
@@ -31,6 +31,16 @@ func IsISO8601(s string) bool {
 	return regex.MatchString(s)
 }
 
+// ParseDuration parses either an ISO-8601 formatted duration (e.g. PT1M30S),
+// or a Go duration string (e.g. 1m30s).
+func ParseDuration(s string) (time.Duration, error) {
+	if IsISO8601(s) {
+		return ParseISO8601(s)
+	}
+
+	return time.ParseDuration(s)
+}
+
 // Parses an ISO-8601 formatted duration.
 //
 // This attempts to mimic Java's Duration.parse. Note the leading plus/minus
@@ -139,3 +139,22 @@ func TestParseOverflow(t *testing.T) {
 	_, err = ParseISO8601(durationStr)
 	require.Error(t, err)
 }
+
+func TestParseDuration(t *testing.T) {
+	for s, expected := range map[string]time.Duration{
+		"PT1M30S": 90 * time.Second,
+		"P1D":     24 * time.Hour,
+		"-PT0.5S": -500 * time.Millisecond,
+		"1m30s":   90 * time.Second,
+		"-500ms":  -500 * time.Millisecond,
+	} {
+		actual, err := ParseDuration(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, expected, actual, s)
+	}
+
+	for _, s := range []string{"", "PT", "1x", "P1X"} {
+		_, err := ParseDuration(s)
+		assert.Error(t, err, s)
+	}
+}
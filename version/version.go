@@ -0,0 +1,67 @@
+// Package version exposes the build information of the binary.
+//
+// The information is populated at build time via ldflags, for example:
+//
+//	go build -ldflags "\
+//	    -X github.com/kinecosystem/agora-common/version.Version=v1.2.3 \
+//	    -X github.com/kinecosystem/agora-common/version.Commit=$(git rev-parse HEAD) \
+//	    -X github.com/kinecosystem/agora-common/version.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package version
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Populated via ldflags.
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// Info is the build information of the binary.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build information of the binary.
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+}
+
+// NewCollector returns a collector that exports the build information as the
+// agora_build_info metric, which always has a value of 1.
+func NewCollector() prometheus.Collector {
+	info := Get()
+	return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: "agora",
+		Name:      "build_info",
+		Help:      "Build information of the binary, with a constant value of 1.",
+		ConstLabels: prometheus.Labels{
+			"version":    info.Version,
+			"commit":     info.Commit,
+			"build_time": info.BuildTime,
+			"go_version": info.GoVersion,
+		},
+	}, func() float64 { return 1 })
+}
+
+// Handler returns an http.Handler that serves the build information as JSON.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(Get())
+	})
+}
@@ -0,0 +1,57 @@
+package version
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersion(t *testing.T) {
+	Version, Commit, BuildTime = "v1.2.3", "abc123", "2021-01-01T00:00:00Z"
+	defer func() {
+		Version, Commit, BuildTime = "dev", "unknown", "unknown"
+	}()
+
+	expected := Info{
+		Version:   "v1.2.3",
+		Commit:    "abc123",
+		BuildTime: "2021-01-01T00:00:00Z",
+		GoVersion: runtime.Version(),
+	}
+	assert.Equal(t, expected, Get())
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/version", nil))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var served Info
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &served))
+	assert.Equal(t, expected, served)
+
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(NewCollector()))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	assert.Equal(t, "agora_build_info", families[0].GetName())
+
+	m := families[0].GetMetric()[0]
+	labels := make(map[string]string)
+	for _, l := range m.GetLabel() {
+		labels[l.GetName()] = l.GetValue()
+	}
+	assert.Equal(t, map[string]string{
+		"version":    "v1.2.3",
+		"commit":     "abc123",
+		"build_time": "2021-01-01T00:00:00Z",
+		"go_version": runtime.Version(),
+	}, labels)
+	assert.EqualValues(t, 1, m.GetGauge().GetValue())
+}
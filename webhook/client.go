@@ -0,0 +1,341 @@
+// Package webhook provides a client for invoking app webhooks, as well as
+// utilities for verifying webhook requests.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/kinecosystem/agora-common/metrics"
+	"github.com/kinecosystem/agora-common/retry"
+	"github.com/kinecosystem/agora-common/retry/backoff"
+	"github.com/kinecosystem/agora-common/webhook/createaccount"
+	"github.com/kinecosystem/agora-common/webhook/events"
+	"github.com/kinecosystem/agora-common/webhook/signtransaction"
+)
+
+const (
+	// AgoraHMACHeader is the header containing the base64 encoded
+	// HMAC-SHA256 of the request body, keyed by the webhook secret.
+	AgoraHMACHeader = "X-Agora-HMAC-SHA256"
+
+	endpointCreateAccount   = "create_account"
+	endpointEvents          = "events"
+	endpointSignTransaction = "sign_transaction"
+
+	defaultTimeout     = 10 * time.Second
+	defaultMaxAttempts = 3
+)
+
+var (
+	requestCounterVec = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "webhook",
+		Name:      "requests_total",
+		Help:      "Number of webhook requests made",
+	}, []string{"endpoint", "status_code"})
+	requestTimings = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "webhook",
+		Name:      "request_duration_seconds",
+		Buckets:   metrics.MinuteDistributionBuckets,
+	}, []string{"endpoint"})
+	retryCount = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "webhook",
+		Name:      "retry_count",
+		Buckets:   prometheus.LinearBuckets(1.0, 1.0, 3),
+	}, []string{"endpoint"})
+)
+
+func init() {
+	requestCounterVec = metrics.Register(requestCounterVec).(*prometheus.CounterVec)
+	requestTimings = metrics.Register(requestTimings).(*prometheus.HistogramVec)
+	retryCount = metrics.Register(retryCount).(*prometheus.HistogramVec)
+}
+
+// Error is returned when a webhook responds with an unexpected status code.
+type Error struct {
+	StatusCode int
+	Body       []byte
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	return "unexpected webhook response: " + strconv.Itoa(e.StatusCode)
+}
+
+// Client invokes app webhooks.
+type Client struct {
+	httpClient *http.Client
+	opts       clientOpts
+}
+
+type clientOpts struct {
+	timeout     time.Duration
+	httpClient  *http.Client
+	maxAttempts uint
+
+	// strategies replace the default backoff if customStrategies is set.
+	strategies       []retry.Strategy
+	customStrategies bool
+}
+
+// ClientOption configures a Client.
+type ClientOption func(o *clientOpts)
+
+// WithTimeout configures the per-attempt timeout of webhook requests.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(o *clientOpts) {
+		o.timeout = timeout
+	}
+}
+
+// WithHTTPClient configures the underlying http.Client used by the Client.
+//
+// If specified, WithTimeout is ignored.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(o *clientOpts) {
+		o.httpClient = httpClient
+	}
+}
+
+// WithMaxAttempts configures the maximum number of attempts made for each
+// request, including the first. Values less than 1 are treated as 1.
+func WithMaxAttempts(maxAttempts uint) ClientOption {
+	return func(o *clientOpts) {
+		o.maxAttempts = maxAttempts
+	}
+}
+
+// WithRetryStrategies configures the strategies used to retry failed requests,
+// replacing the default backoff. Unlike the default backoff, delays induced by
+// the provided strategies are not interrupted when the request context is done.
+//
+// Only service errors (5xx) and transport errors are ever retried, and never
+// more than allowed by WithMaxAttempts; the provided strategies are applied in
+// addition to that.
+func WithRetryStrategies(strategies ...retry.Strategy) ClientOption {
+	return func(o *clientOpts) {
+		o.strategies = strategies
+		o.customStrategies = true
+	}
+}
+
+// NewClient returns a new Client.
+func NewClient(opts ...ClientOption) *Client {
+	o := &clientOpts{
+		timeout:     defaultTimeout,
+		maxAttempts: defaultMaxAttempts,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.maxAttempts < 1 {
+		o.maxAttempts = 1
+	}
+
+	httpClient := o.httpClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: o.timeout}
+	}
+
+	return &Client{
+		httpClient: httpClient,
+		opts:       *o,
+	}
+}
+
+// retryStrategies returns the strategies for retrying a request made with the
+// provided context. The default backoff is interrupted once the context is
+// done.
+func (c *Client) retryStrategies(ctx context.Context) []retry.Strategy {
+	strategies := []retry.Strategy{
+		retry.NonRetriableErrors(errNonRetriable),
+		retry.Limit(c.opts.maxAttempts),
+	}
+	if c.opts.customStrategies {
+		return append(strategies, c.opts.strategies...)
+	}
+
+	return append(strategies, retry.BackoffWithJitterWithContext(ctx, backoff.BinaryExponential(100*time.Millisecond), time.Second, 0.1))
+}
+
+// errNonRetriable marks responses that were received, but should not be retried.
+var errNonRetriable = errors.New("non retriable")
+
+type nonRetriableError struct {
+	err error
+}
+
+func (e *nonRetriableError) Error() string { return e.err.Error() }
+func (e *nonRetriableError) Is(target error) bool {
+	return target == errNonRetriable
+}
+
+// SignTransaction invokes the sign transaction webhook.
+//
+// If the webhook rejected the transaction, a nil SuccessResponse and non-nil
+// ForbiddenResponse is returned.
+func (c *Client) SignTransaction(ctx context.Context, url string, secret []byte, req *signtransaction.Request) (*signtransaction.SuccessResponse, *signtransaction.ForbiddenResponse, error) {
+	status, body, err := c.do(ctx, endpointSignTransaction, url, secret, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch status {
+	case http.StatusOK:
+		resp := &signtransaction.SuccessResponse{}
+		if err := json.Unmarshal(body, resp); err != nil {
+			return nil, nil, errors.Wrap(err, "invalid sign transaction response")
+		}
+		return resp, nil, nil
+	case http.StatusForbidden:
+		resp := &signtransaction.ForbiddenResponse{}
+		if err := json.Unmarshal(body, resp); err != nil {
+			return nil, nil, errors.Wrap(err, "invalid sign transaction forbidden response")
+		}
+		return nil, resp, nil
+	default:
+		return nil, nil, &Error{StatusCode: status, Body: body}
+	}
+}
+
+// CreateAccount invokes the create account webhook.
+//
+// If the webhook rejected the creation, a nil SuccessResponse and non-nil
+// ForbiddenResponse is returned.
+func (c *Client) CreateAccount(ctx context.Context, url string, secret []byte, req *createaccount.Request) (*createaccount.SuccessResponse, *createaccount.ForbiddenResponse, error) {
+	status, body, err := c.do(ctx, endpointCreateAccount, url, secret, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch status {
+	case http.StatusOK:
+		resp := &createaccount.SuccessResponse{}
+		if err := json.Unmarshal(body, resp); err != nil {
+			return nil, nil, errors.Wrap(err, "invalid create account response")
+		}
+		return resp, nil, nil
+	case http.StatusForbidden:
+		resp := &createaccount.ForbiddenResponse{}
+		if err := json.Unmarshal(body, resp); err != nil {
+			return nil, nil, errors.Wrap(err, "invalid create account forbidden response")
+		}
+		return nil, resp, nil
+	default:
+		return nil, nil, &Error{StatusCode: status, Body: body}
+	}
+}
+
+// Events invokes the events webhook.
+func (c *Client) Events(ctx context.Context, url string, secret []byte, e []events.Event) error {
+	status, body, err := c.do(ctx, endpointEvents, url, secret, e)
+	if err != nil {
+		return err
+	}
+
+	if status != http.StatusOK {
+		return &Error{StatusCode: status, Body: body}
+	}
+
+	return nil
+}
+
+// do performs the request, retrying on transport and service errors. The status
+// and body of the final response are returned for any non-5xx response.
+func (c *Client) do(ctx context.Context, endpoint, url string, secret []byte, v interface{}) (status int, body []byte, err error) {
+	reqBody, err := json.Marshal(v)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "failed to marshal request")
+	}
+
+	start := time.Now()
+	attempts, err := retry.RetryWithContext(ctx, func(ctx context.Context) error {
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(reqBody))
+		if err != nil {
+			return &nonRetriableError{err: errors.Wrap(err, "failed to create request")}
+		}
+		req = req.WithContext(ctx)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(AgoraHMACHeader, Sign(secret, reqBody))
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			requestCounterVec.WithLabelValues(endpoint, "").Inc()
+			if ctx.Err() != nil {
+				return &nonRetriableError{err: err}
+			}
+			return err
+		}
+		defer resp.Body.Close()
+
+		requestCounterVec.WithLabelValues(endpoint, strconv.Itoa(resp.StatusCode)).Inc()
+
+		status = resp.StatusCode
+		body, err = ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return errors.Wrap(err, "failed to read response body")
+		}
+
+		if status >= 500 {
+			return &Error{StatusCode: status, Body: body}
+		}
+
+		return nil
+	}, c.retryStrategies(ctx)...)
+	requestTimings.WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+	retryCount.WithLabelValues(endpoint).Observe(float64(attempts))
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return status, body, nil
+}
+
+// Sign returns the base64 encoded HMAC-SHA256 of the body, keyed by secret.
+func Sign(secret, body []byte) string {
+	h := hmac.New(sha256.New, secret)
+	h.Write(body)
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// Verify returns whether or not the provided signature is valid for the body.
+func Verify(secret, body []byte, signature string) bool {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	h := hmac.New(sha256.New, secret)
+	h.Write(body)
+	return hmac.Equal(h.Sum(nil), sig)
+}
+
+// VerifyRequest reads the body of the provided request, and verifies it
+// against the signature contained in the AgoraHMACHeader.
+//
+// The body is returned if the signature is valid.
+func VerifyRequest(r *http.Request, secret []byte) ([]byte, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read body")
+	}
+
+	if !Verify(secret, body, r.Header.Get(AgoraHMACHeader)) {
+		return nil, errors.New("invalid signature")
+	}
+
+	return body, nil
+}
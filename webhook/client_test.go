@@ -0,0 +1,134 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kinecosystem/agora-common/webhook/events"
+	"github.com/kinecosystem/agora-common/webhook/signtransaction"
+)
+
+func TestClient_SignTransaction(t *testing.T) {
+	secret := []byte("secret")
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+
+		body, err := VerifyRequest(r, secret)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req signtransaction.Request
+		require.NoError(t, json.Unmarshal(body, &req))
+
+		switch {
+		case n == 1:
+			w.WriteHeader(http.StatusInternalServerError)
+		case req.KinVersion == 4:
+			_ = json.NewEncoder(w).Encode(&signtransaction.SuccessResponse{Signature: []byte{1, 2}})
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(w).Encode(signtransaction.NewForbiddenResponse("rejected", signtransaction.InvoiceError{
+				OperationIndex: 1,
+				Reason:         signtransaction.SKUNotFound,
+			}))
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(WithRetryStrategies())
+
+	// First attempt fails with a 500, and should be retried.
+	success, forbidden, err := c.SignTransaction(context.Background(), server.URL, secret, &signtransaction.Request{KinVersion: 4})
+	require.NoError(t, err)
+	assert.Nil(t, forbidden)
+	assert.Equal(t, []byte{1, 2}, success.Signature)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+
+	success, forbidden, err = c.SignTransaction(context.Background(), server.URL, secret, &signtransaction.Request{KinVersion: 3})
+	require.NoError(t, err)
+	assert.Nil(t, success)
+	assert.Equal(t, "rejected", forbidden.Message)
+	assert.Equal(t, signtransaction.SKUNotFound, forbidden.InvoiceErrors[0].Reason)
+
+	_, _, err = c.SignTransaction(context.Background(), server.URL, []byte("wrong"), &signtransaction.Request{KinVersion: 4})
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, err.(*Error).StatusCode)
+}
+
+func TestClient_Events(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	c := NewClient(WithMaxAttempts(2), WithRetryStrategies())
+	err := c.Events(context.Background(), server.URL, nil, []events.Event{})
+	require.Error(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, err.(*Error).StatusCode)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+
+	// Custom strategies do not remove the default attempt limit.
+	c = NewClient(WithRetryStrategies(func(uint, error) bool { return true }))
+	atomic.StoreInt32(&calls, 0)
+	require.Error(t, c.Events(context.Background(), server.URL, nil, []events.Event{}))
+	assert.EqualValues(t, defaultMaxAttempts, atomic.LoadInt32(&calls))
+
+	// Cancelled requests should not be retried
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		cancel()
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer slow.Close()
+
+	atomic.StoreInt32(&calls, 0)
+	assert.Error(t, c.Events(ctx, slow.URL, nil, []events.Event{}))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+}
+
+func TestClient_CancelDuringBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		time.AfterFunc(10*time.Millisecond, cancel)
+	}))
+	defer server.Close()
+
+	// The default backoff (at least 90ms) is interrupted once the context is
+	// cancelled, rather than being slept through.
+	start := time.Now()
+	err := NewClient().Events(ctx, server.URL, nil, []events.Event{})
+	assert.Equal(t, context.Canceled, err)
+	assert.True(t, time.Since(start) < 90*time.Millisecond)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+}
+
+func TestSignVerify(t *testing.T) {
+	body := []byte("body")
+	sig := Sign([]byte("secret"), body)
+
+	assert.True(t, Verify([]byte("secret"), body, sig))
+	assert.False(t, Verify([]byte("other"), body, sig))
+	assert.False(t, Verify([]byte("secret"), []byte("other"), sig))
+	assert.False(t, Verify([]byte("secret"), body, "invalid"))
+}
@@ -1,5 +1,11 @@
 package createaccount
 
+import (
+	"github.com/pkg/errors"
+
+	"github.com/kinecosystem/agora-common/solana"
+)
+
 // Request contains the body of a create account request.
 type Request struct {
 	KinVersion int `json:"kin_version"`
@@ -7,8 +13,66 @@ type Request struct {
 	SolanaTransaction []byte `json:"solana_transaction"`
 }
 
+const (
+	// Unauthorized indicates the subsidizer will not fund the account creation.
+	Unauthorized Reason = "unauthorized"
+	// RateLimited indicates the requester has exceeded the allowed creation rate.
+	RateLimited Reason = "rate_limited"
+	// InvalidTransaction indicates the provided transaction was malformed.
+	InvalidTransaction Reason = "invalid_transaction"
+)
+
 // SuccessResponse represents a 200 OK response to a create account request.
 type SuccessResponse struct {
 	// Signature is a base64-encoded transaction signature.
 	Signature []byte `json:"signature"`
 }
+
+// ForbiddenResponse represents a 403 Forbidden response to a create account request.
+type ForbiddenResponse struct {
+	Message string `json:"message"`
+	Reason  Reason `json:"reason"`
+}
+
+// Reason indicates why a create account request was rejected
+type Reason string
+
+// NewRequest returns a Request for the provided solana transaction.
+func NewRequest(txn solana.Transaction) *Request {
+	return &Request{
+		KinVersion:        4,
+		SolanaTransaction: txn.Marshal(),
+	}
+}
+
+// GetSolanaTransaction returns the unmarshalled solana transaction.
+func (r *Request) GetSolanaTransaction() (*solana.Transaction, error) {
+	if len(r.SolanaTransaction) == 0 {
+		return nil, errors.New("solana_transaction cannot have length of 0")
+	}
+
+	txn := &solana.Transaction{}
+	if err := txn.Unmarshal(r.SolanaTransaction); err != nil {
+		return nil, errors.New("solana_transaction was not a valid solana transaction")
+	}
+
+	return txn, nil
+}
+
+// NewSuccessResponse returns a SuccessResponse containing the subsidizer
+// signature of the provided transaction.
+func NewSuccessResponse(txn solana.Transaction) *SuccessResponse {
+	return &SuccessResponse{
+		Signature: append([]byte(nil), txn.Signature()...),
+	}
+}
+
+// GetSignature returns the subsidizer signature contained in the response.
+func (r *SuccessResponse) GetSignature() (sig solana.Signature, err error) {
+	if len(r.Signature) != len(sig) {
+		return sig, errors.Errorf("invalid signature length: %d", len(r.Signature))
+	}
+
+	copy(sig[:], r.Signature)
+	return sig, nil
+}
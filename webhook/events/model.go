@@ -27,7 +27,7 @@ type StellarEvent struct {
 	ResultXDR   []byte `json:"result_xdr"`
 }
 
-// SolanaEvent is stellar specific data related to
+// SolanaEvent is solana specific data related to
 // a transaction.
 type SolanaEvent struct {
 	Transaction         []byte `json:"transaction"`
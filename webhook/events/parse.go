@@ -0,0 +1,128 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/kinecosystem/go/xdr"
+	"github.com/pkg/errors"
+
+	"github.com/kinecosystem/agora-common/solana"
+)
+
+// ParseEvents parses the body of an events webhook request, validating
+// each of the contained events.
+func ParseEvents(body []byte) ([]Event, error) {
+	var events []Event
+	if err := json.Unmarshal(body, &events); err != nil {
+		return nil, errors.Wrap(err, "invalid events body")
+	}
+
+	for i := range events {
+		if err := events[i].Validate(); err != nil {
+			return nil, errors.Wrapf(err, "invalid event at %d", i)
+		}
+	}
+
+	return events, nil
+}
+
+// Validate returns an error if the event is malformed.
+func (e *Event) Validate() error {
+	if e.TransactionEvent == nil {
+		return errors.New("missing transaction_event")
+	}
+
+	return e.TransactionEvent.Validate()
+}
+
+// Validate returns an error if the transaction event is malformed.
+//
+// Exactly one of StellarEvent or SolanaEvent must be set, and it must
+// be consistent with the events KinVersion.
+func (e *TransactionEvent) Validate() error {
+	if len(e.TxID) == 0 {
+		return errors.New("missing tx_id")
+	}
+
+	if e.StellarEvent != nil && e.SolanaEvent != nil {
+		return errors.New("only one of stellar_event or solana_event may be set")
+	}
+
+	switch e.KinVersion {
+	case 2, 3:
+		if e.StellarEvent == nil {
+			return errors.New("missing stellar_event")
+		}
+		if len(e.StellarEvent.EnvelopeXDR) == 0 {
+			return errors.New("stellar_event.envelope_xdr cannot have length of 0")
+		}
+	case 4:
+		if e.SolanaEvent == nil {
+			return errors.New("missing solana_event")
+		}
+		if len(e.SolanaEvent.Transaction) == 0 {
+			return errors.New("solana_event.transaction cannot have length of 0")
+		}
+	default:
+		return errors.Errorf("unsupported kin_version: %d", e.KinVersion)
+	}
+
+	return nil
+}
+
+// GetEnvelopeXDR returns the unmarshalled transaction envelope.
+func (e *StellarEvent) GetEnvelopeXDR() (*xdr.TransactionEnvelope, error) {
+	if len(e.EnvelopeXDR) == 0 {
+		return nil, errors.New("envelope_xdr cannot have length of 0")
+	}
+
+	envelope := &xdr.TransactionEnvelope{}
+	if _, err := xdr.Unmarshal(bytes.NewBuffer(e.EnvelopeXDR), envelope); err != nil {
+		return nil, errors.New("envelope_xdr was not a valid transaction envelope")
+	}
+
+	return envelope, nil
+}
+
+// GetResultXDR returns the unmarshalled transaction result.
+func (e *StellarEvent) GetResultXDR() (*xdr.TransactionResult, error) {
+	if len(e.ResultXDR) == 0 {
+		return nil, errors.New("result_xdr cannot have length of 0")
+	}
+
+	result := &xdr.TransactionResult{}
+	if _, err := xdr.Unmarshal(bytes.NewBuffer(e.ResultXDR), result); err != nil {
+		return nil, errors.New("result_xdr was not a valid transaction result")
+	}
+
+	return result, nil
+}
+
+// GetTransaction returns the unmarshalled solana transaction.
+func (e *SolanaEvent) GetTransaction() (*solana.Transaction, error) {
+	if len(e.Transaction) == 0 {
+		return nil, errors.New("transaction cannot have length of 0")
+	}
+
+	var txn solana.Transaction
+	if err := txn.Unmarshal(e.Transaction); err != nil {
+		return nil, errors.Wrap(err, "transaction was not a valid solana transaction")
+	}
+
+	return &txn, nil
+}
+
+// GetTransactionError returns the parsed transaction error, if any.
+func (e *SolanaEvent) GetTransactionError() (*solana.TransactionError, error) {
+	if len(e.TransactionErrorRaw) == 0 {
+		return nil, nil
+	}
+
+	var raw interface{}
+	if err := json.Unmarshal(e.TransactionErrorRaw, &raw); err != nil {
+		return nil, errors.Wrap(err, "invalid transaction_error_raw")
+	}
+
+	return solana.ParseTransactionError(raw)
+}
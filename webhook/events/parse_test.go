@@ -0,0 +1,73 @@
+package events
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kinecosystem/agora-common/solana"
+	"github.com/kinecosystem/agora-common/solana/memo"
+)
+
+func TestParseEvents(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	txn := solana.NewTransaction(pub, memo.Instruction("1-test"))
+	txErr := solana.NewTransactionError(solana.TransactionErrorAccountInUse)
+	rawErr, err := txErr.JSONString()
+	require.NoError(t, err)
+
+	events := []Event{
+		{
+			TransactionEvent: &TransactionEvent{
+				KinVersion: 4,
+				TxID:       txn.Signature(),
+				SolanaEvent: &SolanaEvent{
+					Transaction:         txn.Marshal(),
+					TransactionError:    txErr.Error(),
+					TransactionErrorRaw: []byte(rawErr),
+				},
+			},
+		},
+	}
+
+	body, err := json.Marshal(events)
+	require.NoError(t, err)
+
+	parsed, err := ParseEvents(body)
+	require.NoError(t, err)
+	require.Len(t, parsed, 1)
+
+	actual, err := parsed[0].TransactionEvent.SolanaEvent.GetTransaction()
+	require.NoError(t, err)
+	assert.Equal(t, txn.Marshal(), actual.Marshal())
+
+	actualErr, err := parsed[0].TransactionEvent.SolanaEvent.GetTransactionError()
+	require.NoError(t, err)
+	assert.Equal(t, txErr.Error(), actualErr.Error())
+}
+
+func TestParseEvents_Invalid(t *testing.T) {
+	for _, events := range [][]Event{
+		{{}},
+		{{TransactionEvent: &TransactionEvent{KinVersion: 4, SolanaEvent: &SolanaEvent{Transaction: []byte{1}}}}},
+		{{TransactionEvent: &TransactionEvent{KinVersion: 4, TxID: []byte{1}}}},
+		{{TransactionEvent: &TransactionEvent{KinVersion: 4, TxID: []byte{1}, SolanaEvent: &SolanaEvent{}}}},
+		{{TransactionEvent: &TransactionEvent{KinVersion: 3, TxID: []byte{1}, SolanaEvent: &SolanaEvent{Transaction: []byte{1}}}}},
+		{{TransactionEvent: &TransactionEvent{KinVersion: 3, TxID: []byte{1}, StellarEvent: &StellarEvent{}}}},
+		{{TransactionEvent: &TransactionEvent{KinVersion: 1, TxID: []byte{1}, StellarEvent: &StellarEvent{EnvelopeXDR: []byte{1}}}}},
+	} {
+		body, err := json.Marshal(events)
+		require.NoError(t, err)
+
+		_, err = ParseEvents(body)
+		assert.Error(t, err)
+	}
+
+	_, err := ParseEvents([]byte("{"))
+	assert.Error(t, err)
+}
@@ -2,9 +2,15 @@ package signtransaction
 
 import (
 	"bytes"
+	"crypto/ed25519"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/kinecosystem/go/xdr"
 	"github.com/pkg/errors"
+
+	commonpb "github.com/kinecosystem/agora-api/genproto/common/v3"
+
+	"github.com/kinecosystem/agora-common/solana"
 )
 
 // Request contains the body of a sign transaction request.
@@ -31,7 +37,7 @@ type SuccessResponse struct {
 	//
 	// The endpoint may or may not have provided a signature based on the
 	// provided transaction.
-	Signature []byte
+	Signature []byte `json:"signature"`
 }
 
 // ForbiddenResponse represents a 403 Forbidden response to a sign transaction request.
@@ -61,3 +67,109 @@ func (r *SuccessResponse) GetEnvelopeXDR() (*xdr.TransactionEnvelope, error) {
 
 	return e, nil
 }
+
+// GetSolanaTransaction returns the unmarshalled solana transaction.
+func (r *Request) GetSolanaTransaction() (*solana.Transaction, error) {
+	if len(r.SolanaTransaction) == 0 {
+		return nil, errors.New("solana_transaction cannot have length of 0")
+	}
+
+	txn := &solana.Transaction{}
+	if err := txn.Unmarshal(r.SolanaTransaction); err != nil {
+		return nil, errors.New("solana_transaction was not a valid solana transaction")
+	}
+
+	return txn, nil
+}
+
+// GetInvoiceList returns the unmarshalled invoice list, if one was provided.
+func (r *Request) GetInvoiceList() (*commonpb.InvoiceList, error) {
+	if len(r.InvoiceList) == 0 {
+		return nil, nil
+	}
+
+	il := &commonpb.InvoiceList{}
+	if err := proto.Unmarshal(r.InvoiceList, il); err != nil {
+		return nil, errors.New("invoice_list was not a valid invoice list")
+	}
+
+	return il, nil
+}
+
+// NewSolanaSuccessResponse returns a SuccessResponse containing the
+// (subsidizer) signature of the provided transaction.
+func NewSolanaSuccessResponse(txn solana.Transaction) *SuccessResponse {
+	return &SuccessResponse{
+		Signature: append([]byte(nil), txn.Signature()...),
+	}
+}
+
+// SignSolanaTransaction signs the solana transaction contained in the
+// request with the provided subsidizer key, returning a SuccessResponse
+// containing the resulting signature.
+func SignSolanaTransaction(r *Request, subsidizer ed25519.PrivateKey) (*SuccessResponse, error) {
+	txn, err := r.GetSolanaTransaction()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := txn.Sign(subsidizer); err != nil {
+		return nil, errors.Wrap(err, "failed to sign transaction")
+	}
+
+	return NewSolanaSuccessResponse(*txn), nil
+}
+
+// GetSignature returns the solana signature contained in the response.
+func (r *SuccessResponse) GetSignature() (sig solana.Signature, err error) {
+	if len(r.Signature) != len(sig) {
+		return sig, errors.Errorf("invalid signature length: %d", len(r.Signature))
+	}
+
+	copy(sig[:], r.Signature)
+	return sig, nil
+}
+
+// ApplySignature sets the signature contained in the response as the
+// first (subsidizer) signature of the provided transaction.
+func (r *SuccessResponse) ApplySignature(txn *solana.Transaction) error {
+	sig, err := r.GetSignature()
+	if err != nil {
+		return err
+	}
+
+	if len(txn.Signatures) == 0 {
+		return errors.New("transaction has no allocated signatures")
+	}
+	if len(txn.Message.Accounts) == 0 {
+		return errors.New("transaction has no accounts")
+	}
+	if len(txn.Message.Accounts[0]) != ed25519.PublicKeySize {
+		return errors.New("transaction has an invalid subsidizer account")
+	}
+
+	if !ed25519.Verify(txn.Message.Accounts[0], txn.Message.Marshal(), sig[:]) {
+		return errors.New("signature does not match transaction")
+	}
+
+	txn.Signatures[0] = sig
+	return nil
+}
+
+// NewForbiddenResponse returns a ForbiddenResponse with the provided message
+// and invoice errors.
+func NewForbiddenResponse(message string, invoiceErrors ...InvoiceError) *ForbiddenResponse {
+	return &ForbiddenResponse{
+		Message:       message,
+		InvoiceErrors: invoiceErrors,
+	}
+}
+
+// AddInvoiceError adds an error for the operation (or its corresponding invoice)
+// at the specified index.
+func (r *ForbiddenResponse) AddInvoiceError(operationIndex uint32, reason Reason) {
+	r.InvoiceErrors = append(r.InvoiceErrors, InvoiceError{
+		OperationIndex: operationIndex,
+		Reason:         reason,
+	})
+}
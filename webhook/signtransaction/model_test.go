@@ -0,0 +1,78 @@
+package signtransaction
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kinecosystem/agora-common/solana"
+	"github.com/kinecosystem/agora-common/solana/memo"
+)
+
+func TestSignSolanaTransaction(t *testing.T) {
+	subsidizer, subsidizerKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	txn := solana.NewTransaction(subsidizer, memo.Instruction("1-test"))
+	req := &Request{
+		KinVersion:        4,
+		SolanaTransaction: txn.Marshal(),
+	}
+
+	il, err := req.GetInvoiceList()
+	assert.NoError(t, err)
+	assert.Nil(t, il)
+
+	resp, err := SignSolanaTransaction(req, subsidizerKey)
+	require.NoError(t, err)
+
+	require.NoError(t, resp.ApplySignature(&txn))
+	assert.True(t, ed25519.Verify(subsidizer, txn.Message.Marshal(), txn.Signature()))
+
+	// Signature from a different key should not be applied
+	_, otherKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	other := NewSolanaSuccessResponse(txn)
+	copy(other.Signature, ed25519.Sign(otherKey, txn.Message.Marshal()))
+	assert.Error(t, other.ApplySignature(&txn))
+
+	_, err = SignSolanaTransaction(&Request{}, subsidizerKey)
+	assert.Error(t, err)
+
+	// Malformed transactions are rejected, rather than causing a panic.
+	malformed := txn
+	malformed.Message.Accounts = nil
+	assert.Error(t, resp.ApplySignature(&malformed))
+}
+
+func TestSuccessResponse_JSON(t *testing.T) {
+	resp := &SuccessResponse{Signature: []byte{1, 2, 3}}
+
+	// The signature is encoded with the lowercase key used by the other
+	// webhook models.
+	b, err := json.Marshal(resp)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"envelope_xdr":null,"signature":"AQID"}`, string(b))
+
+	// Responses encoded with the previous (field name) key are still
+	// decoded, as JSON keys are matched case insensitively.
+	for _, body := range []string{`{"signature":"AQID"}`, `{"Signature":"AQID"}`} {
+		var decoded SuccessResponse
+		require.NoError(t, json.Unmarshal([]byte(body), &decoded))
+		assert.Equal(t, resp.Signature, decoded.Signature)
+	}
+}
+
+func TestForbiddenResponse(t *testing.T) {
+	resp := NewForbiddenResponse("rejected", InvoiceError{OperationIndex: 0, Reason: AlreadyPaid})
+	resp.AddInvoiceError(2, SKUNotFound)
+
+	assert.Equal(t, "rejected", resp.Message)
+	assert.Equal(t, []InvoiceError{
+		{OperationIndex: 0, Reason: AlreadyPaid},
+		{OperationIndex: 2, Reason: SKUNotFound},
+	}, resp.InvoiceErrors)
+}